@@ -222,6 +222,9 @@ const docTemplate = `{
                 "password": {
                     "type": "string"
                 },
+                "totpCode": {
+                    "type": "string"
+                },
                 "username": {
                     "type": "string"
                 }
@@ -263,6 +266,9 @@ const docTemplate = `{
                 "token"
             ],
             "properties": {
+                "codeVerifier": {
+                    "type": "string"
+                },
                 "token": {
                     "type": "string"
                 }
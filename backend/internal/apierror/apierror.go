@@ -0,0 +1,202 @@
+// Package apierror - единый формат ошибок API.
+//
+// Обработчик не пишет ответ с ошибкой сам, а передаёт её в Abort;
+// Middleware один раз рендерит её в тело
+//
+//	{"error": "team is full", "code": "TEAM_FULL", "details": [...], "meta": {...}}
+//
+// error - текст для человека (его показывает фронтенд), code - стабильный код,
+// по которому ветвятся фронтенд и бот. Причина ошибки (Cause) в ответ не попадает,
+// только в лог.
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code - машиночитаемый код ошибки; значения не меняются между релизами
+type Code string
+
+// Общие коды
+const (
+	CodeBadRequest       Code = "BAD_REQUEST"
+	CodeInvalidID        Code = "INVALID_ID"
+	CodeValidationFailed Code = "VALIDATION_FAILED"
+	CodeUnauthorized     Code = "UNAUTHORIZED"
+	CodeTokenInvalid     Code = "TOKEN_INVALID"
+	CodeForbidden        Code = "FORBIDDEN"
+	CodeNotFound         Code = "NOT_FOUND"
+	CodeConflict         Code = "CONFLICT"
+	CodeTooManyRequests  Code = "TOO_MANY_REQUESTS"
+	CodeInternal         Code = "INTERNAL"
+	CodeUnavailable      Code = "UNAVAILABLE"
+)
+
+// Коды предметной области
+const (
+	CodeUserNotFound         Code = "USER_NOT_FOUND"
+	CodeUserErased           Code = "USER_ERASED"
+	CodeTeamNotFound         Code = "TEAM_NOT_FOUND"
+	CodeHackathonNotFound    Code = "HACKATHON_NOT_FOUND"
+	CodeInviteNotFound       Code = "INVITE_NOT_FOUND"
+	CodeJoinRequestNotFound  Code = "JOIN_REQUEST_NOT_FOUND"
+	CodeNotificationNotFound Code = "NOTIFICATION_NOT_FOUND"
+	CodeSessionNotFound      Code = "SESSION_NOT_FOUND"
+	CodeAPIKeyNotFound       Code = "API_KEY_NOT_FOUND"
+	CodeItemNotFound         Code = "ITEM_NOT_FOUND"
+	CodeCaseNotFound         Code = "CASE_NOT_FOUND"
+
+	CodeTeamFull             Code = "TEAM_FULL"
+	CodeTeamClosed           Code = "TEAM_CLOSED"
+	CodeAlreadyInTeam        Code = "ALREADY_IN_TEAM"
+	CodeNotTeamCaptain       Code = "NOT_TEAM_CAPTAIN"
+	CodeNotInvitee           Code = "NOT_INVITEE"
+	CodeCaptainCannotLeave   Code = "CAPTAIN_CANNOT_LEAVE"
+	CodeInviteAlreadySent    Code = "INVITE_ALREADY_SENT"
+	CodeJoinRequestPending   Code = "JOIN_REQUEST_PENDING"
+	CodeAlreadyProcessed     Code = "ALREADY_PROCESSED"
+	CodeAlreadySwiped        Code = "ALREADY_SWIPED"
+	CodeRegistrationClosed   Code = "REGISTRATION_CLOSED"
+	CodeAlreadyRegistered    Code = "ALREADY_REGISTERED"
+	CodeNotRegistered        Code = "NOT_REGISTERED"
+	CodeInvalidCredentials   Code = "INVALID_CREDENTIALS"
+	CodeTOTPRequired         Code = "TOTP_REQUIRED"
+	CodeAccountLocked        Code = "ACCOUNT_LOCKED"
+	CodeTokenReused          Code = "TOKEN_REUSED"
+	CodeSessionRevoked       Code = "SESSION_REVOKED"
+	CodeReadOnlySession      Code = "READ_ONLY_SESSION"
+	CodeInitDataInvalid      Code = "INIT_DATA_INVALID"
+	CodeAPIKeyInvalid        Code = "API_KEY_INVALID"
+	CodeServiceAuthFailed    Code = "SERVICE_AUTH_FAILED"
+	CodeConfirmationRequired Code = "CONFIRMATION_REQUIRED"
+	CodeCaseInvalid          Code = "CASE_INVALID"
+
+	CodeIdempotencyKeyInvalid Code = "IDEMPOTENCY_KEY_INVALID"
+	CodeIdempotencyKeyReused  Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInUse   Code = "IDEMPOTENCY_KEY_IN_USE"
+)
+
+// FieldError - ошибка в конкретном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error - ошибка API: HTTP статус, код, текст и необязательные детали
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	// Details - ошибки по полям запроса
+	Details []FieldError
+	// Meta - дополнительные данные для клиента (например, лимит команды)
+	Meta map[string]interface{}
+	// Cause - исходная ошибка; только для лога
+	Cause error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// WithDetails - копия ошибки с ошибками полей
+func (e *Error) WithDetails(details ...FieldError) *Error {
+	c := *e
+	c.Details = append(append([]FieldError(nil), e.Details...), details...)
+	return &c
+}
+
+// WithMeta - копия ошибки с дополнительным полем meta
+func (e *Error) WithMeta(key string, value interface{}) *Error {
+	c := *e
+	c.Meta = make(map[string]interface{}, len(e.Meta)+1)
+	for k, v := range e.Meta {
+		c.Meta[k] = v
+	}
+	c.Meta[key] = value
+	return &c
+}
+
+// WithCause - копия ошибки с причиной для лога
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.Cause = err
+	return &c
+}
+
+// Body - тело ответа
+type Body struct {
+	Error   string                 `json:"error"`
+	Code    Code                   `json:"code"`
+	Details []FieldError           `json:"details,omitempty"`
+	Meta    map[string]interface{} `json:"meta,omitempty"`
+}
+
+// Body - тело ответа для этой ошибки
+func (e *Error) Body() Body {
+	return Body{Error: e.Message, Code: e.Code, Details: e.Details, Meta: e.Meta}
+}
+
+// ============================================
+// CONSTRUCTORS
+// ============================================
+
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(code Code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+func Unauthorized(code Code, message string) *Error {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+func Forbidden(code Code, message string) *Error {
+	return New(http.StatusForbidden, code, message)
+}
+
+func NotFound(code Code, message string) *Error {
+	return New(http.StatusNotFound, code, message)
+}
+
+func Conflict(code Code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+// InvalidID - параметр пути или запроса не является ID
+func InvalidID(message string) *Error {
+	return BadRequest(CodeInvalidID, message)
+}
+
+// Validation - тело запроса не прошло проверку; details - по полям
+func Validation(details ...FieldError) *Error {
+	return New(http.StatusUnprocessableEntity, CodeValidationFailed, "validation failed").WithDetails(details...)
+}
+
+// Internal - сбой на стороне сервера; message безопасен для клиента, cause уходит в лог
+func Internal(message string, cause error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: message, Cause: cause}
+}
+
+// Unavailable - зависимость (БД, Redis) недоступна
+func Unavailable(message string, cause error) *Error {
+	return &Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable, Message: message, Cause: cause}
+}
+
+// From - ошибка API для произвольной ошибки; неизвестные становятся 500 без подробностей
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return Internal("internal server error", err)
+}
@@ -0,0 +1,152 @@
+package apierror
+
+import (
+	"backend/internal/logging"
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// render - Render в тестовом контексте; возвращает ответ и строки лога
+func render(t *testing.T, err error) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, "/x", nil)
+	c.Request = req.WithContext(logging.WithLogger(req.Context(), slog.New(slog.NewJSONHandler(&logs, nil))))
+	Render(c, err)
+	return w, logs.String()
+}
+
+func TestRender(t *testing.T) {
+	secret := errors.New("pq: password authentication failed for user app at 10.0.0.5")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantLogged bool
+	}{
+		{
+			name:       "not found",
+			err:        NotFound(CodeUserNotFound, "user not found"),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"user not found","code":"USER_NOT_FOUND"}`,
+		},
+		{
+			name:       "validation",
+			err:        Validation(FieldError{Field: "name", Message: "is required"}, FieldError{Field: "size", Message: "must be at least 2"}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"error":"validation failed","code":"VALIDATION_FAILED","details":[{"field":"name","message":"is required"},{"field":"size","message":"must be at least 2"}]}`,
+		},
+		{
+			name:       "meta",
+			err:        Conflict(CodeConflict, "team is full").WithMeta("maxSize", 4),
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"team is full","code":"CONFLICT","meta":{"maxSize":4}}`,
+		},
+		{
+			name:       "wrapped api error",
+			err:        fmt.Errorf("handler: %w", Forbidden(CodeForbidden, "access denied")),
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"error":"access denied","code":"FORBIDDEN"}`,
+		},
+		{
+			name:       "cause of a client error",
+			err:        NotFound(CodeUserNotFound, "user not found").WithCause(secret),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"user not found","code":"USER_NOT_FOUND"}`,
+		},
+		{
+			name:       "internal",
+			err:        Internal("failed to load user", secret),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"failed to load user","code":"INTERNAL"}`,
+			wantLogged: true,
+		},
+		{
+			name:       "unavailable",
+			err:        Unavailable("failed to check session", secret),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"error":"failed to check session","code":"UNAVAILABLE"}`,
+			wantLogged: true,
+		},
+		{
+			name:       "plain error",
+			err:        secret,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"internal server error","code":"INTERNAL"}`,
+			wantLogged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, logs := render(t, tt.err)
+			if w.Code != tt.wantStatus || w.Body.String() != tt.wantBody {
+				t.Fatalf("got %d %s, want %d %s", w.Code, w.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+			// Причина уходит только в лог и только для 5xx
+			if strings.Contains(w.Body.String(), "10.0.0.5") {
+				t.Fatalf("cause leaked to the client: %s", w.Body.String())
+			}
+			if logged := strings.Contains(logs, "10.0.0.5"); logged != tt.wantLogged {
+				t.Fatalf("cause logged %v, want %v: %s", logged, tt.wantLogged, logs)
+			}
+		})
+	}
+}
+
+func TestCopiesDoNotShareState(t *testing.T) {
+	base := BadRequest(CodeBadRequest, "bad request").WithMeta("a", 1).WithDetails(FieldError{Field: "a"})
+
+	withMeta := base.WithMeta("b", 2)
+	withDetails := base.WithDetails(FieldError{Field: "b"})
+	withCause := base.WithCause(errors.New("cause"))
+
+	if len(base.Meta) != 1 || len(base.Details) != 1 || base.Cause != nil {
+		t.Fatalf("base modified: %+v", base)
+	}
+	if len(withMeta.Meta) != 2 || len(withDetails.Details) != 2 || withCause.Cause == nil {
+		t.Fatalf("copies %+v %+v %+v", withMeta, withDetails, withCause)
+	}
+	if !errors.Is(withCause, withCause.Cause) {
+		t.Fatal("cause is not unwrapped")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/abort", func(c *gin.Context) {
+		Abort(c, BadRequest(CodeBadRequest, "first"))
+		Abort(c, NotFound(CodeNotFound, "last"))
+	})
+	// Ответ уже записан - ошибка только для лога, тело не портим
+	r.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusOK, "done")
+		Abort(c, Internal("late", errors.New("late")))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/abort", nil))
+	if w.Code != http.StatusNotFound || w.Body.String() != `{"error":"last","code":"NOT_FOUND"}` {
+		t.Fatalf("abort: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/written", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "done" {
+		t.Fatalf("written: %d %s", w.Code, w.Body.String())
+	}
+}
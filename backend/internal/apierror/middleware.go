@@ -0,0 +1,42 @@
+package apierror
+
+import (
+	"backend/internal/logging"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Abort - прервать обработку запроса с ошибкой; ответ запишет Middleware
+func Abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// Middleware - рендерит ошибку, переданную через Abort (последнюю, если их несколько).
+// Ошибки 5xx логируются с причиной; клиент видит только Message.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		Render(c, c.Errors.Last().Err)
+	}
+}
+
+// Render - сразу записать ответ с ошибкой (для кода вне цепочки Middleware, например recovery)
+func Render(c *gin.Context, err error) {
+	apiErr := From(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).LogAttrs(ctx, slog.LevelError, "request failed",
+			slog.String("code", string(apiErr.Code)),
+			slog.String("message", apiErr.Message),
+			logging.Err(apiErr.Cause),
+		)
+	}
+	c.AbortWithStatusJSON(apiErr.Status, apiErr.Body())
+}
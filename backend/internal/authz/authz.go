@@ -0,0 +1,39 @@
+package authz
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Проверки доступа на уровне ресурсов. RequireRoleMiddleware отвечает только
+// на вопрос "какая роль нужна для группы маршрутов", а здесь решается, можно ли
+// конкретному пользователю трогать конкретный хакатон.
+
+var (
+	ErrNotFound  = errors.New("resource not found")
+	ErrForbidden = errors.New("access denied")
+)
+
+// Actor - кто выполняет действие
+type Actor struct {
+	UserID int64
+	Role   models.UserRole
+}
+
+// IsAdmin - администратор имеет глобальный доступ
+func (a Actor) IsAdmin() bool {
+	return a.Role == models.RoleAdmin
+}
+
+// ActorFromContext - собрать Actor из контекста, заполненного JWTAuthMiddleware
+func ActorFromContext(c *gin.Context) (Actor, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return Actor{}, false
+	}
+	role, _ := middleware.GetUserRole(c)
+	return Actor{UserID: userID, Role: models.UserRole(role)}, true
+}
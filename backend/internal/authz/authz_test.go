@@ -0,0 +1,78 @@
+package authz
+
+import (
+	"backend/internal/models"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+func TestCanManageHackathon(t *testing.T) {
+	hackathon := &models.Hackathon{ID: 1, CreatorID: 10}
+
+	tests := []struct {
+		name  string
+		actor Actor
+		want  bool
+	}{
+		{"admin manages any hackathon", Actor{UserID: 99, Role: models.RoleAdmin}, true},
+		{"creator manages own hackathon", Actor{UserID: 10, Role: models.RoleHackathonCreator}, true},
+		{"creator cannot manage another's hackathon", Actor{UserID: 11, Role: models.RoleHackathonCreator}, false},
+		{"owner id without creator role", Actor{UserID: 10, Role: models.RoleUser}, false},
+		{"participant", Actor{UserID: 12, Role: models.RoleUser}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanManageHackathon(tt.actor, hackathon); got != tt.want {
+				t.Fatalf("CanManageHackathon = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// dryRunDB - GORM без подключения: запросы только собираются в SQL
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestScopeManaged(t *testing.T) {
+	db := dryRunDB(t)
+	query := func(actor Actor) string {
+		return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			var hackathons []models.Hackathon
+			return tx.Scopes(ScopeManaged(actor)).Find(&hackathons)
+		})
+	}
+
+	if sql := query(Actor{UserID: 1, Role: models.RoleAdmin}); strings.Contains(sql, "creator_id") {
+		t.Errorf("admin query is scoped: %s", sql)
+	}
+	if sql := query(Actor{UserID: 10, Role: models.RoleHackathonCreator}); !strings.Contains(sql, "creator_id = 10") {
+		t.Errorf("creator query is not scoped to own hackathons: %s", sql)
+	}
+}
+
+func TestActorFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	if _, ok := ActorFromContext(c); ok {
+		t.Fatal("actor found in an anonymous request")
+	}
+
+	c.Set("user_id", int64(10))
+	c.Set("user_role", "hackathon_creator")
+	actor, ok := ActorFromContext(c)
+	if !ok || actor.UserID != 10 || actor.Role != models.RoleHackathonCreator || actor.IsAdmin() {
+		t.Fatalf("unexpected actor %+v", actor)
+	}
+}
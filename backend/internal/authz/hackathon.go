@@ -0,0 +1,79 @@
+package authz
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// CanManageHackathon - админ управляет любым хакатоном, создатель - только своими
+func CanManageHackathon(actor Actor, hackathon *models.Hackathon) bool {
+	if actor.IsAdmin() {
+		return true
+	}
+	return actor.Role == models.RoleHackathonCreator && hackathon.CreatorID == actor.UserID
+}
+
+// HackathonPolicy - проверки доступа к хакатонам, которым нужна БД
+type HackathonPolicy struct {
+	db *gorm.DB
+}
+
+func NewHackathonPolicy(db *gorm.DB) *HackathonPolicy {
+	return &HackathonPolicy{db: db}
+}
+
+// LoadManaged - загрузить хакатон, если actor может им управлять.
+// Возвращает ErrNotFound или ErrForbidden.
+func (p *HackathonPolicy) LoadManaged(ctx context.Context, actor Actor, hackathonID int64) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := p.db.WithContext(ctx).First(&hackathon, hackathonID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load hackathon: %w", err)
+	}
+
+	if !CanManageHackathon(actor, &hackathon) {
+		return nil, ErrForbidden
+	}
+	return &hackathon, nil
+}
+
+// ScopeManaged - ограничить запрос к hackathons теми, которыми управляет actor
+func ScopeManaged(actor Actor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if actor.IsAdmin() {
+			return db
+		}
+		return db.Where("creator_id = ?", actor.UserID)
+	}
+}
+
+// NonParticipants - какие из userIDs не зарегистрированы на хакатон
+func (p *HackathonPolicy) NonParticipants(ctx context.Context, hackathonID int64, userIDs []int64) ([]int64, error) {
+	var registered []int64
+	err := p.db.WithContext(ctx).
+		Model(&models.HackathonParticipant{}).
+		Where("hackathon_id = ? AND user_id IN ?", hackathonID, userIDs).
+		Pluck("user_id", &registered).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check participants: %w", err)
+	}
+
+	known := make(map[int64]bool, len(registered))
+	for _, id := range registered {
+		known[id] = true
+	}
+
+	var missing []int64
+	for _, id := range userIDs {
+		if !known[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ============================================
+// ADMIN ACCOUNTS
+// ============================================
+
+func adminUsage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  backend admin create --login <login> (--telegram-id <id> | --user-id <id>) [--name <name>]
+  backend admin passwd --login <login>
+  backend admin totp --login <login> [--disable]
+  backend admin unlock --login <login>
+
+The password is read from stdin, so it never ends up in shell history:
+  echo "$ADMIN_PASSWORD" | backend admin create --login alice --telegram-id 123456`)
+}
+
+func runAdmin(args []string) error {
+	if len(args) == 0 {
+		adminUsage()
+		return errors.New("admin command required")
+	}
+
+	switch args[0] {
+	case "create":
+		return adminCreate(args[1:])
+	case "passwd":
+		return adminPasswd(args[1:])
+	case "totp":
+		return adminTOTP(args[1:])
+	case "unlock":
+		return adminUnlock(args[1:])
+	case "help", "-h", "--help":
+		adminUsage()
+		return nil
+	default:
+		adminUsage()
+		return fmt.Errorf("unknown admin command %q", args[0])
+	}
+}
+
+// adminCreate - выдать пользователю роль admin и создать ему учётные данные.
+// Если пользователя с таким telegram id ещё нет, он будет создан.
+func adminCreate(args []string) error {
+	fs := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	login := fs.String("login", "", "admin login")
+	telegramID := fs.Int64("telegram-id", 0, "telegram id of the admin")
+	userID := fs.Int64("user-id", 0, "id of an existing user")
+	name := fs.String("name", "", "username for a newly created user")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("--login is required")
+	}
+	if (*telegramID == 0) == (*userID == 0) {
+		return errors.New("exactly one of --telegram-id or --user-id is required")
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := connectDB(); err != nil {
+		return err
+	}
+	userRepo := repositories.NewUserRepository(database.DB)
+	adminAuth := services.NewAdminAuthService(repositories.NewAdminRepository(database.DB))
+
+	var user *models.User
+	if *userID != 0 {
+		user, err = userRepo.GetByID(ctx, *userID)
+		if err != nil {
+			return err
+		}
+	} else {
+		user, err = userRepo.GetByTelegramID(ctx, *telegramID)
+		if err != nil {
+			username := *name
+			if username == "" {
+				username = *login
+			}
+			user, err = userRepo.CreateOrUpdate(ctx, *telegramID, username)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Сначала учётные данные: без роли admin по ним всё равно не войти
+	if _, err := adminAuth.CreateAdmin(ctx, user.ID, *login, password); err != nil {
+		return err
+	}
+
+	if user.Role != models.RoleAdmin {
+		if err := userRepo.UpdateRole(ctx, user.ID, models.RoleAdmin); err != nil {
+			return fmt.Errorf("failed to grant admin role: %w", err)
+		}
+	}
+
+	fmt.Printf("admin %q created for user %d (telegram id %d)\n", *login, user.ID, user.TelegramUserID)
+	return nil
+}
+
+func adminPasswd(args []string) error {
+	fs := flag.NewFlagSet("admin passwd", flag.ContinueOnError)
+	login := fs.String("login", "", "admin login")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("--login is required")
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	adminAuth := services.NewAdminAuthService(repositories.NewAdminRepository(database.DB))
+	if err := adminAuth.SetPassword(context.Background(), *login, password); err != nil {
+		return err
+	}
+
+	fmt.Printf("password for %q updated\n", *login)
+	return nil
+}
+
+func adminTOTP(args []string) error {
+	fs := flag.NewFlagSet("admin totp", flag.ContinueOnError)
+	login := fs.String("login", "", "admin login")
+	disable := fs.Bool("disable", false, "turn off the second factor")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("--login is required")
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	adminAuth := services.NewAdminAuthService(repositories.NewAdminRepository(database.DB))
+	ctx := context.Background()
+
+	if *disable {
+		if err := adminAuth.DisableTOTP(ctx, *login); err != nil {
+			return err
+		}
+		fmt.Printf("totp disabled for %q\n", *login)
+		return nil
+	}
+
+	uri, err := adminAuth.EnableTOTP(ctx, *login)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("totp enabled for %q, add this to your authenticator app:\n%s\n", *login, uri)
+	return nil
+}
+
+func adminUnlock(args []string) error {
+	fs := flag.NewFlagSet("admin unlock", flag.ContinueOnError)
+	login := fs.String("login", "", "admin login")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("--login is required")
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	adminAuth := services.NewAdminAuthService(repositories.NewAdminRepository(database.DB))
+	if err := adminAuth.Unlock(context.Background(), *login); err != nil {
+		return err
+	}
+
+	fmt.Printf("admin %q unlocked\n", *login)
+	return nil
+}
+
+// connectDB - подключиться к базе с конфигурацией из CONFIG_FILE и окружения
+func connectDB() error {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := database.Connect(cfg.Database); err != nil {
+		return fmt.Errorf("failed to connect PostgreSQL: %w", err)
+	}
+	return nil
+}
+
+// readPassword - прочитать пароль из первой строки stdin
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < services.AdminMinPasswordLength {
+		return "", services.ErrAdminPasswordTooShort
+	}
+	return password, nil
+}
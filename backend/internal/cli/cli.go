@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// Run - выполнить подкоманду бинарника (например, `backend admin create ...`).
+// Без аргументов main запускает HTTP сервер, сюда попадаем только с подкомандой.
+func Run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "admin":
+		err = runAdmin(args[1:])
+	case "migrate":
+		err = runMigrate(args[1:])
+	case "healthcheck":
+		err = runHealthcheck(args[1:])
+	case "help", "-h", "--help":
+		usage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		usage()
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  backend [flags]         start HTTP server (see "backend -h" for flags)
+  backend admin <command> manage admin panel accounts (see "backend admin help")
+  backend migrate <cmd>   apply, roll back or inspect schema migrations (see "backend migrate help")
+  backend healthcheck     exit 0 if the running server is ready (GET /readyz), for docker healthcheck`)
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"backend/internal/database"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// ============================================
+// SCHEMA MIGRATIONS
+// ============================================
+
+func migrateUsage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  backend migrate up [--to <version>] [--dry-run]
+  backend migrate down [--steps <n>] [--dry-run]
+  backend migrate status
+
+Migrations are embedded from backend/migrations and tracked in the schema_migrations table.
+up and down take a PostgreSQL advisory lock, so replicas never migrate concurrently.`)
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		migrateUsage()
+		return errors.New("migrate command required")
+	}
+
+	switch args[0] {
+	case "up":
+		return migrateUp(args[1:])
+	case "down":
+		return migrateDown(args[1:])
+	case "status":
+		return migrateStatus(args[1:])
+	case "help", "-h", "--help":
+		migrateUsage()
+		return nil
+	default:
+		migrateUsage()
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
+
+// migrateUp - применить неприменённые миграции
+func migrateUp(args []string) error {
+	fs := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+	to := fs.Int64("to", 0, "apply migrations up to this version (default: all)")
+	dryRun := fs.Bool("dry-run", false, "print what would be applied without changing the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	migrator, err := newMigrator(*dryRun)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(context.Background(), *to)
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("schema is up to date")
+	} else if !*dryRun {
+		fmt.Printf("applied %d migration(s)\n", len(applied))
+	}
+	return nil
+}
+
+// migrateDown - откатить последние применённые миграции
+func migrateDown(args []string) error {
+	fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	dryRun := fs.Bool("dry-run", false, "print what would be rolled back without changing the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	migrator, err := newMigrator(*dryRun)
+	if err != nil {
+		return err
+	}
+	rolledBack, err := migrator.Down(context.Background(), *steps)
+	if err != nil {
+		return err
+	}
+
+	if len(rolledBack) == 0 {
+		fmt.Println("nothing to roll back")
+	} else if !*dryRun {
+		fmt.Printf("rolled back %d migration(s)\n", len(rolledBack))
+	}
+	return nil
+}
+
+// migrateStatus - таблица версий: применена ли, когда, не изменён ли файл
+func migrateStatus(args []string) error {
+	fs := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	migrator, err := newMigrator(false)
+	if err != nil {
+		return err
+	}
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Local().Format(time.DateTime)
+		}
+		var note string
+		switch {
+		case s.Missing:
+			note = "file missing"
+		case s.Modified:
+			note = "modified after apply"
+		case !s.Reversible():
+			note = "irreversible"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+	}
+	return w.Flush()
+}
+
+func newMigrator(dryRun bool) (*database.Migrator, error) {
+	if err := connectDB(); err != nil {
+		return nil, err
+	}
+	migrator, err := database.NewSchemaMigrator()
+	if err != nil {
+		return nil, err
+	}
+	migrator.DryRun = dryRun
+	migrator.Logf = func(format string, args ...interface{}) {
+		fmt.Printf(format+"\n", args...)
+	}
+	return migrator, nil
+}
@@ -0,0 +1,474 @@
+// Package config - типизированная конфигурация backend.
+//
+// Порядок источников (каждый следующий перекрывает предыдущий):
+// значения по умолчанию, YAML файл (--config или CONFIG_FILE), переменные окружения, флаги.
+// Load проверяет результат и в production отказывается стартовать с небезопасными значениями.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Значения по умолчанию, которые годятся только для локального запуска
+const (
+	defaultPostgresPassword = "postgres"
+	minHMACSecretLength     = 32
+)
+
+// Secret - строка, которая не попадает в логи: fmt и yaml выводят её как "***"
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "***"
+}
+
+func (s Secret) GoString() string { return strconv.Quote(s.String()) }
+
+// MarshalYAML - при выводе конфига секрет тоже скрыт
+func (s Secret) MarshalYAML() (interface{}, error) { return s.String(), nil }
+
+// Value - настоящее значение секрета
+func (s Secret) Value() string { return string(s) }
+
+type Config struct {
+	// Env - development или production
+	Env string `yaml:"env"`
+
+	Log       LogConfig       `yaml:"log"`
+	HTTP      HTTPConfig      `yaml:"http"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Redis     RedisConfig     `yaml:"redis"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Telegram  TelegramConfig  `yaml:"telegram"`
+	Services  ServicesConfig  `yaml:"services"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+}
+
+type LogConfig struct {
+	// Level - debug, info, warn или error
+	Level string `yaml:"level"`
+	// Format - text (удобно читать) или json (для сборщиков логов)
+	Format string `yaml:"format"`
+}
+
+type HTTPConfig struct {
+	Addr string `yaml:"addr"`
+
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	// ShutdownTimeout - сколько ждать запросы и фоновые задачи при остановке
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// TrustedProxies - адреса и подсети прокси, которым верим в X-Forwarded-For.
+	// От них зависит IP клиента в логах, блокировке входа и ограничении частоты.
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+type MetricsConfig struct {
+	// Addr - отдельный порт для /metrics, чтобы метрики не торчали наружу вместе с API;
+	// пустая строка отключает метрики
+	Addr string `yaml:"addr"`
+}
+
+type RateLimitConfig struct {
+	// Enabled - ограничивать частоту запросов к swipe, invites, token и открытию кейсов;
+	// выключать только для нагрузочных тестов
+	Enabled bool `yaml:"enabled"`
+}
+
+// Экспортёры трейсов
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+type TracingConfig struct {
+	// Exporter - none, otlp (OTLP/HTTP коллектор) или stdout (отладка)
+	Exporter string `yaml:"exporter"`
+	// Endpoint - адрес коллектора для otlp, например otel-collector:4318
+	Endpoint string `yaml:"endpoint"`
+	// Insecure - otlp без TLS (локальный коллектор)
+	Insecure bool `yaml:"insecure"`
+	// ServiceName - service.name в трейсах
+	ServiceName string `yaml:"serviceName"`
+	// SampleRatio - доля трейсов, которые начинаются здесь (0..1); входящий traceparent решает сам
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password Secret `yaml:"password"`
+	Name     string `yaml:"name"`
+
+	// MigrateOnStart - применять миграции при старте под advisory lock
+	MigrateOnStart bool `yaml:"migrateOnStart"`
+	// AutoMigrate - GORM AutoMigrate поверх миграций, только для разработки
+	AutoMigrate bool `yaml:"autoMigrate"`
+}
+
+// DSN - строка подключения для драйвера postgres
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
+		d.Host, d.User, d.Password.Value(), d.Name, d.Port,
+	)
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr"`
+	User     string `yaml:"user"`
+	Password Secret `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+type JWTConfig struct {
+	// Secret - общий HS256 секрет (kid "default")
+	Secret Secret `yaml:"secret"`
+	// Keys - набор ключей kid=ALG:путь,... для ротации
+	Keys string `yaml:"keys"`
+	// ActiveKID - ключ для подписи новых токенов (по умолчанию первый из Keys)
+	ActiveKID string `yaml:"activeKid"`
+
+	AccessTTL  time.Duration `yaml:"accessTtl"`
+	RefreshTTL time.Duration `yaml:"refreshTtl"`
+}
+
+type TelegramConfig struct {
+	// BotToken - токен бота для проверки подписи initData
+	BotToken Secret `yaml:"botToken"`
+	// AuthMaxAge - насколько старым может быть auth_date в initData
+	AuthMaxAge time.Duration `yaml:"authMaxAge"`
+}
+
+type ServicesConfig struct {
+	// BotSecret - секрет, которым tgbot подписывает запросы к /api/bot
+	BotSecret Secret `yaml:"botSecret"`
+	// Secrets - секреты остальных внутренних сервисов: ID сервиса -> секрет
+	Secrets map[string]Secret `yaml:"secrets"`
+}
+
+// ServiceSecrets - все секреты сервисов; бот под ID "tgbot"
+func (s ServicesConfig) ServiceSecrets() map[string][]byte {
+	secrets := make(map[string][]byte, len(s.Secrets)+1)
+	for id, secret := range s.Secrets {
+		secrets[id] = []byte(secret)
+	}
+	if s.BotSecret != "" {
+		secrets["tgbot"] = []byte(s.BotSecret)
+	}
+	return secrets
+}
+
+// IsProduction - запущен ли backend в production
+func (c *Config) IsProduction() bool { return c.Env == EnvProduction }
+
+// Default - конфигурация для локального запуска через docker-compose
+func Default() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		Log: LogConfig{Level: "info", Format: "text"},
+		HTTP: HTTPConfig{
+			Addr:              "0.0.0.0:8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      60 * time.Second, // выгрузки участников хакатона бывают большими
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+			// nginx фронтенда в сети docker
+			TrustedProxies: []string{"127.0.0.1/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+		},
+		Database: DatabaseConfig{
+			Host:     "postgres",
+			Port:     5432,
+			User:     "postgres",
+			Password: defaultPostgresPassword,
+			Name:     "itam_hackaton",
+		},
+		Redis: RedisConfig{Addr: "redis:6379"},
+		JWT: JWTConfig{
+			AccessTTL:  time.Hour,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		Telegram: TelegramConfig{AuthMaxAge: 24 * time.Hour},
+		Metrics:  MetricsConfig{Addr: "0.0.0.0:9090"},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			ServiceName: "itam-hackaton-backend",
+			SampleRatio: 1,
+		},
+		RateLimit: RateLimitConfig{Enabled: true},
+	}
+}
+
+// Load - собрать конфигурацию из файла, окружения и флагов args и проверить её.
+// args - аргументы командной строки без имени программы; nil - без флагов (для CLI подкоманд).
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("backend", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML config file (env CONFIG_FILE)")
+	env := fs.String("env", "", "environment: development or production (env APP_ENV)")
+	addr := fs.String("addr", "", "HTTP listen address (env HTTP_ADDR)")
+	migrateOnStart := fs.Bool("migrate-on-start", false, "apply pending migrations on start (env MIGRATE_ON_START)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	cfg := Default()
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	// Флаги перекрывают всё остальное, но только если их задали явно
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "env":
+			cfg.Env = *env
+		case "addr":
+			cfg.HTTP.Addr = *addr
+		case "migrate-on-start":
+			cfg.Database.MigrateOnStart = *migrateOnStart
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv - переменные окружения; имена те же, что использовались до появления конфига
+func (c *Config) loadEnv() error {
+	var errs []error
+	str := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	secret := func(key string, dst *Secret) {
+		if v := os.Getenv(key); v != "" {
+			*dst = Secret(v)
+		}
+	}
+	integer := func(key string, dst *int) {
+		if v := os.Getenv(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", key, err))
+				return
+			}
+			*dst = n
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v := os.Getenv(key); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", key, err))
+				return
+			}
+			*dst = b
+		}
+	}
+	float := func(key string, dst *float64) {
+		if v := os.Getenv(key); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", key, err))
+				return
+			}
+			*dst = f
+		}
+	}
+	duration := func(key string, dst *time.Duration) {
+		if v := os.Getenv(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", key, err))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	str("APP_ENV", &c.Env)
+	str("LOG_LEVEL", &c.Log.Level)
+	str("LOG_FORMAT", &c.Log.Format)
+	str("HTTP_ADDR", &c.HTTP.Addr)
+	duration("HTTP_READ_TIMEOUT", &c.HTTP.ReadTimeout)
+	duration("HTTP_WRITE_TIMEOUT", &c.HTTP.WriteTimeout)
+	duration("HTTP_IDLE_TIMEOUT", &c.HTTP.IdleTimeout)
+	duration("SHUTDOWN_TIMEOUT", &c.HTTP.ShutdownTimeout)
+	// TRUSTED_PROXIES=подсеть,...; пустое значение - не доверять никому
+	if v, ok := os.LookupEnv("TRUSTED_PROXIES"); ok {
+		c.HTTP.TrustedProxies = nil
+		for _, proxy := range strings.Split(v, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				c.HTTP.TrustedProxies = append(c.HTTP.TrustedProxies, proxy)
+			}
+		}
+	}
+
+	str("POSTGRES_HOST", &c.Database.Host)
+	integer("POSTGRES_PORT", &c.Database.Port)
+	str("POSTGRES_USER", &c.Database.User)
+	secret("POSTGRES_PASSWORD", &c.Database.Password)
+	str("POSTGRES_DB", &c.Database.Name)
+	boolean("MIGRATE_ON_START", &c.Database.MigrateOnStart)
+	boolean("DB_AUTO_MIGRATE", &c.Database.AutoMigrate)
+
+	str("REDISADDR", &c.Redis.Addr)
+	str("REDISUSER", &c.Redis.User)
+	secret("REDISPASSWORD", &c.Redis.Password)
+	integer("REDISDB", &c.Redis.DB)
+
+	secret("JWT_SECRET", &c.JWT.Secret)
+	str("JWT_KEYS", &c.JWT.Keys)
+	str("JWT_ACTIVE_KID", &c.JWT.ActiveKID)
+	duration("JWT_ACCESS_TTL", &c.JWT.AccessTTL)
+	duration("JWT_REFRESH_TTL", &c.JWT.RefreshTTL)
+
+	secret("TELOXIDE_TOKEN", &c.Telegram.BotToken)
+	duration("TELEGRAM_AUTH_MAX_AGE", &c.Telegram.AuthMaxAge)
+
+	secret("BOT_SERVICE_SECRET", &c.Services.BotSecret)
+	// SERVICE_SECRETS=id:секрет,... дополняет секреты из файла
+	for _, entry := range strings.Split(os.Getenv("SERVICE_SECRETS"), ",") {
+		id, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || value == "" {
+			continue
+		}
+		if c.Services.Secrets == nil {
+			c.Services.Secrets = map[string]Secret{}
+		}
+		c.Services.Secrets[id] = Secret(value)
+	}
+
+	if v, ok := os.LookupEnv("METRICS_ADDR"); ok {
+		c.Metrics.Addr = v
+	}
+
+	// Стандартные имена переменных OpenTelemetry
+	str("OTEL_TRACES_EXPORTER", &c.Tracing.Exporter)
+	str("OTEL_EXPORTER_OTLP_ENDPOINT", &c.Tracing.Endpoint)
+	boolean("OTEL_EXPORTER_OTLP_INSECURE", &c.Tracing.Insecure)
+	str("OTEL_SERVICE_NAME", &c.Tracing.ServiceName)
+	float("OTEL_TRACES_SAMPLER_ARG", &c.Tracing.SampleRatio)
+
+	boolean("RATE_LIMIT_ENABLED", &c.RateLimit.Enabled)
+
+	return errors.Join(errs...)
+}
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// Validate - проверить конфигурацию; в production дополнительно запрещены небезопасные значения
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Env == EnvDevelopment || c.Env == EnvProduction, "env must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Env)
+	check(validLogLevels[strings.ToLower(c.Log.Level)], "log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Log.Format == "text" || c.Log.Format == "json", "log.format must be text or json, got %q", c.Log.Format)
+	check(c.HTTP.Addr != "", "http.addr is required")
+	check(c.HTTP.ReadHeaderTimeout > 0 && c.HTTP.ReadTimeout > 0 && c.HTTP.WriteTimeout > 0 && c.HTTP.IdleTimeout > 0,
+		"http timeouts must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdownTimeout must be positive")
+	check(c.Metrics.Addr != c.HTTP.Addr, "metrics.addr must differ from http.addr")
+	check(c.Tracing.Exporter == TracingExporterNone || c.Tracing.Exporter == TracingExporterOTLP || c.Tracing.Exporter == TracingExporterStdout,
+		"tracing.exporter must be none, otlp or stdout, got %q", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio must be between 0 and 1")
+	if c.Tracing.Exporter == TracingExporterOTLP {
+		check(c.Tracing.Endpoint != "", "tracing.endpoint is required for the otlp exporter")
+	}
+
+	check(c.Database.Host != "", "database.host is required")
+	check(c.Database.Port > 0 && c.Database.Port < 65536, "database.port must be between 1 and 65535")
+	check(c.Database.User != "", "database.user is required")
+	check(c.Database.Name != "", "database.name is required")
+	check(c.Redis.Addr != "", "redis.addr is required")
+
+	check(c.JWT.AccessTTL > 0, "jwt.accessTtl must be positive")
+	check(c.JWT.RefreshTTL > c.JWT.AccessTTL, "jwt.refreshTtl must be longer than jwt.accessTtl")
+	check(c.Telegram.AuthMaxAge > 0, "telegram.authMaxAge must be positive")
+	if c.JWT.Secret != "" {
+		check(len(c.JWT.Secret) >= minHMACSecretLength, "jwt.secret must be at least %d bytes", minHMACSecretLength)
+	}
+	serviceSecrets := c.Services.ServiceSecrets()
+	serviceIDs := make([]string, 0, len(serviceSecrets))
+	for id := range serviceSecrets {
+		serviceIDs = append(serviceIDs, id)
+	}
+	sort.Strings(serviceIDs)
+	for _, id := range serviceIDs {
+		check(len(serviceSecrets[id]) >= minHMACSecretLength, "services: secret of %q must be at least %d bytes", id, minHMACSecretLength)
+	}
+
+	if c.IsProduction() {
+		check(c.Database.Password != "" && c.Database.Password != defaultPostgresPassword,
+			"production: database.password must be set to a non-default value")
+		check(c.JWT.Secret != "" || c.JWT.Keys != "",
+			"production: jwt.secret or jwt.keys is required, an ephemeral signing key would log everyone out on restart")
+		check(!c.Database.AutoMigrate, "production: database.autoMigrate is for development only")
+		check(c.Telegram.BotToken != "", "production: telegram.botToken is required, without it no Telegram login can be verified")
+		check(len(serviceSecrets) > 0, "production: services.botSecret or services.secrets is required, without it /api/bot rejects every request")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// String - конфигурация для лога при старте; секреты скрыты
+func (c *Config) String() string {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Sprintf("<config: %v>", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var longSecret = Secret(strings.Repeat("s", minHMACSecretLength))
+
+// productionConfig - production-конфигурация, которая проходит Validate
+func productionConfig() *Config {
+	cfg := Default()
+	cfg.Env = EnvProduction
+	cfg.Database.Password = "prod-password"
+	cfg.JWT.Secret = longSecret
+	cfg.Telegram.BotToken = "123:abc"
+	cfg.Services.BotSecret = longSecret
+	return cfg
+}
+
+func TestValidateDefaults(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("default config is invalid: %v", err)
+	}
+	if err := productionConfig().Validate(); err != nil {
+		t.Fatalf("production config is invalid: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		match  string
+	}{
+		{"unknown env", func(c *Config) { c.Env = "staging" }, "env must be"},
+		{"refresh shorter than access", func(c *Config) { c.JWT.RefreshTTL = c.JWT.AccessTTL }, "jwt.refreshTtl"},
+		{"metrics on the http address", func(c *Config) { c.Metrics.Addr = c.HTTP.Addr }, "metrics.addr"},
+		{"otlp without endpoint", func(c *Config) { c.Tracing.Exporter = TracingExporterOTLP }, "tracing.endpoint"},
+		{"short jwt secret", func(c *Config) { c.JWT.Secret = "short" }, "jwt.secret"},
+		{"short bot secret", func(c *Config) { c.Services.BotSecret = "short" }, `secret of "tgbot"`},
+		{"short service secret", func(c *Config) { c.Services.Secrets = map[string]Secret{"crm": "short"} }, `secret of "crm"`},
+		{"production default db password", func(c *Config) { c.Database.Password = defaultPostgresPassword }, "database.password"},
+		{"production without jwt keys", func(c *Config) { c.JWT.Secret = "" }, "jwt.secret or jwt.keys"},
+		{"production automigrate", func(c *Config) { c.Database.AutoMigrate = true }, "autoMigrate"},
+		{"production without bot token", func(c *Config) { c.Telegram.BotToken = "" }, "telegram.botToken"},
+		{"production without service secrets", func(c *Config) { c.Services.BotSecret = "" }, "services.botSecret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := productionConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.match) {
+				t.Fatalf("got %v, want error containing %q", err, tt.match)
+			}
+		})
+	}
+}
+
+func TestValidateServiceSecretsOnly(t *testing.T) {
+	cfg := productionConfig()
+	cfg.Services.BotSecret = ""
+	cfg.Services.Secrets = map[string]Secret{"crm": longSecret}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("secrets without the bot secret rejected: %v", err)
+	}
+}
+
+func TestSecretHidden(t *testing.T) {
+	cfg := productionConfig()
+	if out := cfg.String(); strings.Contains(out, string(longSecret)) || strings.Contains(out, "prod-password") {
+		t.Fatalf("secret leaked into config dump:\n%s", out)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "http:\n  addr: 0.0.0.0:7000\nservices:\n  secrets:\n    crm: " + strings.Repeat("f", minHMACSecretLength) + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	// Переменные окружения разработчика не должны влиять на тест
+	t.Setenv("APP_ENV", "")
+	t.Setenv("BOT_SERVICE_SECRET", "")
+	t.Setenv("SERVICE_SECRETS", "erp:"+strings.Repeat("e", minHMACSecretLength)+", broken")
+
+	cfg, err := Load([]string{"--addr", "0.0.0.0:7100"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.HTTP.Addr != "0.0.0.0:7100" {
+		t.Errorf("flag did not override file: %s", cfg.HTTP.Addr)
+	}
+	secrets := cfg.Services.ServiceSecrets()
+	if len(secrets) != 2 || secrets["crm"] == nil || secrets["erp"] == nil {
+		t.Errorf("file and env secrets not merged: %v", cfg.Services.Secrets)
+	}
+}
@@ -1,11 +1,15 @@
 package database
 
 import (
+	"backend/internal/config"
+	"backend/internal/metrics"
 	"backend/internal/models"
+	"backend/internal/tracing"
+	"backend/migrations"
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"log/slog"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -13,22 +17,17 @@ import (
 
 var DB *gorm.DB
 
-func Connect() (*gorm.DB, error) {
-	host := getEnv("POSTGRES_HOST", "postgres")
-	port := getEnv("POSTGRES_PORT", "5432")
-	user := getEnv("POSTGRES_USER", "postgres")
-	pass := getEnv("POSTGRES_PASSWORD", "postgres")
-	dbname := getEnv("POSTGRES_DB", "itam_hackaton")
-
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		host, user, pass, dbname, port,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+func Connect(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register metrics plugin: %w", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
 
 	DB = db
 	return db, nil
@@ -42,6 +41,9 @@ func Close() error {
 	return sqlDB.Close()
 }
 
+// AutoMigrate - подогнать таблицы под модели средствами GORM.
+// Только для разработки (database.autoMigrate / DB_AUTO_MIGRATE): схемой управляют миграции из backend/migrations,
+// а AutoMigrate не умеет ни откатываться, ни создавать триггеры вроде защиты журнала аудита.
 func AutoMigrate() error {
 	// Миграция всех моделей - GORM добавит новые колонки автоматически
 	modelsToMigrate := []interface{}{
@@ -64,6 +66,13 @@ func AutoMigrate() error {
 		&models.UserCase{},
 		&models.UserAchievement{},
 		&models.ProfileCustomization{},
+		// Admin accounts
+		&models.AdminCredential{},
+		// Organizer integrations
+		&models.APIKey{},
+		// Audit log
+		&models.AuditLog{},
+		&models.ImpersonationSession{},
 	}
 
 	// AutoMigrate создаёт таблицы и добавляет новые колонки
@@ -74,11 +83,45 @@ func AutoMigrate() error {
 	return nil
 }
 
-func getEnv(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+// NewSchemaMigrator - Migrator для текущего подключения и вшитых в бинарник миграций
+func NewSchemaMigrator() (*Migrator, error) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrator(sqlDB, migrations.FS)
+}
+
+// PrepareSchema - схема при старте сервера.
+// MigrateOnStart применяет миграции под advisory lock, иначе сервер только
+// предупреждает о неприменённых. AutoMigrate дополнительно запускает GORM AutoMigrate.
+func PrepareSchema(ctx context.Context, cfg config.DatabaseConfig) error {
+	migrator, err := NewSchemaMigrator()
+	if err != nil {
+		return err
+	}
+
+	if cfg.MigrateOnStart {
+		applied, err := migrator.Up(ctx, 0)
+		if err != nil {
+			return err
+		}
+		slog.Info("schema is up to date", slog.Int("applied", len(applied)))
+	} else {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			slog.Warn("pending migrations, run `backend migrate up` or set MIGRATE_ON_START=true", slog.Int("pending", len(pending)))
+		}
 	}
-	return fallback
+
+	if cfg.AutoMigrate {
+		slog.Warn("DB_AUTO_MIGRATE is enabled - development only")
+		return AutoMigrate()
+	}
+	return nil
 }
 
 func CreateUser(ctx context.Context, telegramUserID int64, username string) (*models.User, error) {
@@ -0,0 +1,391 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================
+// VERSIONED SQL MIGRATIONS
+// ============================================
+
+// migrationLockID - ключ pg_advisory_lock: пока одна реплика мигрирует, остальные ждут
+const migrationLockID int64 = 0x6974616d6d6967 // "itammig"
+
+var ErrIrreversibleMigration = errors.New("migration has no down script")
+
+// Migration - одна версия схемы из пары файлов NNNN_name.up.sql / NNNN_name.down.sql
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum - sha256 up-скрипта; по нему видно, что применённую миграцию отредактировали
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Reversible - есть ли в down-скрипте что-то кроме комментариев
+func (m Migration) Reversible() bool {
+	for _, line := range strings.Split(m.Down, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrationStatus - состояние версии в базе
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+	// Modified - файл изменился после применения
+	Modified bool
+	// Missing - версия есть в schema_migrations, но файла с ней нет
+	Missing bool
+}
+
+// LoadMigrations - прочитать миграции из fsys, отсортированные по версии
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, file := range files {
+		base := path.Base(file)
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", base)
+		}
+
+		stem := strings.TrimSuffix(base, "."+direction+".sql")
+		num, name, ok := strings.Cut(stem, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name", base)
+		}
+		version, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", base, num)
+		}
+
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator - применяет и откатывает миграции, ведёт таблицу schema_migrations.
+// Все операции, меняющие схему, идут под advisory lock, поэтому несколько реплик
+// могут запускать миграции одновременно.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+
+	// DryRun - только показать, что было бы сделано, ничего не меняя
+	DryRun bool
+	// Logf - куда писать ход миграции (по умолчанию log.Printf)
+	Logf func(format string, args ...interface{})
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{db: db, migrations: migrations, Logf: log.Printf}, nil
+}
+
+// Up - применить все неприменённые миграции до версии target включительно (0 - все)
+func (m *Migrator) Up(ctx context.Context, target int64) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if target > 0 && mig.Version > target {
+				break
+			}
+			if row, ok := applied[mig.Version]; ok {
+				if row.checksum != mig.Checksum() {
+					m.Logf("[migrate] warning: %d_%s was modified after it was applied", mig.Version, mig.Name)
+				}
+				continue
+			}
+
+			if m.DryRun {
+				m.Logf("[migrate] would apply %d_%s", mig.Version, mig.Name)
+				done = append(done, mig)
+				continue
+			}
+
+			m.Logf("[migrate] applying %d_%s", mig.Version, mig.Name)
+			err := runInTx(ctx, conn, mig.Up,
+				`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+				mig.Version, mig.Name, mig.Checksum(), time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down - откатить последние steps применённых миграций
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, errors.New("steps must be positive")
+	}
+
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		if len(versions) > steps {
+			versions = versions[:steps]
+		}
+
+		for _, v := range versions {
+			mig, ok := known[v]
+			if !ok {
+				return fmt.Errorf("migration %d_%s is applied but its files are missing", v, applied[v].name)
+			}
+			if !mig.Reversible() {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, ErrIrreversibleMigration)
+			}
+
+			if m.DryRun {
+				m.Logf("[migrate] would roll back %d_%s", mig.Version, mig.Name)
+				done = append(done, mig)
+				continue
+			}
+
+			m.Logf("[migrate] rolling back %d_%s", mig.Version, mig.Name)
+			err := runInTx(ctx, conn, mig.Down, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+			if err != nil {
+				return fmt.Errorf("rollback of %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status - все известные версии и применённые версии без файлов
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Migration: mig}
+		if row, ok := applied[mig.Version]; ok {
+			appliedAt := row.appliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = row.checksum != mig.Checksum()
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for v, row := range applied {
+		appliedAt := row.appliedAt
+		statuses = append(statuses, MigrationStatus{
+			Migration: Migration{Version: v, Name: row.name},
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Pending - миграции, которые ещё не применены
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Version - последняя применённая версия и число неприменённых миграций
+func (m *Migrator) Version(ctx context.Context) (current int64, pending int, err error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, s := range statuses {
+		switch {
+		case s.AppliedAt == nil:
+			pending++
+		case s.Version > current:
+			current = s.Version
+		}
+	}
+	return current, pending, nil
+}
+
+// withLock - выполнить fn на отдельном соединении под advisory lock.
+// Lock держится на сессии, поэтому все запросы внутри идут через conn.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Контекст мог быть уже отменён, а lock нужно снять в любом случае
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			m.Logf("[migrate] failed to release migration lock: %v", err)
+		}
+	}()
+
+	if !m.DryRun {
+		_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL
+		)`)
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+	}
+
+	return fn(conn)
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// applied - строки schema_migrations; пустой результат, если таблицы ещё нет
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+	applied := map[int64]appliedMigration{}
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int64
+		var row appliedMigration
+		if err := rows.Scan(&version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = row
+	}
+	return applied, rows.Err()
+}
+
+// runInTx - выполнить скрипт миграции и обновить schema_migrations в одной транзакции.
+// Скрипт идёт без параметров, поэтому драйвер отправляет его простым протоколом
+// и в одном файле может быть несколько команд.
+func runInTx(ctx context.Context, conn *sql.Conn, script, bookkeeping string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
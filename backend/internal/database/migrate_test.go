@@ -0,0 +1,112 @@
+package database
+
+import (
+	"backend/migrations"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func sqlFile(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_late.up.sql":        sqlFile("CREATE TABLE late (id INT);"),
+		"0002_second.up.sql":      sqlFile("ALTER TABLE first ADD COLUMN x INT;"),
+		"0002_second.down.sql":    sqlFile("ALTER TABLE first DROP COLUMN x;"),
+		"0001_first_table.up.sql": sqlFile("CREATE TABLE first (id INT);"),
+	}
+
+	got, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d migrations, want 3", len(got))
+	}
+	wantVersions := []int64{1, 2, 10}
+	for i, m := range got {
+		if m.Version != wantVersions[i] {
+			t.Fatalf("migration %d has version %d, want %d", i, m.Version, wantVersions[i])
+		}
+	}
+	// Имя - всё после первого "_"
+	if got[0].Name != "first_table" {
+		t.Errorf("name %q, want first_table", got[0].Name)
+	}
+	if got[1].Down != "ALTER TABLE first DROP COLUMN x;" {
+		t.Errorf("down script not attached: %q", got[1].Down)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		match string
+	}{
+		{"unknown suffix", fstest.MapFS{"0001_a.sql": sqlFile("SELECT 1;")}, "suffix"},
+		{"no name", fstest.MapFS{"0001.up.sql": sqlFile("SELECT 1;")}, "NNNN_name"},
+		{"not a number", fstest.MapFS{"abc_a.up.sql": sqlFile("SELECT 1;")}, "invalid version"},
+		{"zero version", fstest.MapFS{"0000_a.up.sql": sqlFile("SELECT 1;")}, "invalid version"},
+		{"conflicting names", fstest.MapFS{
+			"0001_a.up.sql":   sqlFile("SELECT 1;"),
+			"0001_b.down.sql": sqlFile("SELECT 1;"),
+		}, "conflicting names"},
+		{"down without up", fstest.MapFS{"0001_a.down.sql": sqlFile("SELECT 1;")}, "missing up script"},
+		{"empty up", fstest.MapFS{"0001_a.up.sql": sqlFile("  \n")}, "missing up script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMigrations(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.match) {
+				t.Fatalf("got %v, want error containing %q", err, tt.match)
+			}
+		})
+	}
+}
+
+func TestMigrationReversible(t *testing.T) {
+	tests := []struct {
+		down string
+		want bool
+	}{
+		{"", false},
+		{"-- Необратимо: журнал не удаляем\n\n", false},
+		{"-- Откат\nDROP TABLE x;", true},
+	}
+	for _, tt := range tests {
+		if got := (Migration{Down: tt.down}).Reversible(); got != tt.want {
+			t.Errorf("Reversible(%q) = %v, want %v", tt.down, got, tt.want)
+		}
+	}
+}
+
+func TestMigrationChecksum(t *testing.T) {
+	a := Migration{Up: "CREATE TABLE x (id INT);", Down: "DROP TABLE x;"}
+	b := Migration{Up: "CREATE TABLE x (id INT);"}
+	if a.Checksum() != b.Checksum() {
+		t.Error("checksum depends on the down script")
+	}
+	if a.Checksum() == (Migration{Up: "CREATE TABLE x (id BIGINT);"}).Checksum() {
+		t.Error("checksum did not change with the up script")
+	}
+}
+
+// Вшитые миграции должны разбираться, а версии - идти подряд
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	for i, m := range got {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration %d_%s: expected version %d", m.Version, m.Name, i+1)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
+	"backend/internal/logging"
+	"backend/internal/metrics"
 	"backend/internal/middleware"
 	"backend/internal/models"
-	"log"
+	"backend/internal/pagination"
+	"backend/internal/services"
+	"backend/internal/types"
+	"backend/internal/validation"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
+	"gorm.io/gorm"
 )
 
 // ============================================
@@ -30,86 +41,219 @@ func (s *Server) AuthTelegram(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "initData required"})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
-	// TODO: Validate initData with Telegram Bot Token
-	// For now, mock response
-	user, err := s.UserRepo.GetByTelegramID(c.Request.Context(), 123456789) // Mock ID
+	botToken := s.Config.Telegram.BotToken.Value()
+	if botToken == "" {
+		apierror.Abort(c, apierror.Unavailable("telegram auth is not configured", nil))
+		return
+	}
+
+	// Проверяем подпись initData токеном бота и свежесть auth_date
+	initData, err := middleware.ValidateTelegramInitData(req.InitData, botToken, s.Config.Telegram.AuthMaxAge)
 	if err != nil {
-		// Create new user if not exists
-		c.JSON(http.StatusOK, gin.H{
-			"token": "mock-jwt-token",
-			"user": gin.H{
-				"id":   1,
-				"name": "New User",
-				"role": "participant",
-			},
-		})
+		middleware.Logger(c).Info("telegram initData rejected", logging.Err(err))
+		if errors.Is(err, middleware.ErrInitDataExpired) {
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeInitDataInvalid, "initData expired"))
+			return
+		}
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeInitDataInvalid, "invalid initData"))
 		return
 	}
 
-	// Generate JWT token
-	token, err := middleware.GenerateToken(user.ID, user.TelegramUserID, string(user.Role))
+	user, err := s.UserRepo.CreateOrUpdate(c.Request.Context(), initData.User.ID, initData.User.DisplayName())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		apierror.Abort(c, apierror.Internal("failed to register user", err))
+		return
+	}
+
+	token, refreshToken, err := s.issueTokenPair(c, user)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to generate token", err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  user,
+		"token":        token,
+		"refreshToken": refreshToken,
+		"user":         user,
 	})
 }
 
+// issueTokenPair - открыть новую сессию и выпустить access и refresh токены
+func (s *Server) issueTokenPair(c *gin.Context, user *models.User) (string, string, error) {
+	refreshToken, family, err := s.RefreshTokens.Issue(c.Request.Context(), user.ID, sessionClient(c))
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := middleware.GenerateToken(user.ID, user.TelegramUserID, string(user.Role), family.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
 // RefreshToken - обновление JWT токена
+// @Summary Refresh access token
+// @Description Rotates the refresh token and returns a new token pair. Reusing an old refresh token revokes the whole session.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body object{refreshToken=string} true "Refresh token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/auth/refresh [post]
 func (s *Server) RefreshToken(c *gin.Context) {
-	// TODO: Implement refresh token logic
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "not implemented"})
+	var req struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+
+	refreshToken, family, err := s.RefreshTokens.Rotate(c.Request.Context(), req.RefreshToken, sessionClient(c))
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			middleware.Logger(c).Warn("refresh token reuse detected, session revoked")
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeTokenReused, "refresh token reuse detected, please login again"))
+			return
+		}
+		if errors.Is(err, services.ErrRefreshTokenInvalid) {
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeTokenInvalid, "invalid or expired refresh token"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("failed to refresh token", err))
+		return
+	}
+
+	// Берём роль из БД, чтобы изменения прав применялись при следующем обновлении
+	user, err := s.UserRepo.GetByID(c.Request.Context(), family.UserID)
+	if err != nil {
+		s.RefreshTokens.RevokeFamily(c.Request.Context(), family.ID)
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
+		return
+	}
+
+	token, err := middleware.GenerateToken(user.ID, user.TelegramUserID, string(user.Role), family.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to generate token", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        token,
+		"refreshToken": refreshToken,
+		"user":         user,
+	})
+}
+
+// Logout - выход: отзывает сессию по refresh токену или по sid из access токена
+// @Summary Logout
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body object{refreshToken=string} false "Refresh token"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/auth/logout [post]
+func (s *Server) Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	// Тело необязательно: клиент может выйти только с access токеном
+	c.ShouldBindJSON(&req)
+
+	ctx := c.Request.Context()
+
+	// Выход из имперсонации завершает её сеанс, обычные сессии пользователя не трогаем
+	if sessionID, ok := middleware.GetSessionID(c); ok && middleware.IsImpersonating(c) {
+		s.endImpersonation(c, sessionID)
+		return
+	}
+
+	if req.RefreshToken != "" {
+		if err := s.RefreshTokens.Revoke(ctx, req.RefreshToken); err != nil && !errors.Is(err, services.ErrRefreshTokenInvalid) {
+			apierror.Abort(c, apierror.Internal("failed to logout", err))
+			return
+		}
+	}
+
+	if sessionID, ok := middleware.GetSessionID(c); ok {
+		if err := s.RefreshTokens.RevokeFamily(ctx, sessionID); err != nil {
+			apierror.Abort(c, apierror.Internal("failed to logout", err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
 }
 
-// AdminLogin - админ авторизация (секретный вход)
+// GetJWKS - публичные ключи для проверки access токенов другими сервисами
+// @Summary JSON Web Key Set
+// @Description Public keys (RS256/EdDSA) used to sign access tokens. HS256 secrets are never published.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /.well-known/jwks.json [get]
+func (s *Server) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": middleware.GetKeySet().JWKS()})
+}
+
+// AdminLogin - вход в админ-панель по персональному логину
 // @Summary Admin login
-// @Description Authenticate admin with password
+// @Description Authenticate admin with personal login, password and optional TOTP code. Repeated failures lock the account for a while.
 // @Tags admin
 // @Accept json
 // @Produce json
-// @Param input body object{password=string} true "Admin password"
+// @Param input body types.LoginAdmin true "Admin credentials"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Router /admin/api/login [post]
 func (s *Server) AdminLogin(c *gin.Context) {
-	var req struct {
-		Password string `json:"password" binding:"required"`
-	}
+	var req types.LoginAdmin
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "password required"})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
-	// Проверяем пароль
-	if req.Password != ADMINPASS {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+	user, err := s.AdminAuth.Authenticate(c.Request.Context(), req.UserName, req.Password, req.TOTPCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAdminTOTPRequired):
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeTOTPRequired, "totp code required"))
+		case errors.Is(err, services.ErrAdminLocked):
+			middleware.Logger(c).Warn("admin account is locked", slog.String("username", req.UserName))
+			apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeAccountLocked, "too many failed attempts, try again later"))
+		case errors.Is(err, services.ErrAdminInvalidCredentials):
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeInvalidCredentials, "invalid credentials"))
+		default:
+			apierror.Abort(c, apierror.Internal("failed to authenticate", err))
+		}
 		return
 	}
 
-	// Генерируем JWT токен для админа
-	token, err := middleware.GenerateToken(0, 0, "admin")
+	middleware.Logger(c).Info("admin logged in", slog.String("username", req.UserName), logging.UserID(user.ID))
+
+	token, refreshToken, err := s.issueTokenPair(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		apierror.Abort(c, apierror.Internal("failed to generate token", err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":   0,
-			"role": "admin",
-			"name": "Administrator",
-		},
+		"token":        token,
+		"refreshToken": refreshToken,
+		"user":         user,
 	})
 }
 
@@ -172,24 +316,21 @@ func (s *Server) JoinTeamByCode(c *gin.Context) {
 // GetIncomingInvites - получить входящие приглашения в команды
 func (s *Server) GetIncomingInvites(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
 
-	var invites []models.TeamInvite
-	if err := database.DB.Where("invited_user_id = ? AND status = ?", userID, "pending").Find(&invites).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invites"})
+	invites, err := s.Teams.ListIncomingInvites(ctx, userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch invites", err))
 		return
 	}
 
 	// Build response with team and inviter details
 	response := make([]gin.H, len(invites))
 	for i, invite := range invites {
-		var team models.Team
-		var inviter models.User
-		database.DB.First(&team, invite.TeamID)
-		database.DB.First(&inviter, invite.InviterID)
-
+		team := s.inviteTeam(ctx, invite.TeamID)
+		inviter := s.inviteUser(ctx, invite.InviterID)
 		// Get team captain
-		var captain models.User
-		database.DB.First(&captain, team.CaptainID)
+		captain := s.inviteUser(ctx, team.CaptainID)
 
 		response[i] = gin.H{
 			"id":        invite.ID,
@@ -216,20 +357,19 @@ func (s *Server) GetIncomingInvites(c *gin.Context) {
 // GetOutgoingInvites - получить отправленные приглашения
 func (s *Server) GetOutgoingInvites(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
 
-	var invites []models.TeamInvite
-	if err := database.DB.Where("inviter_id = ?", userID).Find(&invites).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invites"})
+	invites, err := s.Teams.ListOutgoingInvites(ctx, userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch invites", err))
 		return
 	}
 
 	// Build response with team and invited user details
 	response := make([]gin.H, len(invites))
 	for i, invite := range invites {
-		var team models.Team
-		var invitedUser models.User
-		database.DB.First(&team, invite.TeamID)
-		database.DB.First(&invitedUser, invite.InvitedUserID)
+		team := s.inviteTeam(ctx, invite.TeamID)
+		invitedUser := s.inviteUser(ctx, invite.InvitedUserID)
 
 		response[i] = gin.H{
 			"id":        invite.ID,
@@ -251,6 +391,26 @@ func (s *Server) GetOutgoingInvites(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// inviteTeam - команда для карточки приглашения; пустая, если её уже удалили
+func (s *Server) inviteTeam(ctx context.Context, teamID int64) *models.Team {
+	team, err := s.Teams.GetByID(ctx, teamID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to get team for invite", slog.Int64("team_id", teamID), logging.Err(err))
+		return &models.Team{}
+	}
+	return team
+}
+
+// inviteUser - участник приглашения; пустой, если его не удалось загрузить
+func (s *Server) inviteUser(ctx context.Context, userID int64) *models.User {
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to get user for invite", slog.Int64("user_id", userID), logging.Err(err))
+		return &models.User{}
+	}
+	return user
+}
+
 // SendInvite - отправить приглашение в команду
 func (s *Server) SendInvite(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
@@ -262,67 +422,62 @@ func (s *Server) SendInvite(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	// Parse IDs
 	teamID, err := strconv.ParseInt(req.TeamID, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 	toUserID, err := strconv.ParseInt(req.ToUserID, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
 		return
 	}
 
 	// Check team exists and user is captain
 	var team models.Team
 	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	if team.CaptainID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only captain can send invites"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotTeamCaptain, "only captain can send invites"))
 		return
 	}
 
 	// Check target user exists
 	var targetUser models.User
 	if err := database.DB.First(&targetUser, toUserID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	// Check if target user is already in a team for this hackathon
-	inTeam, _, err := isUserInTeamForHackathon(toUserID, team.HackathonID)
+	currentTeam, err := s.Membership.TeamForHackathon(c.Request.Context(), toUserID, team.HackathonID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check user team status"})
+		apierror.Abort(c, apierror.Internal("failed to check user team status", err))
 		return
 	}
-	if inTeam {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user is already in a team for this hackathon"})
+	if currentTeam != nil {
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyInTeam, "user is already in a team for this hackathon"))
 		return
 	}
 
 	// Check team capacity
-	hasCapacity, currentCount, maxSize, err := checkTeamCapacity(teamID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check team capacity"})
-		return
-	}
-	if !hasCapacity {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is full", "current": currentCount, "max": maxSize})
+	if err := s.Membership.CheckCapacity(c.Request.Context(), &team); err != nil {
+		writeMembershipError(c, err)
 		return
 	}
 
 	// Check if invite already exists
 	var existingInvite models.TeamInvite
 	if err := database.DB.Where("team_id = ? AND invited_user_id = ? AND status = ?", teamID, toUserID, "pending").First(&existingInvite).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "invite already sent"})
+		apierror.Abort(c, apierror.Conflict(apierror.CodeInviteAlreadySent, "invite already sent"))
 		return
 	}
 
@@ -334,16 +489,17 @@ func (s *Server) SendInvite(c *gin.Context) {
 		Status:        "pending",
 	}
 	if err := database.DB.Create(&invite).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invite"})
+		apierror.Abort(c, apierror.Internal("failed to create invite", err))
 		return
 	}
+	metrics.InvitesSent.WithLabelValues("manual").Inc()
 
 	// Get inviter info
 	var inviter models.User
 	database.DB.First(&inviter, userID)
 
 	// Send notification
-	go s.sendTeamInviteNotification(team, inviter, targetUser, invite.ID)
+	s.sendTeamInviteNotification(c.Request.Context(), team, inviter, targetUser, invite.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"id":       invite.ID,
@@ -360,129 +516,29 @@ func (s *Server) AcceptInvite(c *gin.Context) {
 
 	inviteID, err := strconv.ParseInt(inviteIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite ID"})
-		return
-	}
-
-	// Find invite
-	var invite models.TeamInvite
-	if err := database.DB.First(&invite, inviteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
-		return
-	}
-
-	// Check it's for this user
-	if invite.InvitedUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not your invite"})
-		return
-	}
-
-	if invite.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invite already processed"})
+		apierror.Abort(c, apierror.InvalidID("invalid invite ID"))
 		return
 	}
 
-	// Get team
-	var team models.Team
-	if err := database.DB.First(&team, invite.TeamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
-		return
-	}
-
-	// Check if team is open
-	if team.Status == models.TeamStatusClosed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is not accepting new members"})
-		return
-	}
+	s.acceptInvite(c, userID, inviteID)
+}
 
-	// Check if user already in a team for this hackathon
-	inTeam, _, err := isUserInTeamForHackathon(userID, team.HackathonID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check user team status"})
-		return
-	}
-	if inTeam {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you are already in a team for this hackathon"})
-		return
-	}
+// acceptInvite - принять приглашение и уведомить пригласившего
+func (s *Server) acceptInvite(c *gin.Context, userID, inviteID int64) {
+	ctx := c.Request.Context()
 
-	// Check team capacity
-	hasCapacity, currentCount, maxSize, err := checkTeamCapacity(team.ID)
+	invite, team, err := s.Membership.AcceptInvite(ctx, userID, inviteID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check team capacity"})
-		return
-	}
-	if !hasCapacity {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is full", "current": currentCount, "max": maxSize})
-		return
-	}
-
-	// Get user for notification
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
-		return
-	}
-
-	// Используем транзакцию для атомарности операций
-	tx := database.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Add user to team
-	if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("team_id", team.ID).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to join team"})
-		return
-	}
-
-	// Update invite status
-	if err := tx.Model(&invite).Update("status", "accepted").Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update invite status"})
-		return
-	}
-
-	// Update hackathon participant status if exists
-	if err := tx.Model(&models.HackathonParticipant{}).
-		Where("user_id = ? AND hackathon_id = ?", userID, team.HackathonID).
-		Update("status", "in_team").Error; err != nil {
-		// Не критично, если запись не найдена
-		log.Printf("Warning: failed to update hackathon participant status: %v", err)
-	}
-
-	// Cancel other pending invites for this user for this hackathon
-	if err := tx.Model(&models.TeamInvite{}).
-		Where("invited_user_id = ? AND status = 'pending' AND team_id IN (SELECT id FROM teams WHERE hackathon_id = ?)", userID, team.HackathonID).
-		Update("status", "cancelled").Error; err != nil {
-		log.Printf("Warning: failed to cancel pending invites: %v", err)
-	}
-
-	// Cancel other pending join requests for this user for this hackathon
-	var otherTeams []models.Team
-	if err := tx.Where("hackathon_id = ?", team.HackathonID).Find(&otherTeams).Error; err == nil {
-		for _, t := range otherTeams {
-			if err := tx.Model(&models.TeamJoinRequest{}).
-				Where("team_id = ? AND user_id = ? AND status = 'pending'", t.ID, userID).
-				Update("status", "cancelled").Error; err != nil {
-				log.Printf("Warning: failed to cancel join request for team %d: %v", t.ID, err)
-			}
-		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+		writeMembershipError(c, err)
 		return
 	}
 
 	// Notify inviter
-	var inviter models.User
-	database.DB.First(&inviter, invite.InviterID)
-
-	go s.sendInviteResponseNotification(team, user, inviter, true)
+	user, userErr := s.UserRepo.GetByID(ctx, userID)
+	inviter, inviterErr := s.UserRepo.GetByID(ctx, invite.InviterID)
+	if userErr == nil && inviterErr == nil {
+		s.sendInviteResponseNotification(ctx, *team, *user, *inviter, true)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "invite accepted", "teamId": team.ID})
 }
@@ -494,49 +550,28 @@ func (s *Server) DeclineInvite(c *gin.Context) {
 
 	inviteID, err := strconv.ParseInt(inviteIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid invite ID"))
 		return
 	}
 
-	// Find invite
-	var invite models.TeamInvite
-	if err := database.DB.First(&invite, inviteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
-		return
-	}
-
-	// Check it's for this user
-	if invite.InvitedUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not your invite"})
-		return
-	}
+	s.declineInvite(c, userID, inviteID)
+}
 
-	if invite.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invite already processed"})
-		return
-	}
+// declineInvite - отклонить приглашение и уведомить пригласившего
+func (s *Server) declineInvite(c *gin.Context, userID, inviteID int64) {
+	ctx := c.Request.Context()
 
-	// Update invite status
-	if err := database.DB.Model(&invite).Update("status", "declined").Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update invite status"})
+	invite, err := s.Membership.DeclineInvite(ctx, userID, inviteID)
+	if err != nil {
+		writeMembershipError(c, err)
 		return
 	}
 
 	// Notify inviter
-	var team models.Team
-	var inviter models.User
-	var user models.User
-	if err := database.DB.First(&team, invite.TeamID).Error; err != nil {
-		log.Printf("Warning: failed to get team for notification: %v", err)
-	}
-	if err := database.DB.First(&inviter, invite.InviterID).Error; err != nil {
-		log.Printf("Warning: failed to get inviter for notification: %v", err)
-	}
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		log.Printf("Warning: failed to get user for notification: %v", err)
-	}
-
-	go s.sendInviteResponseNotification(team, user, inviter, false)
+	team := s.inviteTeam(ctx, invite.TeamID)
+	inviter := s.inviteUser(ctx, invite.InviterID)
+	user := s.inviteUser(ctx, userID)
+	s.sendInviteResponseNotification(ctx, *team, *user, *inviter, false)
 
 	c.JSON(http.StatusOK, gin.H{"message": "invite declined"})
 }
@@ -548,25 +583,25 @@ func (s *Server) CancelInvite(c *gin.Context) {
 
 	inviteID, err := strconv.ParseInt(inviteIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid invite ID"))
 		return
 	}
 
 	// Find invite
 	var invite models.TeamInvite
 	if err := database.DB.First(&invite, inviteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeInviteNotFound, "invite not found")))
 		return
 	}
 
 	// Check user is inviter
 	if invite.InviterID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only inviter can cancel"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "only inviter can cancel"))
 		return
 	}
 
 	if invite.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invite already processed"})
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyProcessed, "invite already processed"))
 		return
 	}
 
@@ -583,137 +618,24 @@ func (s *Server) BotAcceptInvite(c *gin.Context) {
 
 	inviteID, err := strconv.ParseInt(inviteIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid invite ID"))
 		return
 	}
 
 	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid telegram ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid telegram ID"))
 		return
 	}
 
 	// Find user by telegram ID
-	var user models.User
-	if err := database.DB.Where("telegram_user_id = ?", telegramID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		return
-	}
-
-	// Find invite
-	var invite models.TeamInvite
-	if err := database.DB.First(&invite, inviteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
-		return
-	}
-
-	// Check it's for this user
-	if invite.InvitedUserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not your invite"})
-		return
-	}
-
-	if invite.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invite already processed"})
-		return
-	}
-
-	// Get team
-	var team models.Team
-	if err := database.DB.First(&team, invite.TeamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
-		return
-	}
-
-	// Check if team is open
-	if team.Status == models.TeamStatusClosed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is not accepting new members"})
-		return
-	}
-
-	// Check if user already in a team for this hackathon
-	inTeam, _, err := isUserInTeamForHackathon(user.ID, team.HackathonID)
+	user, err := s.UserRepo.GetByTelegramID(c.Request.Context(), telegramID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check user team status"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
-	if inTeam {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you are already in a team for this hackathon"})
-		return
-	}
-
-	// Check team capacity
-	hasCapacity, currentCount, maxSize, err := checkTeamCapacity(team.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check team capacity"})
-		return
-	}
-	if !hasCapacity {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is full", "current": currentCount, "max": maxSize})
-		return
-	}
-
-	// Используем транзакцию для атомарности операций
-	tx := database.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Add user to team
-	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("team_id", team.ID).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to join team"})
-		return
-	}
-
-	// Update invite status
-	if err := tx.Model(&invite).Update("status", "accepted").Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update invite status"})
-		return
-	}
-
-	// Update hackathon participant status if exists
-	if err := tx.Model(&models.HackathonParticipant{}).
-		Where("user_id = ? AND hackathon_id = ?", user.ID, team.HackathonID).
-		Update("status", "in_team").Error; err != nil {
-		// Не критично, если запись не найдена
-		log.Printf("Warning: failed to update hackathon participant status: %v", err)
-	}
 
-	// Cancel other pending invites for this user for this hackathon
-	if err := tx.Model(&models.TeamInvite{}).
-		Where("invited_user_id = ? AND status = 'pending' AND team_id IN (SELECT id FROM teams WHERE hackathon_id = ?)", user.ID, team.HackathonID).
-		Update("status", "cancelled").Error; err != nil {
-		log.Printf("Warning: failed to cancel pending invites: %v", err)
-	}
-
-	// Cancel other pending join requests for this user for this hackathon
-	var otherTeams []models.Team
-	if err := tx.Where("hackathon_id = ?", team.HackathonID).Find(&otherTeams).Error; err == nil {
-		for _, t := range otherTeams {
-			if err := tx.Model(&models.TeamJoinRequest{}).
-				Where("team_id = ? AND user_id = ? AND status = 'pending'", t.ID, user.ID).
-				Update("status", "cancelled").Error; err != nil {
-				log.Printf("Warning: failed to cancel join request for team %d: %v", t.ID, err)
-			}
-		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
-		return
-	}
-
-	// Notify inviter
-	var inviter models.User
-	database.DB.First(&inviter, invite.InviterID)
-
-	go s.sendInviteResponseNotification(team, user, inviter, true)
-
-	c.JSON(http.StatusOK, gin.H{"message": "invite accepted", "teamId": team.ID})
+	s.acceptInvite(c, user.ID, inviteID)
 }
 
 // BotDeclineInvite - отклонить приглашение через бота (по telegramId)
@@ -723,60 +645,24 @@ func (s *Server) BotDeclineInvite(c *gin.Context) {
 
 	inviteID, err := strconv.ParseInt(inviteIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid invite ID"))
 		return
 	}
 
 	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid telegram ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid telegram ID"))
 		return
 	}
 
 	// Find user by telegram ID
-	var user models.User
-	if err := database.DB.Where("telegram_user_id = ?", telegramID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-		return
-	}
-
-	// Find invite
-	var invite models.TeamInvite
-	if err := database.DB.First(&invite, inviteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
-		return
-	}
-
-	// Check it's for this user
-	if invite.InvitedUserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not your invite"})
-		return
-	}
-
-	if invite.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invite already processed"})
-		return
-	}
-
-	// Update invite status
-	if err := database.DB.Model(&invite).Update("status", "declined").Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update invite status"})
+	user, err := s.UserRepo.GetByTelegramID(c.Request.Context(), telegramID)
+	if err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
-	// Notify inviter
-	var team models.Team
-	var inviter models.User
-	if err := database.DB.First(&team, invite.TeamID).Error; err != nil {
-		log.Printf("Warning: failed to get team for notification: %v", err)
-	}
-	if err := database.DB.First(&inviter, invite.InviterID).Error; err != nil {
-		log.Printf("Warning: failed to get inviter for notification: %v", err)
-	}
-
-	go s.sendInviteResponseNotification(team, user, inviter, false)
-
-	c.JSON(http.StatusOK, gin.H{"message": "invite declined"})
+	s.declineInvite(c, user.ID, inviteID)
 }
 
 // ============================================
@@ -844,21 +730,56 @@ func (s *Server) GetAdminStats(c *gin.Context) {
 	})
 }
 
-func (s *Server) GetAllUsers(c *gin.Context) {
-	var users []models.User
-
-	// Support pagination
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "50")
+// adminUserListSpec - сортировки и фильтры таблицы пользователей в админке
+var adminUserListSpec = &pagination.Spec[models.User]{
+	Sorts: map[string]pagination.Sort[models.User]{
+		"createdAt": pagination.TimeSort("created_at", func(u models.User) time.Time { return u.CreatedAt }),
+		"name":      pagination.StringSort("COALESCE(name, '')", func(u models.User) string { return u.Name }),
+		"pts":       pagination.IntSort("COALESCE(pts, 0)", func(u models.User) int64 { return int64(u.Pts) }),
+		"mmr":       pagination.IntSort("COALESCE(mmr, 0)", func(u models.User) int64 { return int64(u.Mmr) }),
+	},
+	DefaultSort: "-createdAt",
+	Filters: map[string]pagination.Filter{
+		"role": pagination.Equals("role",
+			func(v string) bool { return models.UserRole(v).IsValid() }, "must be one of: user, hackathon_creator, admin"),
+		"status": {
+			Valid:   func(v string) bool { return v == "looking" || v == "in_team" },
+			Message: "must be one of: looking, in_team",
+			Apply: func(db *gorm.DB, v string) *gorm.DB {
+				if v == "in_team" {
+					return db.Where("team_id IS NOT NULL")
+				}
+				return db.Where("team_id IS NULL")
+			},
+		},
+		"hackathonId": pagination.Equals("current_hackathon_id",
+			func(v string) bool { _, err := strconv.ParseInt(v, 10, 64); return err == nil }, "must be a hackathon ID"),
+		"search": pagination.Search("name", "username"),
+	},
+	ID: func(u models.User) int64 { return u.ID },
+}
 
-	pageNum, _ := strconv.Atoi(page)
-	limitNum, _ := strconv.Atoi(limit)
-	offset := (pageNum - 1) * limitNum
+func (s *Server) GetAllUsers(c *gin.Context) {
+	params, err := pagination.Parse(c, adminUserListSpec)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
 
-	database.DB.Offset(offset).Limit(limitNum).Find(&users)
+	db := database.DB.WithContext(c.Request.Context())
 
 	var total int64
-	database.DB.Model(&models.User{}).Count(&total)
+	if err := params.Filter(db.Model(&models.User{})).Count(&total).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to count users", err))
+		return
+	}
+
+	var users []models.User
+	if err := params.Query(db).Find(&users).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch users", err))
+		return
+	}
+	users, next := params.Next(users)
 
 	// Get customizations for all users
 	userIDs := make([]int64, len(users))
@@ -867,7 +788,9 @@ func (s *Server) GetAllUsers(c *gin.Context) {
 	}
 
 	var customizations []models.ProfileCustomization
-	database.DB.Where("user_id IN ?", userIDs).Find(&customizations)
+	if len(userIDs) > 0 {
+		db.Where("user_id IN ?", userIDs).Find(&customizations)
+	}
 
 	// Create a map for quick lookup
 	customizationMap := make(map[int64]*models.ProfileCustomization)
@@ -889,35 +812,30 @@ func (s *Server) GetAllUsers(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"users": usersWithCustomization,
-		"total": total,
-		"page":  pageNum,
-		"limit": limitNum,
-	})
+	c.JSON(http.StatusOK, pagination.NewPage(usersWithCustomization, next, total))
 }
 
 func (s *Server) AdminUpdateUser(c *gin.Context) {
 	userIDStr := c.Param("id")
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	var req struct {
 		Name string `json:"name"`
-		Role string `json:"role"`
+		Role string `json:"role" binding:"omitempty,user_role"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -929,28 +847,36 @@ func (s *Server) AdminUpdateUser(c *gin.Context) {
 		updates["role"] = req.Role
 	}
 
-	database.DB.Model(&user).Updates(updates)
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, user)
+		return
+	}
+
+	before := user
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to update user", err))
+		return
+	}
 	database.DB.First(&user, userID)
+	// Журнал нельзя вычистить при удалении данных, поэтому персональные поля в него не пишем:
+	// про имя фиксируем только факт изменения
+	recordAudit(c, s.Audit, models.AuditActionUserUpdate, models.AuditEntityUser, user.ID,
+		gin.H{"role": before.Role}, gin.H{"role": user.Role, "nameChanged": user.Name != before.Name})
 
 	c.JSON(http.StatusOK, user)
 }
 
 func (s *Server) GetAllTeams(c *gin.Context) {
-	var teams []models.Team
-
-	database.DB.Find(&teams)
+	page, ok := listTeams(c, database.DB.WithContext(c.Request.Context()), adminTeamListSpec)
+	if !ok {
+		return
+	}
 
 	// Build response with members
-	response := make([]gin.H, len(teams))
-	for i, team := range teams {
-		var members []models.User
-		database.DB.Where("team_id = ?", team.ID).Find(&members)
-
-		// Get captain
-		var captain models.User
-		database.DB.First(&captain, team.CaptainID)
-
-		response[i] = gin.H{
+	items := make([]gin.H, len(page.teams))
+	for i, team := range page.teams {
+		members := page.members[team.ID]
+		items[i] = gin.H{
 			"id":          team.ID,
 			"name":        team.Name,
 			"description": team.Description,
@@ -959,7 +885,7 @@ func (s *Server) GetAllTeams(c *gin.Context) {
 			"status":      team.Status,
 			"members":     members,
 			"memberCount": len(members),
-			"captain":     captain,
+			"captain":     page.captains[team.CaptainID],
 			"background":  team.Background,
 			"borderColor": team.BorderColor,
 			"nameColor":   team.NameColor,
@@ -968,7 +894,7 @@ func (s *Server) GetAllTeams(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, pagination.NewPage(items, page.next, page.total))
 }
 
 func (s *Server) AdminAssignToTeam(c *gin.Context) {
@@ -978,15 +904,23 @@ func (s *Server) AdminAssignToTeam(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, req.UserID).Error; err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	// Update user's team
 	if err := database.DB.Model(&models.User{}).Where("id = ?", req.UserID).Update("team_id", req.TeamID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to assign user to team"})
+		apierror.Abort(c, apierror.Internal("failed to assign user to team", err))
 		return
 	}
+	recordAudit(c, s.Audit, models.AuditActionTeamAssign, models.AuditEntityUser, user.ID,
+		gin.H{"teamId": user.TeamID}, gin.H{"teamId": req.TeamID})
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "user assigned to team",
@@ -1003,13 +937,13 @@ func (s *Server) AdminAssignToTeam(c *gin.Context) {
 func (s *Server) GetMe(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -1134,17 +1068,15 @@ func (s *Server) UpdateProfile(c *gin.Context) {
 		Skills         []string `json:"skills"`
 		VerifiedSkills []string `json:"verifiedSkills"`
 		Bio            string   `json:"bio"`
-		AvatarURL      string   `json:"avatarUrl"`
-		LookingFor     []string `json:"lookingFor"`
-		Experience     string   `json:"experience"`
+		AvatarURL      string   `json:"avatarUrl" binding:"omitempty,image_url"`
+		LookingFor     []string `json:"lookingFor" binding:"omitempty,dive,team_role"`
+		Experience     string   `json:"experience" binding:"omitempty,experience"`
 		ContactInfo    string   `json:"contactInfo"`
 		Tags           []string `json:"tags"`
-		Pts            *int     `json:"pts"`
-		Mmr            *int     `json:"mmr"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -1152,7 +1084,7 @@ func (s *Server) UpdateProfile(c *gin.Context) {
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -1185,12 +1117,6 @@ func (s *Server) UpdateProfile(c *gin.Context) {
 	if req.Tags != nil {
 		updates["tags"] = pq.StringArray(req.Tags)
 	}
-	if req.Pts != nil {
-		updates["pts"] = *req.Pts
-	}
-	if req.Mmr != nil {
-		updates["mmr"] = *req.Mmr
-	}
 
 	// Mark profile as complete if basic info is provided
 	if req.Name != "" && len(req.Skills) > 0 {
@@ -1198,7 +1124,7 @@ func (s *Server) UpdateProfile(c *gin.Context) {
 	}
 
 	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		apierror.Abort(c, apierror.Internal("failed to update profile", err))
 		return
 	}
 
@@ -1227,13 +1153,13 @@ func (s *Server) GetUser(c *gin.Context) {
 	userIDStr := c.Param("id")
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -1264,7 +1190,7 @@ func (s *Server) SendNotification(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -1279,13 +1205,39 @@ func (s *Server) AdminPromoteToCreator(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+
+	userID, err := strconv.ParseInt(req.UserID, 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
+		return
+	}
+
+	// Админа не понижаем
+	if user.Role == models.RoleAdmin {
+		apierror.Abort(c, apierror.Conflict(apierror.CodeConflict, "user is already an admin"))
+		return
+	}
+
+	previousRole := user.Role
+	if err := database.DB.Model(&user).Update("role", models.RoleHackathonCreator).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to update user role", err))
 		return
 	}
+	recordAudit(c, s.Audit, models.AuditActionUserPromote, models.AuditEntityUser, user.ID,
+		gin.H{"role": previousRole}, gin.H{"role": models.RoleHackathonCreator})
 
-	// TODO: Update user role in DB
+	// Новая роль попадёт в access токен при следующем refresh
 	c.JSON(http.StatusOK, gin.H{
-		"userId": req.UserID,
-		"role":   "hackathon_creator",
+		"userId": user.ID,
+		"role":   models.RoleHackathonCreator,
 	})
 }
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/authz"
+	"backend/internal/database"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/validation"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// API KEY MANAGEMENT (владелец хакатона)
+// ============================================
+
+// GetHackathonAPIKeys - ключи интеграций своего хакатона
+// @Summary List hackathon API keys
+// @Tags Creator
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Hackathon ID"
+// @Success 200 {array} models.APIKey
+// @Failure 403 {object} map[string]string
+// @Router /api/creator/hackathons/{id}/api-keys [get]
+func (s *Server) GetHackathonAPIKeys(c *gin.Context) {
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
+		return
+	}
+
+	keys, err := s.APIKeys.List(c.Request.Context(), hackathon.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch api keys", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateHackathonAPIKey - выпустить ключ интеграции для своего хакатона
+// @Summary Create hackathon API key
+// @Description The key itself is returned only once
+// @Tags Creator
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Hackathon ID"
+// @Param request body object true "{\"name\": \"CRM\", \"permissions\": [\"participants:read\"]}"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/creator/hackathons/{id}/api-keys [post]
+func (s *Server) CreateHackathonAPIKey(c *gin.Context) {
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        string                    `json:"name" binding:"required,max=100"`
+		Permissions []models.APIKeyPermission `json:"permissions" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+
+	actor, _ := authz.ActorFromContext(c)
+	plaintext, key, err := s.APIKeys.Create(c.Request.Context(), hackathon.ID, actor.UserID, req.Name, req.Permissions)
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyNameTooLong) {
+			apierror.Abort(c, apierror.Validation(apierror.FieldError{Field: "name", Message: err.Error()}))
+			return
+		}
+		if errors.Is(err, services.ErrAPIKeyNameRequired) ||
+			errors.Is(err, services.ErrAPIKeyNoPermissions) ||
+			errors.Is(err, services.ErrAPIKeyUnknownPermission) {
+			apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, err.Error()).
+				WithMeta("allowedPermissions", models.AllAPIKeyPermissions))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("failed to create api key", err))
+		return
+	}
+
+	recordAudit(c, s.Audit, models.AuditActionAPIKeyCreate, models.AuditEntityAPIKey, key.ID, nil, key)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"key":    plaintext,
+		"apiKey": key,
+	})
+}
+
+// RevokeHackathonAPIKey - отозвать ключ интеграции своего хакатона
+// @Summary Revoke hackathon API key
+// @Tags Creator
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Hackathon ID"
+// @Param keyId path int true "API key ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/creator/hackathons/{id}/api-keys/{keyId} [delete]
+func (s *Server) RevokeHackathonAPIKey(c *gin.Context) {
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.ParseInt(c.Param("keyId"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid api key ID"))
+		return
+	}
+
+	if err := s.APIKeys.Revoke(c.Request.Context(), hackathon.ID, keyID); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			apierror.Abort(c, apierror.NotFound(apierror.CodeAPIKeyNotFound, "api key not found"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("failed to revoke api key", err))
+		return
+	}
+
+	recordAudit(c, s.Audit, models.AuditActionAPIKeyRevoke, models.AuditEntityAPIKey, keyID,
+		gin.H{"hackathonId": hackathon.ID, "revoked": false}, gin.H{"hackathonId": hackathon.ID, "revoked": true})
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
+
+// ============================================
+// INTEGRATIONS (Authorization: ApiKey <key>)
+// ============================================
+
+// apiKeyHackathon - хакатон, к которому привязан ключ запроса
+func apiKeyHackathon(c *gin.Context) (*models.APIKey, *models.Hackathon, bool) {
+	key, ok := middleware.GetAPIKey(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return nil, nil, false
+	}
+
+	var hackathon models.Hackathon
+	if err := database.DB.First(&hackathon, key.HackathonID).Error; err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeHackathonNotFound, "hackathon not found")))
+		return nil, nil, false
+	}
+	return key, &hackathon, true
+}
+
+// IntegrationGetHackathon - хакатон ключа и выданные ключу разрешения
+// @Summary Hackathon of the API key
+// @Tags Integrations
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/integrations/hackathon [get]
+func (s *Server) IntegrationGetHackathon(c *gin.Context) {
+	key, hackathon, ok := apiKeyHackathon(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hackathon":   hackathon,
+		"permissions": key.Permissions,
+	})
+}
+
+// IntegrationGetParticipants - участники хакатона ключа (participants:read)
+// @Summary Hackathon participants
+// @Tags Integrations
+// @Produce json
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /api/integrations/participants [get]
+func (s *Server) IntegrationGetParticipants(c *gin.Context) {
+	_, hackathon, ok := apiKeyHackathon(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "format must be json or csv"))
+		return
+	}
+
+	participants, err := loadHackathonParticipants(c.Request.Context(), hackathon.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch participants", err))
+		return
+	}
+
+	if format == "csv" {
+		writeParticipantsCSV(c, hackathon, participants)
+		return
+	}
+	c.JSON(http.StatusOK, participants)
+}
+
+// IntegrationGetTeams - команды хакатона ключа с составом (teams:read)
+// @Summary Hackathon teams
+// @Tags Integrations
+// @Produce json
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /api/integrations/teams [get]
+func (s *Server) IntegrationGetTeams(c *gin.Context) {
+	_, hackathon, ok := apiKeyHackathon(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "format must be json or csv"))
+		return
+	}
+
+	var teams []models.Team
+	if err := database.DB.Where("hackathon_id = ?", hackathon.ID).Order("id").Find(&teams).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch teams", err))
+		return
+	}
+
+	teamIDs := make([]int64, len(teams))
+	for i, t := range teams {
+		teamIDs[i] = t.ID
+	}
+	members := make(map[int64][]models.User, len(teams))
+	if len(teamIDs) > 0 {
+		var users []models.User
+		if err := database.DB.Where("team_id IN ?", teamIDs).Find(&users).Error; err != nil {
+			apierror.Abort(c, apierror.Internal("failed to fetch team members", err))
+			return
+		}
+		for _, u := range users {
+			members[*u.TeamID] = append(members[*u.TeamID], u)
+		}
+	}
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="hackathon-%d-teams.csv"`, hackathon.ID))
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write([]string{"teamId", "teamName", "status", "captainId", "membersCount", "members"})
+		for _, t := range teams {
+			usernames := make([]string, len(members[t.ID]))
+			for i, u := range members[t.ID] {
+				usernames[i] = csvCell(u.Username)
+			}
+			_ = w.Write(csvRow(
+				strconv.FormatInt(t.ID, 10),
+				t.Name,
+				string(t.Status),
+				strconv.FormatInt(t.CaptainID, 10),
+				strconv.Itoa(len(usernames)),
+				strings.Join(usernames, ";"),
+			))
+		}
+		w.Flush()
+		return
+	}
+
+	response := make([]gin.H, len(teams))
+	for i, t := range teams {
+		teamMembers := make([]gin.H, len(members[t.ID]))
+		for j, u := range members[t.ID] {
+			teamMembers[j] = gin.H{
+				"userId":   u.ID,
+				"username": u.Username,
+				"name":     u.Name,
+				"skills":   u.Skills,
+			}
+		}
+		response[i] = gin.H{
+			"id":        t.ID,
+			"name":      t.Name,
+			"status":    t.Status,
+			"captainId": t.CaptainID,
+			"members":   teamMembers,
+			"createdAt": t.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// IntegrationPostAnnouncement - объявление участникам хакатона ключа (announcements:write)
+// @Summary Post hackathon announcement
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body object true "{\"title\": \"...\", \"message\": \"...\"}"
+// @Success 202 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/integrations/announcements [post]
+func (s *Server) IntegrationPostAnnouncement(c *gin.Context) {
+	_, hackathon, ok := apiKeyHackathon(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Title   string `json:"title" binding:"required,max=200"`
+		Message string `json:"message" binding:"required,max=4000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+
+	if err := s.NotificationService.NotifyHackathonAnnouncement(c.Request.Context(), hackathon.ID, req.Title, req.Message); err != nil {
+		apierror.Abort(c, apierror.Internal("failed to publish announcement", err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "announcement queued"})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ============================================
+// AUDIT HELPERS
+// ============================================
+
+// recordAudit - записать действие текущего пользователя в журнал аудита.
+// before/after - состояние до и после; nil для создания или удаления.
+func recordAudit(c *gin.Context, logger *services.AuditLogger, action, entityType string, entityID int64, before, after interface{}) {
+	entry := services.AuditEntry{
+		Action:     action,
+		EntityType: entityType,
+		Before:     before,
+		After:      after,
+		IP:         c.ClientIP(),
+	}
+	if entityID != 0 {
+		entry.EntityID = &entityID
+	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		entry.ActorID = &userID
+	}
+	if role, ok := middleware.GetUserRole(c); ok {
+		entry.ActorRole = role
+	}
+	// Под имперсонацией действует админ, а пользователь - лишь субъект
+	if actorID, actorRole, ok := middleware.GetActor(c); ok {
+		entry.SubjectID = entry.ActorID
+		entry.ActorID = &actorID
+		entry.ActorRole = actorRole
+	}
+
+	// Ошибка уже залогирована в AuditLogger
+	_ = logger.Record(c.Request.Context(), entry)
+}
+
+// ============================================
+// ADMIN AUDIT LOG
+// ============================================
+
+// idFilter - фильтр column = id
+func idFilter(column string) pagination.Filter {
+	return pagination.Equals(column,
+		func(v string) bool { _, err := strconv.ParseInt(v, 10, 64); return err == nil }, "must be an ID")
+}
+
+// auditTimeFilter - граница по created_at; bound - ">=" для from, "<" для to
+func auditTimeFilter(bound string) pagination.Filter {
+	return pagination.Filter{
+		Valid:   func(v string) bool { _, _, err := parseAuditTime(v); return err == nil },
+		Message: "must be RFC3339 or YYYY-MM-DD",
+		Apply: func(db *gorm.DB, v string) *gorm.DB {
+			t, dateOnly, _ := parseAuditTime(v)
+			// to=2026-01-31 включает весь день
+			if dateOnly && bound == "<" {
+				t = t.AddDate(0, 0, 1)
+			}
+			return db.Where("created_at "+bound+" ?", t)
+		},
+	}
+}
+
+// auditLogSpec - журнал новыми первыми; from включительно, to - нет
+var auditLogSpec = &pagination.Spec[models.AuditLog]{
+	Sorts: map[string]pagination.Sort[models.AuditLog]{
+		"createdAt": pagination.TimeSort("created_at", func(e models.AuditLog) time.Time { return e.CreatedAt }),
+	},
+	DefaultSort: "-createdAt",
+	Filters: map[string]pagination.Filter{
+		"actorId":    idFilter("actor_id"),
+		"action":     pagination.Equals("action", nil, ""),
+		"entityType": pagination.Equals("entity_type", nil, ""),
+		"entityId":   idFilter("entity_id"),
+		"from":       auditTimeFilter(">="),
+		"to":         auditTimeFilter("<"),
+	},
+	MaxLimit: 200,
+	ID:       func(e models.AuditLog) int64 { return e.ID },
+}
+
+// GetAuditLog - журнал аудита с фильтрами (страница)
+// @Summary Query audit log
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param filter[actorId] query int false "Actor user ID"
+// @Param filter[action] query string false "Action, e.g. team.kick"
+// @Param filter[entityType] query string false "Entity type: user, team, hackathon, api_key"
+// @Param filter[entityId] query int false "Entity ID"
+// @Param filter[from] query string false "From (RFC3339 or YYYY-MM-DD), inclusive"
+// @Param filter[to] query string false "To (RFC3339 or YYYY-MM-DD), exclusive; a date means the whole day"
+// @Param sort query string false "createdAt, with - for descending (default -createdAt)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "nextCursor of the previous page"
+// @Success 200 {object} pagination.Page[models.AuditLog]
+// @Failure 422 {object} map[string]interface{}
+// @Router /api/admin/audit [get]
+func (s *Server) GetAuditLog(c *gin.Context) {
+	params, err := pagination.Parse(c, auditLogSpec)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	entries, next, total, err := s.Audit.List(c.Request.Context(), params)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch audit log", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewPage(entries, next, total))
+}
+
+// parseAuditTime - RFC3339 или дата; второй результат - была ли передана только дата
+func parseAuditTime(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	return t, true, err
+}
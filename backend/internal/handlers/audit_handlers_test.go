@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetAuditLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := testDB(t, &models.AuditLog{})
+
+	actor, other := int64(1), int64(2)
+	day := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	entries := []models.AuditLog{
+		{ActorID: &actor, Action: "team.kick", EntityType: models.AuditEntityTeam, CreatedAt: day.Add(-time.Hour)},
+		{ActorID: &actor, Action: "team.kick", EntityType: models.AuditEntityTeam, CreatedAt: day.Add(time.Hour)},
+		{ActorID: &actor, Action: "user.update", EntityType: models.AuditEntityUser, CreatedAt: day.Add(23 * time.Hour)},
+		{ActorID: &other, Action: "team.kick", EntityType: models.AuditEntityTeam, CreatedAt: day.Add(2 * time.Hour)},
+		{ActorID: &actor, Action: "team.kick", EntityType: models.AuditEntityTeam, CreatedAt: day.AddDate(0, 0, 1)},
+	}
+	if err := db.Create(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{Audit: services.NewAuditLogger(repositories.NewAuditRepository(db))}
+	r := gin.New()
+	r.Use(apierror.Middleware())
+	r.GET("/api/admin/audit", s.GetAuditLog)
+
+	get := func(query url.Values) (int, pagination.Page[models.AuditLog]) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/audit?"+query.Encode(), nil))
+		var page pagination.Page[models.AuditLog]
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, page
+	}
+
+	// to=дата включает весь день, следующая полночь уже не входит
+	query := url.Values{"filter[actorId]": {"1"}, "filter[from]": {"2026-01-31"}, "filter[to]": {"2026-01-31"}, "limit": {"1"}}
+	var ids []int64
+	for {
+		status, page := get(query)
+		if status != http.StatusOK || page.Total != 2 {
+			t.Fatalf("status %d, page %+v", status, page)
+		}
+		for _, e := range page.Items {
+			ids = append(ids, e.ID)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		query.Set("cursor", *page.NextCursor)
+	}
+	if len(ids) != 2 || ids[0] != entries[2].ID || ids[1] != entries[1].ID {
+		t.Fatalf("ids %v, want [%d %d] (newest first)", ids, entries[2].ID, entries[1].ID)
+	}
+
+	for _, bad := range []url.Values{
+		{"filter[actorId]": {"me"}},
+		{"filter[from]": {"31.01.2026"}},
+		{"filter[unknown]": {"1"}},
+		{"limit": {"201"}},
+	} {
+		if status, _ := get(bad); status != http.StatusUnprocessableEntity {
+			t.Errorf("%v: status %d, want 422", bad, status)
+		}
+	}
+}
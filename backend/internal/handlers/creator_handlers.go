@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/authz"
+	"backend/internal/database"
+	"backend/internal/logging"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/validation"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// HACKATHON ACCESS HELPERS
+// ============================================
+
+// managedHackathon - хакатон из параметра :id, если текущий пользователь может им управлять.
+// При отказе сам пишет ответ (400/401/403/404/500) и возвращает false.
+func (s *Server) managedHackathon(c *gin.Context) (*models.Hackathon, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid hackathon ID"))
+		return nil, false
+	}
+
+	actor, ok := authz.ActorFromContext(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return nil, false
+	}
+
+	hackathon, err := s.Hackathons.LoadManaged(c.Request.Context(), actor, id)
+	switch {
+	case errors.Is(err, authz.ErrNotFound):
+		apierror.Abort(c, apierror.NotFound(apierror.CodeHackathonNotFound, "hackathon not found"))
+		return nil, false
+	case errors.Is(err, authz.ErrForbidden):
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "you can only manage your own hackathons"))
+		return nil, false
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("failed to load hackathon", err))
+		return nil, false
+	}
+
+	return hackathon, true
+}
+
+// hackathonParticipantRow - регистрация на хакатон вместе с профилем и названием команды
+type hackathonParticipantRow struct {
+	models.HackathonParticipant
+	User     *models.User `json:"user,omitempty"`
+	TeamName string       `json:"teamName,omitempty"`
+}
+
+// loadHackathonParticipants - участники хакатона с профилями и командами
+func loadHackathonParticipants(ctx context.Context, hackathonID int64) ([]hackathonParticipantRow, error) {
+	db := database.DB.WithContext(ctx)
+
+	var participants []models.HackathonParticipant
+	if err := db.Where("hackathon_id = ?", hackathonID).Order("created_at").Find(&participants).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch participants: %w", err)
+	}
+
+	userIDs := make([]int64, 0, len(participants))
+	teamIDs := make([]int64, 0)
+	for _, p := range participants {
+		userIDs = append(userIDs, p.UserID)
+		if p.TeamID != nil {
+			teamIDs = append(teamIDs, *p.TeamID)
+		}
+	}
+
+	users := make(map[int64]*models.User, len(userIDs))
+	if len(userIDs) > 0 {
+		var list []models.User
+		if err := db.Where("id IN ?", userIDs).Find(&list).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch participant users: %w", err)
+		}
+		for i := range list {
+			users[list[i].ID] = &list[i]
+		}
+	}
+
+	teamNames := make(map[int64]string, len(teamIDs))
+	if len(teamIDs) > 0 {
+		var teams []models.Team
+		if err := db.Where("id IN ?", teamIDs).Find(&teams).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch participant teams: %w", err)
+		}
+		for _, t := range teams {
+			teamNames[t.ID] = t.Name
+		}
+	}
+
+	rows := make([]hackathonParticipantRow, len(participants))
+	for i, p := range participants {
+		rows[i] = hackathonParticipantRow{HackathonParticipant: p, User: users[p.UserID]}
+		if p.TeamID != nil {
+			rows[i].TeamName = teamNames[*p.TeamID]
+		}
+	}
+	return rows, nil
+}
+
+// ============================================
+// HACKATHON CREATOR
+// ============================================
+
+// GetManagedHackathons - хакатоны, которыми управляет текущий пользователь
+// @Summary List hackathons I manage
+// @Description Admin gets all hackathons, hackathon_creator only their own
+// @Tags Creator
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Hackathon
+// @Router /api/creator/hackathons [get]
+func (s *Server) GetManagedHackathons(c *gin.Context) {
+	actor, ok := authz.ActorFromContext(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return
+	}
+
+	var hackathons []models.Hackathon
+	err := database.DB.
+		Scopes(authz.ScopeManaged(actor)).
+		Order("created_at DESC").
+		Find(&hackathons).Error
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch hackathons", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, hackathons)
+}
+
+// UpdateHackathonStatus - сменить статус своего хакатона
+// @Summary Change hackathon status
+// @Tags Creator
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Hackathon ID"
+// @Param request body object true "{\"status\": \"registration_open\"}"
+// @Success 200 {object} models.Hackathon
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/creator/hackathons/{id}/status [put]
+func (s *Server) UpdateHackathonStatus(c *gin.Context) {
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Status models.HackathonStatus `json:"status" binding:"required,hackathon_status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+
+	previous := hackathon.Status
+	if err := database.DB.Model(hackathon).Update("status", req.Status).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to update hackathon status", err))
+		return
+	}
+	recordAudit(c, s.Audit, models.AuditActionHackathonStatus, models.AuditEntityHackathon, hackathon.ID,
+		gin.H{"status": previous}, gin.H{"status": req.Status})
+
+	c.JSON(http.StatusOK, hackathon)
+}
+
+// GiveHackathonCase - выдать кейсы участникам своего хакатона
+// @Summary Give cases to hackathon participants
+// @Description All users must be registered for the hackathon
+// @Tags Creator
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Hackathon ID"
+// @Param request body models.GiveCaseRequest true "Give case request"
+// @Param Idempotency-Key header string false "Repeat the request safely: the same key replays the first response"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/creator/hackathons/{id}/cases/give [post]
+func (s *Server) GiveHackathonCase(c *gin.Context) {
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
+		return
+	}
+
+	var req models.GiveCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+	if len(req.UserIDs) == 0 {
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "userIds must not be empty"))
+		return
+	}
+
+	outsiders, err := s.Hackathons.NonParticipants(c.Request.Context(), hackathon.ID, req.UserIDs)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to check participants", err))
+		return
+	}
+	if len(outsiders) > 0 {
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "users are not participants of this hackathon").
+			WithMeta("userIds", outsiders))
+		return
+	}
+
+	given := s.Cases.Give(c.Request.Context(), req)
+	givenCount := len(given)
+	recordAudit(c, s.Audit, models.AuditActionCaseGive, models.AuditEntityHackathon, hackathon.ID, nil, gin.H{
+		"userIds":    given,
+		"caseType":   req.CaseType,
+		"caseName":   req.CaseName,
+		"rarity":     req.Rarity,
+		"givenCount": givenCount,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Cases given successfully",
+		"givenCount": givenCount,
+		"totalUsers": len(req.UserIDs),
+	})
+}
+
+// ExportHackathon - выгрузка участников и команд своего хакатона
+// @Summary Export hackathon participants and teams
+// @Tags Creator
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path int true "Hackathon ID"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/creator/hackathons/{id}/export [get]
+func (s *Server) ExportHackathon(c *gin.Context) {
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "format must be json or csv"))
+		return
+	}
+
+	participants, err := loadHackathonParticipants(c.Request.Context(), hackathon.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch participants", err))
+		return
+	}
+
+	if format == "csv" {
+		writeParticipantsCSV(c, hackathon, participants)
+		return
+	}
+
+	var teams []models.Team
+	if err := database.DB.Where("hackathon_id = ?", hackathon.ID).Find(&teams).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch teams", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hackathon":    hackathon,
+		"participants": participants,
+		"teams":        teams,
+		"exportedAt":   time.Now().UTC(),
+	})
+}
+
+// writeParticipantsCSV - участники хакатона в виде CSV-файла
+func writeParticipantsCSV(c *gin.Context, hackathon *models.Hackathon, participants []hackathonParticipantRow) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="hackathon-%d-participants.csv"`, hackathon.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"userId", "username", "name", "experience", "skills", "contactInfo", "status", "teamId", "teamName", "registeredAt"})
+	for _, p := range participants {
+		var username, name, experience, skills, contact string
+		if p.User != nil {
+			username = p.User.Username
+			name = p.User.Name
+			experience = p.User.Experience
+			skills = strings.Join(p.User.Skills, ";")
+			contact = p.User.ContactInfo
+		}
+		var teamID string
+		if p.TeamID != nil {
+			teamID = strconv.FormatInt(*p.TeamID, 10)
+		}
+		_ = w.Write(csvRow(
+			strconv.FormatInt(p.UserID, 10),
+			username,
+			name,
+			experience,
+			skills,
+			contact,
+			p.Status,
+			teamID,
+			p.TeamName,
+			p.CreatedAt.UTC().Format(time.RFC3339),
+		))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		middleware.Logger(c).Error("failed to write hackathon CSV", logging.Err(err))
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/authz"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/internal/validation"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB - отдельная БД SQLite в памяти со схемой нужных моделей
+func testDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// creatorRouter - маршруты создателя от имени пользователя userID с ролью hackathon_creator
+func creatorRouter(s *Server, userID int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	validation.Register()
+
+	r := gin.New()
+	r.Use(apierror.Middleware())
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("user_role", string(models.RoleHackathonCreator))
+	})
+	r.POST("/api/creator/hackathons/:id/cases/give", s.GiveHackathonCase)
+	return r
+}
+
+func TestGiveHackathonCaseRarity(t *testing.T) {
+	db := testDB(t, &models.Hackathon{}, &models.HackathonParticipant{}, &models.UserCase{}, &models.AuditLog{})
+	hackathon := models.Hackathon{Name: "Hack", CreatorID: 1}
+	if err := db.Create(&hackathon).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.HackathonParticipant{HackathonID: hackathon.ID, UserID: 2, Status: "looking"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{
+		Hackathons: authz.NewHackathonPolicy(db),
+		Cases:      services.NewCaseService(repositories.NewInventoryRepository(db)),
+		Audit:      services.NewAuditLogger(repositories.NewAuditRepository(db)),
+	}
+	r := creatorRouter(s, 1)
+	give := func(rarity string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"userIds":[2],"caseType":"hackathon","caseName":"Hack","rarity":%q}`, rarity)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/creator/hackathons/%d/cases/give", hackathon.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Кейс с неизвестной редкостью не выдаётся - иначе он не откроется
+	w := give("mythic")
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"field":"rarity"`) {
+		t.Fatalf("mythic: %d %s", w.Code, w.Body.String())
+	}
+	var count int64
+	db.Model(&models.UserCase{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("%d cases given with an unknown rarity", count)
+	}
+
+	if w := give("epic"); w.Code != http.StatusOK {
+		t.Fatalf("epic: %d %s", w.Code, w.Body.String())
+	}
+	db.Model(&models.UserCase{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("%d cases given, want 1", count)
+	}
+}
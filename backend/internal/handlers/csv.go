@@ -0,0 +1,25 @@
+package handlers
+
+import "strings"
+
+// csvFormulaPrefixes - с этих символов Excel и LibreOffice начинают формулу
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvCell - значение ячейки CSV, которое табличный редактор не примет за формулу.
+// Названия команд и профили пишут пользователи, поэтому "=HYPERLINK(...)" в имени
+// не должен выполниться у организатора, открывшего выгрузку.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// csvRow - строка CSV с экранированными ячейками
+func csvRow(values ...string) []string {
+	row := make([]string, len(values))
+	for i, v := range values {
+		row[i] = csvCell(v)
+	}
+	return row
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"backend/internal/models"
+	"encoding/csv"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"team", "team"},
+		{`=HYPERLINK("http://evil")`, `'=HYPERLINK("http://evil")`},
+		{"+1+2", "'+1+2"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+		{"'quoted", "'quoted"},
+	}
+	for _, tt := range tests {
+		if got := csvCell(tt.value); got != tt.want {
+			t.Errorf("csvCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriteParticipantsCSVEscapesFormulas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	teamID := int64(3)
+	writeParticipantsCSV(c, &models.Hackathon{ID: 1}, []hackathonParticipantRow{{
+		HackathonParticipant: models.HackathonParticipant{UserID: 2, TeamID: &teamID, Status: "in_team"},
+		User:                 &models.User{Username: "@admin", Name: "=1+1", ContactInfo: "+79990000000"},
+		TeamName:             "-team",
+	}})
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("records %v, err %v", records, err)
+	}
+	row := records[1]
+	for i, want := range map[int]string{0: "2", 1: "'@admin", 2: "'=1+1", 5: "'+79990000000", 7: "3", 8: "'-team"} {
+		if row[i] != want {
+			t.Errorf("column %s = %q, want %q", records[0][i], row[i], want)
+		}
+	}
+}
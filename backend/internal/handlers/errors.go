@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/repositories"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// recordError - ошибка API для неудачной выборки записи: notFound, если записи нет
+// (или err == nil, а запись не подошла), иначе 500 с исходной ошибкой в логе -
+// сбой БД не должен выглядеть как "не найдено"
+func recordError(err error, notFound *apierror.Error) *apierror.Error {
+	if err == nil {
+		return notFound
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, repositories.ErrNotFound) {
+		return notFound.WithCause(err)
+	}
+	return apierror.Internal("internal server error", err)
+}
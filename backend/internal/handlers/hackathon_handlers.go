@@ -1,46 +1,89 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
+	"backend/internal/logging"
 	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/validation"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // ============================================
 // HACKATHON CRUD HANDLERS
 // ============================================
 
-// GetHackathonsReal - получить список хакатонов из БД
+// hackathonListSpec - сортировки и фильтры списка хакатонов
+var hackathonListSpec = &pagination.Spec[models.Hackathon]{
+	Sorts: map[string]pagination.Sort[models.Hackathon]{
+		"createdAt": pagination.TimeSort("created_at", func(h models.Hackathon) time.Time { return h.CreatedAt }),
+		// Хакатоны без даты начала - как самые ранние
+		"startDate": pagination.TimeSort("COALESCE(start_date, 'epoch'::timestamptz)", func(h models.Hackathon) time.Time {
+			if h.StartDate == nil {
+				return time.Unix(0, 0)
+			}
+			return *h.StartDate
+		}),
+		"name": pagination.StringSort("name", func(h models.Hackathon) string { return h.Name }),
+	},
+	DefaultSort: "-createdAt",
+	Filters: map[string]pagination.Filter{
+		"status": pagination.Equals("status",
+			func(v string) bool { return models.HackathonStatus(v).IsValid() },
+			"must be one of: draft, registration_open, active, completed"),
+		"search": pagination.Search("name", "description"),
+	},
+	ID: func(h models.Hackathon) int64 { return h.ID },
+}
+
+// GetHackathonsReal - получить список хакатонов из БД (страница)
 func (s *Server) GetHackathonsReal(c *gin.Context) {
-	var hackathons []models.Hackathon
+	params, err := pagination.Parse(c, hackathonListSpec)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
 
-	query := database.DB.Order("created_at DESC")
+	db := database.DB.WithContext(c.Request.Context())
 
-	// Filter by status if provided
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
+	var total int64
+	if err := params.Filter(db.Model(&models.Hackathon{})).Count(&total).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to count hackathons", err))
+		return
 	}
 
-	if err := query.Find(&hackathons).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch hackathons"})
+	var hackathons []models.Hackathon
+	if err := params.Query(db).Find(&hackathons).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch hackathons", err))
 		return
 	}
+	hackathons, next := params.Next(hackathons)
 
-	// Get participant counts for each hackathon
+	// Participant counts only for hackathons on this page
+	hackathonIDs := make([]int64, len(hackathons))
+	for i, h := range hackathons {
+		hackathonIDs[i] = h.ID
+	}
 	type result struct {
 		HackathonID int64
 		Count       int64
 	}
 	var counts []result
-	database.DB.Model(&models.HackathonParticipant{}).
-		Select("hackathon_id, count(*) as count").
-		Group("hackathon_id").
-		Find(&counts)
+	if len(hackathonIDs) > 0 {
+		db.Model(&models.HackathonParticipant{}).
+			Select("hackathon_id, count(*) as count").
+			Where("hackathon_id IN ?", hackathonIDs).
+			Group("hackathon_id").
+			Find(&counts)
+	}
 
 	countMap := make(map[int64]int64)
 	for _, r := range counts {
@@ -48,9 +91,9 @@ func (s *Server) GetHackathonsReal(c *gin.Context) {
 	}
 
 	// Build response
-	response := make([]gin.H, len(hackathons))
+	items := make([]gin.H, len(hackathons))
 	for i, h := range hackathons {
-		response[i] = gin.H{
+		items[i] = gin.H{
 			"id":                   h.ID,
 			"name":                 h.Name,
 			"description":          h.Description,
@@ -68,7 +111,7 @@ func (s *Server) GetHackathonsReal(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, pagination.NewPage(items, next, total))
 }
 
 // GetActiveHackathonsReal - получить активные хакатоны
@@ -81,7 +124,7 @@ func (s *Server) GetActiveHackathonsReal(c *gin.Context) {
 		Find(&hackathons).Error
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch hackathons"})
+		apierror.Abort(c, apierror.Internal("failed to fetch hackathons", err))
 		return
 	}
 
@@ -92,13 +135,13 @@ func (s *Server) GetActiveHackathonsReal(c *gin.Context) {
 func (s *Server) GetHackathonByID(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hackathon ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid hackathon ID"))
 		return
 	}
 
 	var hackathon models.Hackathon
 	if err := database.DB.First(&hackathon, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "hackathon not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeHackathonNotFound, "hackathon not found")))
 		return
 	}
 
@@ -133,24 +176,24 @@ func (s *Server) GetHackathonByID(c *gin.Context) {
 	})
 }
 
-// CreateHackathonReal - создать хакатон (admin)
+// CreateHackathonReal - создать хакатон (admin, hackathon_creator); создателем становится текущий пользователь
 func (s *Server) CreateHackathonReal(c *gin.Context) {
 	var req struct {
 		Name                 string   `json:"name" binding:"required"`
 		Description          string   `json:"description"`
-		ImageUrl             string   `json:"imageUrl"`
-		StartDate            string   `json:"startDate"`
-		EndDate              string   `json:"endDate"`
-		RegistrationDeadline string   `json:"registrationDeadline"`
-		TeamSize             int      `json:"teamSize"`
-		MaxTeams             int      `json:"maxTeams"`
+		ImageUrl             string   `json:"imageUrl" binding:"omitempty,image_url"`
+		StartDate            string   `json:"startDate" binding:"omitempty,date,date_before=EndDate"`
+		EndDate              string   `json:"endDate" binding:"omitempty,date"`
+		RegistrationDeadline string   `json:"registrationDeadline" binding:"omitempty,date,date_before=StartDate"`
+		TeamSize             int      `json:"teamSize" binding:"gte=0"`
+		MaxTeams             *int     `json:"maxTeams" binding:"omitempty,gte=0"`
 		Tags                 []string `json:"tags"`
 		RequiredStack        []string `json:"requiredStack"`
-		Status               string   `json:"status"`
+		Status               string   `json:"status" binding:"omitempty,hackathon_status"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -160,7 +203,6 @@ func (s *Server) CreateHackathonReal(c *gin.Context) {
 		Name:          req.Name,
 		CreatorID:     userID,
 		TeamSize:      req.TeamSize,
-		MaxTeams:      req.MaxTeams,
 		Tags:          req.Tags,
 		RequiredStack: req.RequiredStack,
 		Status:        models.HackathonStatus(req.Status),
@@ -169,6 +211,9 @@ func (s *Server) CreateHackathonReal(c *gin.Context) {
 	if hackathon.TeamSize == 0 {
 		hackathon.TeamSize = 4
 	}
+	if req.MaxTeams != nil {
+		hackathon.MaxTeams = *req.MaxTeams
+	}
 	if hackathon.Status == "" {
 		hackathon.Status = models.HackathonStatusDraft
 	}
@@ -180,88 +225,54 @@ func (s *Server) CreateHackathonReal(c *gin.Context) {
 		hackathon.ImageUrl = &req.ImageUrl
 	}
 
-	// Helper function to parse date in multiple formats
-	parseDate := func(dateStr string) *time.Time {
-		if dateStr == "" {
-			return nil
-		}
-		// Try RFC3339 first (2025-12-15T10:00:00Z)
-		if t, err := time.Parse(time.RFC3339, dateStr); err == nil {
-			return &t
-		}
-		// Try date only format (2025-12-15)
-		if t, err := time.Parse("2006-01-02", dateStr); err == nil {
-			return &t
-		}
-		// Try datetime without timezone (2025-12-15T10:00:00)
-		if t, err := time.Parse("2006-01-02T15:04:05", dateStr); err == nil {
-			return &t
-		}
-		return nil
-	}
-
 	hackathon.StartDate = parseDate(req.StartDate)
 	hackathon.EndDate = parseDate(req.EndDate)
 	hackathon.RegistrationDeadline = parseDate(req.RegistrationDeadline)
 
 	if err := database.DB.Create(&hackathon).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create hackathon"})
+		apierror.Abort(c, apierror.Internal("failed to create hackathon", err))
 		return
 	}
+	recordAudit(c, s.Audit, models.AuditActionHackathonCreate, models.AuditEntityHackathon, hackathon.ID, nil, hackathon)
 
 	c.JSON(http.StatusCreated, hackathon)
 }
 
-// UpdateHackathonReal - обновить хакатон (admin)
+// UpdateHackathonReal - обновить хакатон (admin - любой, hackathon_creator - свой)
 func (s *Server) UpdateHackathonReal(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hackathon ID"})
-		return
-	}
-
-	var hackathon models.Hackathon
-	if err := database.DB.First(&hackathon, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "hackathon not found"})
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
 		return
 	}
 
 	var req struct {
 		Name                 string   `json:"name"`
 		Description          string   `json:"description"`
-		ImageUrl             string   `json:"imageUrl"`
-		StartDate            string   `json:"startDate"`
-		EndDate              string   `json:"endDate"`
-		RegistrationDeadline string   `json:"registrationDeadline"`
-		TeamSize             int      `json:"teamSize"`
-		MinTeamSize          int      `json:"minTeamSize"`
-		MaxTeamSize          int      `json:"maxTeamSize"`
-		MaxTeams             int      `json:"maxTeams"`
+		ImageUrl             string   `json:"imageUrl" binding:"omitempty,image_url"`
+		StartDate            string   `json:"startDate" binding:"omitempty,date,date_before=EndDate"`
+		EndDate              string   `json:"endDate" binding:"omitempty,date"`
+		RegistrationDeadline string   `json:"registrationDeadline" binding:"omitempty,date,date_before=StartDate"`
+		TeamSize             int      `json:"teamSize" binding:"gte=0"`
+		MinTeamSize          int      `json:"minTeamSize" binding:"gte=0"`
+		MaxTeamSize          int      `json:"maxTeamSize" binding:"gte=0"`
+		MaxTeams             *int     `json:"maxTeams" binding:"omitempty,gte=0"`
 		Tags                 []string `json:"tags"`
 		RequiredStack        []string `json:"requiredStack"`
-		Status               string   `json:"status"`
+		Status               string   `json:"status" binding:"omitempty,hackathon_status"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
-	// Helper function to parse date in multiple formats
-	parseDate := func(dateStr string) *time.Time {
-		if dateStr == "" {
-			return nil
-		}
-		if t, err := time.Parse(time.RFC3339, dateStr); err == nil {
-			return &t
-		}
-		if t, err := time.Parse("2006-01-02", dateStr); err == nil {
-			return &t
-		}
-		if t, err := time.Parse("2006-01-02T15:04:05", dateStr); err == nil {
-			return &t
-		}
-		return nil
+	// Даты, которые не пришли в запросе, берём сохранённые: порядок проверяется для итоговых значений
+	deadline := orDate(parseDate(req.RegistrationDeadline), hackathon.RegistrationDeadline)
+	start := orDate(parseDate(req.StartDate), hackathon.StartDate)
+	end := orDate(parseDate(req.EndDate), hackathon.EndDate)
+	if details := validation.DateOrder(deadline, start, end); len(details) > 0 {
+		apierror.Abort(c, apierror.Validation(details...))
+		return
 	}
 
 	updates := map[string]interface{}{}
@@ -278,8 +289,9 @@ func (s *Server) UpdateHackathonReal(c *gin.Context) {
 	if req.MaxTeamSize > 0 {
 		updates["team_size"] = req.MaxTeamSize
 	}
-	if req.MaxTeams >= 0 {
-		updates["max_teams"] = req.MaxTeams
+	// Поле не пришло - лимит не трогаем; 0 означает "без ограничений"
+	if req.MaxTeams != nil {
+		updates["max_teams"] = *req.MaxTeams
 	}
 	if req.Status != "" {
 		updates["status"] = req.Status
@@ -303,31 +315,42 @@ func (s *Server) UpdateHackathonReal(c *gin.Context) {
 		updates["registration_deadline"] = *t
 	}
 
-	if err := database.DB.Model(&hackathon).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update hackathon"})
+	before := *hackathon
+	if err := database.DB.Model(hackathon).Updates(updates).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to update hackathon", err))
 		return
 	}
 
 	// Reload
-	database.DB.First(&hackathon, id)
+	database.DB.First(hackathon, hackathon.ID)
+	recordAudit(c, s.Audit, models.AuditActionHackathonUpdate, models.AuditEntityHackathon, hackathon.ID, before, hackathon)
 	c.JSON(http.StatusOK, hackathon)
 }
 
 // DeleteHackathonReal - удалить хакатон (admin)
 func (s *Server) DeleteHackathonReal(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
+		return
+	}
+
+	// Участники и хакатон удаляются вместе: иначе при сбое остаётся хакатон без участников
+	err := database.DB.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Delete(&models.HackathonParticipant{}).Error; err != nil {
+			return fmt.Errorf("failed to delete participants: %w", err)
+		}
+		return tx.Delete(&models.Hackathon{}, hackathon.ID).Error
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hackathon ID"})
+		apierror.Abort(c, apierror.Internal("failed to delete hackathon", err))
 		return
 	}
 
-	// Delete participants first
-	database.DB.Where("hackathon_id = ?", id).Delete(&models.HackathonParticipant{})
+	recordAudit(c, s.Audit, models.AuditActionHackathonDelete, models.AuditEntityHackathon, hackathon.ID, hackathon, nil)
 
-	// Delete hackathon
-	if err := database.DB.Delete(&models.Hackathon{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete hackathon"})
-		return
+	// Ключи интеграций удалённого хакатона больше не должны работать
+	if err := s.APIKeys.RevokeAllForHackathon(c.Request.Context(), hackathon.ID); err != nil {
+		middleware.Logger(c).Error("failed to revoke api keys of deleted hackathon", logging.HackathonID(hackathon.ID), logging.Err(err))
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "hackathon deleted"})
@@ -337,22 +360,16 @@ func (s *Server) DeleteHackathonReal(c *gin.Context) {
 // HACKATHON PARTICIPANTS
 // ============================================
 
-// GetHackathonParticipants - получить участников хакатона
+// GetHackathonParticipants - получить участников хакатона (admin - любого, hackathon_creator - своего)
 func (s *Server) GetHackathonParticipants(c *gin.Context) {
-	hackathonID, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hackathon ID"})
+	hackathon, ok := s.managedHackathon(c)
+	if !ok {
 		return
 	}
 
-	var participants []models.HackathonParticipant
-	err = database.DB.
-		Preload("User").
-		Where("hackathon_id = ?", hackathonID).
-		Find(&participants).Error
-
+	participants, err := loadHackathonParticipants(c.Request.Context(), hackathon.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch participants"})
+		apierror.Abort(c, apierror.Internal("failed to fetch participants", err))
 		return
 	}
 
@@ -363,7 +380,7 @@ func (s *Server) GetHackathonParticipants(c *gin.Context) {
 func (s *Server) RegisterForHackathonReal(c *gin.Context) {
 	hackathonID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hackathon ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid hackathon ID"))
 		return
 	}
 
@@ -372,12 +389,12 @@ func (s *Server) RegisterForHackathonReal(c *gin.Context) {
 	// Check if hackathon exists and is open for registration
 	var hackathon models.Hackathon
 	if err := database.DB.First(&hackathon, hackathonID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "hackathon not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeHackathonNotFound, "hackathon not found")))
 		return
 	}
 
 	if hackathon.Status != models.HackathonStatusRegistrationOpen {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "registration is not open"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeRegistrationClosed, "registration is not open"))
 		return
 	}
 
@@ -388,7 +405,7 @@ func (s *Server) RegisterForHackathonReal(c *gin.Context) {
 		First(&existing).Error
 
 	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "already registered"})
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyRegistered, "already registered"))
 		return
 	}
 
@@ -400,7 +417,7 @@ func (s *Server) RegisterForHackathonReal(c *gin.Context) {
 	}
 
 	if err := database.DB.Create(&participant).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register"})
+		apierror.Abort(c, apierror.Internal("failed to register", err))
 		return
 	}
 
@@ -412,3 +429,27 @@ func (s *Server) RegisterForHackathonReal(c *gin.Context) {
 		"participant": participant,
 	})
 }
+
+// ============================================
+// HELPER FUNCTIONS
+// ============================================
+
+// parseDate - дата из запроса; формат уже проверен тегом date, пустая строка - nil
+func parseDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := validation.ParseDate(value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// orDate - новое значение, если оно пришло, иначе сохранённое
+func orDate(updated, stored *time.Time) *time.Time {
+	if updated != nil {
+		return updated
+	}
+	return stored
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
+	"backend/internal/logging"
 	"backend/internal/middleware"
+	"backend/internal/services"
 	"backend/internal/types"
-	"context"
-	"fmt"
+	"backend/internal/validation"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 
@@ -14,55 +18,62 @@ import (
 
 // takeToken godoc
 // @Summary Takes Telegram token
-// @Description Validate tg token, register user if new, and return login result
+// @Description Exchange a one-time login token from the TG bot for a JWT pair. Registers the user if new. If the token was requested with a PKCE-style challenge, codeVerifier is required.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body types.Token true "Token"
 // @Success 200 {object} map[string]interface{} "Returns user info with isNewUser and registered flags"
 // @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/token [post]
-func takeToken(c *gin.Context) {
+func (s *Server) takeToken(c *gin.Context) {
 	var req types.Token
 	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
-		fmt.Printf("Token bind error: %v\n", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
-	fmt.Printf("Received token: '%s' (len=%d)\n", req.Token, len(req.Token))
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
 
-	val, err := redisConn.Get(c.Request.Context(), req.Token).Result()
+	// Перебор токенов: после серии ошибок IP блокируется на время
+	blocked, retryAfter, err := s.LoginTokens.IsBlocked(ctx, ip)
 	if err != nil {
-		fmt.Printf("Redis GET error for token '%s': %v\n", req.Token, err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid or expired token",
-		})
+		apierror.Abort(c, apierror.Internal("failed to check token", err))
+		return
+	}
+	if blocked {
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeTooManyRequests, "too many failed attempts, try again later").
+			WithMeta("retryAfter", int(retryAfter.Seconds())))
 		return
 	}
 
-	ans := splitTokenData(val)
-	idStr, name, time := ans[0], ans[1], ans[2]
-	fmt.Println("Token time:", time)
-
-	telegramUserID, err := strconv.ParseInt(idStr, 10, 64)
+	payload, err := s.LoginTokens.Consume(ctx, req.Token, req.CodeVerifier)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID in token",
-		})
+		if !errors.Is(err, services.ErrLoginTokenInvalid) &&
+			!errors.Is(err, services.ErrLoginTokenExpired) &&
+			!errors.Is(err, services.ErrLoginTokenVerifierMismatch) {
+			apierror.Abort(c, apierror.Internal("failed to check token", err))
+			return
+		}
+
+		middleware.Logger(c).Info("login token rejected", logging.Err(err))
+		if err := s.LoginTokens.RecordFailure(ctx, ip); err != nil {
+			middleware.Logger(c).Error("failed to record login failure", logging.Err(err))
+		}
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeTokenInvalid, "invalid or expired token"))
 		return
 	}
 
-	ctx := context.Background()
+	telegramUserID := payload.TelegramID
+	name := payload.Username
+
 	userExists, err := database.UserExists(ctx, telegramUserID)
 	if err != nil {
-		fmt.Printf("Error checking user existence: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to check user",
-		})
+		apierror.Abort(c, apierror.Internal("failed to check user", err))
 		return
 	}
 
@@ -71,34 +82,27 @@ func takeToken(c *gin.Context) {
 	// Создаём или получаем пользователя
 	user, err := database.CreateUser(ctx, telegramUserID, name)
 	if err != nil {
-		fmt.Printf("Error creating/getting user: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to register user",
-		})
+		apierror.Abort(c, apierror.Internal("failed to register user", err))
 		return
 	}
 	if isNewUser {
-		fmt.Printf("New user registered: %d (%s) with ID: %d\n", telegramUserID, name, user.ID)
+		middleware.Logger(c).Info("new user registered", logging.UserID(user.ID), slog.Int64("telegram_id", telegramUserID))
 	}
 
-	// Delete the token from Redis after successful use
-	redisConn.Del(ctx, req.Token)
-
-	// Generate JWT token for the user - используем реальный ID из базы!
-	jwtToken, err := middleware.GenerateToken(user.ID, telegramUserID, string(user.Role))
+	// Generate JWT token pair for the user - используем реальный ID из базы!
+	jwtToken, refreshToken, err := s.issueTokenPair(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate auth token",
-		})
+		apierror.Abort(c, apierror.Internal("failed to generate auth token", err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token":      jwtToken,
-		"id":         idStr,
-		"name":       name,
-		"isNewUser":  isNewUser,
-		"registered": isNewUser,
+		"token":        jwtToken,
+		"refreshToken": refreshToken,
+		"id":           strconv.FormatInt(telegramUserID, 10),
+		"name":         name,
+		"isNewUser":    isNewUser,
+		"registered":   isNewUser,
 		"user": gin.H{
 			"id":              user.ID,
 			"telegramId":      telegramUserID,
@@ -115,35 +119,3 @@ func takeToken(c *gin.Context) {
 		},
 	})
 }
-
-// adminLogin godoc
-// @Summary      Admin login
-// @Tags         admin
-// @Accept       json
-// @Produce      json
-// @Param        input  body      types.LoginAdmin  true  "Admin credentials"
-// @Success      200    {object}  map[string]interface{}
-// @Failure      400    {object}  map[string]interface{}  "Bad request"
-// @Failure      401    {object}  map[string]interface{}  "Invalid credentials"
-// @Router       /admin/api/login [post]
-func adminLogin(c *gin.Context) {
-	var req types.LoginAdmin
-
-	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	if req.UserName == ADMINUSER && req.Password == ADMINPASS {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
-		})
-		return
-	}
-	c.JSON(http.StatusUnauthorized, gin.H{
-		"error": "Invalid credentials",
-	})
-
-}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"backend/internal/database"
+	"backend/internal/health"
+	"backend/internal/lifecycle"
+	"backend/internal/logging"
+	"backend/internal/middleware"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// readinessCheckTimeout - сколько ждать каждую зависимость в /readyz
+const readinessCheckTimeout = 2 * time.Second
+
+// ============================================
+// READINESS CHECKS
+// ============================================
+
+// newReadinessChecker - Postgres, Redis, версия схемы и фоновые задачи
+func newReadinessChecker(db *gorm.DB, redisConn *redis.Client, migrator *database.Migrator, tasks *lifecycle.Group) *health.Checker {
+	checker := health.NewChecker(readinessCheckTimeout)
+
+	checker.Add("postgres", func(ctx context.Context) (string, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return "", err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return "", err
+		}
+		stats := sqlDB.Stats()
+		return fmt.Sprintf("%d open connections, %d in use", stats.OpenConnections, stats.InUse), nil
+	})
+
+	checker.Add("redis", func(ctx context.Context) (string, error) {
+		return "", redisConn.Ping(ctx).Err()
+	})
+
+	// Реплика со старой схемой не сможет обслужить запросы нового кода
+	checker.Add("migrations", func(ctx context.Context) (string, error) {
+		current, pending, err := migrator.Version(ctx)
+		if err != nil {
+			return "", err
+		}
+		detail := fmt.Sprintf("version %d", current)
+		if pending > 0 {
+			return detail, fmt.Errorf("%d pending migrations", pending)
+		}
+		return detail, nil
+	})
+
+	checker.Add("background_tasks", func(context.Context) (string, error) {
+		active, stopped := tasks.Status()
+		detail := fmt.Sprintf("%d running", active)
+		if stopped {
+			return detail, errors.New("shutting down")
+		}
+		return detail, nil
+	})
+
+	return checker
+}
+
+// ============================================
+// HANDLERS
+// ============================================
+
+// Livez godoc
+// @Summary Liveness probe
+// @Description Процесс жив и обслуживает HTTP; зависимости не проверяются
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /livez [get]
+func (s *Server) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Проверяет Postgres, Redis, версию схемы и фоновые задачи; 503, если реплика не может обслуживать запросы.
+// @Description В ответе только статусы проверок: ошибки пишутся в лог, полный отчёт - /readyz на порту метрик
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (s *Server) Readyz(c *gin.Context) {
+	report := s.Readiness.Run(c.Request.Context())
+	for name, result := range report.Checks {
+		if result.Status != health.StatusOK {
+			middleware.Logger(c).Warn("readiness check failed",
+				slog.String("check", name),
+				slog.String("detail", result.Detail),
+				slog.String(logging.KeyError, result.Error),
+			)
+		}
+	}
+	c.JSON(report.HTTPStatus(), report.Public())
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"backend/internal/health"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadyzHidesDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", func(context.Context) (string, error) { return "3 open connections, 1 in use", nil })
+	checker.Add("redis", func(context.Context) (string, error) {
+		return "", errors.New("dial tcp 10.0.0.5:6379: connect: connection refused")
+	})
+
+	s := &Server{Readiness: checker}
+	r := gin.New()
+	r.GET("/readyz", s.Readyz)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// Ни адреса Redis, ни состояния пула наружу
+	want := `{"status":"fail","checks":{"postgres":{"status":"ok"},"redis":{"status":"fail"}}}`
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != want {
+		t.Fatalf("%d %s", w.Code, w.Body.String())
+	}
+}
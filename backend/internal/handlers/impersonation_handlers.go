@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/services"
+	"backend/internal/validation"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// ADMIN IMPERSONATION ("view as user")
+// ============================================
+
+// impersonateRequest - зачем админ смотрит аккаунт и на сколько минут
+type impersonateRequest struct {
+	Reason     string `json:"reason"`
+	TTLMinutes int    `json:"ttlMinutes"`
+}
+
+// AdminImpersonateUser - выдать короткоживущий токен только для чтения от имени пользователя
+// @Summary Impersonate user (read-only)
+// @Description Issues an access token for the user with the admin in the act claim. Only GET/HEAD/OPTIONS requests are allowed with it. No refresh token is issued.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body object true "{\"reason\": \"support ticket #42\", \"ttlMinutes\": 15}"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/admin/users/{id}/impersonate [post]
+func (s *Server) AdminImpersonateUser(c *gin.Context) {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return
+	}
+	adminRole, _ := middleware.GetUserRole(c)
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
+		return
+	}
+
+	var req impersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, validation.Error(err))
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "reason is required"))
+		return
+	}
+
+	ttl := services.DefaultImpersonationTTL
+	if req.TTLMinutes != 0 {
+		// Диапазон проверяем до умножения: огромное значение переполнит Duration и пройдёт проверку
+		if req.TTLMinutes < 1 || req.TTLMinutes > int(services.MaxImpersonationTTL/time.Minute) {
+			apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "ttlMinutes must be between 1 and 60"))
+			return
+		}
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	user, err := s.UserRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
+		return
+	}
+	if user.ID == adminID {
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "cannot impersonate yourself"))
+		return
+	}
+	// Токен админа дал бы доступ к админским маршрутам - такое "только чтение" не нужно
+	if user.Role == models.RoleAdmin {
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "admins cannot be impersonated"))
+		return
+	}
+	if user.ErasedAt != nil {
+		apierror.Abort(c, apierror.Conflict(apierror.CodeUserErased, "user data has been erased"))
+		return
+	}
+
+	session, err := s.Impersonations.Start(c.Request.Context(), adminID, user.ID, req.Reason, ttl, sessionClient(c))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to start impersonation", err))
+		return
+	}
+
+	accessToken, err := middleware.GenerateImpersonationToken(user.ID, user.TelegramUserID, string(user.Role), adminID, adminRole, session.ID, ttl)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to generate token", err))
+		return
+	}
+
+	recordAudit(c, s.Audit, models.AuditActionImpersonateStart, models.AuditEntityUser, user.ID, nil, gin.H{
+		"sessionId": session.ID,
+		"reason":    session.Reason,
+		"expiresAt": session.ExpiresAt,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"accessToken": accessToken,
+		"expiresAt":   session.ExpiresAt,
+		"session":     session,
+	})
+}
+
+// GetImpersonations - история сеансов имперсонации (последние limit, без курсора:
+// id сеанса - строка, а курсор страниц держит числовой id)
+// @Summary List impersonation sessions
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param adminId query int false "Admin user ID"
+// @Param userId query int false "Impersonated user ID"
+// @Param limit query int false "Max sessions (default 50, max 200)"
+// @Success 200 {object} pagination.Page[models.ImpersonationSession]
+// @Router /api/admin/impersonations [get]
+func (s *Server) GetImpersonations(c *gin.Context) {
+	adminID, _ := strconv.ParseInt(c.Query("adminId"), 10, 64)
+	userID, _ := strconv.ParseInt(c.Query("userId"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	sessions, total, err := s.Impersonations.List(c.Request.Context(), adminID, userID, limit)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch impersonation sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewPage(sessions, nil, total))
+}
+
+// EndImpersonation - досрочно завершить сеанс имперсонации
+// @Summary End impersonation session
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Impersonation session ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/admin/impersonations/{id} [delete]
+func (s *Server) EndImpersonation(c *gin.Context) {
+	s.endImpersonation(c, c.Param("id"))
+}
+
+// endImpersonation - завершить сеанс и записать это в журнал
+func (s *Server) endImpersonation(c *gin.Context, sessionID string) {
+	session, err := s.Impersonations.End(c.Request.Context(), sessionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImpersonationNotFound):
+			apierror.Abort(c, apierror.NotFound(apierror.CodeSessionNotFound, "impersonation session not found"))
+		case errors.Is(err, services.ErrImpersonationEnded):
+			apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyProcessed, "impersonation session has already ended"))
+		default:
+			apierror.Abort(c, apierror.Internal("failed to end impersonation", err))
+		}
+		return
+	}
+
+	recordAudit(c, s.Audit, models.AuditActionImpersonateEnd, models.AuditEntityUser, session.UserID, nil, gin.H{
+		"sessionId": session.ID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "impersonation ended"})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/internal/validation"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestAdminImpersonateUserTTL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validation.Register()
+
+	key, err := middleware.NewHMACKey("test", []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := middleware.NewKeySet("test", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := middleware.GetKeySet()
+	middleware.SetKeySet(ks)
+	t.Cleanup(func() { middleware.SetKeySet(previous) })
+
+	db := testDB(t, &models.User{}, &models.ImpersonationSession{}, &models.AuditLog{})
+	if err := db.Create(&models.User{ID: 42, TelegramUserID: 4200, Username: "user"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	s := &Server{
+		UserRepo:       repositories.NewUserRepository(db),
+		Impersonations: services.NewImpersonationStore(db, client),
+		Audit:          services.NewAuditLogger(repositories.NewAuditRepository(db)),
+	}
+	r := gin.New()
+	r.Use(apierror.Middleware())
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+		c.Set("user_role", string(models.RoleAdmin))
+	})
+	r.POST("/api/admin/users/:id/impersonate", s.AdminImpersonateUser)
+
+	tests := []struct {
+		ttlMinutes int
+		wantStatus int
+		wantTTL    time.Duration
+	}{
+		{0, http.StatusCreated, services.DefaultImpersonationTTL},
+		{1, http.StatusCreated, time.Minute},
+		{60, http.StatusCreated, services.MaxImpersonationTTL},
+		{61, http.StatusBadRequest, 0},
+		{-1, http.StatusBadRequest, 0},
+		// Переполнила бы Duration при умножении на минуту
+		{1 << 62, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.ttlMinutes), func(t *testing.T) {
+			body := fmt.Sprintf(`{"reason":"support ticket","ttlMinutes":%d}`, tt.ttlMinutes)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/42/impersonate", strings.NewReader(body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d: %s", w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var resp struct {
+				AccessToken string                      `json:"accessToken"`
+				Session     models.ImpersonationSession `json:"session"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if ttl := resp.Session.ExpiresAt.Sub(resp.Session.CreatedAt); ttl != tt.wantTTL {
+				t.Fatalf("session lives %v, want %v", ttl, tt.wantTTL)
+			}
+			claims, err := middleware.ValidateToken(resp.AccessToken)
+			if err != nil || claims.Actor == nil || claims.Actor.UserID != 1 || claims.SessionID != resp.Session.ID {
+				t.Fatalf("claims %+v, err %v", claims, err)
+			}
+			if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != tt.wantTTL {
+				t.Fatalf("token lives %v, want %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/models"
-	"math/rand"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/internal/validation"
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -12,12 +15,18 @@ import (
 
 // InventoryHandlers содержит handlers для работы с инвентарём
 type InventoryHandlers struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cases *services.CaseService
+	audit *services.AuditLogger
 }
 
 // NewInventoryHandlers создаёт новый экземпляр handlers
 func NewInventoryHandlers(db *gorm.DB) *InventoryHandlers {
-	return &InventoryHandlers{db: db}
+	return &InventoryHandlers{
+		db:    db,
+		cases: services.NewCaseService(repositories.NewInventoryRepository(db)),
+		audit: services.NewAuditLogger(repositories.NewAuditRepository(db)),
+	}
 }
 
 // GetInventory godoc
@@ -34,25 +43,25 @@ func NewInventoryHandlers(db *gorm.DB) *InventoryHandlers {
 func (h *InventoryHandlers) GetInventory(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "user not authenticated"))
 		return
 	}
 
 	var items []models.CustomizationItem
 	if err := h.db.Where("user_id = ?", userID).Find(&items).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		apierror.Abort(c, apierror.Internal("failed to fetch items", err))
 		return
 	}
 
 	var cases []models.UserCase
 	if err := h.db.Where("user_id = ?", userID).Find(&cases).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cases"})
+		apierror.Abort(c, apierror.Internal("failed to fetch cases", err))
 		return
 	}
 
 	var achievements []models.UserAchievement
 	if err := h.db.Where("user_id = ?", userID).Find(&achievements).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch achievements"})
+		apierror.Abort(c, apierror.Internal("failed to fetch achievements", err))
 		return
 	}
 
@@ -63,7 +72,7 @@ func (h *InventoryHandlers) GetInventory(c *gin.Context) {
 			customization = models.ProfileCustomization{UserID: userID.(int64)}
 			h.db.Create(&customization)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch customization"})
+			apierror.Abort(c, apierror.Internal("failed to fetch customization", err))
 			return
 		}
 	}
@@ -92,13 +101,13 @@ func (h *InventoryHandlers) GetInventory(c *gin.Context) {
 func (h *InventoryHandlers) EquipItem(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "user not authenticated"))
 		return
 	}
 
 	var req models.EquipItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -106,10 +115,10 @@ func (h *InventoryHandlers) EquipItem(c *gin.Context) {
 	var item models.CustomizationItem
 	if err := h.db.Where("user_id = ? AND item_id = ?", userID, req.ItemID).First(&item).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found in inventory"})
+			apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeItemNotFound, "item not found in inventory")))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check item"})
+		apierror.Abort(c, apierror.Internal("failed to check item", err))
 		return
 	}
 
@@ -120,7 +129,7 @@ func (h *InventoryHandlers) EquipItem(c *gin.Context) {
 			customization = models.ProfileCustomization{UserID: userID.(int64)}
 			h.db.Create(&customization)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch customization"})
+			apierror.Abort(c, apierror.Internal("failed to fetch customization", err))
 			return
 		}
 	}
@@ -155,7 +164,7 @@ func (h *InventoryHandlers) EquipItem(c *gin.Context) {
 			customization.Badge1ID = itemIDPtr
 		}
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item type"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "invalid item type"))
 		return
 	}
 
@@ -170,7 +179,7 @@ func (h *InventoryHandlers) EquipItem(c *gin.Context) {
 
 	// Сохраняем кастомизацию
 	if err := h.db.Save(&customization).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save customization"})
+		apierror.Abort(c, apierror.Internal("failed to save customization", err))
 		return
 	}
 
@@ -185,60 +194,41 @@ func (h *InventoryHandlers) EquipItem(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.OpenCaseRequest true "Open case request"
+// @Param Idempotency-Key header string false "Repeat the request safely: the same key replays the first response"
 // @Success 200 {object} models.OpenCaseResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
 // @Router /api/inventory/cases/open [post]
 func (h *InventoryHandlers) OpenCase(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "user not authenticated"))
 		return
 	}
 
 	var req models.OpenCaseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
-	// Находим кейс
-	var userCase models.UserCase
-	if err := h.db.Where("id = ? AND user_id = ? AND is_opened = false", req.CaseID, userID).First(&userCase).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Case not found or already opened"})
+	droppedItem, isNew, err := h.cases.Open(c.Request.Context(), userID.(int64), req.CaseID)
+	if err != nil {
+		if errors.Is(err, services.ErrCaseNotFound) {
+			apierror.Abort(c, apierror.NotFound(apierror.CodeCaseNotFound, "case not found or already opened"))
+			return
+		}
+		if errors.Is(err, services.ErrCaseRarityUnknown) {
+			apierror.Abort(c, apierror.New(http.StatusUnprocessableEntity, apierror.CodeCaseInvalid, "case cannot be opened: unknown rarity"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find case"})
+		apierror.Abort(c, apierror.Internal("failed to open case", err))
 		return
 	}
 
-	// Генерируем выпавший предмет на основе типа кейса
-	droppedItem := generateDroppedItem(userCase.CaseType, userCase.Rarity)
-	droppedItem.UserID = userID.(int64)
-
-	// Проверяем, есть ли уже такой предмет
-	var existingItem models.CustomizationItem
-	isNew := true
-	if err := h.db.Where("user_id = ? AND item_id = ?", userID, droppedItem.ItemID).First(&existingItem).Error; err == nil {
-		// Предмет уже есть - увеличиваем количество
-		existingItem.Quantity++
-		h.db.Save(&existingItem)
-		droppedItem = existingItem
-		isNew = false
-	} else {
-		// Добавляем новый предмет
-		h.db.Create(&droppedItem)
-	}
-
-	// Помечаем кейс открытым
-	now := time.Now()
-	userCase.IsOpened = true
-	userCase.OpenedAt = &now
-	h.db.Save(&userCase)
-
 	c.JSON(http.StatusOK, models.OpenCaseResponse{
-		DroppedItem: droppedItem,
+		DroppedItem: *droppedItem,
 		IsNew:       isNew,
 	})
 }
@@ -251,6 +241,7 @@ func (h *InventoryHandlers) OpenCase(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.GiveCaseRequest true "Give case request"
+// @Param Idempotency-Key header string false "Repeat the request safely: the same key replays the first response"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
@@ -260,29 +251,25 @@ func (h *InventoryHandlers) GiveCase(c *gin.Context) {
 	// Дополнительная проверка для безопасности
 	role, exists := c.Get("user_role")
 	if !exists || role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "admin access required"))
 		return
 	}
 
 	var req models.GiveCaseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
-	// Выдаём кейсы всем пользователям
-	var givenCount int
-	for _, userID := range req.UserIDs {
-		userCase := models.UserCase{
-			UserID:   userID,
-			CaseType: req.CaseType,
-			CaseName: req.CaseName,
-			Rarity:   req.Rarity,
-			IsOpened: false,
-		}
-		if err := h.db.Create(&userCase).Error; err == nil {
-			givenCount++
-		}
+	given := h.cases.Give(c.Request.Context(), req)
+	givenCount := len(given)
+	// Запись на каждого получателя, чтобы выдачу можно было найти по пользователю
+	for _, userID := range given {
+		recordAudit(c, h.audit, models.AuditActionCaseGive, models.AuditEntityUser, userID, nil, gin.H{
+			"caseType": req.CaseType,
+			"caseName": req.CaseName,
+			"rarity":   req.Rarity,
+		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -313,7 +300,7 @@ func (h *InventoryHandlers) GetUserCustomization(c *gin.Context) {
 			c.JSON(http.StatusOK, models.UserCustomizationResponse{})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch customization"})
+		apierror.Abort(c, apierror.Internal("failed to fetch customization", err))
 		return
 	}
 
@@ -393,128 +380,3 @@ func (h *InventoryHandlers) GetUserCustomization(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
-
-// generateDroppedItem генерирует случайный предмет из кейса
-func generateDroppedItem(caseType string, caseRarity models.RarityType) models.CustomizationItem {
-	rand.Seed(time.Now().UnixNano())
-
-	// Определяем возможные редкости на основе типа кейса
-	var possibleRarities []models.RarityType
-	switch caseRarity {
-	case models.RarityCommon:
-		possibleRarities = []models.RarityType{models.RarityCommon}
-	case models.RarityUncommon:
-		possibleRarities = []models.RarityType{models.RarityCommon, models.RarityUncommon}
-	case models.RarityRare:
-		possibleRarities = []models.RarityType{models.RarityCommon, models.RarityUncommon, models.RarityRare}
-	case models.RarityEpic:
-		possibleRarities = []models.RarityType{models.RarityUncommon, models.RarityRare, models.RarityEpic}
-	case models.RarityLegendary:
-		possibleRarities = []models.RarityType{models.RarityRare, models.RarityEpic, models.RarityLegendary}
-	}
-
-	// Случайная редкость с весами
-	rarity := rollRarity(possibleRarities)
-
-	// Случайный тип предмета
-	itemTypes := []models.CustomizationItemType{
-		models.ItemTypeBackground,
-		models.ItemTypeNameColor,
-		models.ItemTypeAvatarFrame,
-		models.ItemTypeBadge,
-	}
-	itemType := itemTypes[rand.Intn(len(itemTypes))]
-
-	// Генерируем предмет
-	return models.CustomizationItem{
-		ItemID:   generateItemID(itemType, rarity),
-		ItemType: itemType,
-		Rarity:   rarity,
-		Name:     generateItemName(itemType, rarity),
-		Value:    generateItemValue(itemType),
-		Quantity: 1,
-	}
-}
-
-func rollRarity(possible []models.RarityType) models.RarityType {
-	// Веса для редкостей
-	weights := map[models.RarityType]int{
-		models.RarityCommon:    50,
-		models.RarityUncommon:  30,
-		models.RarityRare:      15,
-		models.RarityEpic:      4,
-		models.RarityLegendary: 1,
-	}
-
-	totalWeight := 0
-	for _, r := range possible {
-		totalWeight += weights[r]
-	}
-
-	roll := rand.Intn(totalWeight)
-	cumulative := 0
-	for _, r := range possible {
-		cumulative += weights[r]
-		if roll < cumulative {
-			return r
-		}
-	}
-	return possible[0]
-}
-
-func generateItemID(itemType models.CustomizationItemType, rarity models.RarityType) string {
-	prefix := map[models.CustomizationItemType]string{
-		models.ItemTypeBackground:  "bg",
-		models.ItemTypeNameColor:   "color",
-		models.ItemTypeAvatarFrame: "frame",
-		models.ItemTypeBadge:       "badge",
-		models.ItemTypeTitle:       "title",
-		models.ItemTypeEffect:      "effect",
-	}
-	return prefix[itemType] + "_" + string(rarity) + "_" + randomString(6)
-}
-
-func generateItemName(itemType models.CustomizationItemType, rarity models.RarityType) string {
-	rarityNames := map[models.RarityType]string{
-		models.RarityCommon:    "Обычный",
-		models.RarityUncommon:  "Необычный",
-		models.RarityRare:      "Редкий",
-		models.RarityEpic:      "Эпический",
-		models.RarityLegendary: "Легендарный",
-	}
-	typeNames := map[models.CustomizationItemType]string{
-		models.ItemTypeBackground:  "фон",
-		models.ItemTypeNameColor:   "цвет ника",
-		models.ItemTypeAvatarFrame: "рамка",
-		models.ItemTypeBadge:       "значок",
-		models.ItemTypeTitle:       "титул",
-		models.ItemTypeEffect:      "эффект",
-	}
-	return rarityNames[rarity] + " " + typeNames[itemType]
-}
-
-func generateItemValue(itemType models.CustomizationItemType) string {
-	switch itemType {
-	case models.ItemTypeBackground:
-		gradients := []string{
-			"linear-gradient(135deg, #667eea 0%, #764ba2 100%)",
-			"linear-gradient(135deg, #f093fb 0%, #f5576c 100%)",
-			"linear-gradient(135deg, #43e97b 0%, #38f9d7 100%)",
-		}
-		return gradients[rand.Intn(len(gradients))]
-	case models.ItemTypeNameColor:
-		colors := []string{"#fbbf24", "#10b981", "#3b82f6", "#a855f7", "#ef4444"}
-		return colors[rand.Intn(len(colors))]
-	default:
-		return ""
-	}
-}
-
-func randomString(n int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
-	}
-	return string(b)
-}
@@ -1,18 +1,29 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
+	"backend/internal/logging"
+	"backend/internal/metrics"
 	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/repositories"
+	"backend/internal/services"
 	"backend/internal/types"
+	"backend/internal/validation"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // sendNotification godoc
@@ -23,33 +34,35 @@ import (
 // @Produce json
 // @Param input body types.NotificationRequest true "Notification"
 // @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
 // @Router /api/notification [post]
-func sendNotification(c *gin.Context) {
+func (s *Server) sendNotification(c *gin.Context) {
 	var req types.NotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	if req.Type == "" && req.Message == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "message is required",
-		})
+		apierror.Abort(c, apierror.Validation(apierror.FieldError{Field: "message", Message: "message is required"}))
 		return
 	}
 
-	go writeToNotificationStream(req)
+	err := s.Tasks.Go(c.Request.Context(), func(ctx context.Context) {
+		s.writeToNotificationStream(ctx, req)
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Unavailable("server is shutting down", err))
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status": "notification queued",
 	})
 }
 
-func writeToNotificationStream(req types.NotificationRequest) {
-	ctx := context.Background()
-	streamName := "notifications"
+// writeToNotificationStream - событие для Telegram бота в Redis stream "notifications"
+func (s *Server) writeToNotificationStream(ctx context.Context, req types.NotificationRequest) {
 
 	notificationData := map[string]interface{}{
 		"message": req.Message,
@@ -65,58 +78,77 @@ func writeToNotificationStream(req types.NotificationRequest) {
 		}
 	}
 
+	// ID HTTP запроса, породившего событие: бот пишет его в свои логи
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		notificationData["httpRequestId"] = requestID
+	}
+
 	jsonData, err := json.Marshal(notificationData)
 	if err != nil {
-		fmt.Printf("Error marshaling notification: %v\n", err)
+		logging.FromContext(ctx).Error("failed to marshal notification", logging.Err(err))
 		return
 	}
 
 	args := redis.XAddArgs{
-		Stream: streamName,
-		Values: map[string]interface{}{
-			"data": string(jsonData),
-		},
+		Stream: services.NotificationStream,
+		Values: services.StreamEntry(ctx, jsonData),
 	}
 
-	_, err = redisConn.XAdd(ctx, &args).Result()
+	_, err = s.Redis.XAdd(ctx, &args).Result()
 	if err != nil {
-		fmt.Printf("Error writing to Redis Stream: %v\n", err)
+		logging.FromContext(ctx).Error("failed to write notification to stream", logging.Err(err))
 		return
 	}
+	notificationType := req.Type
+	if notificationType == "" {
+		notificationType = "general"
+	}
+	metrics.NotificationsPublished.WithLabelValues(notificationType).Inc()
 
-	fmt.Printf("Notification written to stream: %s\n", string(jsonData))
+	logging.FromContext(ctx).Debug("notification written to stream", slog.String("type", req.Type))
 }
 
-// GetMyNotifications - получить уведомления текущего пользователя
+// notificationListSpec - уведомления только новые первыми; filter[unread]=true - непрочитанные
+var notificationListSpec = &pagination.Spec[models.Notification]{
+	Sorts: map[string]pagination.Sort[models.Notification]{
+		"createdAt": pagination.TimeSort("created_at", func(n models.Notification) time.Time { return n.CreatedAt }),
+	},
+	DefaultSort: "-createdAt",
+	Filters: map[string]pagination.Filter{
+		"unread": {
+			Valid:   func(v string) bool { return v == "true" || v == "false" },
+			Message: "must be true or false",
+			Apply: func(db *gorm.DB, v string) *gorm.DB {
+				return db.Where("is_read = ?", v != "true")
+			},
+		},
+		"type": pagination.Equals("type", nil, ""),
+	},
+	ID: func(n models.Notification) int64 { return n.ID },
+}
+
+// GetMyNotifications - получить уведомления текущего пользователя (страница)
 func (s *Server) GetMyNotifications(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
 
-	// Опциональные параметры
-	unreadOnly := c.Query("unread") == "true"
-	limitStr := c.DefaultQuery("limit", "50")
-	limit, _ := strconv.Atoi(limitStr)
-	if limit <= 0 || limit > 100 {
-		limit = 50
-	}
-
-	query := database.DB.Where("user_id = ?", userID)
-	if unreadOnly {
-		query = query.Where("is_read = ?", false)
+	params, err := pagination.Parse(c, notificationListSpec)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
 	}
 
-	var notifications []models.Notification
-	if err := query.Order("created_at DESC").Limit(limit).Find(&notifications).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notifications"})
+	notifications, next, total, err := s.Notifications.ListForUser(c.Request.Context(), userID, params)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch notifications", err))
 		return
 	}
 
 	// Подсчёт непрочитанных
-	var unreadCount int64
-	database.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&unreadCount)
+	unreadCount, _ := s.Notifications.CountUnread(c.Request.Context(), userID)
 
-	response := make([]gin.H, len(notifications))
+	items := make([]gin.H, len(notifications))
 	for i, n := range notifications {
-		response[i] = gin.H{
+		items[i] = gin.H{
 			"id":        n.ID,
 			"type":      n.Type,
 			"title":     n.Title,
@@ -127,10 +159,10 @@ func (s *Server) GetMyNotifications(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": response,
-		"unreadCount":   unreadCount,
-	})
+	c.JSON(http.StatusOK, struct {
+		pagination.Page[gin.H]
+		UnreadCount int64 `json:"unreadCount"`
+	}{pagination.NewPage(items, next, total), unreadCount})
 }
 
 // MarkNotificationRead - отметить уведомление прочитанным
@@ -138,18 +170,16 @@ func (s *Server) MarkNotificationRead(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
 	notifID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification ID"})
-		return
-	}
-
-	var notif models.Notification
-	if err := database.DB.Where("id = ? AND user_id = ?", notifID, userID).First(&notif).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		apierror.Abort(c, apierror.InvalidID("invalid notification ID"))
 		return
 	}
 
-	if err := database.DB.Model(&notif).Update("is_read", true).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark as read"})
+	if err := s.Notifications.MarkRead(c.Request.Context(), userID, notifID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			apierror.Abort(c, apierror.NotFound(apierror.CodeNotificationNotFound, "notification not found"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("failed to mark as read", err))
 		return
 	}
 
@@ -160,8 +190,8 @@ func (s *Server) MarkNotificationRead(c *gin.Context) {
 func (s *Server) MarkAllNotificationsRead(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
 
-	if err := database.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Update("is_read", true).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark all as read"})
+	if err := s.Notifications.MarkAllRead(c.Request.Context(), userID); err != nil {
+		apierror.Abort(c, apierror.Internal("failed to mark all as read", err))
 		return
 	}
 
@@ -172,97 +202,100 @@ func (s *Server) MarkAllNotificationsRead(c *gin.Context) {
 func (s *Server) GetUnreadCount(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
 
-	var count int64
-	database.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&count)
+	count, _ := s.Notifications.CountUnread(c.Request.Context(), userID)
 
 	c.JSON(http.StatusOK, gin.H{"count": count})
 }
 
-// sendJoinRequestNotification - отправить уведомление капитану о запросе на вступление
-func (s *Server) sendJoinRequestNotification(team models.Team, requestingUser models.User, captain models.User, requestID int64) {
-	// Create notification in DB
-	data, _ := json.Marshal(models.NotificationData{
-		TeamID:       &team.ID,
-		FromUserID:   &requestingUser.ID,
-		FromUserName: requestingUser.Name,
-		TeamName:     team.Name,
-	})
+// sendJoinRequestNotification - отправить уведомление капитану о запросе на вступление (в фоне, через s.Tasks)
+func (s *Server) sendJoinRequestNotification(ctx context.Context, team models.Team, requestingUser models.User, captain models.User, requestID int64) {
+	s.Tasks.Go(ctx, func(ctx context.Context) {
+		// Create notification in DB
+		data, _ := json.Marshal(models.NotificationData{
+			TeamID:       &team.ID,
+			FromUserID:   &requestingUser.ID,
+			FromUserName: requestingUser.Name,
+			TeamName:     team.Name,
+		})
 
-	notification := models.Notification{
-		UserID:  captain.ID,
-		Type:    models.NotificationTypeTeamRequest,
-		Title:   "Запрос на вступление в команду",
-		Message: fmt.Sprintf("%s хочет вступить в вашу команду \"%s\"", requestingUser.Name, team.Name),
-		Data:    data,
-		IsRead:  false,
-	}
-	database.DB.Create(&notification)
-
-	// Send to Telegram via Redis Stream
-	go writeToNotificationStream(types.NotificationRequest{
-		Type:    "join_request",
-		Message: fmt.Sprintf("🔔 %s хочет вступить в вашу команду \"%s\"", requestingUser.Name, team.Name),
-		Data: map[string]interface{}{
-			"targetUserId": captain.TelegramUserID,
-			"teamId":       team.ID,
-			"teamName":     team.Name,
-			"userId":       requestingUser.ID,
-			"userName":     requestingUser.Name,
-			"requestId":    requestID,
-		},
+		notification := models.Notification{
+			UserID:  captain.ID,
+			Type:    models.NotificationTypeTeamRequest,
+			Title:   "Запрос на вступление в команду",
+			Message: fmt.Sprintf("%s хочет вступить в вашу команду \"%s\"", requestingUser.Name, team.Name),
+			Data:    data,
+			IsRead:  false,
+		}
+		s.Notifications.Create(ctx, &notification)
+
+		// Send to Telegram via Redis Stream
+		s.writeToNotificationStream(ctx, types.NotificationRequest{
+			Type:    "join_request",
+			Message: fmt.Sprintf("🔔 %s хочет вступить в вашу команду \"%s\"", requestingUser.Name, team.Name),
+			Data: map[string]interface{}{
+				"targetUserId": captain.TelegramUserID,
+				"teamId":       team.ID,
+				"teamName":     team.Name,
+				"userId":       requestingUser.ID,
+				"userName":     requestingUser.Name,
+				"requestId":    requestID,
+			},
+		})
 	})
 }
 
-// sendRequestResponseNotification - отправить уведомление пользователю о решении по запросу
-func (s *Server) sendRequestResponseNotification(team models.Team, user models.User, accepted bool) {
-	var notifType models.NotificationType
-	var title, message string
-	var notificationTypeStr string
-
-	if accepted {
-		notifType = models.NotificationTypeTeamAccepted
-		title = "Запрос одобрен!"
-		message = fmt.Sprintf("Поздравляем! Вы приняты в команду \"%s\"", team.Name)
-		notificationTypeStr = "team_accepted"
-	} else {
-		notifType = models.NotificationTypeTeamRejected
-		title = "Запрос отклонён"
-		message = fmt.Sprintf("К сожалению, ваш запрос на вступление в команду \"%s\" был отклонён", team.Name)
-		notificationTypeStr = "team_rejected"
-	}
+// sendRequestResponseNotification - отправить уведомление пользователю о решении по запросу (в фоне, через s.Tasks)
+func (s *Server) sendRequestResponseNotification(ctx context.Context, team models.Team, user models.User, accepted bool) {
+	s.Tasks.Go(ctx, func(ctx context.Context) {
+		var notifType models.NotificationType
+		var title, message string
+		var notificationTypeStr string
+
+		if accepted {
+			notifType = models.NotificationTypeTeamAccepted
+			title = "Запрос одобрен!"
+			message = fmt.Sprintf("Поздравляем! Вы приняты в команду \"%s\"", team.Name)
+			notificationTypeStr = "team_accepted"
+		} else {
+			notifType = models.NotificationTypeTeamRejected
+			title = "Запрос отклонён"
+			message = fmt.Sprintf("К сожалению, ваш запрос на вступление в команду \"%s\" был отклонён", team.Name)
+			notificationTypeStr = "team_rejected"
+		}
 
-	data, _ := json.Marshal(models.NotificationData{
-		TeamID:   &team.ID,
-		TeamName: team.Name,
-	})
+		data, _ := json.Marshal(models.NotificationData{
+			TeamID:   &team.ID,
+			TeamName: team.Name,
+		})
 
-	notification := models.Notification{
-		UserID:  user.ID,
-		Type:    notifType,
-		Title:   title,
-		Message: message,
-		Data:    data,
-		IsRead:  false,
-	}
-	database.DB.Create(&notification)
-
-	// Send to Telegram via Redis Stream
-	var emoji string
-	if accepted {
-		emoji = "✅"
-	} else {
-		emoji = "❌"
-	}
+		notification := models.Notification{
+			UserID:  user.ID,
+			Type:    notifType,
+			Title:   title,
+			Message: message,
+			Data:    data,
+			IsRead:  false,
+		}
+		s.Notifications.Create(ctx, &notification)
+
+		// Send to Telegram via Redis Stream
+		var emoji string
+		if accepted {
+			emoji = "✅"
+		} else {
+			emoji = "❌"
+		}
 
-	go writeToNotificationStream(types.NotificationRequest{
-		Type:    notificationTypeStr,
-		Message: fmt.Sprintf("%s %s", emoji, message),
-		Data: map[string]interface{}{
-			"targetUserId": user.TelegramUserID,
-			"teamId":       team.ID,
-			"teamName":     team.Name,
-			"accepted":     accepted,
-		},
+		s.writeToNotificationStream(ctx, types.NotificationRequest{
+			Type:    notificationTypeStr,
+			Message: fmt.Sprintf("%s %s", emoji, message),
+			Data: map[string]interface{}{
+				"targetUserId": user.TelegramUserID,
+				"teamId":       team.ID,
+				"teamName":     team.Name,
+				"accepted":     accepted,
+			},
+		})
 	})
 }
 
@@ -272,7 +305,7 @@ func (s *Server) GetNotificationSettings(c *gin.Context) {
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -289,18 +322,18 @@ func (s *Server) UpdateNotificationSettings(c *gin.Context) {
 		NotificationsEnabled bool `json:"notificationsEnabled"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	if err := database.DB.Model(&user).Update("notifications_enabled", req.NotificationsEnabled).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update settings"})
+		apierror.Abort(c, apierror.Internal("failed to update settings", err))
 		return
 	}
 
@@ -334,13 +367,13 @@ func getBotNotificationSettings(c *gin.Context) {
 	telegramIDStr := c.Param("telegramId")
 	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid telegram ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid telegram ID"))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.Where("telegram_user_id = ?", telegramID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found", "exists": false})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -357,7 +390,7 @@ func updateBotNotificationSettings(c *gin.Context) {
 	telegramIDStr := c.Param("telegramId")
 	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid telegram ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid telegram ID"))
 		return
 	}
 
@@ -365,18 +398,18 @@ func updateBotNotificationSettings(c *gin.Context) {
 		Enabled bool `json:"enabled"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.Where("telegram_user_id = ?", telegramID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	if err := database.DB.Model(&user).Update("notifications_enabled", req.Enabled).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update settings"})
+		apierror.Abort(c, apierror.Internal("failed to update settings", err))
 		return
 	}
 
@@ -386,99 +419,103 @@ func updateBotNotificationSettings(c *gin.Context) {
 	})
 }
 
-// sendTeamInviteNotification - отправить уведомление пользователю о приглашении в команду
-func (s *Server) sendTeamInviteNotification(team models.Team, inviter models.User, invitedUser models.User, inviteID int64) {
-	// Create notification in DB
-	data, _ := json.Marshal(models.NotificationData{
-		TeamID:       &team.ID,
-		FromUserID:   &inviter.ID,
-		FromUserName: inviter.Name,
-		TeamName:     team.Name,
-	})
+// sendTeamInviteNotification - отправить уведомление пользователю о приглашении в команду (в фоне, через s.Tasks)
+func (s *Server) sendTeamInviteNotification(ctx context.Context, team models.Team, inviter models.User, invitedUser models.User, inviteID int64) {
+	s.Tasks.Go(ctx, func(ctx context.Context) {
+		// Create notification in DB
+		data, _ := json.Marshal(models.NotificationData{
+			TeamID:       &team.ID,
+			FromUserID:   &inviter.ID,
+			FromUserName: inviter.Name,
+			TeamName:     team.Name,
+		})
 
-	notification := models.Notification{
-		UserID:  invitedUser.ID,
-		Type:    models.NotificationTypeTeamInvite,
-		Title:   "Приглашение в команду",
-		Message: fmt.Sprintf("%s приглашает вас в команду \"%s\"", inviter.Name, team.Name),
-		Data:    data,
-		IsRead:  false,
-	}
-	database.DB.Create(&notification)
+		notification := models.Notification{
+			UserID:  invitedUser.ID,
+			Type:    models.NotificationTypeTeamInvite,
+			Title:   "Приглашение в команду",
+			Message: fmt.Sprintf("%s приглашает вас в команду \"%s\"", inviter.Name, team.Name),
+			Data:    data,
+			IsRead:  false,
+		}
+		s.Notifications.Create(ctx, &notification)
 
-	// Check if user has notifications enabled
-	if !invitedUser.NotificationsEnabled || invitedUser.TelegramUserID == 0 {
-		return
-	}
+		// Check if user has notifications enabled
+		if !invitedUser.NotificationsEnabled || invitedUser.TelegramUserID == 0 {
+			return
+		}
 
-	// Send to Telegram via Redis Stream
-	writeToNotificationStream(types.NotificationRequest{
-		Type:    "team_invite",
-		Message: fmt.Sprintf("📨 %s приглашает вас в команду \"%s\"", inviter.Name, team.Name),
-		Data: map[string]interface{}{
-			"targetUserId": invitedUser.TelegramUserID,
-			"teamId":       team.ID,
-			"teamName":     team.Name,
-			"inviterId":    inviter.ID,
-			"inviterName":  inviter.Name,
-			"inviteId":     inviteID,
-		},
+		// Send to Telegram via Redis Stream
+		s.writeToNotificationStream(ctx, types.NotificationRequest{
+			Type:    "team_invite",
+			Message: fmt.Sprintf("📨 %s приглашает вас в команду \"%s\"", inviter.Name, team.Name),
+			Data: map[string]interface{}{
+				"targetUserId": invitedUser.TelegramUserID,
+				"teamId":       team.ID,
+				"teamName":     team.Name,
+				"inviterId":    inviter.ID,
+				"inviterName":  inviter.Name,
+				"inviteId":     inviteID,
+			},
+		})
 	})
 }
 
-// sendInviteResponseNotification - отправить уведомление инвайтеру о решении приглашённого
-func (s *Server) sendInviteResponseNotification(team models.Team, invitedUser models.User, inviter models.User, accepted bool) {
-	var notifType models.NotificationType
-	var title, message string
-	var notificationTypeStr string
-	var emoji string
-
-	if accepted {
-		notifType = models.NotificationTypeTeamAccepted
-		title = "Приглашение принято!"
-		message = fmt.Sprintf("%s принял(а) приглашение и присоединился к команде \"%s\"", invitedUser.Name, team.Name)
-		notificationTypeStr = "invite_accepted"
-		emoji = "✅"
-	} else {
-		notifType = models.NotificationTypeTeamRejected
-		title = "Приглашение отклонено"
-		message = fmt.Sprintf("%s отклонил(а) приглашение в команду \"%s\"", invitedUser.Name, team.Name)
-		notificationTypeStr = "invite_rejected"
-		emoji = "❌"
-	}
+// sendInviteResponseNotification - отправить уведомление инвайтеру о решении приглашённого (в фоне, через s.Tasks)
+func (s *Server) sendInviteResponseNotification(ctx context.Context, team models.Team, invitedUser models.User, inviter models.User, accepted bool) {
+	s.Tasks.Go(ctx, func(ctx context.Context) {
+		var notifType models.NotificationType
+		var title, message string
+		var notificationTypeStr string
+		var emoji string
+
+		if accepted {
+			notifType = models.NotificationTypeTeamAccepted
+			title = "Приглашение принято!"
+			message = fmt.Sprintf("%s принял(а) приглашение и присоединился к команде \"%s\"", invitedUser.Name, team.Name)
+			notificationTypeStr = "invite_accepted"
+			emoji = "✅"
+		} else {
+			notifType = models.NotificationTypeTeamRejected
+			title = "Приглашение отклонено"
+			message = fmt.Sprintf("%s отклонил(а) приглашение в команду \"%s\"", invitedUser.Name, team.Name)
+			notificationTypeStr = "invite_rejected"
+			emoji = "❌"
+		}
 
-	data, _ := json.Marshal(models.NotificationData{
-		TeamID:       &team.ID,
-		FromUserID:   &invitedUser.ID,
-		FromUserName: invitedUser.Name,
-		TeamName:     team.Name,
-	})
+		data, _ := json.Marshal(models.NotificationData{
+			TeamID:       &team.ID,
+			FromUserID:   &invitedUser.ID,
+			FromUserName: invitedUser.Name,
+			TeamName:     team.Name,
+		})
 
-	notification := models.Notification{
-		UserID:  inviter.ID,
-		Type:    notifType,
-		Title:   title,
-		Message: message,
-		Data:    data,
-		IsRead:  false,
-	}
-	database.DB.Create(&notification)
+		notification := models.Notification{
+			UserID:  inviter.ID,
+			Type:    notifType,
+			Title:   title,
+			Message: message,
+			Data:    data,
+			IsRead:  false,
+		}
+		s.Notifications.Create(ctx, &notification)
 
-	// Check if inviter has notifications enabled
-	if !inviter.NotificationsEnabled || inviter.TelegramUserID == 0 {
-		return
-	}
+		// Check if inviter has notifications enabled
+		if !inviter.NotificationsEnabled || inviter.TelegramUserID == 0 {
+			return
+		}
 
-	// Send to Telegram via Redis Stream
-	writeToNotificationStream(types.NotificationRequest{
-		Type:    notificationTypeStr,
-		Message: fmt.Sprintf("%s %s", emoji, message),
-		Data: map[string]interface{}{
-			"targetUserId": inviter.TelegramUserID,
-			"teamId":       team.ID,
-			"teamName":     team.Name,
-			"userId":       invitedUser.ID,
-			"userName":     invitedUser.Name,
-		},
+		// Send to Telegram via Redis Stream
+		s.writeToNotificationStream(ctx, types.NotificationRequest{
+			Type:    notificationTypeStr,
+			Message: fmt.Sprintf("%s %s", emoji, message),
+			Data: map[string]interface{}{
+				"targetUserId": inviter.TelegramUserID,
+				"teamId":       team.ID,
+				"teamName":     team.Name,
+				"userId":       invitedUser.ID,
+				"userName":     invitedUser.Name,
+			},
+		})
 	})
 }
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"backend/internal/logging"
+	"backend/internal/services"
+	"backend/internal/types"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNotificationStreamCarriesRequestID(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	s := &Server{Redis: client}
+
+	ctx := logging.WithRequestID(context.Background(), "req-42")
+	s.writeToNotificationStream(ctx, types.NotificationRequest{Type: "team_invite", Message: "hi"})
+	// Событие без HTTP запроса уходит без ID
+	s.writeToNotificationStream(context.Background(), types.NotificationRequest{Message: "cron"})
+
+	entries, err := client.XRange(context.Background(), services.NotificationStream, "-", "+").Result()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("entries %v, err %v", entries, err)
+	}
+	for i, want := range []string{"req-42", ""} {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(entries[i].Values["data"].(string)), &data); err != nil {
+			t.Fatal(err)
+		}
+		got, _ := data["httpRequestId"].(string)
+		if got != want {
+			t.Errorf("entry %d: httpRequestId %q, want %q", i, got, want)
+		}
+	}
+}
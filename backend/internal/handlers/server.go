@@ -1,60 +1,173 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
+	"backend/internal/authz"
+	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/health"
+	"backend/internal/lifecycle"
+	"backend/internal/metrics"
 	"backend/internal/middleware"
+	"backend/internal/models"
 	"backend/internal/repositories"
 	"backend/internal/services"
+	"backend/internal/tracing"
+	"backend/internal/validation"
 	"context"
 	"fmt"
-	"os"
+	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 )
 
-var redisConn *redis.Client
-
-var (
-	ADMINUSER string
-	ADMINPASS string
-)
-
 type Server struct {
+	Config              *config.Config
 	DB                  *gorm.DB
+	Redis               *redis.Client
+	Tasks               *lifecycle.Group
 	UserRepo            *repositories.UserRepository
 	NotificationService *services.NotificationService
+	RefreshTokens       *services.RefreshTokenStore
+	Impersonations      *services.ImpersonationStore
+	Sessions            *services.SessionChecker
+	AdminAuth           *services.AdminAuthService
+	LoginTokens         *services.LoginTokenStore
+	Hackathons          *authz.HackathonPolicy
+	APIKeys             *services.APIKeyService
+	Audit               *services.AuditLogger
+	UserData            *services.UserDataService
+	Notifications       repositories.NotificationRepository
+	Teams               repositories.TeamRepository
+	Membership          *services.TeamMembershipService
+	Matching            *services.MatchingService
+	Cases               *services.CaseService
+	Readiness           *health.Checker
 }
 
-func StartServer() {
-	r := gin.Default()
+// StartServer - поднять зависимости и HTTP сервер и работать до отмены ctx (SIGTERM).
+// При остановке сервер дожидается запросов в обработке и фоновых задач, затем закрывает Redis и Postgres.
+func StartServer(ctx context.Context, cfg *config.Config) error {
+	slog.Info("starting with config\n" + cfg.String())
+
+	app := lifecycle.NewManager(cfg.HTTP.ShutdownTimeout)
+
+	// Трейсы останавливаются последними, чтобы дослать спаны остальных компонентов
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	app.Register("tracing", lifecycle.Hook{OnStop: shutdownTracing})
+
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	if err := validation.Register(); err != nil {
+		return fmt.Errorf("failed to register validators: %w", err)
+	}
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	r.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	r.Use(middleware.RequestLoggerMiddleware(), middleware.RecoveryMiddleware(), middleware.MetricsMiddleware(), apierror.Middleware())
 	r.Use(cors.Default())
-	loadEnv()
+
+	jwtKeys, err := middleware.LoadKeySet(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	middleware.SetKeySet(jwtKeys)
+	middleware.AccessTokenTTL = cfg.JWT.AccessTTL
+
+	serviceSecrets := cfg.Services.ServiceSecrets()
+	if len(serviceSecrets) == 0 {
+		slog.Warn("BOT_SERVICE_SECRET is not set, /api/bot endpoints will reject all requests")
+	}
 
 	// --- Connect PostgreSQL ---
-	db, err := database.Connect()
+	db, err := database.Connect(cfg.Database)
 	if err != nil {
-		panic(fmt.Sprintf("failed to connect PostgreSQL: %v", err))
+		return fmt.Errorf("failed to connect PostgreSQL: %w", err)
 	}
+	app.Register("postgres", lifecycle.Hook{OnStop: func(context.Context) error { return database.Close() }})
 
-	// Auto-migrate
-	if err := database.AutoMigrate(); err != nil {
-		panic(fmt.Sprintf("failed auto-migrate: %v", err))
+	// Миграции схемы (MIGRATE_ON_START / DB_AUTO_MIGRATE)
+	if err := database.PrepareSchema(ctx, cfg.Database); err != nil {
+		database.Close()
+		return fmt.Errorf("failed to prepare database schema: %w", err)
+	}
+	// Мигратор для проверки версии схемы в /readyz
+	migrator, err := database.NewSchemaMigrator()
+	if err != nil {
+		database.Close()
+		return fmt.Errorf("failed to create migrator: %w", err)
 	}
 
 	// --- Connect Redis ---
-	redisConn = connectToRedis()
+	redisConn, err := connectToRedis(ctx, cfg.Redis)
+	if err != nil {
+		database.Close()
+		return err
+	}
+	app.Register("redis", lifecycle.Hook{OnStop: func(context.Context) error { return redisConn.Close() }})
+
+	// Уведомления и прочая работа, которую обработчики запускают в фоне
+	tasks := lifecycle.NewGroup()
+	app.Register("background tasks", tasks)
+
 	notificationService := services.NewNotificationService(redisConn)
 
+	refreshTokens := services.NewRefreshTokenStore(redisConn, cfg.JWT.RefreshTTL)
+	impersonations := services.NewImpersonationStore(db, redisConn)
+
+	teams := repositories.NewTeamRepository(db)
+	notifications := repositories.NewNotificationRepository(db)
+	membership := services.NewTeamMembershipService(teams, repositories.NewHackathonRepository(db))
+
 	server := &Server{
+		Config:              cfg,
 		DB:                  db,
+		Redis:               redisConn,
+		Tasks:               tasks,
 		UserRepo:            repositories.NewUserRepository(db),
 		NotificationService: notificationService,
+		RefreshTokens:       refreshTokens,
+		Impersonations:      impersonations,
+		Sessions:            services.NewSessionChecker(refreshTokens, impersonations),
+		AdminAuth:           services.NewAdminAuthService(repositories.NewAdminRepository(db)),
+		LoginTokens:         services.NewLoginTokenStore(redisConn),
+		Hackathons:          authz.NewHackathonPolicy(db),
+		APIKeys:             services.NewAPIKeyService(repositories.NewAPIKeyRepository(db)),
+		Audit:               services.NewAuditLogger(repositories.NewAuditRepository(db)),
+		UserData:            services.NewUserDataService(db, refreshTokens),
+		Notifications:       notifications,
+		Teams:               teams,
+		Membership:          membership,
+		Matching:            services.NewMatchingService(teams, repositories.NewSwipeRepository(db), notifications, membership),
+		Cases:               services.NewCaseService(repositories.NewInventoryRepository(db)),
+		Readiness:           newReadinessChecker(db, redisConn, migrator, tasks),
+	}
+
+	// Ограничение частоты: политики объявлены рядом с группами маршрутов ниже
+	rateLimiter := services.NewRateLimiter(redisConn)
+	rateLimit := func(policy middleware.RateLimitPolicy) gin.HandlerFunc {
+		if !cfg.RateLimit.Enabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.RateLimitMiddleware(rateLimiter, policy)
 	}
+	// Idempotency-Key для POST, повтор которых создал бы дубликаты
+	idempotent := middleware.IdempotencyMiddleware(services.NewIdempotencyCache(redisConn))
 
 	// ============================================
 	// PUBLIC ROUTES (No Auth Required)
@@ -63,7 +176,7 @@ func StartServer() {
 	{
 		public.POST("/auth/telegram", server.AuthTelegram)
 		public.POST("/auth/refresh", server.RefreshToken)
-		public.POST("/token", takeToken)            // Token exchange from TG bot
+		public.POST("/auth/logout", middleware.OptionalJWTAuthMiddleware(server.Sessions), server.Logout)
 		public.POST("/user/register", registerUser) // Register user from TG bot
 		public.GET("/health", func(c *gin.Context) {
 			c.JSON(200, gin.H{"status": "ok"})
@@ -73,15 +186,29 @@ func StartServer() {
 		inventoryHandlersPublic := NewInventoryHandlers(db)
 		public.GET("/users/:id/customization", inventoryHandlersPublic.GetUserCustomization)
 
-		// Bot API - notification settings by telegram ID
-		public.GET("/bot/notifications/:telegramId", getBotNotificationSettings)
-		public.PUT("/bot/notifications/:telegramId", updateBotNotificationSettings)
+		// Перебор токенов входа: лимит на IP, неудачные попытки ещё и блокируются в takeToken
+		tokens := public.Group("", rateLimit(middleware.RateLimitPolicy{Name: "token", Limit: 10, Window: time.Minute}))
+		tokens.POST("/token", server.takeToken) // Token exchange from TG bot
+	}
 
-		// Bot API - invite accept/decline by telegram ID
-		public.POST("/bot/invites/:id/accept", server.BotAcceptInvite)
-		public.POST("/bot/invites/:id/decline", server.BotDeclineInvite)
+	// ============================================
+	// BOT ROUTES (Signed service requests)
+	// ============================================
+	bot := r.Group("/api/bot")
+	bot.Use(middleware.ServiceAuthMiddleware(serviceSecrets, services.NewNonceCache(redisConn)))
+	{
+		// Notification settings by telegram ID
+		bot.GET("/notifications/:telegramId", getBotNotificationSettings)
+		bot.PUT("/notifications/:telegramId", updateBotNotificationSettings)
+
+		// Invite accept/decline by telegram ID
+		bot.POST("/invites/:id/accept", server.BotAcceptInvite)
+		bot.POST("/invites/:id/decline", server.BotDeclineInvite)
 	}
 
+	// Публичные ключи JWT для других сервисов
+	r.GET("/.well-known/jwks.json", server.GetJWKS)
+
 	// Admin login (separate)
 	r.POST("/admin/api/login", server.AdminLogin)
 
@@ -92,20 +219,31 @@ func StartServer() {
 	// PROTECTED ROUTES (JWT Auth Required)
 	// ============================================
 	protected := r.Group("/api")
-	protected.Use(middleware.JWTAuthMiddleware())
+	protected.Use(middleware.JWTAuthMiddleware(server.Sessions))
 	{
 		// User routes
 		protected.GET("/users/me", server.GetMe)
 		protected.PATCH("/users/me/profile", server.UpdateProfile)
 		protected.GET("/users/:id", server.GetUser)
 
+		// Sessions
+		protected.GET("/users/me/sessions", server.GetMySessions)
+		protected.DELETE("/users/me/sessions", server.RevokeAllMySessions)
+		protected.DELETE("/users/me/sessions/:id", server.RevokeMySession)
+
+		// Personal data
+		protected.GET("/users/me/export", server.ExportMyData)
+		protected.POST("/users/me/erase", server.EraseMe)
+
 		// Recommendations & Swipe
 		protected.GET("/recommendations", server.GetRecommendations)
-		protected.POST("/swipe", server.Swipe)
 		protected.GET("/swipe/preferences", server.GetSwipePreferences)
 		protected.PUT("/swipe/preferences", server.UpdateSwipePreferences)
 		protected.GET("/matches", server.GetMatches)
 
+		swipes := protected.Group("", rateLimit(middleware.RateLimitPolicy{Name: "swipe", Limit: 120, Window: time.Minute}))
+		swipes.POST("/swipe", idempotent, server.Swipe)
+
 		// Notifications (user-specific)
 		protected.GET("/notifications", server.GetMyNotifications)
 		protected.GET("/notifications/unread-count", server.GetUnreadCount)
@@ -129,7 +267,7 @@ func StartServer() {
 		protected.POST("/teams/join", server.JoinTeamByCode)
 
 		// Team Join Requests
-		protected.POST("/teams/:id/request-join", server.RequestJoinTeam)
+		protected.POST("/teams/:id/request-join", idempotent, server.RequestJoinTeam)
 		protected.GET("/teams/:id/join-requests", server.GetTeamJoinRequests)
 		protected.POST("/join-requests/:requestId/handle", server.HandleJoinRequest)
 		protected.GET("/my-join-requests", server.GetMyJoinRequests)
@@ -138,10 +276,13 @@ func StartServer() {
 		// Invites
 		protected.GET("/invites/incoming", server.GetIncomingInvites)
 		protected.GET("/invites/outgoing", server.GetOutgoingInvites)
-		protected.POST("/invites", server.SendInvite)
-		protected.POST("/invites/:id/accept", server.AcceptInvite)
-		protected.POST("/invites/:id/decline", server.DeclineInvite)
-		protected.DELETE("/invites/:id", server.CancelInvite)
+
+		// Каждое приглашение - уведомление в Telegram, поэтому изменения ограничены
+		invites := protected.Group("", rateLimit(middleware.RateLimitPolicy{Name: "invites", Limit: 30, Window: time.Minute}))
+		invites.POST("/invites", idempotent, server.SendInvite)
+		invites.POST("/invites/:id/accept", server.AcceptInvite)
+		invites.POST("/invites/:id/decline", server.DeclineInvite)
+		invites.DELETE("/invites/:id", server.CancelInvite)
 
 		// Hackathons
 		protected.GET("/hackathons", server.GetHackathons)
@@ -156,80 +297,128 @@ func StartServer() {
 		inventoryHandlers := NewInventoryHandlers(db)
 		protected.GET("/inventory", inventoryHandlers.GetInventory)
 		protected.POST("/inventory/equip", inventoryHandlers.EquipItem)
-		protected.POST("/inventory/cases/open", inventoryHandlers.OpenCase)
+
+		cases := protected.Group("", rateLimit(middleware.RateLimitPolicy{Name: "cases", Limit: 20, Window: time.Minute}))
+		cases.POST("/inventory/cases/open", idempotent, inventoryHandlers.OpenCase)
 	}
 
 	// ============================================
 	// ADMIN ROUTES (Admin Role Required)
 	// ============================================
 	admin := r.Group("/api/admin")
-	admin.Use(middleware.JWTAuthMiddleware())
+	admin.Use(middleware.JWTAuthMiddleware(server.Sessions))
 	admin.Use(middleware.RequireRoleMiddleware("admin"))
 	{
 		admin.POST("/promote", server.AdminPromoteToCreator)
 		admin.GET("/stats", server.GetAdminStats)
 		admin.GET("/users", server.GetAllUsers)
 		admin.PUT("/users/:id", server.AdminUpdateUser)
+		admin.GET("/users/:id/sessions", server.AdminGetUserSessions)
+		admin.DELETE("/users/:id/sessions", server.AdminRevokeUserSessions)
+		admin.GET("/users/:id/export", server.AdminExportUser)
+		admin.POST("/users/:id/erase", server.AdminEraseUser)
+		admin.POST("/users/:id/impersonate", server.AdminImpersonateUser)
+		admin.GET("/impersonations", server.GetImpersonations)
+		admin.DELETE("/impersonations/:id", server.EndImpersonation)
 		admin.GET("/teams", server.GetAllTeams)
 		admin.POST("/assign", server.AdminAssignToTeam)
+		admin.GET("/audit", server.GetAuditLog)
 		admin.POST("/hackathons", server.CreateHackathon)
 		admin.PUT("/hackathons/:id", server.AdminUpdateHackathon)
 		admin.DELETE("/hackathons/:id", server.DeleteHackathon)
 
 		// Admin Inventory - выдача кейсов
 		adminInventoryHandlers := NewInventoryHandlers(db)
-		admin.POST("/cases/give", adminInventoryHandlers.GiveCase)
+		admin.POST("/cases/give", idempotent, adminInventoryHandlers.GiveCase)
+	}
+
+	// ============================================
+	// HACKATHON CREATOR ROUTES (hackathon_creator или admin)
+	// Доступ к конкретному хакатону проверяется в handler'ах через authz
+	// ============================================
+	creator := r.Group("/api/creator")
+	creator.Use(middleware.JWTAuthMiddleware(server.Sessions))
+	creator.Use(middleware.RequireRoleMiddleware("hackathon_creator"))
+	{
+		creator.GET("/hackathons", server.GetManagedHackathons)
+		creator.POST("/hackathons", server.CreateHackathon)
+		creator.PUT("/hackathons/:id", server.AdminUpdateHackathon)
+		creator.PUT("/hackathons/:id/status", server.UpdateHackathonStatus)
+		creator.GET("/hackathons/:id/participants", server.GetHackathonParticipants)
+		creator.POST("/hackathons/:id/cases/give", idempotent, server.GiveHackathonCase)
+		creator.GET("/hackathons/:id/export", server.ExportHackathon)
+		creator.GET("/hackathons/:id/api-keys", server.GetHackathonAPIKeys)
+		creator.POST("/hackathons/:id/api-keys", server.CreateHackathonAPIKey)
+		creator.DELETE("/hackathons/:id/api-keys/:keyId", server.RevokeHackathonAPIKey)
+	}
+
+	// ============================================
+	// INTEGRATION ROUTES (Authorization: ApiKey <key>)
+	// Ключ привязан к одному хакатону, разрешения проверяются на каждом маршруте
+	// ============================================
+	integrations := r.Group("/api/integrations")
+	integrations.Use(middleware.APIKeyAuthMiddleware(server.APIKeys))
+	{
+		integrations.GET("/hackathon", server.IntegrationGetHackathon)
+		integrations.GET("/participants", middleware.RequireAPIKeyPermission(models.APIKeyPermReadParticipants), server.IntegrationGetParticipants)
+		integrations.GET("/teams", middleware.RequireAPIKeyPermission(models.APIKeyPermReadTeams), server.IntegrationGetTeams)
+		integrations.POST("/announcements", middleware.RequireAPIKeyPermission(models.APIKeyPermWriteAnnouncements), server.IntegrationPostAnnouncement)
 	}
 
-	// Health check endpoint
+	// Health check endpoints: /health и /api/health оставлены для старых проверок и равны /livez,
+	// балансировщик и docker healthcheck должны смотреть на /readyz
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "service": "itam-hackaton-backend"})
 	})
-
-	if err := r.Run("0.0.0.0:8080"); err != nil {
-		panic(err)
+	r.GET("/livez", server.Livez)
+	r.GET("/readyz", server.Readyz)
+
+	// Метрики на отдельном порту: в compose он не публикуется, Prometheus ходит по внутренней сети
+	if cfg.Metrics.Addr != "" {
+		metrics.Registry.MustRegister(metrics.NewStreamCollector(redisConn, services.NotificationStream))
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		// Полный отчёт готовности с ошибками - только во внутренней сети
+		mux.Handle("/readyz", server.Readiness.Handler())
+		app.Register("metrics", lifecycle.NewHTTPServer(&http.Server{
+			Addr:              cfg.Metrics.Addr,
+			Handler:           mux,
+			ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		}, app.Fail))
 	}
-}
 
-func loadEnv() {
-	ADMINUSER = getEnv("ADMINUSER", "admin")
-	ADMINPASS = getEnv("ADMINPASS", "admin")
-}
+	app.Register("http", lifecycle.NewHTTPServer(&http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}, app.Fail))
 
-func getEnv(key, def string) string {
-	v := os.Getenv(key)
-	if v == "" {
-		return def
-	}
-	return v
+	return app.Run(ctx)
 }
 
 // ---------------------- REDIS ----------------------
 
-func connectToRedis() *redis.Client {
-	redisAddr := getEnv("REDISADDR", "redis:6379")
-	redisUser := getEnv("REDISUSER", "")
-	redisPass := getEnv("REDISPASSWORD", "")
-
-	fmt.Printf("[DEBUG] Redis config: addr=%s, user='%s', pass='%s'\n", redisAddr, redisUser, redisPass)
-
-	opts := &redis.Options{
-		Addr: redisAddr,
-		DB:   0,
-	}
+func connectToRedis(ctx context.Context, cfg config.RedisConfig) (*redis.Client, error) {
+	slog.Info("connecting to redis", slog.String("addr", cfg.Addr), slog.String("user", cfg.User))
 
-	// Only set username/password if they are provided
-	if redisUser != "" {
-		opts.Username = redisUser
-	}
-	if redisPass != "" {
-		opts.Password = redisPass
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.User,
+		Password: cfg.Password.Value(),
+		DB:       cfg.DB,
+	})
+	client.AddHook(metrics.RedisHook{})
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to instrument Redis tracing: %w", err)
 	}
-
-	client := redis.NewClient(opts)
-	if err := client.Ping(context.Background()).Err(); err != nil {
-		panic("Failed to connect Redis: " + err.Error())
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect Redis: %w", err)
 	}
-	fmt.Println("[DEBUG] Successfully connected to Redis!")
-	return client
+	slog.Info("redis connected")
+	return client, nil
 }
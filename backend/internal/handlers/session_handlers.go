@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/logging"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// SESSION HELPERS
+// ============================================
+
+// sessionClient - user agent и IP клиента для записи в сессию
+func sessionClient(c *gin.Context) services.SessionClient {
+	return services.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+// sessionsResponse - список сессий с пометкой текущей
+func sessionsResponse(families []services.RefreshTokenFamily, currentID string) []gin.H {
+	sessions := make([]gin.H, 0, len(families))
+	for _, f := range families {
+		sessions = append(sessions, gin.H{
+			"id":         f.ID,
+			"createdAt":  f.CreatedAt,
+			"lastUsedAt": f.LastUsedAt,
+			"userAgent":  f.UserAgent,
+			"ip":         f.IP,
+			"current":    f.ID == currentID,
+		})
+	}
+	return sessions
+}
+
+// ============================================
+// USER SESSIONS
+// ============================================
+
+// GetMySessions - активные сессии текущего пользователя
+// @Summary List my sessions
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/users/me/sessions [get]
+func (s *Server) GetMySessions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return
+	}
+	currentID, _ := middleware.GetSessionID(c)
+
+	families, err := s.RefreshTokens.ListFamilies(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to load sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessionsResponse(families, currentID)})
+}
+
+// RevokeMySession - завершить одну из своих сессий
+// @Summary Revoke a session
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/users/me/sessions/{id} [delete]
+func (s *Server) RevokeMySession(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	family, err := s.RefreshTokens.GetFamily(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenInvalid) {
+			apierror.Abort(c, apierror.NotFound(apierror.CodeSessionNotFound, "session not found"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("failed to load session", err))
+		return
+	}
+	// Чужую сессию не выдаём даже фактом существования
+	if family.UserID != userID {
+		apierror.Abort(c, apierror.NotFound(apierror.CodeSessionNotFound, "session not found"))
+		return
+	}
+
+	if err := s.RefreshTokens.RevokeFamily(ctx, family.ID); err != nil {
+		apierror.Abort(c, apierror.Internal("failed to revoke session", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// RevokeAllMySessions - выйти везде
+// @Summary Log out everywhere
+// @Description Revokes all sessions of the current user. With keepCurrent=true the session making the request stays active.
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Param keepCurrent query bool false "Keep the current session"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/users/me/sessions [delete]
+func (s *Server) RevokeAllMySessions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return
+	}
+
+	exceptID := ""
+	if keep, _ := strconv.ParseBool(c.Query("keepCurrent")); keep {
+		exceptID, _ = middleware.GetSessionID(c)
+	}
+
+	revoked, err := s.RefreshTokens.RevokeAllForUser(c.Request.Context(), userID, exceptID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to revoke sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "sessions revoked", "revoked": revoked})
+}
+
+// ============================================
+// ADMIN SESSIONS
+// ============================================
+
+// AdminGetUserSessions - сессии любого пользователя
+// @Summary List user sessions (admin)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/admin/users/{id}/sessions [get]
+func (s *Server) AdminGetUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid user id"))
+		return
+	}
+
+	families, err := s.RefreshTokens.ListFamilies(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to load sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessionsResponse(families, "")})
+}
+
+// AdminRevokeUserSessions - принудительно завершить все сессии пользователя
+// @Summary Revoke all user sessions (admin)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/admin/users/{id}/sessions [delete]
+func (s *Server) AdminRevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid user id"))
+		return
+	}
+
+	revoked, err := s.RefreshTokens.RevokeAllForUser(c.Request.Context(), userID, "")
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to revoke sessions", err))
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	middleware.Logger(c).Info("admin revoked user sessions", logging.UserID(userID), slog.Int64("admin_id", adminID), slog.Int("revoked", revoked))
+	recordAudit(c, s.Audit, models.AuditActionSessionRevoke, models.AuditEntityUser, userID, nil, gin.H{"revoked": revoked})
+
+	c.JSON(http.StatusOK, gin.H{"message": "sessions revoked", "revoked": revoked})
+}
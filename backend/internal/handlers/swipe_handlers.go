@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
 	"backend/internal/middleware"
 	"backend/internal/models"
-	"encoding/json"
-	"log"
+	"backend/internal/services"
+	"backend/internal/validation"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -19,22 +21,26 @@ import (
 // GetSwipePreferences - получить настройки свайпа для текущего хакатона
 func (s *Server) GetSwipePreferences(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
 
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	if user.CurrentHackathonID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you must be registered for a hackathon first"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeNotRegistered, "you must be registered for a hackathon first"))
 		return
 	}
 
-	var pref models.SwipePreference
-	err := database.DB.Where("user_id = ? AND hackathon_id = ?", userID, *user.CurrentHackathonID).First(&pref).Error
-
+	pref, err := s.Matching.Preferences(ctx, userID, *user.CurrentHackathonID)
 	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch preferences", err))
+		return
+	}
+
+	if pref == nil {
 		// Возвращаем дефолтные настройки
 		c.JSON(http.StatusOK, gin.H{
 			"minMmr":              nil,
@@ -71,57 +77,35 @@ func (s *Server) UpdateSwipePreferences(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+	ctx := c.Request.Context()
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	if user.CurrentHackathonID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you must be registered for a hackathon first"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeNotRegistered, "you must be registered for a hackathon first"))
 		return
 	}
 
-	hackathonID := *user.CurrentHackathonID
-
-	// Upsert preferences
-	var pref models.SwipePreference
-	err := database.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&pref).Error
-
-	if err != nil {
-		// Создаём новую запись
-		pref = models.SwipePreference{
-			UserID:              userID,
-			HackathonID:         hackathonID,
-			MinMMR:              req.MinMMR,
-			MaxMMR:              req.MaxMMR,
-			PreferredSkills:     req.PreferredSkills,
-			PreferredExperience: req.PreferredExperience,
-			PreferredRoles:      req.PreferredRoles,
-			VerifiedOnly:        req.VerifiedOnly,
-		}
-		if err := database.DB.Create(&pref).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create preferences"})
-			return
-		}
-	} else {
-		// Обновляем существующую
-		updates := map[string]interface{}{
-			"min_mmr":              req.MinMMR,
-			"max_mmr":              req.MaxMMR,
-			"preferred_skills":     req.PreferredSkills,
-			"preferred_experience": req.PreferredExperience,
-			"preferred_roles":      req.PreferredRoles,
-			"verified_only":        req.VerifiedOnly,
-		}
-		if err := database.DB.Model(&pref).Updates(updates).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update preferences"})
-			return
-		}
+	pref := models.SwipePreference{
+		UserID:              userID,
+		HackathonID:         *user.CurrentHackathonID,
+		MinMMR:              req.MinMMR,
+		MaxMMR:              req.MaxMMR,
+		PreferredSkills:     req.PreferredSkills,
+		PreferredExperience: req.PreferredExperience,
+		PreferredRoles:      req.PreferredRoles,
+		VerifiedOnly:        req.VerifiedOnly,
+	}
+	if err := s.Matching.SavePreferences(ctx, &pref); err != nil {
+		apierror.Abort(c, apierror.Internal("failed to save preferences", err))
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -139,15 +123,18 @@ func (s *Server) UpdateSwipePreferences(c *gin.Context) {
 func (s *Server) GetRecommendationsReal(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
 
+	// Контекст запроса - чтобы запросы к БД попали в трейс
+	db := database.DB.WithContext(c.Request.Context())
+
 	// Get user's current hackathon
 	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+	if err := db.First(&user, userID).Error; err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
 	if user.CurrentHackathonID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you must be registered for a hackathon first"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeNotRegistered, "you must be registered for a hackathon first"))
 		return
 	}
 
@@ -155,16 +142,16 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 
 	// Load user's swipe preferences
 	var prefs models.SwipePreference
-	hasPrefs := database.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&prefs).Error == nil
+	hasPrefs := db.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&prefs).Error == nil
 
 	// Get participants of the same hackathon who are looking for team
 	// Exclude: current user, users already swiped, users in same team
-	subQuery := database.DB.
+	subQuery := db.
 		Table("swipes").
 		Select("target_user_id").
 		Where("swiper_team_id = ? OR swiper_team_id IN (SELECT id FROM teams WHERE captain_id = ?)", userID, userID)
 
-	query := database.DB.
+	query := db.
 		Joins("JOIN hackathon_participants hp ON hp.user_id = users.id").
 		Where("hp.hackathon_id = ?", hackathonID).
 		Where("users.id != ?", userID).
@@ -206,7 +193,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 	err := query.Limit(20).Find(&candidates).Error
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch recommendations"})
+		apierror.Abort(c, apierror.Internal("failed to fetch recommendations", err))
 		return
 	}
 
@@ -216,7 +203,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 		// Получаем кастомизацию пользователя
 		var customization *models.ProfileCustomization
 		var profileCustom models.ProfileCustomization
-		if err := database.DB.Where("user_id = ?", u.ID).First(&profileCustom).Error; err == nil {
+		if err := db.Where("user_id = ?", u.ID).First(&profileCustom).Error; err == nil {
 			customization = &profileCustom
 		}
 
@@ -228,7 +215,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 			// Загружаем предметы по ID
 			if customization.BackgroundID != nil {
 				var item models.CustomizationItem
-				if err := database.DB.Where("item_id = ?", *customization.BackgroundID).First(&item).Error; err == nil {
+				if err := db.Where("item_id = ?", *customization.BackgroundID).First(&item).Error; err == nil {
 					cr["background"] = gin.H{
 						"id":     item.ItemID,
 						"name":   item.Name,
@@ -240,7 +227,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 
 			if customization.NameColorID != nil {
 				var item models.CustomizationItem
-				if err := database.DB.Where("item_id = ?", *customization.NameColorID).First(&item).Error; err == nil {
+				if err := db.Where("item_id = ?", *customization.NameColorID).First(&item).Error; err == nil {
 					cr["nameColor"] = gin.H{
 						"id":     item.ItemID,
 						"name":   item.Name,
@@ -252,7 +239,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 
 			if customization.AvatarFrameID != nil {
 				var item models.CustomizationItem
-				if err := database.DB.Where("item_id = ?", *customization.AvatarFrameID).First(&item).Error; err == nil {
+				if err := db.Where("item_id = ?", *customization.AvatarFrameID).First(&item).Error; err == nil {
 					cr["avatarFrame"] = gin.H{
 						"id":     item.ItemID,
 						"name":   item.Name,
@@ -264,7 +251,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 
 			if customization.TitleID != nil {
 				var item models.CustomizationItem
-				if err := database.DB.Where("item_id = ?", *customization.TitleID).First(&item).Error; err == nil {
+				if err := db.Where("item_id = ?", *customization.TitleID).First(&item).Error; err == nil {
 					cr["title"] = gin.H{
 						"id":     item.ItemID,
 						"name":   item.Name,
@@ -276,7 +263,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 
 			if customization.EffectID != nil {
 				var item models.CustomizationItem
-				if err := database.DB.Where("item_id = ?", *customization.EffectID).First(&item).Error; err == nil {
+				if err := db.Where("item_id = ?", *customization.EffectID).First(&item).Error; err == nil {
 					cr["effect"] = gin.H{
 						"id":     item.ItemID,
 						"name":   item.Name,
@@ -292,7 +279,7 @@ func (s *Server) GetRecommendationsReal(c *gin.Context) {
 			for _, badgeID := range badgeIDs {
 				if badgeID != nil {
 					var item models.CustomizationItem
-					if err := database.DB.Where("item_id = ?", *badgeID).First(&item).Error; err == nil {
+					if err := db.Where("item_id = ?", *badgeID).First(&item).Error; err == nil {
 						badges = append(badges, gin.H{
 							"id":     item.ItemID,
 							"name":   item.Name,
@@ -344,174 +331,66 @@ func (s *Server) SwipeReal(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if req.Action != "like" && req.Action != "pass" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be 'like' or 'pass'"})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	userID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
 
-	// Get or create team for the user (captain becomes the team)
-	var team models.Team
-	err := database.DB.Where("captain_id = ?", userID).First(&team).Error
-
-	var swiperTeamID int64
+	result, err := s.Matching.Swipe(ctx, userID, req.TargetUserID, req.Action)
 	if err != nil {
-		// User doesn't have a team, use userID as swiperTeamID (solo user)
-		swiperTeamID = userID
-	} else {
-		swiperTeamID = team.ID
-	}
-
-	// Check if already swiped
-	var existingSwipe models.Swipe
-	err = database.DB.
-		Where("swiper_team_id = ? AND target_user_id = ?", swiperTeamID, req.TargetUserID).
-		First(&existingSwipe).Error
-
-	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "already swiped on this user"})
+		switch {
+		case errors.Is(err, services.ErrInvalidSwipeAction):
+			apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, err.Error()))
+		case errors.Is(err, services.ErrAlreadySwiped):
+			apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadySwiped, err.Error()))
+		default:
+			apierror.Abort(c, apierror.Internal("failed to save swipe", err))
+		}
 		return
 	}
 
-	// Get current user info for notification
-	var currentUser models.User
-	database.DB.First(&currentUser, userID)
-
-	// Create swipe
-	swipe := models.Swipe{
-		SwiperTeamID: swiperTeamID,
-		TargetUserID: req.TargetUserID,
-		Action:       req.Action,
+	response := gin.H{
+		"success":    true,
+		"action":     req.Action,
+		"match":      result.Match != nil,
+		"inviteSent": result.Invite != nil,
 	}
 
-	if err := database.DB.Create(&swipe).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save swipe"})
+	if result.Invite == nil && result.Match == nil {
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
-	// If user is captain and action is "like", automatically send invite
-	var inviteSent bool
-	var inviteID int64
-	if req.Action == "like" && team.ID != 0 && team.CaptainID == userID {
-		// Check if target user is not already in a team for this hackathon
-		inTeam, _, _ := isUserInTeamForHackathon(req.TargetUserID, team.HackathonID)
-		if !inTeam {
-			// Check if invite already exists
-			var existingInvite models.TeamInvite
-			err := database.DB.Where("team_id = ? AND invited_user_id = ? AND status = ?", team.ID, req.TargetUserID, "pending").First(&existingInvite).Error
-			if err != nil {
-				// No existing invite, create one
-				invite := models.TeamInvite{
-					TeamID:        team.ID,
-					InvitedUserID: req.TargetUserID,
-					InviterID:     userID,
-					Status:        "pending",
-				}
-				if err := database.DB.Create(&invite).Error; err == nil {
-					inviteSent = true
-					inviteID = invite.ID
-					log.Printf("[SwipeReal] Auto-created invite ID=%d for user %d to team %d", invite.ID, req.TargetUserID, team.ID)
-					
-					// Send notification to target user
-					var targetUser models.User
-					database.DB.First(&targetUser, req.TargetUserID)
-					go s.sendTeamInviteNotification(team, currentUser, targetUser, invite.ID)
-				}
-			}
-		}
+	currentUser, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		currentUser = &models.User{ID: userID}
 	}
-
-	// If "like", check for mutual match
-	isMatch := false
-	var matchedUserInfo *models.User
-	if req.Action == "like" {
-		// Check if target user also liked current user/team
-		var reverseSwipe models.Swipe
-		err = database.DB.
-			Where("swiper_team_id = ? AND target_user_id = ? AND action = ?", req.TargetUserID, userID, "like").
-			First(&reverseSwipe).Error
-
-		if err == nil {
-			// It's a match!
-			isMatch = true
-
-			// Create match record
-			match := models.Match{
-				TeamID: swiperTeamID,
-				UserID: req.TargetUserID,
-			}
-			database.DB.Create(&match)
-
-			// Get matched user info
-			var targetUser models.User
-			database.DB.First(&targetUser, req.TargetUserID)
-			matchedUserInfo = &targetUser
-
-			// Create notifications for both users
-			notifData := map[string]interface{}{
-				"matchId":      match.ID,
-				"fromUserId":   userID,
-				"fromUserName": currentUser.Name,
-			}
-			notifDataJSON, _ := json.Marshal(notifData)
-
-			// Notify target user
-			notif1 := models.Notification{
-				UserID:  req.TargetUserID,
-				Type:    models.NotificationTypeMatch,
-				Title:   "Новый мэтч! 🎉",
-				Message: currentUser.Name + " тоже хочет с тобой в команду!",
-				Data:    notifDataJSON,
-			}
-			database.DB.Create(&notif1)
-
-			// Notify current user
-			notifData2 := map[string]interface{}{
-				"matchId":      match.ID,
-				"fromUserId":   req.TargetUserID,
-				"fromUserName": targetUser.Name,
-			}
-			notifData2JSON, _ := json.Marshal(notifData2)
-
-			notif2 := models.Notification{
-				UserID:  userID,
-				Type:    models.NotificationTypeMatch,
-				Title:   "Новый мэтч! 🎉",
-				Message: targetUser.Name + " тоже хочет с тобой в команду!",
-				Data:    notifData2JSON,
-			}
-			database.DB.Create(&notif2)
-
-			// Send via Redis (for TG bot)
-			if s.NotificationService != nil {
-				s.NotificationService.SendMatchNotification(req.TargetUserID, currentUser.Name)
-				s.NotificationService.SendMatchNotification(userID, targetUser.Name)
-			}
-		}
+	targetUser, err := s.UserRepo.GetByID(ctx, req.TargetUserID)
+	if err != nil {
+		targetUser = &models.User{ID: req.TargetUserID}
 	}
 
-	response := gin.H{
-		"success":    true,
-		"action":     req.Action,
-		"match":      isMatch,
-		"inviteSent": inviteSent,
+	if result.Invite != nil {
+		response["inviteId"] = result.Invite.ID
+		s.sendTeamInviteNotification(ctx, *result.Team, *currentUser, *targetUser, result.Invite.ID)
 	}
 
-	if inviteSent {
-		response["inviteId"] = inviteID
-	}
+	if result.Match != nil {
+		s.Matching.NotifyMatch(ctx, result.Match, currentUser, targetUser)
+
+		// Send via Redis (for TG bot)
+		if s.NotificationService != nil {
+			s.NotificationService.SendMatchNotification(ctx, req.TargetUserID, currentUser.Name)
+			s.NotificationService.SendMatchNotification(ctx, userID, targetUser.Name)
+		}
 
-	if matchedUserInfo != nil {
 		response["matchedUser"] = gin.H{
-			"id":       matchedUserInfo.ID,
-			"name":     matchedUserInfo.Name,
-			"username": matchedUserInfo.Username,
-			"avatar":   matchedUserInfo.AvatarURL,
+			"id":       targetUser.ID,
+			"name":     targetUser.Name,
+			"username": targetUser.Username,
+			"avatar":   targetUser.AvatarURL,
 		}
 	}
 
@@ -522,27 +401,24 @@ func (s *Server) SwipeReal(c *gin.Context) {
 func (s *Server) GetMatches(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
 
-	var matches []models.Match
-
 	// Get matches where user is either the team captain or the matched user
-	err := database.DB.
-		Where("team_id = ? OR user_id = ?", userID, userID).
-		Find(&matches).Error
-
+	matches, err := s.Matching.Matches(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch matches"})
+		apierror.Abort(c, apierror.Internal("failed to fetch matches", err))
 		return
 	}
 
 	// Get user details for each match
 	response := make([]gin.H, 0)
 	for _, m := range matches {
-		var matchedUser models.User
+		// Вторая сторона: капитан (team_id хранит его id) или пользователь
+		otherID := m.UserID
 		if m.UserID == userID {
-			// Find the team captain
-			database.DB.Where("id = ?", m.TeamID).First(&matchedUser)
-		} else {
-			database.DB.Where("id = ?", m.UserID).First(&matchedUser)
+			otherID = m.TeamID
+		}
+		matchedUser, err := s.UserRepo.GetByID(c.Request.Context(), otherID)
+		if err != nil {
+			matchedUser = &models.User{ID: otherID}
 		}
 
 		response = append(response, gin.H{
@@ -569,13 +445,13 @@ func (s *Server) GetMatches(c *gin.Context) {
 func (s *Server) GetMeReal(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -602,12 +478,12 @@ func (s *Server) UpdateProfileReal(c *gin.Context) {
 		Name       string   `json:"name"`
 		Bio        string   `json:"bio"`
 		Skills     []string `json:"skills"`
-		Experience string   `json:"experience"`
-		LookingFor []string `json:"lookingFor"`
+		Experience string   `json:"experience" binding:"omitempty,experience"`
+		LookingFor []string `json:"lookingFor" binding:"omitempty,dive,team_role"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -634,7 +510,7 @@ func (s *Server) UpdateProfileReal(c *gin.Context) {
 	}
 
 	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		apierror.Abort(c, apierror.Internal("failed to update profile", err))
 		return
 	}
 
@@ -657,13 +533,13 @@ func (s *Server) UpdateProfileReal(c *gin.Context) {
 func (s *Server) GetUserByID(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -681,7 +557,7 @@ func (s *Server) GetUserByID(c *gin.Context) {
 func (s *Server) GetAllUsersReal(c *gin.Context) {
 	var users []models.User
 	if err := database.DB.Find(&users).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch users"})
+		apierror.Abort(c, apierror.Internal("failed to fetch users", err))
 		return
 	}
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
 	"backend/internal/middleware"
 	"backend/internal/models"
@@ -43,11 +44,11 @@ func (s *Server) GetTeamBalance(c *gin.Context) {
 		// Пробуем найти команду через user.team_id
 		var user models.User
 		if err := database.DB.First(&user, userID).Error; err != nil || user.TeamID == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+			apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 			return
 		}
 		if err := database.DB.First(&team, *user.TeamID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+			apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 			return
 		}
 	}
@@ -220,7 +221,7 @@ func (s *Server) GetCandidateCompatibility(c *gin.Context) {
 	candidateID := c.Query("candidateId")
 
 	if candidateID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "candidateId is required"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "candidateId is required"))
 		return
 	}
 
@@ -230,11 +231,11 @@ func (s *Server) GetCandidateCompatibility(c *gin.Context) {
 	if err != nil {
 		var user models.User
 		if err := database.DB.First(&user, userID).Error; err != nil || user.TeamID == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+			apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 			return
 		}
 		if err := database.DB.First(&team, *user.TeamID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+			apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 			return
 		}
 	}
@@ -251,7 +252,7 @@ func (s *Server) GetCandidateCompatibility(c *gin.Context) {
 	// Получаем кандидата
 	var candidate models.User
 	if err := database.DB.First(&candidate, candidateID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "candidate not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "candidate not found")))
 		return
 	}
 
@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
+	"backend/internal/logging"
 	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/services"
+	"backend/internal/validation"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -19,119 +25,172 @@ import (
 // HELPER FUNCTIONS
 // ============================================
 
-// getTeamMemberCount - получить количество участников команды (включая капитана)
-func getTeamMemberCount(teamID int64) (int, error) {
-	var count int64
-	err := database.DB.Model(&models.User{}).Where("team_id = ?", teamID).Count(&count).Error
-	if err != nil {
-		return 0, err
+// writeMembershipError - ответ на ошибку TeamMembershipService
+func writeMembershipError(c *gin.Context, err error) {
+	var full *services.TeamFullError
+	switch {
+	case errors.As(err, &full):
+		apierror.Abort(c, apierror.Conflict(apierror.CodeTeamFull, "team is full").
+			WithMeta("current", full.Current).
+			WithMeta("max", full.Max))
+	case errors.Is(err, services.ErrTeamNotFound):
+		apierror.Abort(c, apierror.NotFound(apierror.CodeTeamNotFound, err.Error()))
+	case errors.Is(err, services.ErrInviteNotFound):
+		apierror.Abort(c, apierror.NotFound(apierror.CodeInviteNotFound, err.Error()))
+	case errors.Is(err, services.ErrJoinRequestNotFound):
+		apierror.Abort(c, apierror.NotFound(apierror.CodeJoinRequestNotFound, err.Error()))
+	case errors.Is(err, services.ErrNotInvitee):
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotInvitee, err.Error()))
+	case errors.Is(err, services.ErrNotTeamCaptain):
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotTeamCaptain, err.Error()))
+	case errors.Is(err, services.ErrAlreadyInTeam):
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyInTeam, "you are already in a team for this hackathon"))
+	case errors.Is(err, services.ErrTeamClosed):
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeTeamClosed, err.Error()))
+	case errors.Is(err, services.ErrInviteProcessed),
+		errors.Is(err, services.ErrRequestProcessed):
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyProcessed, err.Error()))
+	default:
+		apierror.Abort(c, apierror.Internal("failed to update team membership", err))
 	}
+}
 
-	// Проверяем, есть ли капитан в команде по team_id
-	var team models.Team
-	if err := database.DB.First(&team, teamID).Error; err != nil {
-		return 0, err
-	}
+// ============================================
+// TEAM HANDLERS
+// ============================================
 
-	// Если капитан не в team_id, добавляем его к счету
-	var captain models.User
-	if err := database.DB.First(&captain, team.CaptainID).Error; err == nil {
-		if captain.TeamID == nil || *captain.TeamID != teamID {
-			count++
-		}
-	}
+// teamListSpec - сортировки и фильтры списков команд
+var teamListSpec = &pagination.Spec[models.Team]{
+	Sorts: map[string]pagination.Sort[models.Team]{
+		"createdAt": pagination.TimeSort("created_at", func(t models.Team) time.Time { return t.CreatedAt }),
+		"name":      pagination.StringSort("name", func(t models.Team) string { return t.Name }),
+	},
+	DefaultSort: "-createdAt",
+	Filters: map[string]pagination.Filter{
+		"status": pagination.Equals("status",
+			func(v string) bool { return models.TeamStatus(v).IsValid() }, "must be one of: looking, ready, closed"),
+		"search": pagination.Search("name", "description"),
+	},
+	ID: func(t models.Team) int64 { return t.ID },
+}
 
-	return int(count), nil
+// adminTeamListSpec - то же, что teamListSpec, плюс фильтр по хакатону
+var adminTeamListSpec = &pagination.Spec[models.Team]{
+	Sorts:       teamListSpec.Sorts,
+	DefaultSort: teamListSpec.DefaultSort,
+	Filters: map[string]pagination.Filter{
+		"status": teamListSpec.Filters["status"],
+		"search": teamListSpec.Filters["search"],
+		"hackathonId": pagination.Equals("hackathon_id",
+			func(v string) bool { _, err := strconv.ParseInt(v, 10, 64); return err == nil }, "must be a hackathon ID"),
+	},
+	ID: teamListSpec.ID,
 }
 
-// isUserInTeamForHackathon - проверить, находится ли пользователь в команде для данного хакатона
-func isUserInTeamForHackathon(userID int64, hackathonID int64) (bool, *models.Team, error) {
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil, nil
-		}
-		return false, nil, err
+// listTeams - страница команд по query (уже ограниченному хакатоном, если нужно)
+// с участниками и капитанами
+func listTeams(c *gin.Context, query *gorm.DB, spec *pagination.Spec[models.Team]) (*teamPage, bool) {
+	params, err := pagination.Parse(c, spec)
+	if err != nil {
+		apierror.Abort(c, err)
+		return nil, false
 	}
 
-	// Проверяем team_id пользователя
-	if user.TeamID != nil {
-		var team models.Team
-		if err := database.DB.First(&team, *user.TeamID).Error; err == nil {
-			if team.HackathonID == hackathonID {
-				return true, &team, nil
-			}
-		}
+	var total int64
+	if err := params.Filter(query.Session(&gorm.Session{}).Model(&models.Team{})).Count(&total).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to count teams", err))
+		return nil, false
 	}
 
-	// Проверяем, является ли пользователь капитаном команды для этого хакатона
-	var captainTeam models.Team
-	if err := database.DB.Where("captain_id = ? AND hackathon_id = ?", userID, hackathonID).First(&captainTeam).Error; err == nil {
-		return true, &captainTeam, nil
+	var teams []models.Team
+	if err := params.Query(query.Session(&gorm.Session{})).Find(&teams).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch teams", err))
+		return nil, false
 	}
+	teams, next := params.Next(teams)
 
-	return false, nil, nil
+	members, captains, err := loadTeamMembers(query.Session(&gorm.Session{NewDB: true}), teams)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to fetch team members", err))
+		return nil, false
+	}
+
+	return &teamPage{teams: teams, members: members, captains: captains, next: next, total: total}, true
 }
 
-// checkTeamCapacity - проверить, есть ли место в команде
-func checkTeamCapacity(teamID int64) (bool, int, int, error) {
-	var team models.Team
-	if err := database.DB.First(&team, teamID).Error; err != nil {
-		return false, 0, 0, err
+// teamPage - страница команд с участниками
+type teamPage struct {
+	teams    []models.Team
+	members  map[int64][]models.User
+	captains map[int64]models.User
+	next     *string
+	total    int64
+}
+
+// loadTeamMembers - участники и капитаны команд двумя запросами на всю страницу
+func loadTeamMembers(db *gorm.DB, teams []models.Team) (map[int64][]models.User, map[int64]models.User, error) {
+	members := make(map[int64][]models.User, len(teams))
+	captains := make(map[int64]models.User, len(teams))
+	if len(teams) == 0 {
+		return members, captains, nil
 	}
 
-	var hackathon models.Hackathon
-	if err := database.DB.First(&hackathon, team.HackathonID).Error; err != nil {
-		return false, 0, 0, err
+	teamIDs := make([]int64, len(teams))
+	captainIDs := make([]int64, 0, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.ID
+		members[team.ID] = []models.User{}
+		if team.CaptainID != 0 {
+			captainIDs = append(captainIDs, team.CaptainID)
+		}
 	}
 
-	memberCount, err := getTeamMemberCount(teamID)
-	if err != nil {
-		return false, 0, 0, err
+	var users []models.User
+	if err := db.Where("team_id IN ?", teamIDs).Order("id").Find(&users).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, u := range users {
+		members[*u.TeamID] = append(members[*u.TeamID], u)
 	}
 
-	return memberCount < hackathon.TeamSize, memberCount, hackathon.TeamSize, nil
+	if len(captainIDs) > 0 {
+		var captainUsers []models.User
+		if err := db.Where("id IN ?", captainIDs).Find(&captainUsers).Error; err != nil {
+			return nil, nil, err
+		}
+		for _, u := range captainUsers {
+			captains[u.ID] = u
+		}
+	}
+	return members, captains, nil
 }
 
-// ============================================
-// TEAM HANDLERS
-// ============================================
-
-// GetTeamsReal - получить все команды для текущего хакатона
+// GetTeamsReal - команды текущего хакатона пользователя (страница)
 func (s *Server) GetTeamsReal(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
+	db := database.DB.WithContext(c.Request.Context())
 
 	// Get user's current hackathon
 	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+	if err := db.First(&user, userID).Error; err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
-	var teams []models.Team
-	query := database.DB
-
+	query := db
 	if user.CurrentHackathonID != nil {
 		query = query.Where("hackathon_id = ?", *user.CurrentHackathonID)
 	}
 
-	if err := query.Find(&teams).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch teams"})
+	page, ok := listTeams(c, query, teamListSpec)
+	if !ok {
 		return
 	}
 
-	// Build response with members
-	response := make([]gin.H, len(teams))
-	for i, team := range teams {
-		// Get members
-		var members []models.User
-		database.DB.Where("team_id = ?", team.ID).Find(&members)
-
-		// Get captain
-		var captain models.User
-		database.DB.First(&captain, team.CaptainID)
-
-		response[i] = gin.H{
+	items := make([]gin.H, len(page.teams))
+	for i, team := range page.teams {
+		members := page.members[team.ID]
+		items[i] = gin.H{
 			"id":          team.ID,
 			"name":        team.Name,
 			"description": team.Description,
@@ -139,7 +198,7 @@ func (s *Server) GetTeamsReal(c *gin.Context) {
 			"captainId":   team.CaptainID,
 			"status":      team.Status,
 			"inviteCode":  team.InviteCode,
-			"captain":     captain,
+			"captain":     page.captains[team.CaptainID],
 			"members":     members,
 			"memberCount": len(members),
 			"background":  team.Background,
@@ -150,7 +209,7 @@ func (s *Server) GetTeamsReal(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, pagination.NewPage(items, page.next, page.total))
 }
 
 // GetMyTeamReal - получить команду текущего пользователя
@@ -159,11 +218,11 @@ func (s *Server) GetMyTeamReal(c *gin.Context) {
 
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
-	log.Printf("[GetMyTeamReal] userID=%d, teamID=%v", userID, user.TeamID)
+	middleware.Logger(c).Debug("looking up my team", slog.Any("user_team_id", user.TeamID))
 
 	var team models.Team
 	var found bool
@@ -171,24 +230,24 @@ func (s *Server) GetMyTeamReal(c *gin.Context) {
 	if user.TeamID != nil {
 		if err := database.DB.First(&team, *user.TeamID).Error; err == nil {
 			found = true
-			log.Printf("[GetMyTeamReal] Found team by user.TeamID: %d", team.ID)
+			middleware.Logger(c).Debug("found team by user.TeamID", logging.TeamID(team.ID))
 		}
 	}
 
 	if !found {
 		// Check if user is a captain
 		if err := database.DB.Where("captain_id = ?", userID).First(&team).Error; err != nil {
-			log.Printf("[GetMyTeamReal] No team found for user %d", userID)
+			middleware.Logger(c).Debug("no team found for user")
 			c.JSON(http.StatusOK, nil)
 			return
 		}
-		log.Printf("[GetMyTeamReal] Found team as captain: %d", team.ID)
+		middleware.Logger(c).Debug("found team as captain", logging.TeamID(team.ID))
 	}
 
 	// Get members
 	var members []models.User
 	database.DB.Where("team_id = ?", team.ID).Find(&members)
-	log.Printf("[GetMyTeamReal] Members count: %d", len(members))
+	middleware.Logger(c).Debug("team members loaded", slog.Int("count", len(members)))
 
 	// Get captain
 	var captain models.User
@@ -222,7 +281,7 @@ func (s *Server) CreateTeamReal(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -232,14 +291,14 @@ func (s *Server) CreateTeamReal(c *gin.Context) {
 	var existingTeam models.Team
 	err := database.DB.Where("captain_id = ? AND hackathon_id = ?", userID, req.HackathonID).First(&existingTeam).Error
 	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you already have a team for this hackathon"})
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyInTeam, "you already have a team for this hackathon"))
 		return
 	}
 
 	// Check if user is already a member of another team for this hackathon
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -247,7 +306,7 @@ func (s *Server) CreateTeamReal(c *gin.Context) {
 		var existingMemberTeam models.Team
 		if err := database.DB.First(&existingMemberTeam, *user.TeamID).Error; err == nil {
 			if existingMemberTeam.HackathonID == req.HackathonID {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "you are already in a team for this hackathon"})
+				apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyInTeam, "you are already in a team for this hackathon"))
 				return
 			}
 		}
@@ -269,7 +328,7 @@ func (s *Server) CreateTeamReal(c *gin.Context) {
 	}
 
 	if err := database.DB.Create(&team).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create team"})
+		apierror.Abort(c, apierror.Internal("failed to create team", err))
 		return
 	}
 
@@ -284,7 +343,7 @@ func (s *Server) CreateTeamReal(c *gin.Context) {
 	// Update user's team_id
 	if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("team_id", team.ID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user team"})
+		apierror.Abort(c, apierror.Internal("failed to update user team", err))
 		return
 	}
 
@@ -293,11 +352,11 @@ func (s *Server) CreateTeamReal(c *gin.Context) {
 		Where("user_id = ? AND hackathon_id = ?", userID, req.HackathonID).
 		Update("status", "in_team").Error; err != nil {
 		// Если запись не найдена, это не критично - пользователь может быть не зарегистрирован
-		log.Printf("Warning: failed to update hackathon participant status: %v", err)
+		middleware.Logger(c).Warn("failed to update hackathon participant status", logging.Err(err))
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+		apierror.Abort(c, apierror.Internal("failed to commit transaction", err))
 		return
 	}
 
@@ -308,7 +367,7 @@ func (s *Server) CreateTeamReal(c *gin.Context) {
 func (s *Server) UpdateTeamReal(c *gin.Context) {
 	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 
@@ -316,27 +375,27 @@ func (s *Server) UpdateTeamReal(c *gin.Context) {
 
 	var team models.Team
 	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	// Only captain can update
 	if team.CaptainID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only captain can update team"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotTeamCaptain, "only captain can update team"))
 		return
 	}
 
 	var req struct {
 		Name        string  `json:"name"`
 		Description string  `json:"description"`
-		Background  *string `json:"background"`
-		BorderColor *string `json:"borderColor"`
-		NameColor   *string `json:"nameColor"`
-		AvatarUrl   *string `json:"avatarUrl"`
+		Background  *string `json:"background" binding:"omitempty,max=100,css_value"`
+		BorderColor *string `json:"borderColor" binding:"omitempty,max=50,css_value"`
+		NameColor   *string `json:"nameColor" binding:"omitempty,max=100,css_value"`
+		AvatarUrl   *string `json:"avatarUrl" binding:"omitempty,image_url"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -388,7 +447,7 @@ func (s *Server) UpdateTeamReal(c *gin.Context) {
 func (s *Server) LeaveTeamReal(c *gin.Context) {
 	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 
@@ -396,13 +455,13 @@ func (s *Server) LeaveTeamReal(c *gin.Context) {
 
 	var team models.Team
 	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	// Captain can't leave, must delete team
 	if team.CaptainID == userID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "captain cannot leave team, transfer ownership first"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeCaptainCannotLeave, "captain cannot leave team, transfer ownership first"))
 		return
 	}
 
@@ -421,7 +480,7 @@ func (s *Server) LeaveTeamReal(c *gin.Context) {
 func (s *Server) KickMemberReal(c *gin.Context) {
 	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 
@@ -430,37 +489,41 @@ func (s *Server) KickMemberReal(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	userID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
 
-	var team models.Team
-	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+	team, err := s.Teams.GetByID(ctx, teamID)
+	if err != nil {
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	// Only captain can kick
 	if team.CaptainID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only captain can kick members"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotTeamCaptain, "only captain can kick members"))
 		return
 	}
 
 	// Can't kick yourself
 	if req.UserID == userID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot kick yourself"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "cannot kick yourself"))
 		return
 	}
 
-	// Remove from team
-	database.DB.Model(&models.User{}).Where("id = ? AND team_id = ?", req.UserID, teamID).Update("team_id", nil)
-
-	// Update hackathon participant status
-	database.DB.Model(&models.HackathonParticipant{}).
-		Where("user_id = ? AND hackathon_id = ?", req.UserID, team.HackathonID).
-		Update("status", "looking")
+	kicked, err := s.Membership.KickMember(ctx, team, req.UserID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("failed to kick member", err))
+		return
+	}
+	if kicked {
+		// Кики часто оспаривают - фиксируем, кто и кого удалил
+		recordAudit(c, s.Audit, models.AuditActionTeamKick, models.AuditEntityUser, req.UserID,
+			gin.H{"teamId": team.ID}, gin.H{"teamId": nil})
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "member kicked"})
 }
@@ -469,16 +532,16 @@ func (s *Server) KickMemberReal(c *gin.Context) {
 func (s *Server) UpdateTeamStatusReal(c *gin.Context) {
 	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 
 	var req struct {
-		Status string `json:"status" binding:"required"`
+		Status models.TeamStatus `json:"status" binding:"required,team_status"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -486,27 +549,12 @@ func (s *Server) UpdateTeamStatusReal(c *gin.Context) {
 
 	var team models.Team
 	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	if team.CaptainID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only captain can update team status"})
-		return
-	}
-
-	// Validate status
-	validStatuses := []string{"looking", "ready", "closed"}
-	isValid := false
-	for _, s := range validStatuses {
-		if s == req.Status {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotTeamCaptain, "only captain can update team status"))
 		return
 	}
 
@@ -522,7 +570,7 @@ func (s *Server) UpdateTeamStatusReal(c *gin.Context) {
 func (s *Server) GenerateInviteLinkReal(c *gin.Context) {
 	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 
@@ -530,12 +578,12 @@ func (s *Server) GenerateInviteLinkReal(c *gin.Context) {
 
 	var team models.Team
 	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	if team.CaptainID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only captain can generate invite link"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotTeamCaptain, "only captain can generate invite link"))
 		return
 	}
 
@@ -557,91 +605,19 @@ func (s *Server) JoinTeamByCodeReal(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	userID, _ := middleware.GetUserID(c)
 
-	// Find team by code
-	var team models.Team
-	if err := database.DB.Where("invite_code = ?", req.Code).First(&team).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invalid invite code"})
-		return
-	}
-
-	// Check if team is open
-	if team.Status == models.TeamStatusClosed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is not accepting new members"})
-		return
-	}
-
-	// Check if user already in a team for this hackathon
-	inTeam, _, err := isUserInTeamForHackathon(userID, team.HackathonID)
+	team, err := s.Membership.JoinByCode(c.Request.Context(), userID, req.Code)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check user team status"})
-		return
-	}
-	if inTeam {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you are already in a team for this hackathon"})
-		return
-	}
-
-	// Check team capacity
-	hasCapacity, currentCount, maxSize, err := checkTeamCapacity(team.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check team capacity"})
-		return
-	}
-	if !hasCapacity {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is full", "current": currentCount, "max": maxSize})
-		return
-	}
-
-	// Используем транзакцию для атомарности операций
-	tx := database.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Add user to team
-	if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("team_id", team.ID).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to join team"})
-		return
-	}
-
-	// Update hackathon participant status
-	if err := tx.Model(&models.HackathonParticipant{}).
-		Where("user_id = ? AND hackathon_id = ?", userID, team.HackathonID).
-		Update("status", "in_team").Error; err != nil {
-		// Не критично, если запись не найдена
-		log.Printf("Warning: failed to update hackathon participant status: %v", err)
-	}
-
-	// Cancel other pending invites for this user for this hackathon
-	if err := tx.Model(&models.TeamInvite{}).
-		Where("invited_user_id = ? AND status = 'pending' AND team_id IN (SELECT id FROM teams WHERE hackathon_id = ?)", userID, team.HackathonID).
-		Update("status", "cancelled").Error; err != nil {
-		log.Printf("Warning: failed to cancel pending invites: %v", err)
-	}
-
-	// Cancel other pending join requests for this user for this hackathon
-	var otherTeams []models.Team
-	if err := tx.Where("hackathon_id = ?", team.HackathonID).Find(&otherTeams).Error; err == nil {
-		for _, t := range otherTeams {
-			if err := tx.Model(&models.TeamJoinRequest{}).
-				Where("team_id = ? AND user_id = ? AND status = 'pending'", t.ID, userID).
-				Update("status", "cancelled").Error; err != nil {
-				log.Printf("Warning: failed to cancel join request for team %d: %v", t.ID, err)
-			}
+		if errors.Is(err, services.ErrTeamNotFound) {
+			apierror.Abort(c, apierror.NotFound(apierror.CodeInviteNotFound, "invalid invite code"))
+			return
 		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+		writeMembershipError(c, err)
 		return
 	}
 
@@ -662,37 +638,36 @@ func generateInviteCode() string {
 // TEAM JOIN REQUESTS
 // ============================================
 
-// GetPublicTeams - получить список всех команд хакатона (публичный список для просмотра)
+// GetPublicTeams - команды хакатона (публичный список для просмотра, страница)
 func (s *Server) GetPublicTeams(c *gin.Context) {
 	hackathonID := c.Query("hackathonId")
 	if hackathonID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "hackathonId required"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "hackathonId required"))
 		return
 	}
 
-	hid, _ := strconv.ParseInt(hackathonID, 10, 64)
+	hid, err := strconv.ParseInt(hackathonID, 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid hackathon ID"))
+		return
+	}
 
-	var teams []models.Team
-	if err := database.DB.Where("hackathon_id = ?", hid).Find(&teams).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch teams"})
+	// Контекст запроса - чтобы запросы к БД попали в трейс
+	db := database.DB.WithContext(c.Request.Context())
+
+	page, ok := listTeams(c, db.Where("hackathon_id = ?", hid), teamListSpec)
+	if !ok {
 		return
 	}
 
 	// Get hackathon for max team size
 	var hackathon models.Hackathon
-	database.DB.First(&hackathon, hid)
-
-	response := make([]gin.H, 0, len(teams))
-	for _, team := range teams {
-		// Get members
-		var members []models.User
-		database.DB.Where("team_id = ?", team.ID).Find(&members)
+	db.First(&hackathon, hid)
 
-		// Get captain
-		var captain models.User
-		database.DB.First(&captain, team.CaptainID)
-
-		response = append(response, gin.H{
+	items := make([]gin.H, len(page.teams))
+	for i, team := range page.teams {
+		members := page.members[team.ID]
+		items[i] = gin.H{
 			"id":          team.ID,
 			"name":        team.Name,
 			"description": team.Description,
@@ -703,22 +678,22 @@ func (s *Server) GetPublicTeams(c *gin.Context) {
 			"borderColor": team.BorderColor,
 			"nameColor":   team.NameColor,
 			"avatarUrl":   team.AvatarUrl,
-			"captain":     captain,
+			"captain":     page.captains[team.CaptainID],
 			"members":     members,
 			"memberCount": len(members),
 			"maxMembers":  hackathon.TeamSize,
 			"createdAt":   team.CreatedAt,
-		})
+		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, pagination.NewPage(items, page.next, page.total))
 }
 
 // RequestJoinTeam - отправить запрос на вступление в команду
 func (s *Server) RequestJoinTeam(c *gin.Context) {
 	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 
@@ -732,48 +707,43 @@ func (s *Server) RequestJoinTeam(c *gin.Context) {
 	// Check if team exists and is open
 	var team models.Team
 	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	if team.Status == models.TeamStatusClosed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is not accepting new members"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeTeamClosed, "team is not accepting new members"))
 		return
 	}
 
 	// Check team capacity
-	hasCapacity, currentCount, maxSize, err := checkTeamCapacity(teamID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check team capacity"})
-		return
-	}
-	if !hasCapacity {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "team is full", "current": currentCount, "max": maxSize})
+	if err := s.Membership.CheckCapacity(c.Request.Context(), &team); err != nil {
+		writeMembershipError(c, err)
 		return
 	}
 
 	// Check if already has pending request
 	var existingRequest models.TeamJoinRequest
 	if err := database.DB.Where("team_id = ? AND user_id = ? AND status = 'pending'", teamID, userID).First(&existingRequest).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you already have a pending request"})
+		apierror.Abort(c, apierror.Conflict(apierror.CodeJoinRequestPending, "you already have a pending request"))
 		return
 	}
 
 	// Check if user already in a team for this hackathon
-	inTeam, _, err := isUserInTeamForHackathon(userID, team.HackathonID)
+	currentTeam, err := s.Membership.TeamForHackathon(c.Request.Context(), userID, team.HackathonID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check user team status"})
+		apierror.Abort(c, apierror.Internal("failed to check user team status", err))
 		return
 	}
-	if inTeam {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "you are already in a team for this hackathon"})
+	if currentTeam != nil {
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyInTeam, "you are already in a team for this hackathon"))
 		return
 	}
 
 	// Get user for notification
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeUserNotFound, "user not found")))
 		return
 	}
 
@@ -785,7 +755,7 @@ func (s *Server) RequestJoinTeam(c *gin.Context) {
 	}
 
 	if err := database.DB.Create(&joinRequest).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		apierror.Abort(c, apierror.Internal("failed to create request", err))
 		return
 	}
 
@@ -793,7 +763,7 @@ func (s *Server) RequestJoinTeam(c *gin.Context) {
 	var captain models.User
 	database.DB.First(&captain, team.CaptainID)
 
-	s.sendJoinRequestNotification(team, user, captain, joinRequest.ID)
+	s.sendJoinRequestNotification(c.Request.Context(), team, user, captain, joinRequest.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":   "request sent",
@@ -805,7 +775,7 @@ func (s *Server) RequestJoinTeam(c *gin.Context) {
 func (s *Server) GetTeamJoinRequests(c *gin.Context) {
 	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid team ID"))
 		return
 	}
 
@@ -814,12 +784,12 @@ func (s *Server) GetTeamJoinRequests(c *gin.Context) {
 	// Check if user is captain
 	var team models.Team
 	if err := database.DB.First(&team, teamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeTeamNotFound, "team not found")))
 		return
 	}
 
 	if team.CaptainID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only captain can view requests"})
+		apierror.Abort(c, apierror.Forbidden(apierror.CodeNotTeamCaptain, "only captain can view requests"))
 		return
 	}
 
@@ -848,7 +818,7 @@ func (s *Server) GetTeamJoinRequests(c *gin.Context) {
 func (s *Server) HandleJoinRequest(c *gin.Context) {
 	requestID, err := strconv.ParseInt(c.Param("requestId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid request ID"))
 		return
 	}
 
@@ -857,126 +827,30 @@ func (s *Server) HandleJoinRequest(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
 	if req.Action != "accept" && req.Action != "reject" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be 'accept' or 'reject'"})
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "action must be 'accept' or 'reject'"))
 		return
 	}
 
 	userID, _ := middleware.GetUserID(c)
 
-	// Get request
-	var joinRequest models.TeamJoinRequest
-	if err := database.DB.First(&joinRequest, requestID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "request not found"})
-		return
-	}
-
-	// Get team
-	var team models.Team
-	database.DB.First(&team, joinRequest.TeamID)
-
-	// Only captain can handle requests
-	if team.CaptainID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "only captain can handle requests"})
-		return
-	}
-
-	// Get requesting user
-	var requestingUser models.User
-	database.DB.First(&requestingUser, joinRequest.UserID)
-
-	if req.Action == "accept" {
-		// Check if user already in a team for this hackathon
-		inTeam, _, err := isUserInTeamForHackathon(joinRequest.UserID, team.HackathonID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check user team status"})
-			return
-		}
-		if inTeam {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "user is already in a team for this hackathon"})
-			return
-		}
-
-		// Check team capacity
-		hasCapacity, currentCount, maxSize, err := checkTeamCapacity(team.ID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check team capacity"})
-			return
-		}
-		if !hasCapacity {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "team is full", "current": currentCount, "max": maxSize})
-			return
-		}
-
-		// Используем транзакцию для атомарности операций
-		tx := database.DB.Begin()
-		defer func() {
-			if r := recover(); r != nil {
-				tx.Rollback()
-			}
-		}()
-
-		// Add user to team
-		if err := tx.Model(&models.User{}).Where("id = ?", joinRequest.UserID).Update("team_id", team.ID).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add user to team"})
-			return
-		}
-
-		// Update hackathon participant status
-		if err := tx.Model(&models.HackathonParticipant{}).
-			Where("user_id = ? AND hackathon_id = ?", joinRequest.UserID, team.HackathonID).
-			Update("status", "in_team").Error; err != nil {
-			// Не критично, если запись не найдена
-			log.Printf("Warning: failed to update hackathon participant status: %v", err)
-		}
-
-		joinRequest.Status = "accepted"
-		if err := tx.Save(&joinRequest).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update request status"})
-			return
-		}
-
-		// Cancel other pending invites for this user for this hackathon
-		if err := tx.Model(&models.TeamInvite{}).
-			Where("invited_user_id = ? AND status = 'pending' AND team_id IN (SELECT id FROM teams WHERE hackathon_id = ?)", joinRequest.UserID, team.HackathonID).
-			Update("status", "cancelled").Error; err != nil {
-			log.Printf("Warning: failed to cancel pending invites: %v", err)
-		}
-
-		// Cancel other pending join requests from this user for this hackathon
-		var otherTeams []models.Team
-		if err := tx.Where("hackathon_id = ?", team.HackathonID).Find(&otherTeams).Error; err == nil {
-			for _, t := range otherTeams {
-				if err := tx.Model(&models.TeamJoinRequest{}).
-					Where("team_id = ? AND user_id = ? AND status = 'pending'", t.ID, joinRequest.UserID).
-					Update("status", "cancelled").Error; err != nil {
-					log.Printf("Warning: failed to cancel join request for team %d: %v", t.ID, err)
-				}
-			}
-		}
-
-		if err := tx.Commit().Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
-			return
-		}
-
-		// Send acceptance notification
-		s.sendRequestResponseNotification(team, requestingUser, true)
-	} else {
-		joinRequest.Status = "rejected"
-		if err := database.DB.Save(&joinRequest).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update request status"})
+	joinRequest, team, err := s.Membership.HandleJoinRequest(c.Request.Context(), userID, requestID, req.Action == "accept")
+	if err != nil {
+		if errors.Is(err, services.ErrAlreadyInTeam) {
+			apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyInTeam, "user is already in a team for this hackathon"))
 			return
 		}
+		writeMembershipError(c, err)
+		return
+	}
 
-		// Send rejection notification
-		s.sendRequestResponseNotification(team, requestingUser, false)
+	// Уведомляем автора заявки
+	if requestingUser, err := s.UserRepo.GetByID(c.Request.Context(), joinRequest.UserID); err == nil {
+		s.sendRequestResponseNotification(c.Request.Context(), *team, *requestingUser, req.Action == "accept")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -1013,7 +887,7 @@ func (s *Server) GetMyJoinRequests(c *gin.Context) {
 func (s *Server) CancelJoinRequest(c *gin.Context) {
 	requestID, err := strconv.ParseInt(c.Param("requestId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request ID"})
+		apierror.Abort(c, apierror.InvalidID("invalid request ID"))
 		return
 	}
 
@@ -1021,12 +895,12 @@ func (s *Server) CancelJoinRequest(c *gin.Context) {
 
 	var joinRequest models.TeamJoinRequest
 	if err := database.DB.Where("id = ? AND user_id = ?", requestID, userID).First(&joinRequest).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "request not found"})
+		apierror.Abort(c, recordError(err, apierror.NotFound(apierror.CodeJoinRequestNotFound, "request not found")))
 		return
 	}
 
 	if joinRequest.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "can only cancel pending requests"})
+		apierror.Abort(c, apierror.Conflict(apierror.CodeAlreadyProcessed, "can only cancel pending requests"))
 		return
 	}
 
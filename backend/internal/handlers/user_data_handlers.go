@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// PERSONAL DATA (export / erasure)
+// ============================================
+
+// eraseRequest - подтверждение удаления, чтобы случайный запрос не стёр аккаунт
+type eraseRequest struct {
+	Confirm bool `json:"confirm"`
+}
+
+// writeUserExport - отдать архив данных пользователя как JSON-файл
+func (s *Server) writeUserExport(c *gin.Context, userID int64) {
+	export, err := s.UserData.Export(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			apierror.Abort(c, apierror.NotFound(apierror.CodeUserNotFound, "user not found"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("failed to export user data", err))
+		return
+	}
+
+	recordAudit(c, s.Audit, models.AuditActionUserExport, models.AuditEntityUser, userID, nil, nil)
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-export.json"`, userID))
+	c.JSON(http.StatusOK, export)
+}
+
+// eraseUser - обезличить пользователя после явного подтверждения
+func (s *Server) eraseUser(c *gin.Context, userID int64) {
+	var req eraseRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !req.Confirm {
+		apierror.Abort(c, apierror.BadRequest(apierror.CodeConfirmationRequired, "erasure must be confirmed with {\"confirm\": true}"))
+		return
+	}
+
+	result, err := s.UserData.Erase(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			apierror.Abort(c, apierror.NotFound(apierror.CodeUserNotFound, "user not found"))
+		case errors.Is(err, services.ErrUserAlreadyErased):
+			apierror.Abort(c, apierror.Conflict(apierror.CodeUserErased, "user data has already been erased"))
+		default:
+			apierror.Abort(c, apierror.Internal("failed to erase user data", err))
+		}
+		return
+	}
+
+	// В журнал только факт и последствия для команд - без стёртых данных
+	recordAudit(c, s.Audit, models.AuditActionUserErase, models.AuditEntityUser, userID, nil, gin.H{
+		"teamsHandedOver": result.TeamsHandedOver,
+		"teamsDissolved":  result.TeamsDissolved,
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportMyData - выгрузка всех данных текущего пользователя
+// @Summary Export my personal data
+// @Description JSON archive of profile, swipes, matches, notifications, inventory, achievements, team history and sessions
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.UserExport
+// @Router /api/users/me/export [get]
+func (s *Server) ExportMyData(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return
+	}
+	s.writeUserExport(c, userID)
+}
+
+// EraseMe - удалить персональные данные текущего пользователя
+// @Summary Erase my personal data
+// @Description Anonymizes the account, deletes swipes and notifications, hands off or dissolves captained teams and signs out everywhere
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body object true "{\"confirm\": true}"
+// @Success 200 {object} services.ErasureResult
+// @Failure 400 {object} map[string]string
+// @Router /api/users/me/erase [post]
+func (s *Server) EraseMe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "unauthorized"))
+		return
+	}
+	s.eraseUser(c, userID)
+}
+
+// AdminExportUser - выгрузка данных любого пользователя (admin)
+// @Summary Export user personal data
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} services.UserExport
+// @Failure 404 {object} map[string]string
+// @Router /api/admin/users/{id}/export [get]
+func (s *Server) AdminExportUser(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
+		return
+	}
+	s.writeUserExport(c, userID)
+}
+
+// AdminEraseUser - удалить персональные данные любого пользователя (admin)
+// @Summary Erase user personal data
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body object true "{\"confirm\": true}"
+// @Success 200 {object} services.ErasureResult
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/admin/users/{id}/erase [post]
+func (s *Server) AdminEraseUser(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("invalid user ID"))
+		return
+	}
+	s.eraseUser(c, userID)
+}
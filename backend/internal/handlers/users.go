@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/database"
 	"backend/internal/models"
 	"backend/internal/types"
+	"backend/internal/validation"
 	"context"
 	"net/http"
 
@@ -23,9 +25,7 @@ import (
 func registerUser(c *gin.Context) {
 	var req types.RegisterUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		apierror.Abort(c, validation.Error(err))
 		return
 	}
 
@@ -33,17 +33,13 @@ func registerUser(c *gin.Context) {
 
 	exists, err := database.UserExists(ctx, req.TelegramUserID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to check user existence",
-		})
+		apierror.Abort(c, apierror.Internal("failed to check user existence", err))
 		return
 	}
 
 	user, err := database.CreateUser(ctx, req.TelegramUserID, req.Username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to register user",
-		})
+		apierror.Abort(c, apierror.Internal("failed to register user", err))
 		return
 	}
 
@@ -111,9 +107,7 @@ func getAuthorizedUsers(c *gin.Context) {
 
 	users, err := database.GetAllAuthorizedUsers(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to get users",
-		})
+		apierror.Abort(c, apierror.Internal("failed to get users", err))
 		return
 	}
 
@@ -0,0 +1,147 @@
+// Package health - проверки готовности backend к приёму трафика.
+//
+// Каждая проверка выполняется параллельно со своим таймаутом, поэтому
+// зависшая зависимость не задерживает ответ /readyz дольше этого таймаута.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// CheckFunc - проверка зависимости; detail - краткое состояние для ответа
+// (версия схемы, число задач и т.п.), может быть пустым
+type CheckFunc func(ctx context.Context) (detail string, err error)
+
+// CheckResult - результат одной проверки
+type CheckResult struct {
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// Report - ответ /readyz
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// OK - все проверки прошли
+func (r Report) OK() bool { return r.Status == StatusOK }
+
+// Public - только статусы проверок. /readyz открыт всем, а тексты ошибок
+// и состояние пула выдают адреса и устройство инфраструктуры.
+func (r Report) Public() Report {
+	public := Report{Status: r.Status, Checks: make(map[string]CheckResult, len(r.Checks))}
+	for name, result := range r.Checks {
+		public.Checks[name] = CheckResult{Status: result.Status}
+	}
+	return public
+}
+
+// HTTPStatus - 200, если все проверки прошли, иначе 503
+func (r Report) HTTPStatus() int {
+	if r.OK() {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker - набор проверок готовности
+type Checker struct {
+	timeout time.Duration
+	checks  []namedCheck
+}
+
+// NewChecker - timeout ограничивает каждую проверку
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add - добавить проверку; вызывать до первого Run
+func (c *Checker) Add(name string, check CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Handler - полный отчёт с деталями и ошибками; только для внутреннего порта
+func (c *Checker) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(report.HTTPStatus())
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// Run - выполнить все проверки
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.run(ctx, nc.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[nc.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		detail, err := check(ctx)
+		done <- outcome{detail, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		// Проверка не уважает ctx - не ждём её
+		out = outcome{err: ctx.Err()}
+	}
+
+	result := CheckResult{
+		Status:   StatusOK,
+		Detail:   out.detail,
+		Duration: time.Since(start).Round(time.Microsecond).String(),
+	}
+	if out.err != nil {
+		result.Status = StatusFail
+		result.Error = out.err.Error()
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckerRun(t *testing.T) {
+	c := NewChecker(50 * time.Millisecond)
+	c.Add("ok", func(context.Context) (string, error) { return "version 7", nil })
+	c.Add("failing", func(context.Context) (string, error) {
+		return "", errors.New("dial tcp redis:6379: connect: connection refused")
+	})
+	// Не уважает ctx: Run не должен ждать её дольше таймаута
+	release := make(chan struct{})
+	defer close(release)
+	c.Add("hanging", func(context.Context) (string, error) {
+		<-release
+		return "", nil
+	})
+
+	start := time.Now()
+	report := c.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Run took %v with a 50ms timeout", elapsed)
+	}
+
+	if report.OK() || report.Status != StatusFail || report.HTTPStatus() != http.StatusServiceUnavailable {
+		t.Fatalf("report %+v", report)
+	}
+	if got := report.Checks["ok"]; got.Status != StatusOK || got.Detail != "version 7" || got.Error != "" {
+		t.Errorf("ok check %+v", got)
+	}
+	if got := report.Checks["failing"]; got.Status != StatusFail || !strings.Contains(got.Error, "connection refused") {
+		t.Errorf("failing check %+v", got)
+	}
+	if got := report.Checks["hanging"]; got.Status != StatusFail || got.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("hanging check %+v", got)
+	}
+}
+
+func TestCheckerRunsChecksInParallel(t *testing.T) {
+	c := NewChecker(time.Second)
+	for _, name := range []string{"postgres", "redis", "migrations"} {
+		c.Add(name, func(ctx context.Context) (string, error) {
+			time.Sleep(100 * time.Millisecond)
+			return "", nil
+		})
+	}
+
+	start := time.Now()
+	report := c.Run(context.Background())
+	if elapsed := time.Since(start); elapsed >= 250*time.Millisecond {
+		t.Fatalf("three 100ms checks took %v", elapsed)
+	}
+	if !report.OK() || report.HTTPStatus() != http.StatusOK || len(report.Checks) != 3 {
+		t.Fatalf("report %+v", report)
+	}
+}
+
+func TestReportPublic(t *testing.T) {
+	report := Report{Status: StatusFail, Checks: map[string]CheckResult{
+		"postgres": {Status: StatusOK, Detail: "3 open connections, 1 in use", Duration: "1ms"},
+		"redis":    {Status: StatusFail, Error: "dial tcp 10.0.0.5:6379: connection refused", Duration: "1ms"},
+	}}
+
+	body, err := json.Marshal(report.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"status":"fail","checks":{"postgres":{"status":"ok"},"redis":{"status":"fail"}}}`
+	if string(body) != want {
+		t.Fatalf("public report %s, want %s", body, want)
+	}
+	// Исходный отчёт не меняется
+	if report.Checks["redis"].Error == "" {
+		t.Fatal("Public modified the report")
+	}
+}
+
+func TestCheckerHandler(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Add("redis", func(context.Context) (string, error) { return "", errors.New("connection refused") })
+
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusServiceUnavailable || report.Checks["redis"].Error != "connection refused" {
+		t.Fatalf("%d %s", w.Code, w.Body.String())
+	}
+}
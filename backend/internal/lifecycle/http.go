@@ -0,0 +1,47 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// HTTPServer - http.Server как компонент: порт занимается в Start,
+// чтобы ошибка "address already in use" остановила запуск, а Stop
+// перестаёт принимать соединения и дожидается запросов в обработке.
+type HTTPServer struct {
+	server *http.Server
+	fail   func(error)
+}
+
+// NewHTTPServer - fail вызывается, если сервер упал уже после запуска (обычно Manager.Fail)
+func NewHTTPServer(server *http.Server, fail func(error)) *HTTPServer {
+	return &HTTPServer{server: server, fail: fail}
+}
+
+func (s *HTTPServer) Start(context.Context) error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	slog.Info("http server listening", slog.String("addr", ln.Addr().String()))
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.fail(fmt.Errorf("http server: %w", err))
+		}
+	}()
+	return nil
+}
+
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		// Дедлайн вышел - обрываем оставшиеся соединения
+		s.server.Close()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Package lifecycle - запуск и корректная остановка частей приложения.
+//
+// Компоненты стартуют в порядке регистрации и останавливаются в обратном:
+// сначала регистрируют то, от чего зависят остальные (Postgres, Redis),
+// последним - HTTP сервер, чтобы при остановке он первым перестал принимать запросы.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Component - часть приложения со своим запуском и остановкой.
+// Start не должен блокироваться: долгую работу он запускает в горутине.
+// Stop должен уложиться в дедлайн ctx.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Hook - Component из функций; любая может быть nil
+type Hook struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+func (h Hook) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+func (h Hook) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+type namedComponent struct {
+	name string
+	Component
+}
+
+// Manager - набор компонентов приложения
+type Manager struct {
+	components      []namedComponent
+	shutdownTimeout time.Duration
+	failed          chan error
+}
+
+func NewManager(shutdownTimeout time.Duration) *Manager {
+	return &Manager{
+		shutdownTimeout: shutdownTimeout,
+		failed:          make(chan error, 1),
+	}
+}
+
+// Register - добавить компонент; регистрировать нужно до Run
+func (m *Manager) Register(name string, c Component) {
+	m.components = append(m.components, namedComponent{name: name, Component: c})
+}
+
+// Go - зарегистрировать фоновую задачу, которая работает до остановки приложения.
+// run получает контекст, отменяемый при остановке; ошибка из run останавливает приложение.
+func (m *Manager) Go(name string, run func(ctx context.Context) error) {
+	m.Register(name, &job{name: name, run: run, fail: m.Fail})
+}
+
+// Fail - сообщить о фатальной ошибке компонента; приложение начнёт остановку
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failed <- err:
+	default:
+	}
+}
+
+// Run - запустить компоненты и ждать отмены ctx (SIGTERM) или фатальной ошибки,
+// затем остановить всё в обратном порядке за shutdownTimeout
+func (m *Manager) Run(ctx context.Context) error {
+	started := 0
+	var runErr error
+	for _, c := range m.components {
+		if err := c.Start(ctx); err != nil {
+			runErr = fmt.Errorf("failed to start %s: %w", c.name, err)
+			break
+		}
+		slog.Info("component started", slog.String("component", c.name))
+		started++
+	}
+
+	if runErr == nil {
+		select {
+		case <-ctx.Done():
+			slog.Info("shutdown requested")
+		case err := <-m.failed:
+			runErr = err
+			slog.Error("shutting down after failure", slog.Any("error", err))
+		}
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	var stopErrs []error
+	for i := started - 1; i >= 0; i-- {
+		c := m.components[i]
+		if err := c.Stop(stopCtx); err != nil {
+			slog.Error("failed to stop component", slog.String("component", c.name), slog.Any("error", err))
+			stopErrs = append(stopErrs, fmt.Errorf("stop %s: %w", c.name, err))
+			continue
+		}
+		slog.Info("component stopped", slog.String("component", c.name))
+	}
+
+	return errors.Join(append([]error{runErr}, stopErrs...)...)
+}
+
+// job - фоновая задача, зарегистрированная через Manager.Go
+type job struct {
+	name   string
+	run    func(ctx context.Context) error
+	fail   func(error)
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (j *job) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	go func() {
+		defer close(j.done)
+		if err := j.run(ctx); err != nil && ctx.Err() == nil {
+			j.fail(fmt.Errorf("%s: %w", j.name, err))
+		}
+	}()
+	return nil
+}
+
+func (j *job) Stop(ctx context.Context) error {
+	j.cancel()
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Group - короткие фоновые задачи, запущенные из обработчиков запросов
+// (уведомления и т.п.). При остановке Group дожидается их завершения,
+// а по истечении дедлайна отменяет их контекст.
+type Group struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	stopped bool
+	active  int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// ErrStopped - группа уже останавливается и новых задач не принимает
+var ErrStopped = errors.New("background tasks are stopped")
+
+// Go - выполнить fn в отдельной горутине.
+// fn получает значения из ctx (request ID и т.п.), но не его отмену: задача переживает запрос.
+// После начала остановки задача не запускается и возвращается ErrStopped: Redis и Postgres
+// закрываются сразу после Group, и задача всё равно не успела бы отработать.
+func (g *Group) Go(ctx context.Context, fn func(ctx context.Context)) error {
+	g.mu.Lock()
+	if g.stopped {
+		g.mu.Unlock()
+		slog.WarnContext(ctx, "background task rejected during shutdown")
+		return ErrStopped
+	}
+	g.wg.Add(1)
+	g.active++
+	g.mu.Unlock()
+
+	taskCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(g.ctx, cancel)
+	go func() {
+		defer g.wg.Done()
+		defer g.done()
+		defer cancel()
+		defer stop()
+		fn(taskCtx)
+	}()
+	return nil
+}
+
+func (g *Group) done() {
+	g.mu.Lock()
+	g.active--
+	g.mu.Unlock()
+}
+
+// Status - число выполняемых задач и началась ли остановка (для /readyz)
+func (g *Group) Status() (active int, stopped bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.active, g.stopped
+}
+
+func (g *Group) Start(context.Context) error { return nil }
+
+// Stop - дождаться запущенных задач; по дедлайну ctx отменить их
+func (g *Group) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		g.cancel()
+		return fmt.Errorf("background tasks did not finish: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,188 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder - журнал запусков и остановок компонентов
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// hook - компонент, который пишет в журнал; startErr возвращается из Start
+func (r *recorder) hook(name string, startErr error) Hook {
+	return Hook{
+		OnStart: func(context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestManagerStopsInReverseOrder(t *testing.T) {
+	rec := &recorder{}
+	m := NewManager(time.Second)
+	m.Register("postgres", rec.hook("postgres", nil))
+	m.Register("redis", rec.hook("redis", nil))
+	m.Register("http", rec.hook("http", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"start postgres", "start redis", "start http", "stop http", "stop redis", "stop postgres"}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Fatalf("events %v, want %v", rec.events, want)
+	}
+}
+
+func TestManagerStartFailure(t *testing.T) {
+	rec := &recorder{}
+	m := NewManager(time.Second)
+	m.Register("postgres", rec.hook("postgres", nil))
+	m.Register("redis", rec.hook("redis", errors.New("connection refused")))
+	m.Register("http", rec.hook("http", nil))
+
+	err := m.Run(context.Background())
+	if err == nil {
+		t.Fatal("Run succeeded with a failed component")
+	}
+
+	// Останавливаются только запущенные компоненты
+	want := []string{"start postgres", "start redis", "stop postgres"}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Fatalf("events %v, want %v", rec.events, want)
+	}
+}
+
+func TestManagerJobFailure(t *testing.T) {
+	m := NewManager(time.Second)
+	boom := errors.New("boom")
+	m.Go("worker", func(ctx context.Context) error { return boom })
+
+	if err := m.Run(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestGroupStopWaitsForTasks(t *testing.T) {
+	g := NewGroup()
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	if err := g.Go(context.Background(), func(context.Context) {
+		<-release
+		close(finished)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if active, stopped := g.Status(); active != 1 || stopped {
+		t.Fatalf("status %d, %v", active, stopped)
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- g.Stop(context.Background()) }()
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned before the task finished: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Stop returned before the task")
+	}
+	if active, _ := g.Status(); active != 0 {
+		t.Fatalf("%d tasks still active", active)
+	}
+}
+
+func TestGroupStopCancelsAfterDeadline(t *testing.T) {
+	g := NewGroup()
+	cancelled := make(chan struct{})
+	g.Go(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := g.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop: %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("task context not cancelled after the deadline")
+	}
+}
+
+func TestGroupTaskOutlivesRequest(t *testing.T) {
+	g := NewGroup()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	g.Go(ctx, func(ctx context.Context) {
+		time.Sleep(10 * time.Millisecond)
+		done <- ctx.Err()
+	})
+	// Запрос завершился, а задача продолжает работать
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("task cancelled with the request: %v", err)
+	}
+}
+
+func TestGroupGoAfterStop(t *testing.T) {
+	g := NewGroup()
+	if err := g.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	if err := g.Go(context.Background(), func(context.Context) { called = true }); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Go after Stop: %v", err)
+	}
+	if called {
+		t.Fatal("task ran after Stop")
+	}
+}
+
+func TestHTTPServerStartFailsOnBusyPort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	s := NewHTTPServer(&http.Server{Addr: ln.Addr().String()}, func(error) {})
+	if err := s.Start(context.Background()); err == nil {
+		s.Stop(context.Background())
+		t.Fatal("Start succeeded on a busy port")
+	}
+}
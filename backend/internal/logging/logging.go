@@ -0,0 +1,88 @@
+// Package logging - структурированные логи на log/slog.
+//
+// У каждого HTTP запроса свой логгер в контексте (с request_id, а после
+// авторизации - с user_id); обработчики и сервисы берут его через FromContext.
+// Имена полей общие для всего backend, чтобы по ним можно было искать.
+package logging
+
+import (
+	"backend/internal/config"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Общие имена полей
+const (
+	KeyRequestID   = "request_id"
+	KeyTraceID     = "trace_id"
+	KeyUserID      = "user_id"
+	KeyActorID     = "actor_id"
+	KeyHackathonID = "hackathon_id"
+	KeyTeamID      = "team_id"
+	KeyServiceID   = "service_id"
+	KeyError       = "error"
+)
+
+func UserID(id int64) slog.Attr      { return slog.Int64(KeyUserID, id) }
+func ActorID(id int64) slog.Attr     { return slog.Int64(KeyActorID, id) }
+func HackathonID(id int64) slog.Attr { return slog.Int64(KeyHackathonID, id) }
+func TeamID(id int64) slog.Attr      { return slog.Int64(KeyTeamID, id) }
+func Err(err error) slog.Attr        { return slog.Any(KeyError, err) }
+
+// New - логгер с уровнем и форматом (text или json) из конфигурации
+func New(cfg config.LogConfig, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(cfg.Format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text", "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected text or json", cfg.Format)
+	}
+}
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	requestIDKey
+)
+
+// WithLogger - положить логгер в контекст
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext - логгер запроса или slog.Default(), если его нет
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With - контекст, логгер которого дополнен полями args
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+// WithRequestID - запомнить ID запроса: он попадает в логи и в события для бота
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, id)
+	return With(ctx, slog.String(KeyRequestID, id))
+}
+
+// RequestID - ID запроса из контекста; "" вне HTTP запроса
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
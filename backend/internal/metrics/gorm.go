@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start"
+
+// GormPlugin - замер запросов GORM: db.Use(metrics.GormPlugin{})
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string { return "metrics" }
+
+// Initialize - таймер ставится первым колбэком операции и снимается последним
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("metrics:before_create", startQueryTimer),
+		cb.Create().After("*").Register("metrics:after_create", observeQuery("create")),
+		cb.Query().Before("*").Register("metrics:before_query", startQueryTimer),
+		cb.Query().After("*").Register("metrics:after_query", observeQuery("query")),
+		cb.Update().Before("*").Register("metrics:before_update", startQueryTimer),
+		cb.Update().After("*").Register("metrics:after_update", observeQuery("update")),
+		cb.Delete().Before("*").Register("metrics:before_delete", startQueryTimer),
+		cb.Delete().After("*").Register("metrics:after_delete", observeQuery("delete")),
+		cb.Row().Before("*").Register("metrics:before_row", startQueryTimer),
+		cb.Row().After("*").Register("metrics:after_row", observeQuery("row")),
+		cb.Raw().Before("*").Register("metrics:before_raw", startQueryTimer),
+		cb.Raw().After("*").Register("metrics:after_raw", observeQuery("raw")),
+	)
+}
+
+func startQueryTimer(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		// "Не найдено" - обычный ответ, а не сбой базы
+		status := "ok"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			status = "error"
+		}
+		DBQueryDuration.With(prometheus.Labels{
+			"operation": operation,
+			"table":     table,
+			"status":    status,
+		}).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,106 @@
+// Package metrics - метрики Prometheus.
+//
+// Все метрики регистрируются в собственном Registry и отдаются через Handler
+// на отдельном порту (metrics.addr), а не вместе с публичным API.
+// Метки только с ограниченным набором значений: маршрут, а не путь; тип, а не ID.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry - реестр метрик backend; сюда же регистрируются коллекторы вроде StreamCollector
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ============================================
+// ИНФРАСТРУКТУРА
+// ============================================
+
+var (
+	// HTTPRequestDuration - длительность запросов по маршруту и статусу
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// DBQueryDuration - длительность запросов GORM по операции и таблице
+	DBQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "GORM query latency by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	// RedisErrors - ошибки команд Redis (redis.Nil ошибкой не считается)
+	RedisErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_errors_total",
+		Help: "Failed Redis commands by command name.",
+	}, []string{"command"})
+
+	// RateLimited - запросы, отклонённые ограничением частоты, по политике
+	RateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limited_requests_total",
+		Help: "Requests rejected by rate limiting by policy.",
+	}, []string{"policy"})
+)
+
+// ============================================
+// ДОМЕННЫЕ СЧЁТЧИКИ
+// ============================================
+
+var (
+	// Swipes - свайпы по действию (like, pass)
+	Swipes = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "swipes_total",
+		Help: "Swipes by action.",
+	}, []string{"action"})
+
+	// MatchesCreated - взаимные лайки
+	MatchesCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "matches_created_total",
+		Help: "Mutual likes that created a match.",
+	})
+
+	// InvitesSent - приглашения в команду: от капитана вручную (manual) или после лайка (swipe)
+	InvitesSent = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "team_invites_sent_total",
+		Help: "Team invites sent by source.",
+	}, []string{"source"})
+
+	// InvitesAccepted - принятые приглашения
+	InvitesAccepted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "team_invites_accepted_total",
+		Help: "Team invites accepted by the invited user.",
+	})
+
+	// CasesOpened - открытые кейсы по редкости кейса
+	CasesOpened = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "cases_opened_total",
+		Help: "Cases opened by case rarity.",
+	}, []string{"rarity"})
+
+	// NotificationsPublished - события, записанные в Redis stream для бота
+	NotificationsPublished = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_published_total",
+		Help: "Events written to the notifications stream by type.",
+	}, []string{"type"})
+)
+
+// Handler - обработчик /metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
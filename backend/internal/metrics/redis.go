@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook - счётчик ошибок Redis: client.AddHook(metrics.RedisHook{})
+type RedisHook struct{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			RedisErrors.WithLabelValues("dial").Inc()
+		}
+		return conn, err
+	}
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		countRedisError(cmd, err)
+		return err
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			countRedisError(cmd, cmd.Err())
+		}
+		return err
+	}
+}
+
+func countRedisError(cmd redis.Cmder, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		RedisErrors.WithLabelValues(cmd.Name()).Inc()
+	}
+}
+
+// StreamCollector - длина Redis stream и отставание групп потребителей.
+// Значения читаются из Redis при каждом сборе метрик.
+type StreamCollector struct {
+	client *redis.Client
+	stream string
+
+	length  *prometheus.Desc
+	lag     *prometheus.Desc
+	pending *prometheus.Desc
+}
+
+// NewStreamCollector - коллектор для stream; регистрировать в Registry
+func NewStreamCollector(client *redis.Client, stream string) *StreamCollector {
+	labels := prometheus.Labels{"stream": stream}
+	return &StreamCollector{
+		client: client,
+		stream: stream,
+		length: prometheus.NewDesc("redis_stream_length",
+			"Number of entries in the Redis stream.", nil, labels),
+		lag: prometheus.NewDesc("redis_stream_group_lag",
+			"Entries not yet delivered to the consumer group.", []string{"group"}, labels),
+		pending: prometheus.NewDesc("redis_stream_group_pending",
+			"Entries delivered to the consumer group but not acknowledged.", []string{"group"}, labels),
+	}
+}
+
+func (c *StreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.length
+	ch <- c.lag
+	ch <- c.pending
+}
+
+func (c *StreamCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	length, err := c.client.XLen(ctx, c.stream).Result()
+	if err != nil {
+		slog.Warn("failed to read stream length", slog.String("stream", c.stream), slog.Any("error", err))
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.length, prometheus.GaugeValue, float64(length))
+
+	// Пока бот не создал группу, XINFO GROUPS на пустом stream отвечает ошибкой
+	groups, err := c.client.XInfoGroups(ctx, c.stream).Result()
+	if err != nil {
+		return
+	}
+	for _, g := range groups {
+		// Lag = -1, если Redis не может его посчитать (например, после XDEL)
+		if g.Lag >= 0 {
+			ch <- prometheus.MustNewConstMetric(c.lag, prometheus.GaugeValue, float64(g.Lag), g.Name)
+		}
+		ch <- prometheus.MustNewConstMetric(c.pending, prometheus.GaugeValue, float64(g.Pending), g.Name)
+	}
+}
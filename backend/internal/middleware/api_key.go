@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/logging"
+	"backend/internal/models"
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// API KEY AUTH (интеграции организаторов)
+// ============================================
+
+// APIKeyAuthenticator - проверка ключа из заголовка Authorization: ApiKey <key>
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, plaintext string) (*models.APIKey, error)
+}
+
+// APIKeyAuthMiddleware - аналог JWTAuthMiddleware для интеграций.
+// Ключ привязан к хакатону, поэтому в контекст кладётся сам ключ, а не пользователь.
+func APIKeyAuthMiddleware(keys APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "apikey" || parts[1] == "" {
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "invalid authorization format, use: ApiKey <key>"))
+			return
+		}
+
+		key, err := keys.Authenticate(c.Request.Context(), strings.TrimSpace(parts[1]))
+		if err != nil {
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeAPIKeyInvalid, "invalid or revoked API key"))
+			return
+		}
+
+		c.Set("api_key", key)
+		setLogAttrs(c, logging.HackathonID(key.HackathonID), slog.Int64("api_key_id", key.ID))
+		c.Next()
+	}
+}
+
+// RequireAPIKeyPermission - пропускает запрос, только если у ключа есть разрешение
+func RequireAPIKeyPermission(perm models.APIKeyPermission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := GetAPIKey(c)
+		if !ok {
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "API key required"))
+			return
+		}
+
+		if !key.HasPermission(perm) {
+			apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "API key lacks required permission").
+				WithMeta("requiredPermission", perm))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetAPIKey - ключ, которым аутентифицирован запрос
+func GetAPIKey(c *gin.Context) (*models.APIKey, bool) {
+	key, exists := c.Get("api_key")
+	if !exists {
+		return nil, false
+	}
+	return key.(*models.APIKey), true
+}
@@ -1,9 +1,9 @@
 package middleware
 
 import (
+	"backend/internal/apierror"
 	"backend/internal/models"
 	"backend/internal/repositories"
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -28,28 +28,24 @@ func RequireAuth(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if telegramUserIDStr == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "telegram_user_id required"})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "telegram_user_id required"))
 			return
 		}
 
 		telegramUserID, err := strconv.ParseInt(telegramUserIDStr, 10, 64)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid telegram_user_id"})
-			c.Abort()
+			apierror.Abort(c, apierror.InvalidID("invalid telegram_user_id"))
 			return
 		}
 
 		user, err := userRepo.GetByTelegramID(c.Request.Context(), telegramUserID)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUserNotFound, "user not found"))
 			return
 		}
 
 		if !user.Authorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "user not authorized"})
-			c.Abort()
+			apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "user not authorized"))
 			return
 		}
 
@@ -67,22 +63,19 @@ func RequireRole(required models.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		auth, ok := GetAuthContext(c)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "authentication required"))
 			return
 		}
 
 		switch required {
 		case models.RoleAdmin:
 			if auth.Role != models.RoleAdmin {
-				c.JSON(http.StatusForbidden, gin.H{"error": "admin role required"})
-				c.Abort()
+				apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "admin role required"))
 				return
 			}
 		case models.RoleHackathonCreator:
 			if auth.Role != models.RoleAdmin && auth.Role != models.RoleHackathonCreator {
-				c.JSON(http.StatusForbidden, gin.H{"error": "hackathon_creator role required"})
-				c.Abort()
+				apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "hackathon_creator role required"))
 				return
 			}
 		}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// IDEMPOTENCY KEYS
+// ============================================
+
+// Заголовки идемпотентных запросов
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+const (
+	// IdempotencyTTL - сколько хранится ответ; повтор после этого выполнится заново
+	IdempotencyTTL = 24 * time.Hour
+	// idempotencyLockTTL - сколько живёт запись выполняющегося запроса,
+	// если реплика упала, не успев ни сохранить ответ, ни освободить ключ
+	idempotencyLockTTL = 2 * time.Minute
+
+	maxIdempotencyKeySize = 255
+	maxIdempotentBodySize = 1 << 20
+)
+
+// IdempotencyStore - записи ключей идемпотентности, общие для всех реплик
+type IdempotencyStore interface {
+	// Reserve - занять свободный ключ; если он занят, вернуть его запись
+	Reserve(ctx context.Context, key string, record []byte, ttl time.Duration) (existing []byte, reserved bool, err error)
+	Save(ctx context.Context, key string, record []byte, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
+// idempotencyRecord - запись ключа: отпечаток запроса и, когда он выполнен, ответ
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	// Status 0 - запрос ещё выполняется
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// IdempotencyMiddleware - поддержка заголовка Idempotency-Key для POST, которые нельзя выполнять дважды.
+//
+// Первый запрос с ключом выполняется, его ответ (кроме 5xx) сохраняется на IdempotencyTTL.
+// Повтор с тем же ключом и тем же телом получает сохранённый ответ с Idempotent-Replayed: true,
+// с другим телом или маршрутом - 409 IDEMPOTENCY_KEY_REUSED, пока первый ещё выполняется -
+// 409 IDEMPOTENCY_KEY_IN_USE. После 5xx ключ освобождается, и запрос можно повторить.
+// Ключи у каждого пользователя свои. Без заголовка запрос обрабатывается как обычно.
+//
+// Ставится после RateLimitMiddleware, чтобы 429 не сохранялся как ответ.
+func IdempotencyMiddleware(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeySize || !isVisibleASCII(key) {
+			apierror.Abort(c, apierror.BadRequest(apierror.CodeIdempotencyKeyInvalid,
+				"Idempotency-Key must be 1-255 visible ASCII characters"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "failed to read request body"))
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodeBadRequest, "request body too large"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := sha256.Sum256([]byte(c.Request.Method + "\n" + c.Request.URL.Path + "\n" + string(body)))
+		record := idempotencyRecord{Fingerprint: hex.EncodeToString(fingerprint[:])}
+		storeKey := idempotencyScope(c) + ":" + hashIdempotencyKey(key)
+
+		pending, _ := json.Marshal(record)
+		existing, reserved, err := store.Reserve(c.Request.Context(), storeKey, pending, idempotencyLockTTL)
+		if err != nil {
+			apierror.Abort(c, apierror.Unavailable("failed to check idempotency key", err))
+			return
+		}
+		if !reserved {
+			replayIdempotent(c, existing, record.Fingerprint)
+			return
+		}
+
+		// Контекст запроса отменяется, если клиент ушёл, а запись надо закрыть в любом случае
+		ctx := context.WithoutCancel(c.Request.Context())
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := store.Release(ctx, storeKey); err != nil {
+				Logger(c).Warn("failed to release idempotency key", slog.Any("error", err))
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// Ошибку из apierror.Abort рендерим здесь, чтобы она попала в сохранённый ответ
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			apierror.Render(c, c.Errors.Last().Err)
+		}
+		c.Writer = writer.ResponseWriter
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		record.Status = c.Writer.Status()
+		record.ContentType = c.Writer.Header().Get("Content-Type")
+		record.Body = writer.body.Bytes()
+		data, err := json.Marshal(record)
+		if err == nil {
+			err = store.Save(ctx, storeKey, data, IdempotencyTTL)
+		}
+		if err != nil {
+			Logger(c).Warn("failed to save idempotent response", slog.Any("error", err))
+			return
+		}
+		saved = true
+	}
+}
+
+// replayIdempotent - ответ на повтор ключа, который уже занят
+func replayIdempotent(c *gin.Context, existing []byte, fingerprint string) {
+	var record idempotencyRecord
+	if err := json.Unmarshal(existing, &record); err != nil {
+		apierror.Abort(c, apierror.Internal("failed to read idempotency record", err))
+		return
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		apierror.Abort(c, apierror.Conflict(apierror.CodeIdempotencyKeyReused,
+			"Idempotency-Key was already used for a different request"))
+	case record.Status == 0:
+		c.Header("Retry-After", "1")
+		apierror.Abort(c, apierror.Conflict(apierror.CodeIdempotencyKeyInUse,
+			"a request with this Idempotency-Key is still in progress"))
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(record.Status, record.ContentType, record.Body)
+		c.Abort()
+	}
+}
+
+// idempotencyScope - чьи это ключи: пользователя из JWT или, без него, IP
+func idempotencyScope(c *gin.Context) string {
+	if userID, ok := GetUserID(c); ok {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// hashIdempotencyKey - ключ клиента в Redis только хешем: длина и символы под контролем
+func hashIdempotencyKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func isVisibleASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// recordingWriter - копирует тело ответа, чтобы сохранить его для повторов
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotency - IdempotencyStore в памяти
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	records map[string][]byte
+	err     error
+}
+
+func newMemoryIdempotency() *memoryIdempotency {
+	return &memoryIdempotency{records: map[string][]byte{}}
+}
+
+func (m *memoryIdempotency) Reserve(_ context.Context, key string, record []byte, _ time.Duration) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	if existing, ok := m.records[key]; ok {
+		return existing, false, nil
+	}
+	m.records[key] = record
+	return nil, true, nil
+}
+
+func (m *memoryIdempotency) Save(_ context.Context, key string, record []byte, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[key] = record
+	return nil
+}
+
+func (m *memoryIdempotency) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, key)
+	return nil
+}
+
+// idempotencyRouter - POST /x, который считает вызовы и отвечает status
+type idempotencyRouter struct {
+	*gin.Engine
+	calls  int
+	status int
+	// during - вызывается внутри обработчика, пока ключ занят
+	during func()
+}
+
+func newIdempotencyRouter(store IdempotencyStore) *idempotencyRouter {
+	r := &idempotencyRouter{Engine: gin.New(), status: http.StatusCreated}
+	r.Use(apierror.Middleware())
+	r.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			c.Set("user_id", int64(len(id)))
+		}
+	})
+	r.POST("/x", IdempotencyMiddleware(store), func(c *gin.Context) {
+		r.calls++
+		if during := r.during; during != nil {
+			r.during = nil
+			during()
+		}
+		if r.status >= http.StatusInternalServerError {
+			apierror.Abort(c, apierror.Internal("boom", errors.New("boom")))
+			return
+		}
+		c.JSON(r.status, gin.H{"call": r.calls})
+	})
+	return r
+}
+
+func (r *idempotencyRouter) post(key, body, user string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	r := newIdempotencyRouter(newMemoryIdempotency())
+
+	first := r.post("k1", `{"a":1}`, "u")
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("first: %d %v", first.Code, first.Header())
+	}
+
+	replay := r.post("k1", `{"a":1}`, "u")
+	if replay.Code != http.StatusCreated || replay.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replay: %d %v", replay.Code, replay.Header())
+	}
+	if replay.Body.String() != first.Body.String() || r.calls != 1 {
+		t.Fatalf("replay body %q (first %q), handler calls %d", replay.Body.String(), first.Body.String(), r.calls)
+	}
+
+	// Тот же ключ с другим телом
+	reused := r.post("k1", `{"a":2}`, "u")
+	if reused.Code != http.StatusConflict || !strings.Contains(reused.Body.String(), string(apierror.CodeIdempotencyKeyReused)) {
+		t.Fatalf("reused: %d %s", reused.Code, reused.Body.String())
+	}
+
+	// Ключи у каждого пользователя свои
+	if other := r.post("k1", `{"a":1}`, "uu"); other.Code != http.StatusCreated || r.calls != 2 {
+		t.Fatalf("other user: %d, handler calls %d", other.Code, r.calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	r := newIdempotencyRouter(newMemoryIdempotency())
+
+	// Повтор приходит, пока первый запрос ещё выполняется
+	var w *httptest.ResponseRecorder
+	r.during = func() { w = r.post("k1", `{}`, "u") }
+	if first := r.post("k1", `{}`, "u"); first.Code != http.StatusCreated {
+		t.Fatalf("first: %d", first.Code)
+	}
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), string(apierror.CodeIdempotencyKeyInUse)) {
+		t.Fatalf("in progress: %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("Retry-After %q", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestIdempotencyServerErrorReleasesKey(t *testing.T) {
+	store := newMemoryIdempotency()
+	r := newIdempotencyRouter(store)
+	r.status = http.StatusInternalServerError
+
+	if w := r.post("k1", `{}`, "u"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d", w.Code)
+	}
+	if len(store.records) != 0 {
+		t.Fatalf("key kept after 5xx: %v", store.records)
+	}
+
+	r.status = http.StatusCreated
+	if w := r.post("k1", `{}`, "u"); w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" || r.calls != 2 {
+		t.Fatalf("retry: %d, handler calls %d", w.Code, r.calls)
+	}
+}
+
+func TestIdempotencyRequestChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		body       string
+		storeErr   error
+		wantStatus int
+		wantCalls  int
+	}{
+		{"no header", "", `{}`, nil, http.StatusCreated, 1},
+		{"key too long", strings.Repeat("k", maxIdempotencyKeySize+1), `{}`, nil, http.StatusBadRequest, 0},
+		{"non-ascii key", "ключ", `{}`, nil, http.StatusBadRequest, 0},
+		{"key with space", "a b", `{}`, nil, http.StatusBadRequest, 0},
+		{"body too large", "k1", strings.Repeat("x", maxIdempotentBodySize+1), nil, http.StatusRequestEntityTooLarge, 0},
+		{"store unavailable", "k1", `{}`, errors.New("connection refused"), http.StatusServiceUnavailable, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryIdempotency()
+			store.err = tt.storeErr
+			r := newIdempotencyRouter(store)
+
+			w := r.post(tt.key, tt.body, "u")
+			if w.Code != tt.wantStatus || r.calls != tt.wantCalls {
+				t.Fatalf("status %d, handler calls %d; want %d, %d", w.Code, r.calls, tt.wantStatus, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"backend/internal/apierror"
+	"backend/internal/logging"
+	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -21,62 +23,101 @@ type JWTClaims struct {
 	UserID     int64  `json:"user_id"`
 	TelegramID int64  `json:"telegram_id"`
 	Role       string `json:"role"`
+	SessionID  string `json:"sid,omitempty"` // ID семейства refresh токенов или сессии имперсонации
+	// Actor - кто на самом деле действует (claim act, RFC 8693).
+	// Задан только в токенах имперсонации: UserID - пользователь, которого смотрят, Actor - админ.
+	Actor *ActorClaims `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ActorClaims - реальный пользователь за токеном имперсонации
+type ActorClaims struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// IsImpersonation - выдан ли токен для просмотра от имени другого пользователя
+func (c *JWTClaims) IsImpersonation() bool {
+	return c.Actor != nil
+}
+
 // ============================================
 // JWT UTILITIES
 // ============================================
 
-var jwtSecret []byte
+// Ключи подписи; выставляются при старте сервера через SetKeySet
+var jwtKeys *KeySet
 
-func init() {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "itam-hackaton-default-secret-change-in-production"
-	}
-	jwtSecret = []byte(secret)
+// AccessTokenTTL - время жизни access токена; дальше клиент обновляет его через refresh
+var AccessTokenTTL = time.Hour
+
+var errNoJWTKeys = errors.New("jwt keys are not configured")
+
+// SetKeySet - задать ключи подписи и проверки JWT
+func SetKeySet(ks *KeySet) {
+	jwtKeys = ks
+}
+
+// GetKeySet - текущий набор ключей (для JWKS)
+func GetKeySet() *KeySet {
+	return jwtKeys
 }
 
 // GenerateToken - создаёт новый JWT токен
-func GenerateToken(userID, telegramID int64, role string) (string, error) {
+func GenerateToken(userID, telegramID int64, role, sessionID string) (string, error) {
 	claims := JWTClaims{
 		UserID:     userID,
 		TelegramID: telegramID,
 		Role:       role,
+		SessionID:  sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 часа
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "itam-hackaton",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	if jwtKeys == nil {
+		return "", errNoJWTKeys
+	}
+	return jwtKeys.Sign(claims)
 }
 
-// GenerateRefreshToken - создаёт refresh токен (7 дней)
-func GenerateRefreshToken(userID int64) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   string(rune(userID)),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Issuer:    "itam-hackaton-refresh",
+// GenerateImpersonationToken - короткоживущий токен только для чтения: subject - пользователь,
+// которого смотрит админ actorID. Refresh токена у имперсонации нет.
+func GenerateImpersonationToken(userID, telegramID int64, role string, actorID int64, actorRole, sessionID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := JWTClaims{
+		UserID:     userID,
+		TelegramID: telegramID,
+		Role:       role,
+		SessionID:  sessionID,
+		Actor: &ActorClaims{
+			UserID: actorID,
+			Role:   actorRole,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "itam-hackaton",
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	if jwtKeys == nil {
+		return "", errNoJWTKeys
+	}
+	return jwtKeys.Sign(claims)
 }
 
 // ValidateToken - валидирует JWT токен и возвращает claims
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем алгоритм подписи
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return jwtSecret, nil
-	})
+	if jwtKeys == nil {
+		return nil, errNoJWTKeys
+	}
+
+	// Ключ выбирается по kid, алгоритм должен совпадать с алгоритмом ключа
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, jwtKeys.Keyfunc,
+		jwt.WithValidMethods(jwtKeys.ValidMethods()))
 
 	if err != nil {
 		return nil, err
@@ -93,29 +134,52 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 // GIN MIDDLEWARE
 // ============================================
 
+// SessionValidator - проверка, что сессия (claim sid) не отозвана
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+	IsImpersonationActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// checkSession - access токен принимается, только пока жива его сессия
+func checkSession(c *gin.Context, sessions SessionValidator, claims *JWTClaims) error {
+	if sessions == nil {
+		return nil
+	}
+	if claims.SessionID == "" {
+		return errSessionRevoked
+	}
+
+	isActive := sessions.IsSessionActive
+	if claims.IsImpersonation() {
+		isActive = sessions.IsImpersonationActive
+	}
+	active, err := isActive(c.Request.Context(), claims.SessionID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return errSessionRevoked
+	}
+	return nil
+}
+
+var errSessionRevoked = errors.New("session has been revoked")
+
 // JWTAuthMiddleware - middleware для проверки JWT токена
 // Перехватывает все запросы и проверяет Authorization: Bearer <token>
-func JWTAuthMiddleware() gin.HandlerFunc {
+func JWTAuthMiddleware(sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Authorization header required",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "authorization header required"))
 			return
 		}
 
 		// Проверяем формат "Bearer <token>"
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Invalid authorization format. Use: Bearer <token>",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "invalid authorization format, use: Bearer <token>"))
 			return
 		}
 
@@ -124,27 +188,56 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		// Валидируем токен
 		claims, err := ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Invalid or expired token",
-				"detail":  err.Error(),
-			})
-			c.Abort()
+			// Причину (подпись, срок, формат) клиенту не раскрываем
+			Logger(c).Debug("token rejected", logging.Err(err))
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeTokenInvalid, "invalid or expired token"))
+			return
+		}
+
+		if err := checkSession(c, sessions, claims); err != nil {
+			if !errors.Is(err, errSessionRevoked) {
+				apierror.Abort(c, apierror.Unavailable("failed to check session", err))
+				return
+			}
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeSessionRevoked, "session has been revoked"))
+			return
+		}
+
+		// Имперсонация только для просмотра: всё, что меняет состояние, запрещено
+		if claims.IsImpersonation() && !isReadOnlyMethod(c.Request.Method) {
+			apierror.Abort(c, apierror.Forbidden(apierror.CodeReadOnlySession, "impersonation sessions are read-only"))
 			return
 		}
 
 		// Сохраняем claims в контекст для использования в handlers
-		c.Set("user_id", claims.UserID)
-		c.Set("telegram_id", claims.TelegramID)
-		c.Set("user_role", claims.Role)
-		c.Set("jwt_claims", claims)
+		setClaims(c, claims)
 
 		c.Next()
 	}
 }
 
+// setClaims - положить claims в контекст запроса
+func setClaims(c *gin.Context, claims *JWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("telegram_id", claims.TelegramID)
+	c.Set("user_role", claims.Role)
+	c.Set("session_id", claims.SessionID)
+	c.Set("jwt_claims", claims)
+	if claims.IsImpersonation() {
+		c.Set("actor_id", claims.Actor.UserID)
+		c.Set("actor_role", claims.Actor.Role)
+		setLogAttrs(c, logging.UserID(claims.UserID), logging.ActorID(claims.Actor.UserID))
+		return
+	}
+	setLogAttrs(c, logging.UserID(claims.UserID))
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 // OptionalJWTAuthMiddleware - опциональная проверка JWT (не блокирует запрос)
-func OptionalJWTAuthMiddleware() gin.HandlerFunc {
+func OptionalJWTAuthMiddleware(sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -159,11 +252,8 @@ func OptionalJWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		claims, err := ValidateToken(parts[1])
-		if err == nil {
-			c.Set("user_id", claims.UserID)
-			c.Set("telegram_id", claims.TelegramID)
-			c.Set("user_role", claims.Role)
-			c.Set("jwt_claims", claims)
+		if err == nil && checkSession(c, sessions, claims) == nil {
+			setClaims(c, claims)
 		}
 
 		c.Next()
@@ -175,17 +265,13 @@ func RequireRoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("user_role")
 		if !exists {
-			log.Printf("[RequireRoleMiddleware] No user_role in context, returning 401")
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Authentication required",
-			})
-			c.Abort()
+			Logger(c).Debug("no user_role in context")
+			apierror.Abort(c, apierror.Unauthorized(apierror.CodeUnauthorized, "authentication required"))
 			return
 		}
 
 		userRole := role.(string)
-		log.Printf("[RequireRoleMiddleware] User role: %s, allowed roles: %v", userRole, allowedRoles)
+		Logger(c).Debug("role check", slog.String("role", userRole), slog.Any("allowed_roles", allowedRoles))
 
 		// Проверяем, есть ли роль пользователя в списке разрешённых
 		allowed := false
@@ -202,13 +288,9 @@ func RequireRoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		}
 
 		if !allowed {
-			log.Printf("[RequireRoleMiddleware] Access denied for role %s", userRole)
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":          "forbidden",
-				"message":        "Insufficient permissions",
-				"required_roles": allowedRoles,
-			})
-			c.Abort()
+			Logger(c).Info("access denied", slog.String("role", userRole))
+			apierror.Abort(c, apierror.Forbidden(apierror.CodeForbidden, "insufficient permissions").
+				WithMeta("requiredRoles", allowedRoles))
 			return
 		}
 
@@ -247,6 +329,31 @@ func GetUserRole(c *gin.Context) (string, bool) {
 	return role.(string), true
 }
 
+// GetSessionID - получить ID сессии (семейства refresh токенов) из контекста
+func GetSessionID(c *gin.Context) (string, bool) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return "", false
+	}
+	return sessionID.(string), sessionID.(string) != ""
+}
+
+// GetActor - реальный пользователь (админ), если запрос идёт под имперсонацией
+func GetActor(c *gin.Context) (int64, string, bool) {
+	actorID, exists := c.Get("actor_id")
+	if !exists {
+		return 0, "", false
+	}
+	actorRole, _ := c.Get("actor_role")
+	return actorID.(int64), actorRole.(string), true
+}
+
+// IsImpersonating - идёт ли запрос под токеном имперсонации
+func IsImpersonating(c *gin.Context) bool {
+	_, exists := c.Get("actor_id")
+	return exists
+}
+
 // GetJWTClaims - получить все claims из контекста
 func GetJWTClaims(c *gin.Context) (*JWTClaims, bool) {
 	claims, exists := c.Get("jwt_claims")
@@ -0,0 +1,303 @@
+package middleware
+
+import (
+	"backend/internal/config"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ============================================
+// JWT SIGNING KEYS
+// ============================================
+
+// legacyKeyID - kid ключа из jwt.secret (JWT_SECRET). Токены, выпущенные до появления kid,
+// заголовка не имеют и проверяются этим ключом.
+const legacyKeyID = "default"
+
+// SigningKey - ключ подписи/проверки JWT.
+// Для HS256 оба ключа - общий секрет; для RS256/EdDSA у ключа, снятого с ротации,
+// может быть только публичная часть.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign - есть ли у ключа приватная часть
+func (k *SigningKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// KeySet - активный ключ подписи и все ключи, которым ещё доверяем при проверке.
+// Ротация: добавляем новый ключ, делаем его активным, а старый оставляем в наборе,
+// пока не истекут подписанные им токены.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	order  []string
+}
+
+// NewKeySet - собрать набор ключей; activeID должен указывать на ключ с приватной частью
+func NewKeySet(activeID string, keys ...*SigningKey) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("jwt key id is empty")
+		}
+		if _, dup := ks.keys[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate jwt key id %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+		ks.order = append(ks.order, k.ID)
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active jwt key %q is not configured", activeID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("active jwt key %q has no private key", activeID)
+	}
+	ks.active = active
+	return ks, nil
+}
+
+// Sign - подписать claims активным ключом и проставить kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.ID
+	return token.SignedString(ks.active.signKey)
+}
+
+// Keyfunc - выбрать ключ проверки по kid и не дать подменить алгоритм
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = legacyKeyID
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.verifyKey, nil
+}
+
+// ValidMethods - алгоритмы, которые вообще встречаются в наборе
+func (ks *KeySet) ValidMethods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, id := range ks.order {
+		alg := ks.keys[id].Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWK - публичный ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS - публичные ключи набора. HMAC секреты сюда никогда не попадают,
+// поэтому сторонние сервисы могут проверять только токены с асимметричной подписью.
+func (ks *KeySet) JWKS() []JWK {
+	jwks := []JWK{}
+	for _, id := range ks.order {
+		k := ks.keys[id]
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "RSA",
+				Kid: k.ID,
+				Use: "sig",
+				Alg: k.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "OKP",
+				Kid: k.ID,
+				Use: "sig",
+				Alg: k.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return jwks
+}
+
+// ============================================
+// LOADING
+// ============================================
+
+// Минимальная длина HS256 секрета (RFC 7518, 3.2)
+const minHMACKeyLength = 32
+
+// NewHMACKey - HS256 ключ из общего секрета
+func NewHMACKey(id string, secret []byte) (*SigningKey, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("jwt key %q: HS256 secret is empty", id)
+	}
+	if len(secret) < minHMACKeyLength {
+		slog.Warn("HS256 secret is too short", slog.String("kid", id), slog.Int("min_length", minHMACKeyLength))
+	}
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// ParsePEMKey - RS256/EdDSA ключ из PEM. Приватный ключ годится и для подписи,
+// публичный - только для проверки (ключ, выведенный из ротации).
+func ParsePEMKey(id, alg string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("jwt key %q: no PEM block found", id)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt key %q: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %q: %w", id, err)
+	}
+
+	key := &SigningKey{ID: id}
+	switch alg {
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+		switch k := parsed.(type) {
+		case *rsa.PrivateKey:
+			key.signKey, key.verifyKey = k, &k.PublicKey
+		case *rsa.PublicKey:
+			key.verifyKey = k
+		default:
+			return nil, fmt.Errorf("jwt key %q: RS256 requires an RSA key", id)
+		}
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+		switch k := parsed.(type) {
+		case ed25519.PrivateKey:
+			key.signKey, key.verifyKey = k, k.Public().(ed25519.PublicKey)
+		case ed25519.PublicKey:
+			key.verifyKey = k
+		default:
+			return nil, fmt.Errorf("jwt key %q: EdDSA requires an Ed25519 key", id)
+		}
+	default:
+		return nil, fmt.Errorf("jwt key %q: unsupported algorithm %q", id, alg)
+	}
+
+	return key, nil
+}
+
+// LoadKeySet - собрать набор ключей из конфигурации:
+//
+//	Keys      - kid=ALG:путь,... например 2026-10=EdDSA:/run/secrets/jwt-2026-10.pem,legacy=HS256:/run/secrets/jwt.key
+//	ActiveKID - ключ для подписи новых токенов (по умолчанию первый из Keys)
+//	Secret    - старый общий HS256 секрет, kid "default"; проверяет и токены без kid
+//
+// Если не задано ничего, генерируется временный ключ: токены не переживут рестарт.
+func LoadKeySet(cfg config.JWTConfig) (*KeySet, error) {
+	var keys []*SigningKey
+	activeID := cfg.ActiveKID
+
+	if cfg.Keys != "" {
+		for _, entry := range strings.Split(cfg.Keys, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			key, err := loadKeySpec(entry)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	if cfg.Secret != "" {
+		key, err := NewHMACKey(legacyKeyID, []byte(cfg.Secret.Value()))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		slog.Warn("no jwt.keys or jwt.secret configured, using an ephemeral key; tokens will not survive a restart")
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate jwt key: %w", err)
+		}
+		key, _ := NewHMACKey(legacyKeyID, secret)
+		keys = append(keys, key)
+	}
+
+	if activeID == "" {
+		activeID = keys[0].ID
+	}
+	return NewKeySet(activeID, keys...)
+}
+
+// loadKeySpec - разобрать запись вида kid=ALG:путь
+func loadKeySpec(entry string) (*SigningKey, error) {
+	id, rest, ok := strings.Cut(entry, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid jwt keys entry %q, expected kid=ALG:path", entry)
+	}
+	alg, path, ok := strings.Cut(rest, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid jwt keys entry %q, expected kid=ALG:path", entry)
+	}
+	id, alg, path = strings.TrimSpace(id), strings.TrimSpace(alg), strings.TrimSpace(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %q: %w", id, err)
+	}
+
+	if alg == "HS256" {
+		return NewHMACKey(id, []byte(strings.TrimSpace(string(data))))
+	}
+	return ParsePEMKey(id, alg, data)
+}
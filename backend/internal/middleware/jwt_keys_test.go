@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"backend/internal/config"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testClaims() JWTClaims {
+	return JWTClaims{
+		UserID: 42,
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func parseWith(ks *KeySet, token string) (*JWTClaims, error) {
+	parsed, err := jwt.ParseWithClaims(token, &JWTClaims{}, ks.Keyfunc, jwt.WithValidMethods(ks.ValidMethods()))
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Claims.(*JWTClaims), nil
+}
+
+func newEd25519Key(t *testing.T, id string) (*SigningKey, ed25519.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePEMKey(id, "EdDSA", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePEMKey: %v", err)
+	}
+	return key, pub
+}
+
+func TestKeySetSignSetsKid(t *testing.T) {
+	key, _ := newEd25519Key(t, "2026-10")
+	ks, err := NewKeySet("2026-10", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := ks.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "2026-10" || parsed.Method.Alg() != "EdDSA" {
+		t.Fatalf("unexpected header %v", parsed.Header)
+	}
+	claims, err := parseWith(ks, token)
+	if err != nil || claims.UserID != 42 {
+		t.Fatalf("token does not verify: %v", err)
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	legacy, err := NewHMACKey(legacyKeyID, []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKS, err := NewKeySet(legacyKeyID, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := oldKS.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Токен, выпущенный до появления kid
+	noKid := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	noKidToken, err := noKid.SignedString([]byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, _ := newEd25519Key(t, "2026-10")
+	ks, err := NewKeySet("2026-10", next, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := ks.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"old key": oldToken, "no kid": noKidToken, "new key": newToken} {
+		if _, err := parseWith(ks, token); err != nil {
+			t.Errorf("%s: token rejected after rotation: %v", name, err)
+		}
+	}
+
+	// После вывода старого ключа из набора его токены не принимаются
+	retired, err := NewKeySet("2026-10", next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseWith(retired, oldToken); err == nil {
+		t.Error("token of a removed key accepted")
+	}
+}
+
+func TestKeySetRejectsForeignTokens(t *testing.T) {
+	key, pub := newEd25519Key(t, "ed")
+	ks, err := NewKeySet("ed", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknownKid := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	unknownKid.Header["kid"] = "missing"
+	unknownToken, _ := unknownKid.SignedString([]byte(strings.Repeat("k", 32)))
+
+	// Подмена алгоритма: HS256 с публичным ключом в роли секрета
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	confused.Header["kid"] = "ed"
+	confusedToken, _ := confused.SignedString([]byte(pub))
+
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, testClaims())
+	none.Header["kid"] = "ed"
+	noneToken, _ := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	for name, token := range map[string]string{"unknown kid": unknownToken, "alg confusion": confusedToken, "alg none": noneToken} {
+		if _, err := parseWith(ks, token); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+}
+
+func TestNewKeySetErrors(t *testing.T) {
+	hmacKey, _ := NewHMACKey("a", []byte(strings.Repeat("s", 32)))
+	dupKey, _ := NewHMACKey("a", []byte(strings.Repeat("t", 32)))
+	_, pub, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(pub.Public())
+	publicOnly, err := ParsePEMKey("pub", "EdDSA", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePEMKey public: %v", err)
+	}
+	if publicOnly.CanSign() {
+		t.Fatal("public-only key reports CanSign")
+	}
+
+	tests := []struct {
+		name   string
+		active string
+		keys   []*SigningKey
+	}{
+		{"missing active", "b", []*SigningKey{hmacKey}},
+		{"duplicate kid", "a", []*SigningKey{hmacKey, dupKey}},
+		{"active without private key", "pub", []*SigningKey{hmacKey, publicOnly}},
+		{"empty kid", "", []*SigningKey{{Method: jwt.SigningMethodHS256}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewKeySet(tt.active, tt.keys...); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestKeySetJWKSOmitsSecrets(t *testing.T) {
+	hmacKey, _ := NewHMACKey(legacyKeyID, []byte(strings.Repeat("s", 32)))
+	edKey, _ := newEd25519Key(t, "ed")
+	ks, err := NewKeySet("ed", edKey, hmacKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := ks.JWKS()
+	if len(jwks) != 1 {
+		t.Fatalf("JWKS has %d keys, want only the Ed25519 one: %+v", len(jwks), jwks)
+	}
+	if jwks[0].Kid != "ed" || jwks[0].Kty != "OKP" || jwks[0].Crv != "Ed25519" || jwks[0].X == "" {
+		t.Fatalf("unexpected JWK %+v", jwks[0])
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "hs.key")
+	if err := os.WriteFile(secretPath, []byte(strings.Repeat("x", 32)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ks, err := LoadKeySet(config.JWTConfig{
+		Keys:      "file=HS256:" + secretPath,
+		ActiveKID: "file",
+		Secret:    config.Secret(strings.Repeat("s", 32)),
+	})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	token, err := ks.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseWith(ks, token); err != nil {
+		t.Fatalf("token does not verify: %v", err)
+	}
+
+	for _, keys := range []string{"no-equals", "kid=HS256", "kid=HS256:" + filepath.Join(dir, "missing")} {
+		if _, err := LoadKeySet(config.JWTConfig{Keys: keys}); err == nil {
+			t.Errorf("Keys=%q: expected error", keys)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useTestKeys - HS256 ключ на время теста
+func useTestKeys(t *testing.T) {
+	t.Helper()
+	key, err := NewHMACKey("test", []byte(strings.Repeat("s", minHMACKeyLength)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeySet("test", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := jwtKeys
+	SetKeySet(ks)
+	t.Cleanup(func() { SetKeySet(previous) })
+}
+
+// memorySessions - SessionValidator со списками живых сессий
+type memorySessions struct {
+	sessions       map[string]bool
+	impersonations map[string]bool
+}
+
+func (m *memorySessions) IsSessionActive(_ context.Context, id string) (bool, error) {
+	return m.sessions[id], nil
+}
+
+func (m *memorySessions) IsImpersonationActive(_ context.Context, id string) (bool, error) {
+	return m.impersonations[id], nil
+}
+
+// authRouter - /x на все методы за JWTAuthMiddleware; отвечает тем, что лежит в контексте
+func authRouter(sessions SessionValidator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(apierror.Middleware())
+	r.Any("/x", JWTAuthMiddleware(sessions), func(c *gin.Context) {
+		userID, _ := GetUserID(c)
+		actorID, actorRole, _ := GetActor(c)
+		c.JSON(http.StatusOK, gin.H{"userId": userID, "actorId": actorID, "actorRole": actorRole})
+	})
+	return r
+}
+
+// authResponse - ответ authRouter или ошибка API
+type authResponse struct {
+	UserID    int64  `json:"userId"`
+	ActorID   int64  `json:"actorId"`
+	ActorRole string `json:"actorRole"`
+	Code      string `json:"code"`
+}
+
+// authRequest - запрос к /x с токеном
+func authRequest(t *testing.T, r http.Handler, method, token string) (int, authResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/x", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	var resp authResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s: %v in %q", method, err, w.Body.String())
+	}
+	return w.Code, resp
+}
+
+func TestImpersonationIsReadOnly(t *testing.T) {
+	useTestKeys(t)
+	sessions := &memorySessions{sessions: map[string]bool{"s1": true}, impersonations: map[string]bool{"i1": true}}
+	r := authRouter(sessions)
+
+	token, err := GenerateImpersonationToken(42, 4200, "user", 1, "admin", "i1", 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, resp := authRequest(t, r, http.MethodGet, token)
+	if status != http.StatusOK || resp.UserID != 42 || resp.ActorID != 1 || resp.ActorRole != "admin" {
+		t.Fatalf("GET: %d %+v", status, resp)
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		status, resp := authRequest(t, r, method, token)
+		if status != http.StatusForbidden || resp.Code != string(apierror.CodeReadOnlySession) {
+			t.Errorf("%s: %d %+v", method, status, resp)
+		}
+	}
+
+	// Обычный токен пишет как раньше, и актора в контексте нет
+	regular, err := GenerateToken(42, 4200, "user", "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, resp := authRequest(t, r, http.MethodPost, regular); status != http.StatusOK || resp.ActorID != 0 {
+		t.Fatalf("regular POST: %d %+v", status, resp)
+	}
+
+	// Завершённый сеанс имперсонации больше не принимается, даже на чтение
+	delete(sessions.impersonations, "i1")
+	if status, resp := authRequest(t, r, http.MethodGet, token); status != http.StatusUnauthorized || resp.Code != string(apierror.CodeSessionRevoked) {
+		t.Fatalf("ended session: %d %+v", status, resp)
+	}
+	// Сессия проверяется в своём хранилище: тот же sid среди обычных сессий не поможет
+	sessions.sessions["i1"] = true
+	if status, _ := authRequest(t, r, http.MethodGet, token); status != http.StatusUnauthorized {
+		t.Fatalf("impersonation checked against refresh sessions: %d", status)
+	}
+}
+
+func TestImpersonationTokenTTL(t *testing.T) {
+	useTestKeys(t)
+
+	token, err := GenerateImpersonationToken(42, 4200, "user", 1, "admin", "i1", 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ValidateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != 5*time.Minute {
+		t.Fatalf("token lives %v, want 5m", ttl)
+	}
+	if !claims.IsImpersonation() || claims.Actor.UserID != 1 || claims.SessionID != "i1" {
+		t.Fatalf("claims %+v", claims)
+	}
+
+	expired, err := GenerateImpersonationToken(42, 4200, "user", 1, "admin", "i1", -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := authRouter(&memorySessions{impersonations: map[string]bool{"i1": true}})
+	if status, resp := authRequest(t, r, http.MethodGet, expired); status != http.StatusUnauthorized || resp.Code != string(apierror.CodeTokenInvalid) {
+		t.Fatalf("expired token: %d %+v", status, resp)
+	}
+}
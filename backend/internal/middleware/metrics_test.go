@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"backend/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestCount - сколько запросов записано в http_request_duration_seconds с такими метками
+func requestCount(t *testing.T, method, route, status string) uint64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["method"] == method && labels["route"] == route && labels["status"] == status {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsMiddlewareRouteLabel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(MetricsMiddleware())
+	r.GET("/api/teams/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	before := requestCount(t, http.MethodGet, "/api/teams/:id", "200")
+	beforeUnmatched := requestCount(t, http.MethodGet, "unmatched", "404")
+	for _, path := range []string{"/api/teams/1", "/api/teams/2", "/api/teams/3", "/api/unknown/7"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// ID в пути не создают новых рядов: все запросы попадают в шаблон маршрута
+	if got := requestCount(t, http.MethodGet, "/api/teams/:id", "200") - before; got != 3 {
+		t.Fatalf("%d requests labelled with the route template, want 3", got)
+	}
+	if got := requestCount(t, http.MethodGet, "/api/teams/1", "200"); got != 0 {
+		t.Fatal("request labelled with the raw path")
+	}
+	if got := requestCount(t, http.MethodGet, "unmatched", "404") - beforeUnmatched; got != 1 {
+		t.Fatalf("%d unmatched requests, want 1", got)
+	}
+}
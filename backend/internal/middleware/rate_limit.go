@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/metrics"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// RATE LIMITING
+// ============================================
+
+// RateLimiter - счётчик запросов в скользящем окне, общий для всех реплик
+type RateLimiter interface {
+	// Allow - учесть запрос; reset - через сколько освободится место в окне
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, reset time.Duration, err error)
+}
+
+// RateLimitPolicy - не больше Limit запросов за Window.
+// Name входит в ключ Redis и метку метрики, поэтому у каждой группы маршрутов своё имя.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitMiddleware - ограничение частоты запросов группы маршрутов.
+// Окно ведётся на пользователя (user_id из JWT), для анонимных маршрутов - на IP.
+// Админы не ограничиваются. Если Redis недоступен, запрос пропускается: лимит
+// защищает от злоупотреблений, а не от отказа сервиса.
+//
+// Заголовки ответа - по draft-ietf-httpapi-ratelimit-headers:
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset (секунды), RateLimit-Policy
+// и Retry-After при 429.
+func RateLimitMiddleware(limiter RateLimiter, policy RateLimitPolicy) gin.HandlerFunc {
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds()))
+
+	return func(c *gin.Context) {
+		if role, ok := GetUserRole(c); ok && role == "admin" {
+			c.Next()
+			return
+		}
+
+		key := policy.Name + ":ip:" + c.ClientIP()
+		if userID, ok := GetUserID(c); ok {
+			key = policy.Name + ":user:" + strconv.FormatInt(userID, 10)
+		}
+
+		allowed, remaining, reset, err := limiter.Allow(c.Request.Context(), key, policy.Limit, policy.Window)
+		if err != nil {
+			Logger(c).Warn("rate limit check failed, allowing request",
+				slog.String("policy", policy.Name), slog.Any("error", err))
+			c.Next()
+			return
+		}
+
+		resetSeconds := strconv.Itoa(ceilSeconds(reset))
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", resetSeconds)
+		c.Header("RateLimit-Policy", policyHeader)
+
+		if !allowed {
+			metrics.RateLimited.WithLabelValues(policy.Name).Inc()
+			c.Header("Retry-After", resetSeconds)
+			apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeTooManyRequests, "too many requests").
+				WithMeta("retryAfter", ceilSeconds(reset)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ceilSeconds - длительность в целых секундах с округлением вверх, не меньше 1
+func ceilSeconds(d time.Duration) int {
+	return max(1, int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeLimiter - RateLimiter с заранее заданным ответом; запоминает ключи
+type fakeLimiter struct {
+	allowed   bool
+	remaining int
+	reset     time.Duration
+	err       error
+	keys      []string
+}
+
+func (f *fakeLimiter) Allow(_ context.Context, key string, _ int, _ time.Duration) (bool, int, time.Duration, error) {
+	f.keys = append(f.keys, key)
+	return f.allowed, f.remaining, f.reset, f.err
+}
+
+func newRateLimitRouter(limiter RateLimiter, userID int64, role string) *gin.Engine {
+	r := gin.New()
+	r.Use(apierror.Middleware())
+	r.Use(func(c *gin.Context) {
+		if userID != 0 {
+			c.Set("user_id", userID)
+			c.Set("user_role", role)
+		}
+	})
+	r.GET("/x", RateLimitMiddleware(limiter, RateLimitPolicy{Name: "api", Limit: 10, Window: time.Minute}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		limiter    *fakeLimiter
+		userID     int64
+		role       string
+		wantStatus int
+		wantKey    string
+	}{
+		{"allowed user", &fakeLimiter{allowed: true, remaining: 4, reset: 1500 * time.Millisecond}, 7, "user", http.StatusOK, "api:user:7"},
+		{"anonymous by ip", &fakeLimiter{allowed: true, remaining: 9, reset: time.Minute}, 0, "", http.StatusOK, "api:ip:192.0.2.1"},
+		{"over limit", &fakeLimiter{allowed: false, remaining: 0, reset: 1500 * time.Millisecond}, 7, "user", http.StatusTooManyRequests, "api:user:7"},
+		{"admin not limited", &fakeLimiter{allowed: false}, 1, "admin", http.StatusOK, ""},
+		{"redis down fails open", &fakeLimiter{err: errors.New("connection refused")}, 7, "user", http.StatusOK, "api:user:7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			newRateLimitRouter(tt.limiter, tt.userID, tt.role).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantKey == "" {
+				if len(tt.limiter.keys) != 0 {
+					t.Fatalf("limiter called with %v", tt.limiter.keys)
+				}
+				return
+			}
+			if len(tt.limiter.keys) != 1 || tt.limiter.keys[0] != tt.wantKey {
+				t.Fatalf("keys %v, want [%s]", tt.limiter.keys, tt.wantKey)
+			}
+			if tt.limiter.err != nil {
+				if w.Header().Get("RateLimit-Limit") != "" {
+					t.Fatal("rate limit headers set without a limiter answer")
+				}
+				return
+			}
+
+			if w.Header().Get("RateLimit-Limit") != "10" || w.Header().Get("RateLimit-Policy") != "10;w=60" {
+				t.Fatalf("headers %v", w.Header())
+			}
+			if tt.wantStatus == http.StatusTooManyRequests {
+				// 1.5 с округляются вверх
+				if w.Header().Get("Retry-After") != "2" || w.Header().Get("RateLimit-Reset") != "2" {
+					t.Fatalf("Retry-After %q, RateLimit-Reset %q", w.Header().Get("Retry-After"), w.Header().Get("RateLimit-Reset"))
+				}
+			}
+		})
+	}
+}
+
+func TestCeilSeconds(t *testing.T) {
+	for d, want := range map[time.Duration]int{0: 1, time.Millisecond: 1, time.Second: 1, 1001 * time.Millisecond: 2, time.Minute: 60} {
+		if got := ceilSeconds(d); got != want {
+			t.Errorf("ceilSeconds(%v) = %d, want %d", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/logging"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ============================================
+// REQUEST ID & ACCESS LOG
+// ============================================
+
+// RequestIDHeader - заголовок с ID запроса; приходит от nginx или генерируется здесь
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLoggerMiddleware - ID запроса, логгер запроса в контексте и строка access лога.
+// Путь логируется без query string: в ней бывают коды и токены.
+func RequestLoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		// Спан запроса открывает otelgin раньше; trace_id связывает лог с трейсом
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			ctx = logging.With(ctx, slog.String(logging.KeyTraceID, sc.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		// Пробы оркестратора приходят каждые несколько секунд - успешные не засоряют лог
+		if probeRoutes[route] && level == slog.LevelInfo {
+			level = slog.LevelDebug
+		}
+
+		// Логгер берём после c.Next: авторизация могла добавить в него user_id
+		ctx = c.Request.Context()
+		logging.FromContext(ctx).LogAttrs(ctx, level, "http request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+var probeRoutes = map[string]bool{"/health": true, "/api/health": true, "/livez": true, "/readyz": true}
+
+// RecoveryMiddleware - паника в обработчике превращается в 500 и запись в лог со стеком
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logging.FromContext(c.Request.Context()).Error("panic recovered",
+					slog.Any("panic", rec),
+					slog.String("stack", string(debug.Stack())),
+				)
+				// Паника уже залогирована со стеком, поэтому ответ пишем сами, без Render
+				c.AbortWithStatusJSON(http.StatusInternalServerError, apierror.Internal("internal server error", nil).Body())
+			}
+		}()
+		c.Next()
+	}
+}
+
+// Logger - логгер текущего запроса (request_id, user_id, ...)
+func Logger(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context())
+}
+
+// GetRequestID - ID текущего запроса
+func GetRequestID(c *gin.Context) string {
+	return c.GetString("request_id")
+}
+
+// setLogAttrs - дополнить логгер запроса полями (user_id после авторизации и т.п.)
+func setLogAttrs(c *gin.Context, args ...any) {
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), args...))
+}
+
+// validRequestID - принимаем чужой ID, только если он короткий и из безопасных символов,
+// чтобы через заголовок нельзя было подделать строки лога
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
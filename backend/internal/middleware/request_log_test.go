@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"backend/internal/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDRouter - /x отвечает ID запроса из контекста gin и из контекста запроса
+func requestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLoggerMiddleware())
+	r.GET("/x", func(c *gin.Context) {
+		c.String(http.StatusOK, GetRequestID(c)+" "+logging.RequestID(c.Request.Context()))
+	})
+	return r
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	r := requestIDRouter()
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"from proxy", "3f2a-9c.01_b", true},
+		{"too long", strings.Repeat("a", 65), false},
+		// Перевод строки позволил бы подделать запись в логе
+		{"unsafe characters", "abc\nlevel=ERROR", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if id == "" || !validRequestID(id) {
+				t.Fatalf("response request ID %q", id)
+			}
+			if (id == tt.incoming) != tt.keep {
+				t.Fatalf("incoming %q, response %q", tt.incoming, id)
+			}
+			// Обработчик видит тот же ID, что ушёл в заголовке ответа
+			if body := w.Body.String(); body != id+" "+id {
+				t.Fatalf("context request IDs %q, header %q", body, id)
+			}
+		})
+	}
+
+	// Сгенерированные ID не повторяются
+	first, second := httptest.NewRecorder(), httptest.NewRecorder()
+	r.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/x", nil))
+	r.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/x", nil))
+	if first.Header().Get(RequestIDHeader) == second.Header().Get(RequestIDHeader) {
+		t.Fatal("generated request IDs repeat")
+	}
+}
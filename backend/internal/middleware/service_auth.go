@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/logging"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================
+// SERVICE-TO-SERVICE AUTH
+// ============================================
+
+// Заголовки подписанного запроса от внутреннего сервиса (бот и т.п.)
+const (
+	ServiceIDHeader        = "X-Service-Id"
+	ServiceTimestampHeader = "X-Service-Timestamp"
+	ServiceNonceHeader     = "X-Service-Nonce"
+	ServiceSignatureHeader = "X-Service-Signature"
+)
+
+// ServiceRequestMaxSkew - насколько timestamp запроса может отличаться от часов сервера.
+// Nonce хранится вдвое дольше, так что повтор внутри окна будет замечен.
+const ServiceRequestMaxSkew = 5 * time.Minute
+
+// Ограничения на размер тела, которое читаем для подписи, и на длину nonce
+const (
+	maxServiceBodySize  = 1 << 20
+	maxServiceNonceSize = 128
+)
+
+// NonceStore - одноразовые nonce для защиты от повтора запросов
+type NonceStore interface {
+	// UseNonce - false, если nonce уже встречался
+	UseNonce(ctx context.Context, serviceID, nonce string, ttl time.Duration) (bool, error)
+}
+
+// ServiceRequestStringToSign - каноническая строка, которую подписывает сервис:
+//
+//	METHOD \n PATH?QUERY \n TIMESTAMP \n NONCE \n hex(sha256(body))
+func ServiceRequestStringToSign(method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return method + "\n" + requestURI + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:])
+}
+
+// SignServiceRequest - hex(HMAC-SHA256(secret, stringToSign))
+func SignServiceRequest(secret []byte, stringToSign string) string {
+	return hex.EncodeToString(hmacSHA256(secret, []byte(stringToSign)))
+}
+
+// ServiceAuthMiddleware - пропускает только запросы, подписанные секретом одного из сервисов.
+// secrets: ID сервиса (X-Service-Id) -> общий секрет.
+func ServiceAuthMiddleware(secrets map[string][]byte, nonces NonceStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serviceID := c.GetHeader(ServiceIDHeader)
+		timestamp := c.GetHeader(ServiceTimestampHeader)
+		nonce := c.GetHeader(ServiceNonceHeader)
+		signature := c.GetHeader(ServiceSignatureHeader)
+
+		if serviceID == "" || timestamp == "" || nonce == "" || signature == "" {
+			abortServiceAuth(c, "signed service request required")
+			return
+		}
+		if len(nonce) > maxServiceNonceSize {
+			abortServiceAuth(c, "invalid nonce")
+			return
+		}
+
+		secret, ok := secrets[serviceID]
+		if !ok || len(secret) == 0 {
+			abortServiceAuth(c, "unknown service")
+			return
+		}
+
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			abortServiceAuth(c, "invalid timestamp")
+			return
+		}
+		skew := time.Since(time.Unix(ts, 0))
+		if skew > ServiceRequestMaxSkew || skew < -ServiceRequestMaxSkew {
+			abortServiceAuth(c, "request timestamp is outside the allowed window")
+			return
+		}
+
+		// Читаем тело для подписи и возвращаем его обратно для handler'а
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxServiceBodySize+1))
+		if err != nil {
+			apierror.Abort(c, apierror.BadRequest(apierror.CodeBadRequest, "failed to read request body"))
+			return
+		}
+		if len(body) > maxServiceBodySize {
+			apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodeBadRequest, "request body too large"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		expected := SignServiceRequest(secret, ServiceRequestStringToSign(
+			c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body,
+		))
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			Logger(c).Warn("bad service request signature", slog.String(logging.KeyServiceID, serviceID))
+			abortServiceAuth(c, "invalid signature")
+			return
+		}
+
+		// Nonce проверяем после подписи, чтобы чужие запросы не засоряли кэш
+		fresh, err := nonces.UseNonce(c.Request.Context(), serviceID, nonce, 2*ServiceRequestMaxSkew)
+		if err != nil {
+			apierror.Abort(c, apierror.Unavailable("failed to verify request", err))
+			return
+		}
+		if !fresh {
+			abortServiceAuth(c, "replayed request")
+			return
+		}
+
+		c.Set("service_id", serviceID)
+		setLogAttrs(c, slog.String(logging.KeyServiceID, serviceID))
+		c.Next()
+	}
+}
+
+func abortServiceAuth(c *gin.Context, message string) {
+	apierror.Abort(c, apierror.Unauthorized(apierror.CodeServiceAuthFailed, message))
+}
+
+// GetServiceID - ID сервиса, подписавшего запрос
+func GetServiceID(c *gin.Context) (string, bool) {
+	serviceID, exists := c.Get("service_id")
+	if !exists {
+		return "", false
+	}
+	return serviceID.(string), true
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"backend/internal/apierror"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// memoryNonces - NonceStore в памяти
+type memoryNonces struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (m *memoryNonces) UseNonce(_ context.Context, serviceID, nonce string, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.seen == nil {
+		m.seen = map[string]bool{}
+	}
+	key := serviceID + ":" + nonce
+	if m.seen[key] {
+		return false, nil
+	}
+	m.seen[key] = true
+	return true, nil
+}
+
+var testServiceSecret = []byte("bot-secret-0123456789abcdef012345")
+
+func newServiceAuthRouter(nonces NonceStore) *gin.Engine {
+	r := gin.New()
+	r.Use(apierror.Middleware())
+	r.POST("/api/bot/invites", ServiceAuthMiddleware(map[string][]byte{"tgbot": testServiceSecret}, nonces), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		serviceID, _ := GetServiceID(c)
+		c.String(http.StatusOK, serviceID+":"+string(body))
+	})
+	return r
+}
+
+type signedRequest struct {
+	serviceID string
+	secret    []byte
+	timestamp time.Time
+	nonce     string
+	uri       string
+	body      string
+	// signedBody - что подписать, если отличается от body
+	signedBody *string
+}
+
+func (s signedRequest) build() *http.Request {
+	ts := strconv.FormatInt(s.timestamp.Unix(), 10)
+	signed := s.body
+	if s.signedBody != nil {
+		signed = *s.signedBody
+	}
+	req := httptest.NewRequest(http.MethodPost, s.uri, strings.NewReader(s.body))
+	req.Header.Set(ServiceIDHeader, s.serviceID)
+	req.Header.Set(ServiceTimestampHeader, ts)
+	req.Header.Set(ServiceNonceHeader, s.nonce)
+	req.Header.Set(ServiceSignatureHeader, SignServiceRequest(s.secret,
+		ServiceRequestStringToSign(http.MethodPost, s.uri, ts, s.nonce, []byte(signed))))
+	return req
+}
+
+func validSignedRequest(nonce string) signedRequest {
+	return signedRequest{
+		serviceID: "tgbot",
+		secret:    testServiceSecret,
+		timestamp: time.Now(),
+		nonce:     nonce,
+		uri:       "/api/bot/invites?teamId=1",
+		body:      `{"userId":5}`,
+	}
+}
+
+func TestServiceAuthMiddleware(t *testing.T) {
+	tampered := `{"userId":6}`
+
+	tests := []struct {
+		name   string
+		modify func(*signedRequest)
+		want   int
+	}{
+		{"valid", func(*signedRequest) {}, http.StatusOK},
+		{"unknown service", func(r *signedRequest) { r.serviceID = "other" }, http.StatusUnauthorized},
+		{"wrong secret", func(r *signedRequest) { r.secret = []byte("wrong") }, http.StatusUnauthorized},
+		{"body changed after signing", func(r *signedRequest) { r.signedBody = &tampered }, http.StatusUnauthorized},
+		{"timestamp too old", func(r *signedRequest) { r.timestamp = time.Now().Add(-ServiceRequestMaxSkew - time.Minute) }, http.StatusUnauthorized},
+		{"timestamp in the future", func(r *signedRequest) { r.timestamp = time.Now().Add(ServiceRequestMaxSkew + time.Minute) }, http.StatusUnauthorized},
+		{"nonce too long", func(r *signedRequest) { r.nonce = strings.Repeat("n", maxServiceNonceSize+1) }, http.StatusUnauthorized},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newServiceAuthRouter(&memoryNonces{})
+			signed := validSignedRequest("nonce-" + strconv.Itoa(i))
+			tt.modify(&signed)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, signed.build())
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want == http.StatusOK && w.Body.String() != `tgbot:{"userId":5}` {
+				t.Fatalf("handler did not get service id and body: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServiceAuthMiddlewareRejectsReplay(t *testing.T) {
+	r := newServiceAuthRouter(&memoryNonces{})
+	signed := validSignedRequest("once")
+
+	first := httptest.NewRecorder()
+	r.ServeHTTP(first, signed.build())
+	if first.Code != http.StatusOK {
+		t.Fatalf("first request: %d %s", first.Code, first.Body.String())
+	}
+
+	replay := httptest.NewRecorder()
+	r.ServeHTTP(replay, signed.build())
+	if replay.Code != http.StatusUnauthorized || !strings.Contains(replay.Body.String(), "replayed") {
+		t.Fatalf("replay: %d %s", replay.Code, replay.Body.String())
+	}
+}
+
+func TestServiceAuthMiddlewareMissingHeaders(t *testing.T) {
+	r := newServiceAuthRouter(&memoryNonces{})
+	req := validSignedRequest("n").build()
+	req.Header.Del(ServiceSignatureHeader)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), string(apierror.CodeServiceAuthFailed)) {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServiceRequestStringToSign(t *testing.T) {
+	got := ServiceRequestStringToSign("POST", "/api/bot/x?a=1", "1700000000", "n1", []byte("{}"))
+	// sha256("{}")
+	want := "POST\n/api/bot/x?a=1\n1700000000\nn1\n44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+	if got != want {
+		t.Fatalf("string to sign:\n%q\nwant\n%q", got, want)
+	}
+}
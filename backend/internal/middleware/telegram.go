@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================
+// TELEGRAM WEBAPP INIT DATA
+// ============================================
+
+// Допустимое расхождение часов при проверке auth_date из будущего
+const telegramClockSkew = 30 * time.Second
+
+var (
+	ErrInitDataMalformed    = errors.New("malformed initData")
+	ErrInitDataHashMissing  = errors.New("initData hash is missing")
+	ErrInitDataBadSignature = errors.New("initData signature mismatch")
+	ErrInitDataExpired      = errors.New("initData is expired")
+	ErrInitDataNoUser       = errors.New("initData has no user")
+)
+
+// TelegramWebAppUser - пользователь из initData Telegram WebApp
+type TelegramWebAppUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name,omitempty"`
+	Username     string `json:"username,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+	PhotoURL     string `json:"photo_url,omitempty"`
+}
+
+// DisplayName - username, а если его нет, то имя и фамилия
+func (u TelegramWebAppUser) DisplayName() string {
+	if u.Username != "" {
+		return u.Username
+	}
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name != "" {
+		return name
+	}
+	return "user" + strconv.FormatInt(u.ID, 10)
+}
+
+// TelegramInitData - проверенные данные запуска WebApp
+type TelegramInitData struct {
+	User     TelegramWebAppUser
+	AuthDate time.Time
+	QueryID  string
+}
+
+// ValidateTelegramInitData - проверяет подпись initData и свежесть auth_date
+// https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func ValidateTelegramInitData(initData, botToken string, maxAge time.Duration) (*TelegramInitData, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, ErrInitDataMalformed
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, ErrInitDataHashMissing
+	}
+	expected, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, ErrInitDataMalformed
+	}
+
+	// data-check-string: все поля кроме hash, отсортированные по ключу
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if k != "hash" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + values.Get(k)
+	}
+	dataCheckString := strings.Join(pairs, "\n")
+
+	secretKey := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	if !hmac.Equal(hmacSHA256(secretKey, []byte(dataCheckString)), expected) {
+		return nil, ErrInitDataBadSignature
+	}
+
+	authDateUnix, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, ErrInitDataMalformed
+	}
+	authDate := time.Unix(authDateUnix, 0)
+	age := time.Since(authDate)
+	if age > maxAge || age < -telegramClockSkew {
+		return nil, ErrInitDataExpired
+	}
+
+	rawUser := values.Get("user")
+	if rawUser == "" {
+		return nil, ErrInitDataNoUser
+	}
+	var user TelegramWebAppUser
+	if err := json.Unmarshal([]byte(rawUser), &user); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInitDataMalformed, err)
+	}
+	if user.ID == 0 {
+		return nil, ErrInitDataNoUser
+	}
+
+	return &TelegramInitData{
+		User:     user,
+		AuthDate: authDate,
+		QueryID:  values.Get("query_id"),
+	}, nil
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:TEST-bot-token"
+
+// signInitData - initData, подписанный так же, как это делает Telegram
+func signInitData(t *testing.T, botToken string, fields map[string]string) string {
+	t.Helper()
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + fields[k]
+	}
+
+	secretKey := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	hash := hex.EncodeToString(hmacSHA256(secretKey, []byte(strings.Join(pairs, "\n"))))
+
+	values := url.Values{}
+	for k, v := range fields {
+		values.Set(k, v)
+	}
+	values.Set("hash", hash)
+	return values.Encode()
+}
+
+func initDataFields(authDate time.Time) map[string]string {
+	return map[string]string{
+		"auth_date": strconv.FormatInt(authDate.Unix(), 10),
+		"query_id":  "AAH-query",
+		"user":      `{"id":42,"first_name":"Анна","username":"anna"}`,
+	}
+}
+
+func TestValidateTelegramInitData(t *testing.T) {
+	now := time.Now()
+
+	valid, err := ValidateTelegramInitData(signInitData(t, testBotToken, initDataFields(now)), testBotToken, time.Hour)
+	if err != nil {
+		t.Fatalf("valid initData rejected: %v", err)
+	}
+	if valid.User.ID != 42 || valid.User.Username != "anna" || valid.QueryID != "AAH-query" {
+		t.Fatalf("unexpected parsed data: %+v", valid)
+	}
+
+	tampered := signInitData(t, testBotToken, initDataFields(now))
+	tampered = strings.Replace(tampered, "anna", "admin", 1)
+
+	noUser := initDataFields(now)
+	delete(noUser, "user")
+
+	zeroUser := initDataFields(now)
+	zeroUser["user"] = `{"first_name":"Анна"}`
+
+	tests := []struct {
+		name     string
+		initData string
+		want     error
+	}{
+		{"wrong bot token", signInitData(t, "other:token", initDataFields(now)), ErrInitDataBadSignature},
+		{"tampered field", tampered, ErrInitDataBadSignature},
+		{"missing hash", "auth_date=1&user=%7B%7D", ErrInitDataHashMissing},
+		{"hash not hex", "auth_date=1&hash=zz", ErrInitDataMalformed},
+		{"broken query", "%zz", ErrInitDataMalformed},
+		{"expired", signInitData(t, testBotToken, initDataFields(now.Add(-2*time.Hour))), ErrInitDataExpired},
+		{"from the future", signInitData(t, testBotToken, initDataFields(now.Add(time.Hour))), ErrInitDataExpired},
+		{"no user", signInitData(t, testBotToken, noUser), ErrInitDataNoUser},
+		{"user without id", signInitData(t, testBotToken, zeroUser), ErrInitDataNoUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateTelegramInitData(tt.initData, testBotToken, time.Hour)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTelegramInitDataClockSkew(t *testing.T) {
+	initData := signInitData(t, testBotToken, initDataFields(time.Now().Add(10*time.Second)))
+	if _, err := ValidateTelegramInitData(initData, testBotToken, time.Hour); err != nil {
+		t.Fatalf("auth_date within clock skew rejected: %v", err)
+	}
+}
+
+func TestTelegramWebAppUserDisplayName(t *testing.T) {
+	tests := []struct {
+		user TelegramWebAppUser
+		want string
+	}{
+		{TelegramWebAppUser{ID: 1, Username: "anna", FirstName: "Анна"}, "anna"},
+		{TelegramWebAppUser{ID: 1, FirstName: "Анна", LastName: "Петрова"}, "Анна Петрова"},
+		{TelegramWebAppUser{ID: 7}, "user7"},
+	}
+	for _, tt := range tests {
+		if got := tt.user.DisplayName(); got != tt.want {
+			t.Errorf("DisplayName() = %q, want %q", got, tt.want)
+		}
+	}
+}
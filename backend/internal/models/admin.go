@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// AdminCredential - учётные данные для входа в админ-панель.
+// Привязаны к реальному пользователю, чтобы действия админа можно было атрибутировать.
+type AdminCredential struct {
+	ID           int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       int64  `gorm:"uniqueIndex;not null" json:"userId"`
+	User         *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Login        string `gorm:"uniqueIndex;not null" json:"login"`
+	PasswordHash string `gorm:"not null" json:"-"` // bcrypt
+
+	// Второй фактор (TOTP, RFC 6238)
+	TOTPSecret  string `gorm:"column:totp_secret" json:"-"`
+	TOTPEnabled bool   `gorm:"column:totp_enabled;default:false" json:"totpEnabled"`
+	// TOTPLastStep - шаг последнего принятого кода; коды этого шага и раньше не принимаются
+	TOTPLastStep int64 `gorm:"column:totp_last_step;default:0" json:"-"`
+
+	// Блокировка после серии неудачных попыток
+	FailedAttempts int        `gorm:"default:0" json:"failedAttempts"`
+	LockedUntil    *time.Time `json:"lockedUntil,omitempty"`
+	LastLoginAt    *time.Time `json:"lastLoginAt,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APIKeyPermission - что разрешено делать ключу интеграции
+type APIKeyPermission string
+
+const (
+	APIKeyPermReadParticipants   APIKeyPermission = "participants:read"
+	APIKeyPermReadTeams          APIKeyPermission = "teams:read"
+	APIKeyPermWriteAnnouncements APIKeyPermission = "announcements:write"
+)
+
+// AllAPIKeyPermissions - полный список известных разрешений
+var AllAPIKeyPermissions = []APIKeyPermission{
+	APIKeyPermReadParticipants,
+	APIKeyPermReadTeams,
+	APIKeyPermWriteAnnouncements,
+}
+
+// IsValid - известное ли разрешение
+func (p APIKeyPermission) IsValid() bool {
+	for _, known := range AllAPIKeyPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey - ключ организатора для интеграций (таблицы, CRM), привязанный к одному хакатону.
+// Сам ключ показывается один раз при создании, в БД хранится только его SHA-256.
+type APIKey struct {
+	ID          int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID int64          `gorm:"index;not null" json:"hackathonId"`
+	CreatedByID int64          `gorm:"index;not null" json:"createdById"`
+	Name        string         `gorm:"not null" json:"name"`
+	Prefix      string         `gorm:"type:varchar(16);not null" json:"prefix"` // видимая часть ключа, чтобы отличать ключи в списке
+	KeyHash     string         `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Permissions pq.StringArray `gorm:"type:text[]" json:"permissions"`
+
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// HasPermission - выдано ли ключу разрешение
+func (k *APIKey) HasPermission(perm APIKeyPermission) bool {
+	for _, p := range k.Permissions {
+		if APIKeyPermission(p) == perm {
+			return true
+		}
+	}
+	return false
+}
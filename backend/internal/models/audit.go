@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Действия, которые попадают в журнал аудита
+const (
+	AuditActionUserUpdate       = "user.update"
+	AuditActionUserPromote      = "user.promote"
+	AuditActionUserExport       = "user.export"
+	AuditActionUserErase        = "user.erase"
+	AuditActionTeamAssign       = "team.assign"
+	AuditActionTeamKick         = "team.kick"
+	AuditActionCaseGive         = "case.give"
+	AuditActionHackathonCreate  = "hackathon.create"
+	AuditActionHackathonUpdate  = "hackathon.update"
+	AuditActionHackathonStatus  = "hackathon.status"
+	AuditActionHackathonDelete  = "hackathon.delete"
+	AuditActionAPIKeyCreate     = "api_key.create"
+	AuditActionAPIKeyRevoke     = "api_key.revoke"
+	AuditActionImpersonateStart = "impersonation.start"
+	AuditActionImpersonateEnd   = "impersonation.end"
+	AuditActionSessionRevoke    = "session.revoke"
+)
+
+// Типы сущностей в журнале аудита
+const (
+	AuditEntityUser      = "user"
+	AuditEntityTeam      = "team"
+	AuditEntityHackathon = "hackathon"
+	AuditEntityAPIKey    = "api_key"
+)
+
+// AuditLog - запись журнала аудита. Таблица только дополняется:
+// UPDATE и DELETE запрещены триггером в БД. Поэтому в Before/After пишутся только
+// идентификаторы и служебные поля, без персональных данных - удаление пользователя их не тронет.
+type AuditLog struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	ActorID   *int64 `gorm:"index" json:"actorId,omitempty"`
+	ActorRole string `gorm:"type:varchar(30)" json:"actorRole"`
+	// SubjectID - пользователь, от имени которого действовал админ при имперсонации
+	SubjectID *int64 `gorm:"index" json:"subjectId,omitempty"`
+
+	Action     string `gorm:"type:varchar(64);not null;index" json:"action"`
+	EntityType string `gorm:"type:varchar(32);not null;index:idx_audit_entity" json:"entityType"`
+	EntityID   *int64 `gorm:"index:idx_audit_entity" json:"entityId,omitempty"`
+
+	// Только изменившиеся поля: значения до и после действия
+	Before map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"before,omitempty"`
+	After  map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"after,omitempty"`
+
+	IP string `gorm:"type:varchar(64)" json:"ip"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"createdAt"`
+}
@@ -11,6 +11,15 @@ const (
 	HackathonStatusCompleted        HackathonStatus = "completed"
 )
 
+// IsValid - известный ли статус
+func (s HackathonStatus) IsValid() bool {
+	switch s {
+	case HackathonStatusDraft, HackathonStatusRegistrationOpen, HackathonStatusActive, HackathonStatusCompleted:
+		return true
+	}
+	return false
+}
+
 type Hackathon struct {
 	ID          int64           `gorm:"primaryKey;autoIncrement" json:"id"`
 	Name        string          `gorm:"not null" json:"name"`
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ImpersonationSession - сеанс просмотра приложения админом от имени пользователя.
+// Записи не удаляются: это история того, кто, когда и зачем смотрел чужой аккаунт.
+type ImpersonationSession struct {
+	ID        string `gorm:"primaryKey;type:varchar(64)" json:"id"` // claim sid токена имперсонации
+	AdminID   int64  `gorm:"not null;index" json:"adminId"`
+	UserID    int64  `gorm:"not null;index" json:"userId"`
+	Reason    string `gorm:"type:text;not null" json:"reason"`
+	IP        string `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent string `gorm:"type:text" json:"userAgent"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime;index" json:"createdAt"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expiresAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+}
+
+// IsActive - сеанс не завершён вручную и не истёк
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
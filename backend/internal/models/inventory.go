@@ -12,6 +12,15 @@ const (
 	RarityLegendary RarityType = "legendary"
 )
 
+// IsValid - известная ли редкость
+func (r RarityType) IsValid() bool {
+	switch r {
+	case RarityCommon, RarityUncommon, RarityRare, RarityEpic, RarityLegendary:
+		return true
+	}
+	return false
+}
+
 type EquipmentSlot string
 
 const (
@@ -204,7 +213,7 @@ type GiveCaseRequest struct {
 	UserIDs  []int64    `json:"userIds" binding:"required"`
 	CaseType string     `json:"caseType" binding:"required"`
 	CaseName string     `json:"caseName" binding:"required"`
-	Rarity   RarityType `json:"rarity" binding:"required"`
+	Rarity   RarityType `json:"rarity" binding:"required,rarity"`
 }
 
 // ========================================
@@ -10,6 +10,15 @@ const (
 	TeamStatusClosed  TeamStatus = "closed"
 )
 
+// IsValid - известный ли статус
+func (s TeamStatus) IsValid() bool {
+	switch s {
+	case TeamStatusLooking, TeamStatusReady, TeamStatusClosed:
+		return true
+	}
+	return false
+}
+
 type Team struct {
 	ID          int64      `gorm:"primaryKey;autoIncrement" json:"id"`
 	HackathonID int64      `gorm:"index" json:"hackathonId"`
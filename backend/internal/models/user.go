@@ -14,6 +14,21 @@ const (
 	RoleAdmin            UserRole = "admin"
 )
 
+// IsValid - известная ли роль
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleUser, RoleHackathonCreator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// ExperienceLevels - допустимые значения User.Experience
+var ExperienceLevels = []string{"student", "junior", "middle", "senior"}
+
+// TeamRoles - роли, которые можно указать в User.LookingFor
+var TeamRoles = []string{"frontend", "backend", "fullstack", "design", "designer", "ml", "data", "devops", "pm", "mobile"}
+
 type User struct {
 	ID             int64    `gorm:"primaryKey;autoIncrement" json:"id"`
 	TelegramUserID int64    `gorm:"uniqueIndex;not null" json:"telegramUserId"`
@@ -45,6 +60,9 @@ type User struct {
 	// Notification settings
 	NotificationsEnabled bool `gorm:"default:true" json:"notificationsEnabled"`
 
+	// Персональные данные удалены по запросу; строка остаётся только ради статистики
+	ErasedAt *time.Time `json:"erasedAt,omitempty"`
+
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
 }
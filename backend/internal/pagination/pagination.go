@@ -0,0 +1,335 @@
+// Package pagination - курсорная пагинация, сортировка и фильтры списков.
+//
+// Запрос:
+//
+//	GET /api/teams?limit=50&sort=-createdAt&filter[status]=looking&cursor=...
+//
+// Ответ - всегда Page:
+//
+//	{"items": [...], "nextCursor": "eyJ...", "total": 1234}
+//
+// Страницы выбираются по ключу (значение сортировки, id), а не через OFFSET,
+// поэтому далёкие страницы больших списков стоят столько же, сколько первая,
+// и записи не дублируются, если между запросами появились новые.
+// Курсор непрозрачный: клиент передаёт nextCursor как есть, пока тот не станет null.
+// Курсор запоминает сортировку и фильтры, с другими он не принимается.
+package pagination
+
+import (
+	"backend/internal/apierror"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultLimit - размер страницы, если limit не задан
+	DefaultLimit = 50
+	// MaxLimit - наибольший limit, если в Spec не задан свой
+	MaxLimit = 100
+)
+
+// Page - ответ списка
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextCursor - курсор следующей страницы; null на последней
+	NextCursor *string `json:"nextCursor"`
+	// Total - сколько всего записей подходит под фильтры
+	Total int64 `json:"total"`
+}
+
+// NewPage - ответ списка; nil превращается в пустой массив
+func NewPage[T any](items []T, nextCursor *string, total int64) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, NextCursor: nextCursor, Total: total}
+}
+
+// ============================================
+// SPEC
+// ============================================
+
+// Sort - поле сортировки для строк типа R
+type Sort[R any] struct {
+	// column - SQL выражение; только из Spec, не из запроса
+	column string
+	encode func(R) string
+	decode func(string) (any, error)
+}
+
+// TimeSort - сортировка по столбцу времени
+func TimeSort[R any](column string, value func(R) time.Time) Sort[R] {
+	return Sort[R]{
+		column: column,
+		encode: func(r R) string { return value(r).UTC().Format(time.RFC3339Nano) },
+		decode: func(s string) (any, error) { return time.Parse(time.RFC3339Nano, s) },
+	}
+}
+
+// IntSort - сортировка по целому столбцу
+func IntSort[R any](column string, value func(R) int64) Sort[R] {
+	return Sort[R]{
+		column: column,
+		encode: func(r R) string { return strconv.FormatInt(value(r), 10) },
+		decode: func(s string) (any, error) { return strconv.ParseInt(s, 10, 64) },
+	}
+}
+
+// StringSort - сортировка по текстовому столбцу
+func StringSort[R any](column string, value func(R) string) Sort[R] {
+	return Sort[R]{
+		column: column,
+		encode: func(r R) string { return value(r) },
+		decode: func(s string) (any, error) { return s, nil },
+	}
+}
+
+// Filter - допустимый фильтр filter[name]=value
+type Filter struct {
+	// Valid - проверка значения; nil - подходит любое непустое
+	Valid func(value string) bool
+	// Message - что ожидается, для ответа 422
+	Message string
+	// Apply - условие запроса для проверенного значения
+	Apply func(db *gorm.DB, value string) *gorm.DB
+}
+
+// Equals - фильтр column = value
+func Equals(column string, valid func(string) bool, message string) Filter {
+	return Filter{
+		Valid:   valid,
+		Message: message,
+		Apply:   func(db *gorm.DB, value string) *gorm.DB { return db.Where(column+" = ?", value) },
+	}
+}
+
+// Search - подстрока без учёта регистра хотя бы в одном из столбцов
+func Search(columns ...string) Filter {
+	return Filter{
+		Valid:   func(value string) bool { return len(value) <= 100 },
+		Message: "must be at most 100 characters",
+		Apply: func(db *gorm.DB, value string) *gorm.DB {
+			pattern := "%" + likeEscaper.Replace(value) + "%"
+			conditions := make([]string, len(columns))
+			args := make([]interface{}, len(columns))
+			for i, column := range columns {
+				conditions[i] = column + " ILIKE ?"
+				args[i] = pattern
+			}
+			return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+		},
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Spec - что разрешено в списке: сортировки, фильтры и размер страницы.
+// Всё, чего нет в Spec, отклоняется с 422.
+type Spec[R any] struct {
+	// Sorts - имя из ?sort= (без "-") -> поле
+	Sorts map[string]Sort[R]
+	// DefaultSort - сортировка без ?sort=, например "-createdAt"
+	DefaultSort string
+	Filters     map[string]Filter
+	// MaxLimit - наибольший limit; 0 - пакетный MaxLimit
+	MaxLimit int
+	// IDColumn - столбец, завершающий сортировку; пустой - "id"
+	IDColumn string
+	// ID - id строки для курсора
+	ID func(R) int64
+}
+
+// ============================================
+// PARAMS
+// ============================================
+
+// Params - разобранные limit, sort, filter и cursor одного запроса
+type Params[R any] struct {
+	spec    *Spec[R]
+	Limit   int
+	sort    Sort[R]
+	desc    bool
+	filters map[string]string
+	// signature - сортировка и фильтры; курсор действителен только с ними
+	signature string
+	// after - ключ последней строки предыдущей страницы
+	after      *cursor
+	afterValue any
+}
+
+type cursor struct {
+	Signature string `json:"s"`
+	Value     string `json:"v"`
+	ID        int64  `json:"id"`
+}
+
+// Parse - разобрать параметры списка; все нарушения возвращаются одной ошибкой 422
+func Parse[R any](c *gin.Context, spec *Spec[R]) (*Params[R], error) {
+	var details []apierror.FieldError
+	p := &Params[R]{spec: spec, Limit: DefaultLimit, filters: map[string]string{}}
+
+	maxLimit := spec.MaxLimit
+	if maxLimit == 0 {
+		maxLimit = MaxLimit
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxLimit {
+			details = append(details, apierror.FieldError{
+				Field:   "limit",
+				Message: fmt.Sprintf("must be between 1 and %d", maxLimit),
+			})
+		} else {
+			p.Limit = limit
+		}
+	}
+	p.Limit = min(p.Limit, maxLimit)
+
+	sortParam := c.DefaultQuery("sort", spec.DefaultSort)
+	name, desc := strings.CutPrefix(sortParam, "-")
+	if s, ok := spec.Sorts[name]; ok {
+		p.sort, p.desc = s, desc
+	} else {
+		details = append(details, apierror.FieldError{
+			Field:   "sort",
+			Message: "must be one of: " + strings.Join(sortedKeys(spec.Sorts), ", ") + " (prefix with - for descending)",
+		})
+	}
+
+	query := c.QueryMap("filter")
+	for _, name := range sortedKeys(query) {
+		value := query[name]
+		field := "filter[" + name + "]"
+		f, ok := spec.Filters[name]
+		switch {
+		case !ok:
+			details = append(details, apierror.FieldError{
+				Field:   field,
+				Message: "unknown filter; allowed: " + strings.Join(sortedKeys(spec.Filters), ", "),
+			})
+		case value == "":
+			// Пустое значение - фильтр не задан (так проще собирать запрос на клиенте)
+		case f.Valid != nil && !f.Valid(value):
+			details = append(details, apierror.FieldError{Field: field, Message: f.Message})
+		default:
+			p.filters[name] = value
+		}
+	}
+
+	p.signature = signature(sortParam, p.filters)
+	if raw := c.Query("cursor"); raw != "" && p.sort.decode != nil {
+		after, err := decodeCursor(raw)
+		if err == nil && after.Signature != p.signature {
+			err = errors.New("cursor was issued for another sort or filter")
+		}
+		if err == nil {
+			p.afterValue, err = p.sort.decode(after.Value)
+		}
+		if err != nil {
+			details = append(details, apierror.FieldError{
+				Field:   "cursor",
+				Message: "is invalid or does not match sort and filter; start again without cursor",
+			})
+		} else {
+			p.after = after
+		}
+	}
+
+	if len(details) > 0 {
+		return nil, apierror.Validation(details...)
+	}
+	return p, nil
+}
+
+// Filter - применить фильтры (для подсчёта total)
+func (p *Params[R]) Filter(db *gorm.DB) *gorm.DB {
+	for _, name := range sortedKeys(p.filters) {
+		db = p.spec.Filters[name].Apply(db, p.filters[name])
+	}
+	return db
+}
+
+// Query - фильтры, курсор, сортировка и limit+1: по лишней строке Next понимает,
+// есть ли следующая страница
+func (p *Params[R]) Query(db *gorm.DB) *gorm.DB {
+	db = p.Filter(db)
+
+	idColumn := p.spec.IDColumn
+	if idColumn == "" {
+		idColumn = "id"
+	}
+	direction, compare := "ASC", ">"
+	if p.desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if p.after != nil {
+		db = db.Where("("+p.sort.column+", "+idColumn+") "+compare+" (?, ?)", p.afterValue, p.after.ID)
+	}
+	return db.
+		Order(p.sort.column + " " + direction).
+		Order(idColumn + " " + direction).
+		Limit(p.Limit + 1)
+}
+
+// Next - отрезать лишнюю строку и выдать курсор следующей страницы (nil, если её нет)
+func (p *Params[R]) Next(rows []R) ([]R, *string) {
+	if len(rows) <= p.Limit {
+		return rows, nil
+	}
+	rows = rows[:p.Limit]
+	last := rows[len(rows)-1]
+	next := encodeCursor(cursor{
+		Signature: p.signature,
+		Value:     p.sort.encode(last),
+		ID:        p.spec.ID(last),
+	})
+	return rows, &next
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// signature - короткий отпечаток сортировки и фильтров
+func signature(sortParam string, filters map[string]string) string {
+	h := fnv.New64a()
+	h.Write([]byte(sortParam))
+	for _, name := range sortedKeys(filters) {
+		h.Write([]byte{0})
+		h.Write([]byte(name + "=" + filters[name]))
+	}
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
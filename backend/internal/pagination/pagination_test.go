@@ -0,0 +1,206 @@
+package pagination
+
+import (
+	"backend/internal/apierror"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+type row struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+var testSpec = &Spec[row]{
+	Sorts: map[string]Sort[row]{
+		"createdAt": TimeSort("created_at", func(r row) time.Time { return r.CreatedAt }),
+		"id":        IntSort("id", func(r row) int64 { return r.ID }),
+		"name":      StringSort("name", func(r row) string { return r.Name }),
+	},
+	DefaultSort: "-createdAt",
+	Filters: map[string]Filter{
+		"status": Equals("status", func(v string) bool { return v == "open" || v == "closed" }, "must be one of: open, closed"),
+		"search": Search("name", "description"),
+	},
+	MaxLimit: 20,
+	ID:       func(r row) int64 { return r.ID },
+}
+
+// parse - Parse для строки запроса
+func parse(t *testing.T, query string) (*Params[row], error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/x?"+query, nil)
+	return Parse(c, testSpec)
+}
+
+// rows - n строк с id 1..n, созданных по минуте одна за другой
+func rows(n int) []row {
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	out := make([]row, n)
+	for i := range out {
+		out[i] = row{ID: int64(i + 1), Name: "team", CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+	}
+	return out
+}
+
+func TestParseDefaults(t *testing.T) {
+	p, err := parse(t, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DefaultLimit больше MaxLimit спеки - берётся меньший
+	if p.Limit != 20 || !p.desc || p.after != nil {
+		t.Fatalf("unexpected params %+v", p)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		fields []string
+	}{
+		{"limit=0", []string{"limit"}},
+		{"limit=21", []string{"limit"}},
+		{"limit=ten", []string{"limit"}},
+		{"sort=password", []string{"sort"}},
+		{"filter[role]=admin", []string{"filter[role]"}},
+		{"filter[status]=looking", []string{"filter[status]"}},
+		{"filter[search]=" + strings.Repeat("a", 101), []string{"filter[search]"}},
+		{"cursor=not-base64!", []string{"cursor"}},
+		{"limit=0&sort=x&filter[status]=bad", []string{"limit", "sort", "filter[status]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parse(t, tt.query)
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) || len(apiErr.Details) != len(tt.fields) {
+				t.Fatalf("got %v, want errors for %v", err, tt.fields)
+			}
+			for i, field := range tt.fields {
+				if apiErr.Details[i].Field != field {
+					t.Fatalf("details %+v, want fields %v", apiErr.Details, tt.fields)
+				}
+			}
+		})
+	}
+
+	// Пустой фильтр считается незаданным
+	if _, err := parse(t, "filter[status]="); err != nil {
+		t.Fatalf("empty filter rejected: %v", err)
+	}
+}
+
+func TestNextAndCursor(t *testing.T) {
+	p, err := parse(t, "limit=2&sort=id&filter[status]=open")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, next := p.Next(rows(3))
+	if len(page) != 2 || next == nil {
+		t.Fatalf("page %d rows, next %v", len(page), next)
+	}
+	if _, last := p.Next(rows(2)); last != nil {
+		t.Fatal("cursor on the last page")
+	}
+
+	c, err := decodeCursor(*next)
+	if err != nil || c.ID != 2 || c.Value != "2" || c.Signature != p.signature {
+		t.Fatalf("cursor %+v, err %v", c, err)
+	}
+
+	// С тем же sort и filter курсор принимается
+	p2, err := parse(t, "limit=2&sort=id&filter[status]=open&cursor="+*next)
+	if err != nil || p2.after == nil || p2.after.ID != 2 || p2.afterValue != int64(2) {
+		t.Fatalf("cursor not accepted: %+v, %v", p2, err)
+	}
+	// limit в подпись не входит
+	if _, err := parse(t, "limit=5&sort=id&filter[status]=open&cursor="+*next); err != nil {
+		t.Fatalf("cursor rejected with another limit: %v", err)
+	}
+
+	// С другой сортировкой или фильтром - нет
+	for _, query := range []string{
+		"sort=-id&filter[status]=open",
+		"sort=id&filter[status]=closed",
+		"sort=id",
+	} {
+		if _, err := parse(t, query+"&cursor="+*next); err == nil {
+			t.Errorf("cursor accepted with %s", query)
+		}
+	}
+}
+
+func TestCursorValueDecoded(t *testing.T) {
+	p, err := parse(t, "limit=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, next := p.Next(rows(2))
+
+	p2, err := parse(t, "limit=1&cursor="+*next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := p2.afterValue.(time.Time); !ok || !got.Equal(rows(1)[0].CreatedAt) {
+		t.Fatalf("after value %v", p2.afterValue)
+	}
+
+	// Подделанное значение под той же подписью не разбирается
+	forged := encodeCursor(cursor{Signature: p.signature, Value: "yesterday", ID: 1})
+	if _, err := parse(t, "limit=1&cursor="+forged); err == nil {
+		t.Fatal("cursor with a bad value accepted")
+	}
+}
+
+func TestSignature(t *testing.T) {
+	a := signature("-createdAt", map[string]string{"status": "open", "search": "x"})
+	b := signature("-createdAt", map[string]string{"search": "x", "status": "open"})
+	if a != b {
+		t.Fatal("signature depends on map order")
+	}
+	if a == signature("createdAt", map[string]string{"status": "open", "search": "x"}) {
+		t.Fatal("signature ignores sort direction")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := parse(t, "limit=2&sort=name&filter[search]=50%25_a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, next := p.Next([]row{{ID: 7, Name: "b"}, {ID: 8, Name: "c"}, {ID: 9, Name: "d"}})
+	p, err = parse(t, "limit=2&sort=name&filter[search]=50%25_a&cursor="+*next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var out []row
+		return p.Query(tx.Table("teams")).Find(&out)
+	})
+	for _, want := range []string{
+		`name ILIKE "%50\%\_a%" OR description ILIKE "%50\%\_a%"`,
+		`(name, id) > ("c", 8)`,
+		"ORDER BY name ASC,id ASC",
+		"LIMIT 3",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("query %q does not contain %q", sql, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+
+func (r *AdminRepository) GetByLogin(ctx context.Context, login string) (*models.AdminCredential, error) {
+	cred := &models.AdminCredential{}
+
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("login = ?", login).
+		First(cred).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("admin not found")
+		}
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+func (r *AdminRepository) Create(ctx context.Context, cred *models.AdminCredential) error {
+	if err := r.db.WithContext(ctx).Create(cred).Error; err != nil {
+		return fmt.Errorf("failed to create admin: %w", err)
+	}
+	return nil
+}
+
+func (r *AdminRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AdminCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password_hash":   passwordHash,
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		}).Error
+}
+
+func (r *AdminRepository) UpdateTOTP(ctx context.Context, id int64, secret string, enabled bool) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AdminCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"totp_secret":    secret,
+			"totp_enabled":   enabled,
+			"totp_last_step": 0,
+		}).Error
+}
+
+// UseTOTPStep - принять TOTP код шага step, если код этого или более позднего шага ещё не принимался.
+// Условный UPDATE атомарен: из параллельных входов с одним кодом пройдёт один.
+func (r *AdminRepository) UseTOTPStep(ctx context.Context, id int64, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.AdminCredential{}).
+		Where("id = ? AND COALESCE(totp_last_step, 0) < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// IncrementFailures - атомарно увеличить счётчик неудачных попыток и вернуть новое значение.
+// Параллельные попытки получают разные значения, поэтому порог блокировки не проскочить.
+func (r *AdminRepository) IncrementFailures(ctx context.Context, id int64) (int, error) {
+	var attempts int
+	err := r.db.WithContext(ctx).
+		Raw("UPDATE admin_credentials SET failed_attempts = COALESCE(failed_attempts, 0) + 1, updated_at = ? WHERE id = ? RETURNING failed_attempts",
+			time.Now(), id).
+		Scan(&attempts).Error
+	return attempts, err
+}
+
+// Lock - заблокировать аккаунт до lockedUntil; счётчик попыток начинается заново
+func (r *AdminRepository) Lock(ctx context.Context, id int64, lockedUntil time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AdminCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    lockedUntil,
+		}).Error
+}
+
+// RecordSuccess - сбросить счётчик и отметить вход.
+// false - аккаунт заблокировали, пока проверялся пароль (параллельная неудачная попытка).
+func (r *AdminRepository) RecordSuccess(ctx context.Context, id int64) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&models.AdminCredential{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until <= ?)", id, now).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    nil,
+			"last_login_at":   now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *AdminRepository) Unlock(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AdminCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		}).Error
+}
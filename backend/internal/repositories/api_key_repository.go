@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetActiveByHash - неотозванный ключ по SHA-256
+func (r *APIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+
+	err := r.db.WithContext(ctx).
+		Where("key_hash = ? AND revoked_at IS NULL", keyHash).
+		First(key).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepository) ListByHackathon(ctx context.Context, hackathonID int64) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.WithContext(ctx).
+		Where("hackathon_id = ?", hackathonID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Revoke - отозвать ключ хакатона; false, если активного ключа с таким ID нет
+func (r *APIKeyRepository) Revoke(ctx context.Context, hackathonID, id int64) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ? AND hackathon_id = ? AND revoked_at IS NULL", id, hackathonID).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+// RevokeAllForHackathon - отозвать все ключи хакатона (например, при его удалении)
+func (r *APIKeyRepository) RevokeAllForHackathon(ctx context.Context, hackathonID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("hackathon_id = ? AND revoked_at IS NULL", hackathonID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"backend/internal/pagination"
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// List - страница журнала и сколько всего записей под фильтрами
+func (r *AuditRepository) List(ctx context.Context, page *pagination.Params[models.AuditLog]) ([]models.AuditLog, *string, int64, error) {
+	query := r.db.WithContext(ctx).Session(&gorm.Session{})
+
+	var total int64
+	if err := page.Filter(query.Model(&models.AuditLog{})).Count(&total).Error; err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	var entries []models.AuditLog
+	if err := page.Query(query).Find(&entries).Error; err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to fetch audit logs: %w", err)
+	}
+	entries, next := page.Next(entries)
+	return entries, next, total, nil
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound - запись не найдена. Репозитории доменных сущностей возвращают её
+// вместо gorm.ErrRecordNotFound, чтобы сервисы не зависели от gorm.
+var ErrNotFound = errors.New("record not found")
+
+// notFound - заменить gorm.ErrRecordNotFound на ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// HackathonRepository - хакатоны
+type HackathonRepository interface {
+	GetByID(ctx context.Context, id int64) (*models.Hackathon, error)
+}
+
+type hackathonRepository struct {
+	db *gorm.DB
+}
+
+func NewHackathonRepository(db *gorm.DB) HackathonRepository {
+	return &hackathonRepository{db: db}
+}
+
+func (r *hackathonRepository) GetByID(ctx context.Context, id int64) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := r.db.WithContext(ctx).First(&hackathon, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &hackathon, nil
+}
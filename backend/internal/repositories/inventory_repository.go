@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryRepository - кейсы и предметы кастомизации пользователя
+type InventoryRepository interface {
+	// Transaction - выполнить fn в одной транзакции; tx работает внутри неё
+	Transaction(ctx context.Context, fn func(tx InventoryRepository) error) error
+
+	// GetUnopenedCase - неоткрытый кейс пользователя
+	GetUnopenedCase(ctx context.Context, userID, caseID int64) (*models.UserCase, error)
+	// MarkCaseOpened - открыть кейс; ErrNotFound, если его успели открыть параллельно
+	MarkCaseOpened(ctx context.Context, userCase *models.UserCase, openedAt time.Time) error
+	CreateCase(ctx context.Context, userCase *models.UserCase) error
+
+	// AddItem - положить предмет в инвентарь; такой же предмет увеличивает количество.
+	// Возвращает сохранённый предмет и был ли он новым.
+	AddItem(ctx context.Context, item models.CustomizationItem) (*models.CustomizationItem, bool, error)
+}
+
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &inventoryRepository{db: db}
+}
+
+func (r *inventoryRepository) Transaction(ctx context.Context, fn func(tx InventoryRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&inventoryRepository{db: tx})
+	})
+}
+
+func (r *inventoryRepository) GetUnopenedCase(ctx context.Context, userID, caseID int64) (*models.UserCase, error) {
+	var userCase models.UserCase
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND is_opened = false", caseID, userID).
+		First(&userCase).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &userCase, nil
+}
+
+func (r *inventoryRepository) MarkCaseOpened(ctx context.Context, userCase *models.UserCase, openedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.UserCase{}).
+		Where("id = ? AND is_opened = false", userCase.ID).
+		Updates(map[string]interface{}{"is_opened": true, "opened_at": openedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	userCase.IsOpened = true
+	userCase.OpenedAt = &openedAt
+	return nil
+}
+
+func (r *inventoryRepository) CreateCase(ctx context.Context, userCase *models.UserCase) error {
+	return r.db.WithContext(ctx).Create(userCase).Error
+}
+
+func (r *inventoryRepository) AddItem(ctx context.Context, item models.CustomizationItem) (*models.CustomizationItem, bool, error) {
+	db := r.db.WithContext(ctx)
+
+	var existing models.CustomizationItem
+	err := db.Where("user_id = ? AND item_id = ?", item.UserID, item.ItemID).First(&existing).Error
+	if err == nil {
+		if err := db.Model(&existing).Update("quantity", gorm.Expr("quantity + 1")).Error; err != nil {
+			return nil, false, err
+		}
+		existing.Quantity++
+		return &existing, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	if err := db.Create(&item).Error; err != nil {
+		return nil, false, err
+	}
+	return &item, true, nil
+}
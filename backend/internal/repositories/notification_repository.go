@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"backend/internal/pagination"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRepository - уведомления внутри приложения
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	// ListForUser - страница уведомлений пользователя и сколько их всего под фильтрами
+	ListForUser(ctx context.Context, userID int64, page *pagination.Params[models.Notification]) ([]models.Notification, *string, int64, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	// MarkRead - отметить прочитанным; ErrNotFound, если уведомление не принадлежит пользователю
+	MarkRead(ctx context.Context, userID, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID int64) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *notificationRepository) ListForUser(ctx context.Context, userID int64, page *pagination.Params[models.Notification]) ([]models.Notification, *string, int64, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID).Session(&gorm.Session{})
+
+	var total int64
+	if err := page.Filter(query.Model(&models.Notification{})).Count(&total).Error; err != nil {
+		return nil, nil, 0, err
+	}
+
+	var notifications []models.Notification
+	if err := page.Query(query).Find(&notifications).Error; err != nil {
+		return nil, nil, 0, err
+	}
+	notifications, next := page.Next(notifications)
+	return notifications, next, total, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("is_read", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Update("is_read", true).Error
+}
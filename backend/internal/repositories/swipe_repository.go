@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// SwipeRepository - свайпы и мэтчи
+type SwipeRepository interface {
+	// Exists - свайпал ли уже swiperID (команда или соло-пользователь) на пользователя
+	Exists(ctx context.Context, swiperID, targetUserID int64) (bool, error)
+	// HasLiked - лайкнул ли swiperID пользователя
+	HasLiked(ctx context.Context, swiperID, targetUserID int64) (bool, error)
+	Create(ctx context.Context, swipe *models.Swipe) error
+
+	CreateMatch(ctx context.Context, match *models.Match) error
+	// ListMatches - мэтчи, где пользователь с любой из сторон
+	ListMatches(ctx context.Context, userID int64) ([]models.Match, error)
+
+	// GetPreference - настройки подбора пользователя на хакатоне
+	GetPreference(ctx context.Context, userID, hackathonID int64) (*models.SwipePreference, error)
+	// SavePreference - создать или перезаписать настройки подбора
+	SavePreference(ctx context.Context, pref *models.SwipePreference) error
+}
+
+type swipeRepository struct {
+	db *gorm.DB
+}
+
+func NewSwipeRepository(db *gorm.DB) SwipeRepository {
+	return &swipeRepository{db: db}
+}
+
+func (r *swipeRepository) Exists(ctx context.Context, swiperID, targetUserID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Swipe{}).
+		Where("swiper_team_id = ? AND target_user_id = ?", swiperID, targetUserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *swipeRepository) HasLiked(ctx context.Context, swiperID, targetUserID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Swipe{}).
+		Where("swiper_team_id = ? AND target_user_id = ? AND action = ?", swiperID, targetUserID, "like").
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *swipeRepository) Create(ctx context.Context, swipe *models.Swipe) error {
+	return r.db.WithContext(ctx).Create(swipe).Error
+}
+
+func (r *swipeRepository) CreateMatch(ctx context.Context, match *models.Match) error {
+	return r.db.WithContext(ctx).Create(match).Error
+}
+
+func (r *swipeRepository) ListMatches(ctx context.Context, userID int64) ([]models.Match, error) {
+	var matches []models.Match
+	err := r.db.WithContext(ctx).
+		Where("team_id = ? OR user_id = ?", userID, userID).
+		Find(&matches).Error
+	return matches, err
+}
+
+func (r *swipeRepository) GetPreference(ctx context.Context, userID, hackathonID int64) (*models.SwipePreference, error) {
+	var pref models.SwipePreference
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).
+		First(&pref).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &pref, nil
+}
+
+func (r *swipeRepository) SavePreference(ctx context.Context, pref *models.SwipePreference) error {
+	existing, err := r.GetPreference(ctx, pref.UserID, pref.HackathonID)
+	if errors.Is(err, ErrNotFound) {
+		return r.db.WithContext(ctx).Create(pref).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	pref.ID = existing.ID
+	return r.db.WithContext(ctx).Model(existing).Updates(map[string]interface{}{
+		"min_mmr":              pref.MinMMR,
+		"max_mmr":              pref.MaxMMR,
+		"preferred_skills":     pref.PreferredSkills,
+		"preferred_experience": pref.PreferredExperience,
+		"preferred_roles":      pref.PreferredRoles,
+		"verified_only":        pref.VerifiedOnly,
+	}).Error
+}
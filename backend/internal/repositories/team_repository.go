@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// TeamRepository - команды, приглашения и заявки на вступление
+type TeamRepository interface {
+	// Transaction - выполнить fn в одной транзакции; tx работает внутри неё
+	Transaction(ctx context.Context, fn func(tx TeamRepository) error) error
+
+	GetByID(ctx context.Context, id int64) (*models.Team, error)
+	GetByInviteCode(ctx context.Context, code string) (*models.Team, error)
+	// GetUserTeam - команда из users.team_id
+	GetUserTeam(ctx context.Context, userID int64) (*models.Team, error)
+	// GetCaptainedBy - первая команда, где пользователь капитан
+	GetCaptainedBy(ctx context.Context, userID int64) (*models.Team, error)
+	GetCaptainedInHackathon(ctx context.Context, userID, hackathonID int64) (*models.Team, error)
+	// CountMembers - участники команды вместе с капитаном, даже если у капитана не проставлен team_id
+	CountMembers(ctx context.Context, team *models.Team) (int, error)
+	// SetUserTeam - перевести пользователя в команду
+	SetUserTeam(ctx context.Context, userID, teamID int64) error
+	// MarkParticipantInTeam - статус регистрации на хакатон "in_team"; отсутствие регистрации ошибкой не считается
+	MarkParticipantInTeam(ctx context.Context, userID, hackathonID int64) error
+	// RemoveMember - убрать пользователя из команды; false, если он в ней не состоял
+	RemoveMember(ctx context.Context, teamID, userID int64) (bool, error)
+	// MarkParticipantLooking - вернуть регистрации на хакатон статус "looking"
+	MarkParticipantLooking(ctx context.Context, userID, hackathonID int64) error
+
+	GetInvite(ctx context.Context, id int64) (*models.TeamInvite, error)
+	GetPendingInvite(ctx context.Context, teamID, userID int64) (*models.TeamInvite, error)
+	CreateInvite(ctx context.Context, invite *models.TeamInvite) error
+	SetInviteStatus(ctx context.Context, inviteID int64, status string) error
+	// ListIncomingInvites - ожидающие приглашения пользователя
+	ListIncomingInvites(ctx context.Context, userID int64) ([]models.TeamInvite, error)
+	// ListOutgoingInvites - все приглашения, отправленные пользователем
+	ListOutgoingInvites(ctx context.Context, inviterID int64) ([]models.TeamInvite, error)
+
+	GetJoinRequest(ctx context.Context, id int64) (*models.TeamJoinRequest, error)
+	SetJoinRequestStatus(ctx context.Context, requestID int64, status string) error
+
+	// CancelPendingInHackathon - отменить все ожидающие приглашения и заявки пользователя в командах хакатона
+	CancelPendingInHackathon(ctx context.Context, userID, hackathonID int64) error
+}
+
+type teamRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{db: db}
+}
+
+func (r *teamRepository) Transaction(ctx context.Context, fn func(tx TeamRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&teamRepository{db: tx})
+	})
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id int64) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.WithContext(ctx).First(&team, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) GetByInviteCode(ctx context.Context, code string) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.WithContext(ctx).Where("invite_code = ?", code).First(&team).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) GetUserTeam(ctx context.Context, userID int64) (*models.Team, error) {
+	var team models.Team
+	err := r.db.WithContext(ctx).
+		Where("id = (SELECT team_id FROM users WHERE id = ?)", userID).
+		First(&team).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) GetCaptainedBy(ctx context.Context, userID int64) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.WithContext(ctx).Where("captain_id = ?", userID).First(&team).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) GetCaptainedInHackathon(ctx context.Context, userID, hackathonID int64) (*models.Team, error) {
+	var team models.Team
+	err := r.db.WithContext(ctx).
+		Where("captain_id = ? AND hackathon_id = ?", userID, hackathonID).
+		First(&team).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) CountMembers(ctx context.Context, team *models.Team) (int, error) {
+	db := r.db.WithContext(ctx)
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("team_id = ?", team.ID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	// Если капитан не в team_id, добавляем его к счету
+	var captain models.User
+	if err := db.First(&captain, team.CaptainID).Error; err == nil {
+		if captain.TeamID == nil || *captain.TeamID != team.ID {
+			count++
+		}
+	}
+
+	return int(count), nil
+}
+
+func (r *teamRepository) SetUserTeam(ctx context.Context, userID, teamID int64) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("team_id", teamID).Error
+}
+
+func (r *teamRepository) MarkParticipantInTeam(ctx context.Context, userID, hackathonID int64) error {
+	return r.db.WithContext(ctx).Model(&models.HackathonParticipant{}).
+		Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).
+		Update("status", "in_team").Error
+}
+
+func (r *teamRepository) RemoveMember(ctx context.Context, teamID, userID int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND team_id = ?", userID, teamID).
+		Update("team_id", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *teamRepository) MarkParticipantLooking(ctx context.Context, userID, hackathonID int64) error {
+	return r.db.WithContext(ctx).Model(&models.HackathonParticipant{}).
+		Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).
+		Update("status", "looking").Error
+}
+
+func (r *teamRepository) GetInvite(ctx context.Context, id int64) (*models.TeamInvite, error) {
+	var invite models.TeamInvite
+	if err := r.db.WithContext(ctx).First(&invite, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &invite, nil
+}
+
+func (r *teamRepository) GetPendingInvite(ctx context.Context, teamID, userID int64) (*models.TeamInvite, error) {
+	var invite models.TeamInvite
+	err := r.db.WithContext(ctx).
+		Where("team_id = ? AND invited_user_id = ? AND status = ?", teamID, userID, "pending").
+		First(&invite).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &invite, nil
+}
+
+func (r *teamRepository) CreateInvite(ctx context.Context, invite *models.TeamInvite) error {
+	return r.db.WithContext(ctx).Create(invite).Error
+}
+
+func (r *teamRepository) SetInviteStatus(ctx context.Context, inviteID int64, status string) error {
+	return r.db.WithContext(ctx).Model(&models.TeamInvite{}).Where("id = ?", inviteID).Update("status", status).Error
+}
+
+func (r *teamRepository) ListIncomingInvites(ctx context.Context, userID int64) ([]models.TeamInvite, error) {
+	var invites []models.TeamInvite
+	err := r.db.WithContext(ctx).
+		Where("invited_user_id = ? AND status = ?", userID, "pending").
+		Find(&invites).Error
+	return invites, err
+}
+
+func (r *teamRepository) ListOutgoingInvites(ctx context.Context, inviterID int64) ([]models.TeamInvite, error) {
+	var invites []models.TeamInvite
+	err := r.db.WithContext(ctx).Where("inviter_id = ?", inviterID).Find(&invites).Error
+	return invites, err
+}
+
+func (r *teamRepository) GetJoinRequest(ctx context.Context, id int64) (*models.TeamJoinRequest, error) {
+	var request models.TeamJoinRequest
+	if err := r.db.WithContext(ctx).First(&request, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &request, nil
+}
+
+func (r *teamRepository) SetJoinRequestStatus(ctx context.Context, requestID int64, status string) error {
+	return r.db.WithContext(ctx).Model(&models.TeamJoinRequest{}).Where("id = ?", requestID).Update("status", status).Error
+}
+
+func (r *teamRepository) CancelPendingInHackathon(ctx context.Context, userID, hackathonID int64) error {
+	db := r.db.WithContext(ctx)
+	hackathonTeams := db.Model(&models.Team{}).Select("id").Where("hackathon_id = ?", hackathonID)
+
+	if err := db.Model(&models.TeamInvite{}).
+		Where("invited_user_id = ? AND status = 'pending' AND team_id IN (?)", userID, hackathonTeams).
+		Update("status", "cancelled").Error; err != nil {
+		return err
+	}
+
+	return db.Model(&models.TeamJoinRequest{}).
+		Where("user_id = ? AND status = 'pending' AND team_id IN (?)", userID, hackathonTeams).
+		Update("status", "cancelled").Error
+}
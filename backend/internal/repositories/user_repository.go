@@ -60,7 +60,7 @@ func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramUserID int
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user not found: %w", ErrNotFound)
 		}
 		return nil, err
 	}
@@ -74,7 +74,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user not found: %w", ErrNotFound)
 		}
 		return nil, err
 	}
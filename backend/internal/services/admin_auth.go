@@ -0,0 +1,189 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// После стольких неудачных попыток подряд аккаунт блокируется
+	adminMaxFailedAttempts = 5
+	adminLockoutDuration   = 15 * time.Minute
+
+	AdminMinPasswordLength = 12
+	adminTOTPIssuer        = "ITAM Hackathon Admin"
+)
+
+var (
+	ErrAdminInvalidCredentials = errors.New("invalid credentials")
+	ErrAdminLocked             = errors.New("admin account is temporarily locked")
+	ErrAdminTOTPRequired       = errors.New("totp code required")
+	ErrAdminPasswordTooShort   = fmt.Errorf("password must be at least %d characters", AdminMinPasswordLength)
+)
+
+// Хеш для сравнения, когда логин не найден: время ответа не выдаёт существование логина
+var adminDummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+	return hash
+})
+
+// AdminAuthService - вход в админ-панель по персональным учётным данным
+type AdminAuthService struct {
+	repo *repositories.AdminRepository
+}
+
+func NewAdminAuthService(repo *repositories.AdminRepository) *AdminAuthService {
+	return &AdminAuthService{repo: repo}
+}
+
+// Authenticate - проверить логин, пароль и (если включён) TOTP код.
+// Возвращает пользователя, за которым закреплены учётные данные.
+func (s *AdminAuthService) Authenticate(ctx context.Context, login, password, totpCode string) (*models.User, error) {
+	cred, err := s.repo.GetByLogin(ctx, login)
+	if err != nil {
+		bcrypt.CompareHashAndPassword(adminDummyHash(), []byte(password))
+		return nil, ErrAdminInvalidCredentials
+	}
+
+	now := time.Now()
+	if cred.LockedUntil != nil && cred.LockedUntil.After(now) {
+		return nil, ErrAdminLocked
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(cred.PasswordHash), []byte(password)); err != nil {
+		return nil, s.recordFailure(ctx, cred)
+	}
+
+	if cred.TOTPEnabled {
+		if totpCode == "" {
+			return nil, ErrAdminTOTPRequired
+		}
+		step, ok := ValidateTOTP(cred.TOTPSecret, totpCode, now, cred.TOTPLastStep)
+		if !ok {
+			return nil, s.recordFailure(ctx, cred)
+		}
+		// Код, уже принятый при другом входе (в том числе параллельном), второй раз не проходит
+		fresh, err := s.repo.UseTOTPStep(ctx, cred.ID, step)
+		if err != nil {
+			return nil, err
+		}
+		if !fresh {
+			return nil, s.recordFailure(ctx, cred)
+		}
+	}
+
+	// Учётка остаётся, но если роль админа сняли - войти уже нельзя
+	if cred.User == nil || cred.User.Role != models.RoleAdmin {
+		return nil, ErrAdminInvalidCredentials
+	}
+
+	unlocked, err := s.repo.RecordSuccess(ctx, cred.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !unlocked {
+		return nil, ErrAdminLocked
+	}
+
+	return cred.User, nil
+}
+
+// recordFailure - учесть неудачную попытку. Решение о блокировке принимается по значению
+// счётчика из базы, а не по прочитанной ранее записи: параллельные попытки видят разные значения.
+func (s *AdminAuthService) recordFailure(ctx context.Context, cred *models.AdminCredential) error {
+	attempts, err := s.repo.IncrementFailures(ctx, cred.ID)
+	if err != nil {
+		return err
+	}
+	if attempts < adminMaxFailedAttempts {
+		return ErrAdminInvalidCredentials
+	}
+
+	if err := s.repo.Lock(ctx, cred.ID, time.Now().Add(adminLockoutDuration)); err != nil {
+		return err
+	}
+	return ErrAdminLocked
+}
+
+// CreateAdmin - создать учётные данные админа для существующего пользователя
+func (s *AdminAuthService) CreateAdmin(ctx context.Context, userID int64, login, password string) (*models.AdminCredential, error) {
+	hash, err := hashAdminPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	cred := &models.AdminCredential{
+		UserID:       userID,
+		Login:        login,
+		PasswordHash: hash,
+	}
+	if err := s.repo.Create(ctx, cred); err != nil {
+		return nil, err
+	}
+	return cred, nil
+}
+
+// SetPassword - сменить пароль (заодно снимает блокировку)
+func (s *AdminAuthService) SetPassword(ctx context.Context, login, password string) error {
+	cred, err := s.repo.GetByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	hash, err := hashAdminPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(ctx, cred.ID, hash)
+}
+
+// EnableTOTP - сгенерировать новый TOTP секрет и вернуть otpauth:// ссылку
+func (s *AdminAuthService) EnableTOTP(ctx context.Context, login string) (string, error) {
+	cred, err := s.repo.GetByLogin(ctx, login)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.UpdateTOTP(ctx, cred.ID, secret, true); err != nil {
+		return "", err
+	}
+	return TOTPProvisioningURI(secret, adminTOTPIssuer, login), nil
+}
+
+func (s *AdminAuthService) DisableTOTP(ctx context.Context, login string) error {
+	cred, err := s.repo.GetByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateTOTP(ctx, cred.ID, "", false)
+}
+
+func (s *AdminAuthService) Unlock(ctx context.Context, login string) error {
+	cred, err := s.repo.GetByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	return s.repo.Unlock(ctx, cred.ID)
+}
+
+func hashAdminPassword(password string) (string, error) {
+	if len(password) < AdminMinPasswordLength {
+		return "", ErrAdminPasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
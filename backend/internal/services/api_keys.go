@@ -0,0 +1,164 @@
+package services
+
+import (
+	"backend/internal/logging"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// Все ключи начинаются с этого префикса - так их проще найти в утёкших конфигах
+	apiKeyPrefix = "hk_"
+	// Сколько символов ключа после префикса сохраняется открыто для отображения
+	apiKeyVisibleChars = 8
+
+	// last_used_at обновляем не чаще, чем раз в этот интервал, чтобы не писать в БД на каждый запрос
+	apiKeyTouchInterval = time.Minute
+
+	// APIKeyMaxNameLength - наибольшая длина названия ключа в символах
+	APIKeyMaxNameLength = 100
+)
+
+var (
+	ErrAPIKeyInvalid           = errors.New("api key is invalid or revoked")
+	ErrAPIKeyNameRequired      = errors.New("api key name is required")
+	ErrAPIKeyNameTooLong       = fmt.Errorf("api key name must be at most %d characters", APIKeyMaxNameLength)
+	ErrAPIKeyNoPermissions     = errors.New("at least one permission is required")
+	ErrAPIKeyUnknownPermission = errors.New("unknown api key permission")
+	ErrAPIKeyNotFound          = errors.New("api key not found")
+)
+
+// APIKeyService - ключи организаторов для интеграций
+type APIKeyService struct {
+	repo *repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(repo *repositories.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create - выпустить ключ для хакатона. Открытый ключ возвращается только здесь.
+func (s *APIKeyService) Create(ctx context.Context, hackathonID, createdByID int64, name string, permissions []models.APIKeyPermission) (string, *models.APIKey, error) {
+	name, err := normalizeAPIKeyName(name)
+	if err != nil {
+		return "", nil, err
+	}
+	perms, err := normalizeAPIKeyPermissions(permissions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	plaintext := apiKeyPrefix + secret
+
+	key := &models.APIKey{
+		HackathonID: hackathonID,
+		CreatedByID: createdByID,
+		Name:        name,
+		Prefix:      plaintext[:len(apiKeyPrefix)+apiKeyVisibleChars],
+		KeyHash:     hashToken(plaintext),
+		Permissions: perms,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, key, nil
+}
+
+// normalizeAPIKeyName - название без пробелов по краям; длина считается в символах, а не байтах,
+// иначе кириллица режется посреди символа
+func normalizeAPIKeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrAPIKeyNameRequired
+	}
+	if !utf8.ValidString(name) || utf8.RuneCountInString(name) > APIKeyMaxNameLength {
+		return "", ErrAPIKeyNameTooLong
+	}
+	return name, nil
+}
+
+// normalizeAPIKeyPermissions - проверенные права без повторов
+func normalizeAPIKeyPermissions(permissions []models.APIKeyPermission) (pq.StringArray, error) {
+	if len(permissions) == 0 {
+		return nil, ErrAPIKeyNoPermissions
+	}
+
+	perms := make(pq.StringArray, 0, len(permissions))
+	seen := make(map[models.APIKeyPermission]bool, len(permissions))
+	for _, p := range permissions {
+		if !p.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyUnknownPermission, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, string(p))
+		}
+	}
+	return perms, nil
+}
+
+// Authenticate - найти активный ключ по открытому значению и отметить использование
+func (s *APIKeyService) Authenticate(ctx context.Context, plaintext string) (*models.APIKey, error) {
+	if !strings.HasPrefix(plaintext, apiKeyPrefix) {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	key, err := s.repo.GetActiveByHash(ctx, hashToken(plaintext))
+	if err != nil {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			logging.FromContext(ctx).Warn("failed to update api key last_used_at", slog.Int64("api_key_id", key.ID), logging.Err(err))
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+// List - все ключи хакатона, включая отозванные
+func (s *APIKeyService) List(ctx context.Context, hackathonID int64) ([]models.APIKey, error) {
+	keys, err := s.repo.ListByHackathon(ctx, hackathonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke - отозвать ключ хакатона
+func (s *APIKeyService) Revoke(ctx context.Context, hackathonID, keyID int64) error {
+	revoked, err := s.repo.Revoke(ctx, hackathonID, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// RevokeAllForHackathon - отозвать все ключи хакатона
+func (s *APIKeyService) RevokeAllForHackathon(ctx context.Context, hackathonID int64) error {
+	if err := s.repo.RevokeAllForHackathon(ctx, hackathonID); err != nil {
+		return fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+	return nil
+}
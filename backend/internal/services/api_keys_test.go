@@ -0,0 +1,54 @@
+package services
+
+import (
+	"backend/internal/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAPIKeyName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		err   error
+	}{
+		{"trimmed", "  CRM  ", "CRM", nil},
+		{"empty", "   ", "", ErrAPIKeyNameRequired},
+		// 100 кириллических символов - 200 байт, но в пределах лимита
+		{"cyrillic at the limit", strings.Repeat("ж", APIKeyMaxNameLength), strings.Repeat("ж", APIKeyMaxNameLength), nil},
+		{"cyrillic over the limit", strings.Repeat("ж", APIKeyMaxNameLength+1), "", ErrAPIKeyNameTooLong},
+		{"invalid utf-8", "bad\xff", "", ErrAPIKeyNameTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeAPIKeyName(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAPIKeyPermissions(t *testing.T) {
+	perms, err := normalizeAPIKeyPermissions([]models.APIKeyPermission{
+		models.APIKeyPermReadTeams, models.APIKeyPermReadParticipants, models.APIKeyPermReadTeams,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 2 || perms[0] != string(models.APIKeyPermReadTeams) {
+		t.Fatalf("unexpected permissions %v", perms)
+	}
+
+	if _, err := normalizeAPIKeyPermissions(nil); !errors.Is(err, ErrAPIKeyNoPermissions) {
+		t.Fatalf("empty: got %v", err)
+	}
+	if _, err := normalizeAPIKeyPermissions([]models.APIKeyPermission{"teams:delete"}); !errors.Is(err, ErrAPIKeyUnknownPermission) {
+		t.Fatalf("unknown: got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"backend/internal/logging"
+	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// Поля, которые меняются сами по себе и только засоряют diff
+var auditIgnoredFields = map[string]bool{
+	"updatedAt": true,
+}
+
+// Поля-идентификаторы: в diff остаются, даже если не менялись, иначе по записи не понять, к чему она относится
+var auditIdentityFields = map[string]bool{
+	"id":          true,
+	"hackathonId": true,
+}
+
+// AuditEntry - действие, которое нужно записать в журнал.
+// Before/After - любые значения, сериализуемые в JSON-объект (модели, gin.H, map).
+type AuditEntry struct {
+	ActorID    *int64
+	ActorRole  string
+	SubjectID  *int64
+	Action     string
+	EntityType string
+	EntityID   *int64
+	Before     interface{}
+	After      interface{}
+	IP         string
+}
+
+// AuditLogger - запись и чтение журнала аудита
+type AuditLogger struct {
+	repo *repositories.AuditRepository
+}
+
+func NewAuditLogger(repo *repositories.AuditRepository) *AuditLogger {
+	return &AuditLogger{repo: repo}
+}
+
+// Record - записать действие. Для обновлений в журнал попадают только изменившиеся поля.
+// Ошибка записи логируется: действие уже выполнено, откатывать его из-за журнала не будем.
+func (a *AuditLogger) Record(ctx context.Context, entry AuditEntry) error {
+	before, err := auditObject(entry.Before)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to record audit log", slog.String("action", entry.Action), logging.Err(err))
+		return err
+	}
+	after, err := auditObject(entry.After)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to record audit log", slog.String("action", entry.Action), logging.Err(err))
+		return err
+	}
+	if before != nil && after != nil {
+		before, after = auditDiff(before, after)
+	}
+
+	record := &models.AuditLog{
+		ActorID:    entry.ActorID,
+		ActorRole:  entry.ActorRole,
+		SubjectID:  entry.SubjectID,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     before,
+		After:      after,
+		IP:         entry.IP,
+	}
+	if err := a.repo.Create(ctx, record); err != nil {
+		logging.FromContext(ctx).Error("failed to record audit log", slog.String("action", entry.Action), logging.Err(err))
+		return err
+	}
+	return nil
+}
+
+// List - страница журнала
+func (a *AuditLogger) List(ctx context.Context, page *pagination.Params[models.AuditLog]) ([]models.AuditLog, *string, int64, error) {
+	return a.repo.List(ctx, page)
+}
+
+// auditObject - привести значение к JSON-объекту
+func auditObject(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit data: %w", err)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("audit data must be a JSON object: %w", err)
+	}
+	return obj, nil
+}
+
+// auditDiff - оставить только поля, значения которых различаются, и идентификаторы
+func auditDiff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+
+	for key, old := range before {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if val, ok := after[key]; !ok || !reflect.DeepEqual(old, val) || auditIdentityFields[key] {
+			changedBefore[key] = old
+			if ok {
+				changedAfter[key] = val
+			}
+		}
+	}
+	for key, val := range after {
+		if _, ok := before[key]; !ok && !auditIgnoredFields[key] {
+			changedAfter[key] = val
+		}
+	}
+
+	return changedBefore, changedAfter
+}
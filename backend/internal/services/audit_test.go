@@ -0,0 +1,35 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before := map[string]interface{}{"id": 5.0, "hackathonId": 1.0, "name": "A", "revoked": false, "updatedAt": "t1"}
+	after := map[string]interface{}{"id": 5.0, "hackathonId": 1.0, "name": "A", "revoked": true, "updatedAt": "t2"}
+
+	gotBefore, gotAfter := auditDiff(before, after)
+
+	wantBefore := map[string]interface{}{"id": 5.0, "hackathonId": 1.0, "revoked": false}
+	wantAfter := map[string]interface{}{"id": 5.0, "hackathonId": 1.0, "revoked": true}
+	if !reflect.DeepEqual(gotBefore, wantBefore) {
+		t.Errorf("before = %v, want %v", gotBefore, wantBefore)
+	}
+	if !reflect.DeepEqual(gotAfter, wantAfter) {
+		t.Errorf("after = %v, want %v", gotAfter, wantAfter)
+	}
+}
+
+func TestAuditDiffAddedAndRemovedFields(t *testing.T) {
+	gotBefore, gotAfter := auditDiff(
+		map[string]interface{}{"teamId": 3.0},
+		map[string]interface{}{"role": "admin"},
+	)
+	if !reflect.DeepEqual(gotBefore, map[string]interface{}{"teamId": 3.0}) {
+		t.Errorf("before = %v", gotBefore)
+	}
+	if !reflect.DeepEqual(gotAfter, map[string]interface{}{"role": "admin"}) {
+		t.Errorf("after = %v", gotAfter)
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"backend/internal/metrics"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+var (
+	ErrCaseNotFound = errors.New("case not found or already opened")
+	// ErrCaseRarityUnknown - у кейса редкость, из которой нечего выбросить
+	ErrCaseRarityUnknown = errors.New("case has an unknown rarity")
+)
+
+// CaseService - открытие кейсов
+type CaseService struct {
+	inventory repositories.InventoryRepository
+}
+
+func NewCaseService(inventory repositories.InventoryRepository) *CaseService {
+	return &CaseService{inventory: inventory}
+}
+
+// Open - открыть кейс пользователя: выпавший предмет и был ли он новым в инвентаре.
+// Кейс помечается открытым в той же транзакции, поэтому дважды его не открыть.
+func (s *CaseService) Open(ctx context.Context, userID, caseID int64) (*models.CustomizationItem, bool, error) {
+	var item *models.CustomizationItem
+	var isNew bool
+	var rarity models.RarityType
+
+	err := s.inventory.Transaction(ctx, func(tx repositories.InventoryRepository) error {
+		userCase, err := tx.GetUnopenedCase(ctx, userID, caseID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrCaseNotFound
+			}
+			return fmt.Errorf("failed to find case: %w", err)
+		}
+
+		// Генерируем выпавший предмет на основе типа кейса; до отметки об открытии,
+		// чтобы кейс с неизвестной редкостью остался неоткрытым
+		dropped, err := generateDroppedItem(userCase.CaseType, userCase.Rarity)
+		if err != nil {
+			return err
+		}
+		dropped.UserID = userID
+
+		if err := tx.MarkCaseOpened(ctx, userCase, time.Now()); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrCaseNotFound
+			}
+			return fmt.Errorf("failed to open case: %w", err)
+		}
+
+		rarity = userCase.Rarity
+
+		item, isNew, err = tx.AddItem(ctx, dropped)
+		if err != nil {
+			return fmt.Errorf("failed to add item: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	metrics.CasesOpened.WithLabelValues(string(rarity)).Inc()
+	return item, isNew, nil
+}
+
+// Give - выдать кейс каждому из req.UserIDs; возвращает тех, кому удалось
+func (s *CaseService) Give(ctx context.Context, req models.GiveCaseRequest) []int64 {
+	given := make([]int64, 0, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		userCase := models.UserCase{
+			UserID:   userID,
+			CaseType: req.CaseType,
+			CaseName: req.CaseName,
+			Rarity:   req.Rarity,
+			IsOpened: false,
+		}
+		if err := s.inventory.CreateCase(ctx, &userCase); err == nil {
+			given = append(given, userID)
+		}
+	}
+	return given
+}
+
+// generateDroppedItem генерирует случайный предмет из кейса
+func generateDroppedItem(caseType string, caseRarity models.RarityType) (models.CustomizationItem, error) {
+	// Определяем возможные редкости на основе типа кейса
+	var possibleRarities []models.RarityType
+	switch caseRarity {
+	case models.RarityCommon:
+		possibleRarities = []models.RarityType{models.RarityCommon}
+	case models.RarityUncommon:
+		possibleRarities = []models.RarityType{models.RarityCommon, models.RarityUncommon}
+	case models.RarityRare:
+		possibleRarities = []models.RarityType{models.RarityCommon, models.RarityUncommon, models.RarityRare}
+	case models.RarityEpic:
+		possibleRarities = []models.RarityType{models.RarityUncommon, models.RarityRare, models.RarityEpic}
+	case models.RarityLegendary:
+		possibleRarities = []models.RarityType{models.RarityRare, models.RarityEpic, models.RarityLegendary}
+	default:
+		return models.CustomizationItem{}, fmt.Errorf("%w: %q", ErrCaseRarityUnknown, caseRarity)
+	}
+
+	// Случайная редкость с весами
+	rarity := rollRarity(possibleRarities)
+
+	// Случайный тип предмета
+	itemTypes := []models.CustomizationItemType{
+		models.ItemTypeBackground,
+		models.ItemTypeNameColor,
+		models.ItemTypeAvatarFrame,
+		models.ItemTypeBadge,
+	}
+	itemType := itemTypes[rand.Intn(len(itemTypes))]
+
+	// Генерируем предмет
+	return models.CustomizationItem{
+		ItemID:   generateItemID(itemType, rarity),
+		ItemType: itemType,
+		Rarity:   rarity,
+		Name:     generateItemName(itemType, rarity),
+		Value:    generateItemValue(itemType),
+		Quantity: 1,
+	}, nil
+}
+
+func rollRarity(possible []models.RarityType) models.RarityType {
+	// Веса для редкостей
+	weights := map[models.RarityType]int{
+		models.RarityCommon:    50,
+		models.RarityUncommon:  30,
+		models.RarityRare:      15,
+		models.RarityEpic:      4,
+		models.RarityLegendary: 1,
+	}
+
+	totalWeight := 0
+	for _, r := range possible {
+		totalWeight += weights[r]
+	}
+
+	roll := rand.Intn(totalWeight)
+	cumulative := 0
+	for _, r := range possible {
+		cumulative += weights[r]
+		if roll < cumulative {
+			return r
+		}
+	}
+	return possible[0]
+}
+
+func generateItemID(itemType models.CustomizationItemType, rarity models.RarityType) string {
+	prefix := map[models.CustomizationItemType]string{
+		models.ItemTypeBackground:  "bg",
+		models.ItemTypeNameColor:   "color",
+		models.ItemTypeAvatarFrame: "frame",
+		models.ItemTypeBadge:       "badge",
+		models.ItemTypeTitle:       "title",
+		models.ItemTypeEffect:      "effect",
+	}
+	return prefix[itemType] + "_" + string(rarity) + "_" + randomString(6)
+}
+
+func generateItemName(itemType models.CustomizationItemType, rarity models.RarityType) string {
+	rarityNames := map[models.RarityType]string{
+		models.RarityCommon:    "Обычный",
+		models.RarityUncommon:  "Необычный",
+		models.RarityRare:      "Редкий",
+		models.RarityEpic:      "Эпический",
+		models.RarityLegendary: "Легендарный",
+	}
+	typeNames := map[models.CustomizationItemType]string{
+		models.ItemTypeBackground:  "фон",
+		models.ItemTypeNameColor:   "цвет ника",
+		models.ItemTypeAvatarFrame: "рамка",
+		models.ItemTypeBadge:       "значок",
+		models.ItemTypeTitle:       "титул",
+		models.ItemTypeEffect:      "эффект",
+	}
+	return rarityNames[rarity] + " " + typeNames[itemType]
+}
+
+func generateItemValue(itemType models.CustomizationItemType) string {
+	switch itemType {
+	case models.ItemTypeBackground:
+		gradients := []string{
+			"linear-gradient(135deg, #667eea 0%, #764ba2 100%)",
+			"linear-gradient(135deg, #f093fb 0%, #f5576c 100%)",
+			"linear-gradient(135deg, #43e97b 0%, #38f9d7 100%)",
+		}
+		return gradients[rand.Intn(len(gradients))]
+	case models.ItemTypeNameColor:
+		colors := []string{"#fbbf24", "#10b981", "#3b82f6", "#a855f7", "#ef4444"}
+		return colors[rand.Intn(len(colors))]
+	default:
+		return ""
+	}
+}
+
+func randomString(n int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[rand.Intn(len(letters))]
+	}
+	return string(b)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryInventory - InventoryRepository в памяти; транзакция - просто вызов fn
+type memoryInventory struct {
+	cases map[int64]*models.UserCase
+	items []models.CustomizationItem
+}
+
+func (m *memoryInventory) Transaction(_ context.Context, fn func(tx repositories.InventoryRepository) error) error {
+	return fn(m)
+}
+
+func (m *memoryInventory) GetUnopenedCase(_ context.Context, userID, caseID int64) (*models.UserCase, error) {
+	c, ok := m.cases[caseID]
+	if !ok || c.UserID != userID || c.IsOpened {
+		return nil, repositories.ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *memoryInventory) MarkCaseOpened(_ context.Context, userCase *models.UserCase, _ time.Time) error {
+	userCase.IsOpened = true
+	return nil
+}
+
+func (m *memoryInventory) CreateCase(_ context.Context, userCase *models.UserCase) error {
+	userCase.ID = int64(len(m.cases) + 1)
+	m.cases[userCase.ID] = userCase
+	return nil
+}
+
+func (m *memoryInventory) AddItem(_ context.Context, item models.CustomizationItem) (*models.CustomizationItem, bool, error) {
+	m.items = append(m.items, item)
+	return &item, true, nil
+}
+
+func TestCaseOpen(t *testing.T) {
+	ctx := context.Background()
+	inventory := &memoryInventory{cases: map[int64]*models.UserCase{
+		1: {ID: 1, UserID: 7, CaseType: "event", Rarity: models.RarityEpic},
+		2: {ID: 2, UserID: 7, CaseType: "event", Rarity: "mythic"},
+	}}
+	cases := NewCaseService(inventory)
+
+	item, isNew, err := cases.Open(ctx, 7, 1)
+	if err != nil || !isNew {
+		t.Fatalf("Open: %v", err)
+	}
+	// Из эпического кейса выпадает от необычного до эпического
+	switch item.Rarity {
+	case models.RarityUncommon, models.RarityRare, models.RarityEpic:
+	default:
+		t.Fatalf("epic case dropped %s", item.Rarity)
+	}
+	if item.UserID != 7 || !inventory.cases[1].IsOpened {
+		t.Fatalf("item %+v, case opened %v", item, inventory.cases[1].IsOpened)
+	}
+
+	if _, _, err := cases.Open(ctx, 7, 1); !errors.Is(err, ErrCaseNotFound) {
+		t.Fatalf("second open: %v", err)
+	}
+
+	// Неизвестная редкость - ошибка, а не паника; кейс остаётся неоткрытым
+	if _, _, err := cases.Open(ctx, 7, 2); !errors.Is(err, ErrCaseRarityUnknown) {
+		t.Fatalf("unknown rarity: %v", err)
+	}
+	if inventory.cases[2].IsOpened || len(inventory.items) != 1 {
+		t.Fatal("case with unknown rarity was opened")
+	}
+}
+
+func TestGenerateDroppedItemRarities(t *testing.T) {
+	for _, rarity := range []models.RarityType{
+		models.RarityCommon, models.RarityUncommon, models.RarityRare, models.RarityEpic, models.RarityLegendary,
+	} {
+		for i := 0; i < 50; i++ {
+			item, err := generateDroppedItem("event", rarity)
+			if err != nil {
+				t.Fatalf("%s: %v", rarity, err)
+			}
+			if !item.Rarity.IsValid() || item.Quantity != 1 || item.ItemID == "" {
+				t.Fatalf("%s: unexpected item %+v", rarity, item)
+			}
+		}
+	}
+	if _, err := generateDroppedItem("event", ""); !errors.Is(err, ErrCaseRarityUnknown) {
+		t.Fatalf("empty rarity: %v", err)
+	}
+}
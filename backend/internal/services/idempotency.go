@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyCache - записи Idempotency-Key в Redis.
+// Содержимое записи (отпечаток запроса, сохранённый ответ) формирует middleware.
+type IdempotencyCache struct {
+	redisClient *redis.Client
+}
+
+func NewIdempotencyCache(redisClient *redis.Client) *IdempotencyCache {
+	return &IdempotencyCache{redisClient: redisClient}
+}
+
+func idempotencyKey(key string) string { return "idempotency:" + key }
+
+// Reserve - занять ключ записью record, если он свободен.
+// Одна команда SET NX GET: из двух реплик, получивших повтор одновременно, ключ займёт одна.
+// Если ключ уже занят, возвращается его запись.
+func (c *IdempotencyCache) Reserve(ctx context.Context, key string, record []byte, ttl time.Duration) (existing []byte, reserved bool, err error) {
+	existing, err = c.redisClient.SetArgs(ctx, idempotencyKey(key), record, redis.SetArgs{
+		Mode: "NX",
+		TTL:  ttl,
+		Get:  true,
+	}).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	return existing, false, nil
+}
+
+// Save - заменить запись ключа (сохранить ответ) с новым TTL
+func (c *IdempotencyCache) Save(ctx context.Context, key string, record []byte, ttl time.Duration) error {
+	if err := c.redisClient.Set(ctx, idempotencyKey(key), record, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release - освободить ключ, чтобы запрос можно было повторить
+func (c *IdempotencyCache) Release(ctx context.Context, key string) error {
+	if err := c.redisClient.Del(ctx, idempotencyKey(key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyCache(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	cache := NewIdempotencyCache(client)
+
+	existing, reserved, err := cache.Reserve(ctx, "user:7:abc", []byte("pending"), time.Minute)
+	if err != nil || !reserved || existing != nil {
+		t.Fatalf("first reserve: existing=%q reserved=%v err=%v", existing, reserved, err)
+	}
+
+	// Занятый ключ не перезаписывается, возвращается его запись
+	existing, reserved, err = cache.Reserve(ctx, "user:7:abc", []byte("other"), time.Minute)
+	if err != nil || reserved || string(existing) != "pending" {
+		t.Fatalf("second reserve: existing=%q reserved=%v err=%v", existing, reserved, err)
+	}
+
+	if err := cache.Save(ctx, "user:7:abc", []byte("done"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("idempotency:user:7:abc"); ttl != time.Hour {
+		t.Fatalf("TTL after save %v, want 1h", ttl)
+	}
+	if existing, _, _ := cache.Reserve(ctx, "user:7:abc", []byte("pending"), time.Minute); string(existing) != "done" {
+		t.Fatalf("saved record %q", existing)
+	}
+
+	if err := cache.Release(ctx, "user:7:abc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, reserved, _ := cache.Reserve(ctx, "user:7:abc", []byte("pending"), time.Minute); !reserved {
+		t.Fatal("key not free after release")
+	}
+}
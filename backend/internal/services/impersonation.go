@@ -0,0 +1,131 @@
+package services
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultImpersonationTTL = 15 * time.Minute
+	MaxImpersonationTTL     = time.Hour
+)
+
+var (
+	ErrImpersonationNotFound = errors.New("impersonation session not found")
+	ErrImpersonationEnded    = errors.New("impersonation session has already ended")
+)
+
+// ImpersonationStore - сеансы имперсонации.
+// История хранится в Postgres, а признак активности - в Redis с TTL сеанса,
+// чтобы проверка на каждом запросе не ходила в базу.
+type ImpersonationStore struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+}
+
+func NewImpersonationStore(db *gorm.DB, redisClient *redis.Client) *ImpersonationStore {
+	return &ImpersonationStore{db: db, redisClient: redisClient}
+}
+
+func impersonationKey(id string) string { return "impersonation:" + id }
+
+// Start - открыть сеанс админа adminID от имени пользователя userID
+func (s *ImpersonationStore) Start(ctx context.Context, adminID, userID int64, reason string, ttl time.Duration, client SessionClient) (*models.ImpersonationSession, error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &models.ImpersonationSession{
+		ID:        id,
+		AdminID:   adminID,
+		UserID:    userID,
+		Reason:    reason,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return nil, fmt.Errorf("failed to save impersonation session: %w", err)
+	}
+	if err := s.redisClient.Set(ctx, impersonationKey(id), adminID, ttl).Err(); err != nil {
+		return nil, fmt.Errorf("failed to activate impersonation session: %w", err)
+	}
+
+	return session, nil
+}
+
+// IsImpersonationActive - не завершён ли сеанс досрочно
+func (s *ImpersonationStore) IsImpersonationActive(ctx context.Context, id string) (bool, error) {
+	n, err := s.redisClient.Exists(ctx, impersonationKey(id)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check impersonation session: %w", err)
+	}
+	return n > 0, nil
+}
+
+// End - завершить сеанс; токен имперсонации сразу перестаёт приниматься
+func (s *ImpersonationStore) End(ctx context.Context, id string) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	if err := s.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImpersonationNotFound
+		}
+		return nil, fmt.Errorf("failed to load impersonation session: %w", err)
+	}
+	if !session.IsActive(time.Now()) {
+		return nil, ErrImpersonationEnded
+	}
+
+	if err := s.redisClient.Del(ctx, impersonationKey(id)).Err(); err != nil {
+		return nil, fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if err := s.db.WithContext(ctx).Model(&session).Update("ended_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+	return &session, nil
+}
+
+// List - последние limit сеансов и сколько всего подходит; adminID и userID фильтруют, если не 0
+func (s *ImpersonationStore) List(ctx context.Context, adminID, userID int64, limit int) ([]models.ImpersonationSession, int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.ImpersonationSession{})
+	if adminID != 0 {
+		query = query.Where("admin_id = ?", adminID)
+	}
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count impersonation sessions: %w", err)
+	}
+
+	var sessions []models.ImpersonationSession
+	if err := query.Order("created_at DESC").Limit(limit).Find(&sessions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list impersonation sessions: %w", err)
+	}
+	return sessions, total, nil
+}
+
+// SessionChecker - проверка claim sid для middleware: обычные сессии живут
+// в RefreshTokenStore, сеансы имперсонации - в ImpersonationStore
+type SessionChecker struct {
+	*RefreshTokenStore
+	*ImpersonationStore
+}
+
+func NewSessionChecker(refreshTokens *RefreshTokenStore, impersonations *ImpersonationStore) *SessionChecker {
+	return &SessionChecker{RefreshTokenStore: refreshTokens, ImpersonationStore: impersonations}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestImpersonationSessionLifecycle(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t, &models.ImpersonationSession{})
+	client, mr := newTestRedis(t)
+	store := NewImpersonationStore(db, client)
+
+	session, err := store.Start(ctx, 1, 42, "support ticket", 10*time.Minute, SessionClient{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.ExpiresAt.Sub(session.CreatedAt) != 10*time.Minute || session.AdminID != 1 || session.UserID != 42 {
+		t.Fatalf("session %+v", session)
+	}
+	// Признак активности живёт ровно столько же, сколько токен
+	if ttl := mr.TTL(impersonationKey(session.ID)); ttl != 10*time.Minute {
+		t.Fatalf("redis ttl %v", ttl)
+	}
+	if active, err := store.IsImpersonationActive(ctx, session.ID); err != nil || !active {
+		t.Fatalf("new session inactive: %v", err)
+	}
+
+	ended, err := store.End(ctx, session.ID)
+	if err != nil || ended.ID != session.ID {
+		t.Fatalf("End: %+v, %v", ended, err)
+	}
+	if active, _ := store.IsImpersonationActive(ctx, session.ID); active {
+		t.Fatal("ended session still active")
+	}
+	var saved models.ImpersonationSession
+	if err := db.First(&saved, "id = ?", session.ID).Error; err != nil || saved.EndedAt == nil {
+		t.Fatalf("ended_at not saved: %+v, %v", saved, err)
+	}
+
+	if _, err := store.End(ctx, session.ID); !errors.Is(err, ErrImpersonationEnded) {
+		t.Fatalf("second End: %v", err)
+	}
+	if _, err := store.End(ctx, "missing"); !errors.Is(err, ErrImpersonationNotFound) {
+		t.Fatalf("unknown session: %v", err)
+	}
+}
+
+func TestImpersonationSessionExpires(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	store := NewImpersonationStore(testDB(t, &models.ImpersonationSession{}), client)
+
+	session, err := store.Start(ctx, 1, 42, "support ticket", time.Minute, SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(time.Minute)
+	if active, _ := store.IsImpersonationActive(ctx, session.ID); active {
+		t.Fatal("expired session still active")
+	}
+}
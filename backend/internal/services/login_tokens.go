@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Одноразовые токены входа, которые выдаёт Telegram бот.
+// Бот кладёт в Redis JSON под ключом login:token:<sha256(token)>, пользователь
+// вводит токен на сайте, и backend обменивает его на пару JWT ровно один раз.
+
+const (
+	// Версия формата, которую понимает backend
+	loginTokenVersion = 1
+	// Жёсткий предел жизни токена независимо от того, что записал бот
+	loginTokenMaxTTL = 10 * time.Minute
+
+	// После стольких неудачных попыток с одного IP вход блокируется на loginFailureWindow
+	loginMaxFailures   = 10
+	loginFailureWindow = 15 * time.Minute
+)
+
+var (
+	ErrLoginTokenInvalid          = errors.New("login token is invalid or already used")
+	ErrLoginTokenExpired          = errors.New("login token is expired")
+	ErrLoginTokenVerifierMismatch = errors.New("login token verifier mismatch")
+)
+
+// LoginTokenPayload - содержимое токена входа (формат v1)
+type LoginTokenPayload struct {
+	Version    int    `json:"v"`
+	TelegramID int64  `json:"telegramId"`
+	Username   string `json:"username"`
+	IssuedAt   int64  `json:"issuedAt"`
+	ExpiresAt  int64  `json:"expiresAt"`
+	// Challenge = base64url(sha256(verifier)); если задан, токен примет только браузер, знающий verifier
+	Challenge string `json:"challenge,omitempty"`
+}
+
+type LoginTokenStore struct {
+	redisClient *redis.Client
+}
+
+func NewLoginTokenStore(redisClient *redis.Client) *LoginTokenStore {
+	return &LoginTokenStore{redisClient: redisClient}
+}
+
+func loginTokenKey(token string) string { return "login:token:" + hashToken(token) }
+func loginFailKey(ip string) string     { return "login:fail:" + ip }
+
+// Consume - атомарно забрать токен (GETDEL) и проверить срок и verifier.
+// Токен сгорает при любой попытке, даже если verifier не подошёл.
+func (s *LoginTokenStore) Consume(ctx context.Context, token, verifier string) (*LoginTokenPayload, error) {
+	raw, err := s.redisClient.GetDel(ctx, loginTokenKey(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrLoginTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login token: %w", err)
+	}
+
+	var payload LoginTokenPayload
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil || payload.Version != loginTokenVersion || payload.TelegramID == 0 {
+		return nil, ErrLoginTokenInvalid
+	}
+
+	now := time.Now()
+	issuedAt := time.Unix(payload.IssuedAt, 0)
+	if now.After(time.Unix(payload.ExpiresAt, 0)) || now.Sub(issuedAt) > loginTokenMaxTTL {
+		return nil, ErrLoginTokenExpired
+	}
+
+	if payload.Challenge != "" {
+		sum := sha256.Sum256([]byte(verifier))
+		challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+		if verifier == "" || subtle.ConstantTimeCompare([]byte(challenge), []byte(payload.Challenge)) != 1 {
+			return nil, ErrLoginTokenVerifierMismatch
+		}
+	}
+
+	return &payload, nil
+}
+
+// IsBlocked - превышен ли лимит неудачных попыток для IP; возвращает, сколько ждать
+func (s *LoginTokenStore) IsBlocked(ctx context.Context, ip string) (bool, time.Duration, error) {
+	count, err := s.redisClient.Get(ctx, loginFailKey(ip)).Int()
+	if errors.Is(err, redis.Nil) {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to read login failures: %w", err)
+	}
+	if count < loginMaxFailures {
+		return false, 0, nil
+	}
+
+	ttl, err := s.redisClient.TTL(ctx, loginFailKey(ip)).Result()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to read login failures: %w", err)
+	}
+	return true, ttl, nil
+}
+
+// RecordFailure - учесть неудачную попытку; окно отсчитывается от первой ошибки
+func (s *LoginTokenStore) RecordFailure(ctx context.Context, ip string) error {
+	pipe := s.redisClient.TxPipeline()
+	pipe.Incr(ctx, loginFailKey(ip))
+	pipe.ExpireNX(ctx, loginFailKey(ip), loginFailureWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return nil
+}
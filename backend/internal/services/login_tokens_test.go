@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// putLoginToken - записать токен так, как это делает бот
+func putLoginToken(t *testing.T, mr *miniredis.Miniredis, token string, payload LoginTokenPayload) {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mr.Set(loginTokenKey(token), string(data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loginPayload(now time.Time) LoginTokenPayload {
+	return LoginTokenPayload{
+		Version:    loginTokenVersion,
+		TelegramID: 1001,
+		Username:   "anna",
+		IssuedAt:   now.Unix(),
+		ExpiresAt:  now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestLoginTokenConsume(t *testing.T) {
+	now := time.Now()
+	withChallenge := loginPayload(now)
+	withChallenge.Challenge = pkceChallenge("browser-verifier")
+
+	oldVersion := loginPayload(now)
+	oldVersion.Version = 0
+
+	noTelegram := loginPayload(now)
+	noTelegram.TelegramID = 0
+
+	expired := loginPayload(now.Add(-10 * time.Minute))
+
+	// Бот записал слишком долгий срок - действует предел loginTokenMaxTTL
+	tooLong := loginPayload(now.Add(-loginTokenMaxTTL - time.Minute))
+	tooLong.ExpiresAt = now.Add(time.Hour).Unix()
+
+	tests := []struct {
+		name     string
+		payload  LoginTokenPayload
+		verifier string
+		want     error
+	}{
+		{"valid", loginPayload(now), "", nil},
+		{"verifier not required but sent", loginPayload(now), "anything", nil},
+		{"valid verifier", withChallenge, "browser-verifier", nil},
+		{"wrong verifier", withChallenge, "other-verifier", ErrLoginTokenVerifierMismatch},
+		{"missing verifier", withChallenge, "", ErrLoginTokenVerifierMismatch},
+		{"unknown version", oldVersion, "", ErrLoginTokenInvalid},
+		{"no telegram id", noTelegram, "", ErrLoginTokenInvalid},
+		{"expired", expired, "", ErrLoginTokenExpired},
+		{"lifetime over the limit", tooLong, "", ErrLoginTokenExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mr := newTestRedis(t)
+			store := NewLoginTokenStore(client)
+			putLoginToken(t, mr, "tok", tt.payload)
+
+			payload, err := store.Consume(context.Background(), "tok", tt.verifier)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && payload.TelegramID != tt.payload.TelegramID {
+				t.Fatalf("unexpected payload %+v", payload)
+			}
+			// Токен сгорает при любой попытке
+			if mr.Exists(loginTokenKey("tok")) {
+				t.Fatal("token still stored after Consume")
+			}
+		})
+	}
+}
+
+func TestLoginTokenSingleUse(t *testing.T) {
+	client, mr := newTestRedis(t)
+	store := NewLoginTokenStore(client)
+	putLoginToken(t, mr, "tok", loginPayload(time.Now()))
+
+	if _, err := store.Consume(context.Background(), "tok", ""); err != nil {
+		t.Fatalf("first Consume: %v", err)
+	}
+	if _, err := store.Consume(context.Background(), "tok", ""); !errors.Is(err, ErrLoginTokenInvalid) {
+		t.Fatalf("second Consume: got %v, want ErrLoginTokenInvalid", err)
+	}
+}
+
+func TestLoginTokenMalformed(t *testing.T) {
+	client, mr := newTestRedis(t)
+	store := NewLoginTokenStore(client)
+	mr.Set(loginTokenKey("tok"), "not json")
+
+	if _, err := store.Consume(context.Background(), "tok", ""); !errors.Is(err, ErrLoginTokenInvalid) {
+		t.Fatalf("got %v, want ErrLoginTokenInvalid", err)
+	}
+}
+
+func TestLoginFailureLockout(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	store := NewLoginTokenStore(client)
+
+	for i := 0; i < loginMaxFailures; i++ {
+		if blocked, _, err := store.IsBlocked(ctx, "10.0.0.1"); err != nil || blocked {
+			t.Fatalf("blocked after %d failures (err %v)", i, err)
+		}
+		if err := store.RecordFailure(ctx, "10.0.0.1"); err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+		// Окно считается от первой ошибки и не продлевается следующими
+		mr.FastForward(time.Second)
+	}
+
+	blocked, wait, err := store.IsBlocked(ctx, "10.0.0.1")
+	if err != nil || !blocked {
+		t.Fatalf("IsBlocked = %v, %v; want blocked", blocked, err)
+	}
+	if wait <= 0 || wait > loginFailureWindow-time.Duration(loginMaxFailures)*time.Second+time.Second {
+		t.Fatalf("unexpected wait %v", wait)
+	}
+	if blocked, _, _ := store.IsBlocked(ctx, "10.0.0.2"); blocked {
+		t.Fatal("another IP is blocked")
+	}
+
+	mr.FastForward(loginFailureWindow)
+	if blocked, _, _ := store.IsBlocked(ctx, "10.0.0.1"); blocked {
+		t.Fatal("still blocked after the window")
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"backend/internal/logging"
+	"backend/internal/metrics"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	ErrInvalidSwipeAction = errors.New("action must be 'like' or 'pass'")
+	ErrAlreadySwiped      = errors.New("already swiped on this user")
+)
+
+// SwipeResult - чем закончился свайп
+type SwipeResult struct {
+	Swipe *models.Swipe
+	// Team - команда, где свайпающий капитан; nil для соло-пользователя
+	Team *models.Team
+	// Invite - приглашение, автоматически отправленное капитаном после лайка
+	Invite *models.TeamInvite
+	// Match - взаимный лайк
+	Match *models.Match
+}
+
+// MatchingService - свайпы, мэтчи и автоприглашения капитанов
+type MatchingService struct {
+	teams         repositories.TeamRepository
+	swipes        repositories.SwipeRepository
+	notifications repositories.NotificationRepository
+	membership    *TeamMembershipService
+}
+
+func NewMatchingService(teams repositories.TeamRepository, swipes repositories.SwipeRepository, notifications repositories.NotificationRepository, membership *TeamMembershipService) *MatchingService {
+	return &MatchingService{
+		teams:         teams,
+		swipes:        swipes,
+		notifications: notifications,
+		membership:    membership,
+	}
+}
+
+// Swipe - лайк или пропуск пользователя targetUserID.
+// Капитан свайпает от имени своей команды, остальные - от своего имени.
+// Лайк капитана сразу отправляет приглашение в команду, взаимный лайк создаёт мэтч.
+func (s *MatchingService) Swipe(ctx context.Context, userID, targetUserID int64, action string) (*SwipeResult, error) {
+	if action != "like" && action != "pass" {
+		return nil, ErrInvalidSwipeAction
+	}
+
+	result := &SwipeResult{}
+	swiperID := userID
+	team, err := s.teams.GetCaptainedBy(ctx, userID)
+	switch {
+	case err == nil:
+		result.Team = team
+		swiperID = team.ID
+	case !errors.Is(err, repositories.ErrNotFound):
+		return nil, fmt.Errorf("failed to load captained team: %w", err)
+	}
+
+	exists, err := s.swipes.Exists(ctx, swiperID, targetUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check swipe: %w", err)
+	}
+	if exists {
+		return nil, ErrAlreadySwiped
+	}
+
+	result.Swipe = &models.Swipe{
+		SwiperTeamID: swiperID,
+		TargetUserID: targetUserID,
+		Action:       action,
+	}
+	if err := s.swipes.Create(ctx, result.Swipe); err != nil {
+		return nil, fmt.Errorf("failed to save swipe: %w", err)
+	}
+	metrics.Swipes.WithLabelValues(action).Inc()
+
+	if action != "like" {
+		return result, nil
+	}
+
+	if result.Team != nil {
+		result.Invite = s.autoInvite(ctx, result.Team, userID, targetUserID)
+	}
+
+	// Взаимный лайк: цель свайпнула текущего пользователя
+	liked, err := s.swipes.HasLiked(ctx, targetUserID, userID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to check reverse swipe", logging.Err(err))
+		return result, nil
+	}
+	if liked {
+		match := &models.Match{TeamID: swiperID, UserID: targetUserID}
+		if err := s.swipes.CreateMatch(ctx, match); err != nil {
+			logging.FromContext(ctx).Error("failed to create match", logging.Err(err))
+			return result, nil
+		}
+		result.Match = match
+		metrics.MatchesCreated.Inc()
+	}
+
+	return result, nil
+}
+
+// autoInvite - приглашение от капитана после лайка, если цель ещё без команды и приглашения нет
+func (s *MatchingService) autoInvite(ctx context.Context, team *models.Team, captainID, targetUserID int64) *models.TeamInvite {
+	current, err := s.membership.TeamForHackathon(ctx, targetUserID, team.HackathonID)
+	if err != nil || current != nil {
+		return nil
+	}
+	if _, err := s.teams.GetPendingInvite(ctx, team.ID, targetUserID); !errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+
+	invite := &models.TeamInvite{
+		TeamID:        team.ID,
+		InvitedUserID: targetUserID,
+		InviterID:     captainID,
+		Status:        "pending",
+	}
+	if err := s.teams.CreateInvite(ctx, invite); err != nil {
+		logging.FromContext(ctx).Error("failed to create invite", logging.Err(err))
+		return nil
+	}
+	metrics.InvitesSent.WithLabelValues("swipe").Inc()
+	logging.FromContext(ctx).Info("auto-created invite", slog.Int64("invite_id", invite.ID), slog.Int64("target_user_id", targetUserID), logging.TeamID(team.ID))
+	return invite
+}
+
+// NotifyMatch - уведомления в приложении обоим участникам мэтча
+func (s *MatchingService) NotifyMatch(ctx context.Context, match *models.Match, user, matchedUser *models.User) {
+	pairs := []struct {
+		to, from *models.User
+	}{
+		{matchedUser, user},
+		{user, matchedUser},
+	}
+	for _, p := range pairs {
+		data, _ := json.Marshal(map[string]interface{}{
+			"matchId":      match.ID,
+			"fromUserId":   p.from.ID,
+			"fromUserName": p.from.Name,
+		})
+		notification := &models.Notification{
+			UserID:  p.to.ID,
+			Type:    models.NotificationTypeMatch,
+			Title:   "Новый мэтч! 🎉",
+			Message: p.from.Name + " тоже хочет с тобой в команду!",
+			Data:    data,
+		}
+		if err := s.notifications.Create(ctx, notification); err != nil {
+			logging.FromContext(ctx).Error("failed to create match notification", logging.Err(err))
+		}
+	}
+}
+
+// Matches - мэтчи пользователя
+func (s *MatchingService) Matches(ctx context.Context, userID int64) ([]models.Match, error) {
+	return s.swipes.ListMatches(ctx, userID)
+}
+
+// Preferences - настройки подбора на хакатоне; nil, если пользователь их не задавал
+func (s *MatchingService) Preferences(ctx context.Context, userID, hackathonID int64) (*models.SwipePreference, error) {
+	pref, err := s.swipes.GetPreference(ctx, userID, hackathonID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	return pref, err
+}
+
+// SavePreferences - создать или перезаписать настройки подбора
+func (s *MatchingService) SavePreferences(ctx context.Context, pref *models.SwipePreference) error {
+	return s.swipes.SavePreference(ctx, pref)
+}
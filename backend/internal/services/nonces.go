@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NonceCache - одноразовые nonce подписанных запросов в Redis
+type NonceCache struct {
+	redisClient *redis.Client
+}
+
+func NewNonceCache(redisClient *redis.Client) *NonceCache {
+	return &NonceCache{redisClient: redisClient}
+}
+
+// UseNonce - запомнить nonce; false, если он уже использовался
+func (n *NonceCache) UseNonce(ctx context.Context, serviceID, nonce string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("service:nonce:%s:%s", serviceID, nonce)
+	ok, err := n.redisClient.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to store nonce: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNonceCacheUseNonce(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	nonces := NewNonceCache(client)
+
+	use := func(serviceID, nonce string) bool {
+		t.Helper()
+		fresh, err := nonces.UseNonce(ctx, serviceID, nonce, time.Minute)
+		if err != nil {
+			t.Fatalf("UseNonce: %v", err)
+		}
+		return fresh
+	}
+
+	if !use("tgbot", "n1") {
+		t.Fatal("new nonce rejected")
+	}
+	if use("tgbot", "n1") {
+		t.Fatal("repeated nonce accepted")
+	}
+	// Nonce у каждого сервиса свои
+	if !use("other", "n1") {
+		t.Fatal("same nonce of another service rejected")
+	}
+
+	mr.FastForward(2 * time.Minute)
+	if !use("tgbot", "n1") {
+		t.Fatal("nonce not released after its TTL")
+	}
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"backend/internal/logging"
+	"backend/internal/metrics"
+	"backend/internal/tracing"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,15 +12,28 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// NotificationStream - Redis stream, из которого Telegram бот читает события
+const NotificationStream = "notifications"
+
+// StreamEntry - поля записи stream: событие в data и контекст трейса
+// (traceparent, tracestate), чтобы потребитель мог продолжить трейс
+func StreamEntry(ctx context.Context, data []byte) map[string]interface{} {
+	values := map[string]interface{}{
+		"data": string(data),
+	}
+	for k, v := range tracing.Inject(ctx) {
+		values[k] = v
+	}
+	return values
+}
+
 type NotificationService struct {
 	redisClient *redis.Client
-	ctx         context.Context
 }
 
 func NewNotificationService(redisClient *redis.Client) *NotificationService {
 	return &NotificationService{
 		redisClient: redisClient,
-		ctx:         context.Background(),
 	}
 }
 
@@ -27,8 +43,9 @@ type NotificationEvent struct {
 	Data    map[string]interface{} `json:"data,omitempty"`
 }
 
-func (ns *NotificationService) PublishNotification(event NotificationEvent) error {
-	streamName := "notifications"
+// PublishNotification - записать событие в stream; ID HTTP запроса из ctx уходит
+// в поле httpRequestId, чтобы связать логи backend и бота
+func (ns *NotificationService) PublishNotification(ctx context.Context, event NotificationEvent) error {
 
 	// Prepare notification data
 	notificationData := map[string]interface{}{
@@ -37,6 +54,9 @@ func (ns *NotificationService) PublishNotification(event NotificationEvent) erro
 		"data":      event.Data,
 		"timestamp": time.Now().Unix(),
 	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		notificationData["httpRequestId"] = requestID
+	}
 
 	jsonData, err := json.Marshal(notificationData)
 	if err != nil {
@@ -44,24 +64,23 @@ func (ns *NotificationService) PublishNotification(event NotificationEvent) erro
 	}
 
 	args := redis.XAddArgs{
-		Stream: streamName,
-		Values: map[string]interface{}{
-			"data": string(jsonData),
-		},
+		Stream: NotificationStream,
+		Values: StreamEntry(ctx, jsonData),
 		MaxLen: 10000,
 		Approx: true,
 	}
 
-	_, err = ns.redisClient.XAdd(ns.ctx, &args).Result()
+	_, err = ns.redisClient.XAdd(ctx, &args).Result()
 	if err != nil {
 		return fmt.Errorf("failed to write to Redis Stream: %w", err)
 	}
+	metrics.NotificationsPublished.WithLabelValues(event.Type).Inc()
 
 	return nil
 }
 
-func (ns *NotificationService) NotifyTeamInvite(teamID, invitedUserID, inviterID int64, teamName string) error {
-	return ns.PublishNotification(NotificationEvent{
+func (ns *NotificationService) NotifyTeamInvite(ctx context.Context, teamID, invitedUserID, inviterID int64, teamName string) error {
+	return ns.PublishNotification(ctx, NotificationEvent{
 		Type:    "team_invite",
 		Message: fmt.Sprintf("You've been invited to join team '%s'", teamName),
 		Data: map[string]interface{}{
@@ -73,8 +92,8 @@ func (ns *NotificationService) NotifyTeamInvite(teamID, invitedUserID, inviterID
 	})
 }
 
-func (ns *NotificationService) NotifyJoinRequest(teamID, userID int64, userName, teamName string) error {
-	return ns.PublishNotification(NotificationEvent{
+func (ns *NotificationService) NotifyJoinRequest(ctx context.Context, teamID, userID int64, userName, teamName string) error {
+	return ns.PublishNotification(ctx, NotificationEvent{
 		Type:    "join_request",
 		Message: fmt.Sprintf("%s wants to join your team '%s'", userName, teamName),
 		Data: map[string]interface{}{
@@ -86,8 +105,8 @@ func (ns *NotificationService) NotifyJoinRequest(teamID, userID int64, userName,
 	})
 }
 
-func (ns *NotificationService) NotifyMatch(teamID, userID int64, teamName, userName string) error {
-	return ns.PublishNotification(NotificationEvent{
+func (ns *NotificationService) NotifyMatch(ctx context.Context, teamID, userID int64, teamName, userName string) error {
+	return ns.PublishNotification(ctx, NotificationEvent{
 		Type:    "match",
 		Message: fmt.Sprintf("🎉 Match! Team '%s' and %s liked each other!", teamName, userName),
 		Data: map[string]interface{}{
@@ -100,8 +119,8 @@ func (ns *NotificationService) NotifyMatch(teamID, userID int64, teamName, userN
 }
 
 // SendMatchNotification sends a match notification to a specific user
-func (ns *NotificationService) SendMatchNotification(userID int64, matchedUserName string) error {
-	return ns.PublishNotification(NotificationEvent{
+func (ns *NotificationService) SendMatchNotification(ctx context.Context, userID int64, matchedUserName string) error {
+	return ns.PublishNotification(ctx, NotificationEvent{
 		Type:    "match",
 		Message: fmt.Sprintf("🎉 Новый мэтч! %s тоже хочет с тобой в команду!", matchedUserName),
 		Data: map[string]interface{}{
@@ -111,8 +130,8 @@ func (ns *NotificationService) SendMatchNotification(userID int64, matchedUserNa
 	})
 }
 
-func (ns *NotificationService) NotifyCaseOpened(userID, caseID int64, items []map[string]interface{}) error {
-	return ns.PublishNotification(NotificationEvent{
+func (ns *NotificationService) NotifyCaseOpened(ctx context.Context, userID, caseID int64, items []map[string]interface{}) error {
+	return ns.PublishNotification(ctx, NotificationEvent{
 		Type:    "case_opened",
 		Message: "You opened a case! Check your inventory for new items.",
 		Data: map[string]interface{}{
@@ -123,8 +142,8 @@ func (ns *NotificationService) NotifyCaseOpened(userID, caseID int64, items []ma
 	})
 }
 
-func (ns *NotificationService) NotifyHackathonAnnouncement(hackathonID int64, title, message string) error {
-	return ns.PublishNotification(NotificationEvent{
+func (ns *NotificationService) NotifyHackathonAnnouncement(ctx context.Context, hackathonID int64, title, message string) error {
+	return ns.PublishNotification(ctx, NotificationEvent{
 		Type:    "hackathon_announcement",
 		Message: fmt.Sprintf("📢 %s: %s", title, message),
 		Data: map[string]interface{}{
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript - скользящее окно на sorted set: по записи на запрос со временем в score.
+// Время берётся из Redis (TIME), поэтому расхождение часов между репликами backend не влияет,
+// а весь подсчёт - одна атомарная операция.
+//
+// KEYS[1] - ключ окна; ARGV: лимит, окно в мс, уникальный член.
+// Возвращает {разрешён 0/1, осталось, мс до освобождения ближайшего места}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[3])
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+// RateLimiter - ограничение частоты запросов в Redis, общее для всех реплик backend
+type RateLimiter struct {
+	redisClient *redis.Client
+}
+
+func NewRateLimiter(redisClient *redis.Client) *RateLimiter {
+	return &RateLimiter{redisClient: redisClient}
+}
+
+// Allow - учесть запрос в окне key; запрещённые запросы в окно не записываются.
+// reset - через сколько освободится ближайшее место в окне.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, reset time.Duration, err error) {
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return false, 0, 0, fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+
+	values, err := slidingWindowScript.Run(ctx, l.redisClient,
+		[]string{"ratelimit:" + key},
+		limit, window.Milliseconds(), hex.EncodeToString(member),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if len(values) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected rate limit reply: %v", values)
+	}
+
+	return values[0] == 1, int(values[1]), time.Duration(values[2]) * time.Millisecond, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	limiter := NewRateLimiter(client)
+
+	// Время в скрипте берётся из TIME, поэтому двигаем часы miniredis
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	mr.SetTime(start)
+
+	for i := 0; i < 3; i++ {
+		allowed, remaining, _, err := limiter.Allow(ctx, "login:ip:10.0.0.1", 3, time.Minute)
+		if err != nil || !allowed || remaining != 2-i {
+			t.Fatalf("request %d: allowed=%v remaining=%d err=%v", i+1, allowed, remaining, err)
+		}
+		mr.SetTime(start.Add(time.Duration(i+1) * 10 * time.Second))
+	}
+
+	// Четвёртый запрос в окне отклонён; место освободится, когда выпадет первый
+	allowed, remaining, reset, err := limiter.Allow(ctx, "login:ip:10.0.0.1", 3, time.Minute)
+	if err != nil || allowed || remaining != 0 {
+		t.Fatalf("over limit: allowed=%v remaining=%d err=%v", allowed, remaining, err)
+	}
+	if reset != 30*time.Second {
+		t.Fatalf("reset %v, want 30s", reset)
+	}
+
+	// Отклонённые запросы не занимают места: после выхода первого из окна проходит ровно один
+	mr.SetTime(start.Add(time.Minute + time.Second))
+	if allowed, _, _, _ := limiter.Allow(ctx, "login:ip:10.0.0.1", 3, time.Minute); !allowed {
+		t.Fatal("request rejected after the oldest one left the window")
+	}
+	if allowed, _, _, _ := limiter.Allow(ctx, "login:ip:10.0.0.1", 3, time.Minute); allowed {
+		t.Fatal("window holds more than the limit")
+	}
+
+	// Окна разных ключей независимы
+	if allowed, remaining, _, _ := limiter.Allow(ctx, "login:ip:10.0.0.2", 3, time.Minute); !allowed || remaining != 2 {
+		t.Fatalf("another key: allowed=%v remaining=%d", allowed, remaining)
+	}
+}
+
+func TestRateLimiterKeyExpires(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	limiter := NewRateLimiter(client)
+
+	if _, _, _, err := limiter.Allow(ctx, "api:user:7", 5, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("ratelimit:api:user:7"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("window key TTL %v, want up to a minute", ttl)
+	}
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// sessionSeenInterval - как часто IsSessionActive обновляет время последнего запроса:
+// точнее для списка сессий не нужно, а писать в Redis на каждый запрос незачем
+const sessionSeenInterval = time.Minute
+
+// rotateFamilyScript - обновить семейство и сохранить новый токен, только если семейство
+// ещё существует. Проверка и запись - одна атомарная операция: отзыв, пришедший во время
+// Rotate, не может быть перезаписан и не оставит живой токен.
+//
+// KEYS: ключ семейства, индекс сессий пользователя, ключ нового токена.
+// ARGV: семейство (JSON), TTL в мс, ID семейства, запись нового токена (JSON).
+// Возвращает 1, если семейство обновлено, 0 - если его уже отозвали.
+var rotateFamilyScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+redis.call('SADD', KEYS[2], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+redis.call('SET', KEYS[3], ARGV[4], 'PX', ARGV[2])
+return 1
+`)
+
+// RefreshTokenStore хранит refresh токены в Redis.
+// Каждый логин открывает семейство (family) токенов: при каждом обновлении
+// старый токен сгорает и выдаётся новый из того же семейства. Повторное
+// предъявление уже использованного токена отзывает всё семейство.
+//
+// Семейство - это и есть сессия пользователя: его ID попадает в claim sid
+// access токена, и после отзыва семейства access токен тоже перестаёт приниматься.
+type RefreshTokenStore struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+func NewRefreshTokenStore(redisClient *redis.Client, ttl time.Duration) *RefreshTokenStore {
+	return &RefreshTokenStore{
+		redisClient: redisClient,
+		ttl:         ttl,
+	}
+}
+
+// RefreshTokenFamily - семейство refresh токенов одного логина (сессия)
+type RefreshTokenFamily struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"userId"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+}
+
+// SessionClient - откуда пришёл логин или обновление токена
+type SessionClient struct {
+	UserAgent string
+	IP        string
+}
+
+type refreshTokenRecord struct {
+	UserID   int64  `json:"userId"`
+	FamilyID string `json:"familyId"`
+}
+
+func refreshTokenKey(hash string) string { return "refresh:token:" + hash }
+func refreshUsedKey(hash string) string  { return "refresh:used:" + hash }
+func refreshFamilyKey(id string) string  { return "refresh:family:" + id }
+func refreshSeenKey(id string) string    { return "refresh:seen:" + id }
+func refreshUserKey(userID int64) string { return fmt.Sprintf("refresh:user:%d", userID) }
+
+// Issue - открыть новое семейство и выдать первый refresh токен
+func (s *RefreshTokenStore) Issue(ctx context.Context, userID int64, client SessionClient) (string, *RefreshTokenFamily, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	family := &RefreshTokenFamily{
+		ID:         familyID,
+		UserID:     userID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+	}
+	if err := s.saveFamily(ctx, family); err != nil {
+		return "", nil, err
+	}
+
+	token, err := s.issueToken(ctx, userID, familyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, family, nil
+}
+
+// Rotate - обменять refresh токен на новый из того же семейства
+func (s *RefreshTokenStore) Rotate(ctx context.Context, token string, client SessionClient) (string, *RefreshTokenFamily, error) {
+	hash := hashToken(token)
+
+	// GETDEL гарантирует, что токен можно использовать ровно один раз
+	raw, err := s.redisClient.GetDel(ctx, refreshTokenKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		// Токен уже был использован - вероятно, его украли. Отзываем всё семейство.
+		familyID, usedErr := s.redisClient.Get(ctx, refreshUsedKey(hash)).Result()
+		if usedErr == nil {
+			if err := s.RevokeFamily(ctx, familyID); err != nil {
+				return "", nil, err
+			}
+			return "", nil, ErrRefreshTokenReused
+		}
+		return "", nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read refresh token: %w", err)
+	}
+
+	var record refreshTokenRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+
+	family, err := s.GetFamily(ctx, record.FamilyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.redisClient.Set(ctx, refreshUsedKey(hash), record.FamilyID, s.ttl).Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	// Продлеваем сессию и запоминаем, откуда её обновили
+	family.LastUsedAt = time.Now()
+	family.UserAgent = client.UserAgent
+	family.IP = client.IP
+
+	newToken, tokenRecord, err := newRefreshToken(record.UserID, record.FamilyID)
+	if err != nil {
+		return "", nil, err
+	}
+	updated, err := s.rotateFamily(ctx, family, newToken, tokenRecord)
+	if err != nil {
+		return "", nil, err
+	}
+	if !updated {
+		// Сессию отозвали, пока токен обменивался
+		return "", nil, ErrRefreshTokenInvalid
+	}
+
+	return newToken, family, nil
+}
+
+// rotateFamily - сохранить обновлённое семейство и новый токен, если семейство не отозвано
+func (s *RefreshTokenStore) rotateFamily(ctx context.Context, family *RefreshTokenFamily, token string, tokenRecord []byte) (bool, error) {
+	familyJSON, err := json.Marshal(family)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal refresh family: %w", err)
+	}
+
+	updated, err := rotateFamilyScript.Run(ctx, s.redisClient,
+		[]string{refreshFamilyKey(family.ID), refreshUserKey(family.UserID), refreshTokenKey(hashToken(token))},
+		familyJSON, s.ttl.Milliseconds(), family.ID, tokenRecord,
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to store refresh family: %w", err)
+	}
+	return updated == 1, nil
+}
+
+// GetFamily - получить активное семейство (ErrRefreshTokenInvalid, если отозвано или истекло)
+func (s *RefreshTokenStore) GetFamily(ctx context.Context, familyID string) (*RefreshTokenFamily, error) {
+	raw, err := s.redisClient.Get(ctx, refreshFamilyKey(familyID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refresh family: %w", err)
+	}
+
+	var family RefreshTokenFamily
+	if err := json.Unmarshal([]byte(raw), &family); err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	if seen, err := s.redisClient.Get(ctx, refreshSeenKey(familyID)).Int64(); err == nil {
+		if t := time.Unix(seen, 0); t.After(family.LastUsedAt) {
+			family.LastUsedAt = t
+		}
+	}
+	return &family, nil
+}
+
+// ListFamilies - активные сессии пользователя, новые первыми
+func (s *RefreshTokenStore) ListFamilies(ctx context.Context, userID int64) ([]RefreshTokenFamily, error) {
+	ids, err := s.redisClient.SMembers(ctx, refreshUserKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	families := make([]RefreshTokenFamily, 0, len(ids))
+	for _, id := range ids {
+		family, err := s.GetFamily(ctx, id)
+		if errors.Is(err, ErrRefreshTokenInvalid) {
+			// Семейство истекло само по TTL - чистим индекс
+			s.redisClient.SRem(ctx, refreshUserKey(userID), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, *family)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].CreatedAt.After(families[j].CreatedAt)
+	})
+	return families, nil
+}
+
+// IsSessionActive - не отозвана ли сессия; заодно отмечает время последнего запроса,
+// но не чаще раза в sessionSeenInterval
+func (s *RefreshTokenStore) IsSessionActive(ctx context.Context, familyID string) (bool, error) {
+	pipe := s.redisClient.Pipeline()
+	exists := pipe.Exists(ctx, refreshFamilyKey(familyID))
+	seen := pipe.Get(ctx, refreshSeenKey(familyID))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	if exists.Val() == 0 {
+		return false, nil
+	}
+
+	now := time.Now()
+	if last, err := seen.Int64(); err != nil || now.Sub(time.Unix(last, 0)) >= sessionSeenInterval {
+		s.redisClient.Set(ctx, refreshSeenKey(familyID), now.Unix(), s.ttl)
+	}
+	return true, nil
+}
+
+// Revoke - отозвать семейство, к которому принадлежит refresh токен
+func (s *RefreshTokenStore) Revoke(ctx context.Context, token string) error {
+	hash := hashToken(token)
+
+	raw, err := s.redisClient.GetDel(ctx, refreshTokenKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read refresh token: %w", err)
+	}
+
+	var record refreshTokenRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return ErrRefreshTokenInvalid
+	}
+
+	return s.RevokeFamily(ctx, record.FamilyID)
+}
+
+// RevokeFamily - отозвать все токены семейства.
+// Токены самого семейства истекут сами: без ключа семейства они не принимаются.
+func (s *RefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	family, err := s.GetFamily(ctx, familyID)
+	if errors.Is(err, ErrRefreshTokenInvalid) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, refreshFamilyKey(familyID), refreshSeenKey(familyID))
+	pipe.SRem(ctx, refreshUserKey(family.UserID), familyID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to revoke refresh family: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllForUser - выйти везде; exceptFamilyID (если задан) остаётся активной.
+// Возвращает число отозванных сессий.
+func (s *RefreshTokenStore) RevokeAllForUser(ctx context.Context, userID int64, exceptFamilyID string) (int, error) {
+	families, err := s.ListFamilies(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, family := range families {
+		if family.ID == exceptFamilyID {
+			continue
+		}
+		if err := s.RevokeFamily(ctx, family.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+func (s *RefreshTokenStore) saveFamily(ctx context.Context, family *RefreshTokenFamily) error {
+	familyJSON, err := json.Marshal(family)
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh family: %w", err)
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, refreshFamilyKey(family.ID), familyJSON, s.ttl)
+	pipe.SAdd(ctx, refreshUserKey(family.UserID), family.ID)
+	pipe.Expire(ctx, refreshUserKey(family.UserID), s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store refresh family: %w", err)
+	}
+	return nil
+}
+
+func (s *RefreshTokenStore) issueToken(ctx context.Context, userID int64, familyID string) (string, error) {
+	token, recordJSON, err := newRefreshToken(userID, familyID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.redisClient.Set(ctx, refreshTokenKey(hashToken(token)), recordJSON, s.ttl).Err(); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// newRefreshToken - случайный токен и его запись для Redis
+func newRefreshToken(userID int64, familyID string) (string, []byte, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	recordJSON, err := json.Marshal(refreshTokenRecord{UserID: userID, FamilyID: familyID})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal refresh token: %w", err)
+	}
+	return token, recordJSON, nil
+}
+
+// В Redis храним только хеш токена, чтобы дамп базы не давал готовых токенов
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis - клиент к miniredis, закрывается вместе с тестом
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, mr
+}
+
+func TestRefreshTokenRotate(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRedis(t)
+	store := NewRefreshTokenStore(client, time.Hour)
+
+	token, family, err := store.Issue(ctx, 7, SessionClient{UserAgent: "ua-1", IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	rotated, rotatedFamily, err := store.Rotate(ctx, token, SessionClient{UserAgent: "ua-2", IP: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if rotated == token {
+		t.Fatal("Rotate returned the same token")
+	}
+	if rotatedFamily.ID != family.ID || rotatedFamily.UserID != 7 {
+		t.Fatalf("rotated into another family: %+v", rotatedFamily)
+	}
+	if rotatedFamily.UserAgent != "ua-2" || rotatedFamily.IP != "10.0.0.2" {
+		t.Fatalf("session client not updated: %+v", rotatedFamily)
+	}
+
+	// Новый токен продолжает цепочку
+	if _, _, err := store.Rotate(ctx, rotated, SessionClient{}); err != nil {
+		t.Fatalf("Rotate of the new token: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRedis(t)
+	store := NewRefreshTokenStore(client, time.Hour)
+
+	token, family, err := store.Issue(ctx, 7, SessionClient{})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	rotated, _, err := store.Rotate(ctx, token, SessionClient{})
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	// Старый токен предъявлен повторно - семейство отзывается целиком
+	if _, _, err := store.Rotate(ctx, token, SessionClient{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse: got %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := store.Rotate(ctx, rotated, SessionClient{}); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("token of revoked family: got %v, want ErrRefreshTokenInvalid", err)
+	}
+	if active, err := store.IsSessionActive(ctx, family.ID); err != nil || active {
+		t.Fatalf("IsSessionActive after reuse = %v, %v; want false", active, err)
+	}
+}
+
+func TestRefreshTokenUnknown(t *testing.T) {
+	client, _ := newTestRedis(t)
+	store := NewRefreshTokenStore(client, time.Hour)
+
+	if _, _, err := store.Rotate(context.Background(), "not-a-token", SessionClient{}); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("got %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestRefreshTokenRevoke(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRedis(t)
+	store := NewRefreshTokenStore(client, time.Hour)
+
+	token, family, err := store.Issue(ctx, 7, SessionClient{})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if err := store.Revoke(ctx, token); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := store.GetFamily(ctx, family.ID); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("GetFamily after logout: got %v, want ErrRefreshTokenInvalid", err)
+	}
+	if err := store.Revoke(ctx, token); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("second Revoke: got %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestRefreshTokenExpires(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	store := NewRefreshTokenStore(client, time.Hour)
+
+	token, _, err := store.Issue(ctx, 7, SessionClient{})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	mr.FastForward(2 * time.Hour)
+	if _, _, err := store.Rotate(ctx, token, SessionClient{}); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("expired token: got %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestRefreshTokenRotateAfterRevoke(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	store := NewRefreshTokenStore(client, time.Hour)
+
+	_, family, err := store.Issue(ctx, 7, SessionClient{})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// Rotate прочитал семейство, и в этот момент пользователь вышел везде
+	if _, err := store.RevokeAllForUser(ctx, 7, ""); err != nil {
+		t.Fatalf("RevokeAllForUser: %v", err)
+	}
+
+	token, record, err := newRefreshToken(7, family.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := store.rotateFamily(ctx, family, token, record)
+	if err != nil {
+		t.Fatalf("rotateFamily: %v", err)
+	}
+	if updated {
+		t.Fatal("revoked family was written back")
+	}
+	if mr.Exists(refreshFamilyKey(family.ID)) || mr.Exists(refreshTokenKey(hashToken(token))) {
+		t.Fatal("revoked session or its new token exists in Redis")
+	}
+	if members, _ := mr.Members(refreshUserKey(7)); len(members) != 0 {
+		t.Fatalf("user index still lists %v", members)
+	}
+}
+
+func TestIsSessionActiveThrottlesLastSeen(t *testing.T) {
+	ctx := context.Background()
+	client, mr := newTestRedis(t)
+	store := NewRefreshTokenStore(client, time.Hour)
+
+	_, family, err := store.Issue(ctx, 7, SessionClient{})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	seenKey := refreshSeenKey(family.ID)
+
+	if active, err := store.IsSessionActive(ctx, family.ID); err != nil || !active {
+		t.Fatalf("IsSessionActive = %v, %v; want true", active, err)
+	}
+	if !mr.Exists(seenKey) {
+		t.Fatal("first request did not record last seen")
+	}
+
+	recent := strconv.FormatInt(time.Now().Add(-10*time.Second).Unix(), 10)
+	mr.Set(seenKey, recent)
+	store.IsSessionActive(ctx, family.ID)
+	if got, _ := mr.Get(seenKey); got != recent {
+		t.Fatalf("last seen rewritten within the interval: %s -> %s", recent, got)
+	}
+
+	stale := strconv.FormatInt(time.Now().Add(-2*sessionSeenInterval).Unix(), 10)
+	mr.Set(seenKey, stale)
+	store.IsSessionActive(ctx, family.ID)
+	if got, _ := mr.Get(seenKey); got == stale {
+		t.Fatal("stale last seen was not updated")
+	}
+
+	if active, err := store.IsSessionActive(ctx, "missing"); err != nil || active {
+		t.Fatalf("IsSessionActive(missing) = %v, %v; want false", active, err)
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"backend/internal/metrics"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Правила вступления в команду. Раньше они были скопированы в каждый handler,
+// который добавляет участника: вход по коду, заявка, приглашение (из приложения и из бота).
+
+var (
+	ErrTeamNotFound        = errors.New("team not found")
+	ErrTeamClosed          = errors.New("team is not accepting new members")
+	ErrAlreadyInTeam       = errors.New("user is already in a team for this hackathon")
+	ErrInviteNotFound      = errors.New("invite not found")
+	ErrNotInvitee          = errors.New("not your invite")
+	ErrInviteProcessed     = errors.New("invite already processed")
+	ErrJoinRequestNotFound = errors.New("request not found")
+	ErrNotTeamCaptain      = errors.New("only captain can handle requests")
+	ErrRequestProcessed    = errors.New("request already processed")
+)
+
+// TeamFullError - в команде не осталось мест
+type TeamFullError struct {
+	Current int
+	Max     int
+}
+
+func (e *TeamFullError) Error() string {
+	return fmt.Sprintf("team is full (%d/%d)", e.Current, e.Max)
+}
+
+// TeamMembershipService - вступление в команды
+type TeamMembershipService struct {
+	teams      repositories.TeamRepository
+	hackathons repositories.HackathonRepository
+}
+
+func NewTeamMembershipService(teams repositories.TeamRepository, hackathons repositories.HackathonRepository) *TeamMembershipService {
+	return &TeamMembershipService{teams: teams, hackathons: hackathons}
+}
+
+// TeamForHackathon - команда пользователя на хакатоне (по team_id или как капитана); nil, если её нет
+func (s *TeamMembershipService) TeamForHackathon(ctx context.Context, userID, hackathonID int64) (*models.Team, error) {
+	team, err := s.teams.GetUserTeam(ctx, userID)
+	if err == nil && team.HackathonID == hackathonID {
+		return team, nil
+	}
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check user team: %w", err)
+	}
+
+	team, err = s.teams.GetCaptainedInHackathon(ctx, userID, hackathonID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user team: %w", err)
+	}
+	return team, nil
+}
+
+// CheckCapacity - *TeamFullError, если в команде нет мест
+func (s *TeamMembershipService) CheckCapacity(ctx context.Context, team *models.Team) error {
+	hackathon, err := s.hackathons.GetByID(ctx, team.HackathonID)
+	if err != nil {
+		return fmt.Errorf("failed to load hackathon: %w", err)
+	}
+	count, err := s.teams.CountMembers(ctx, team)
+	if err != nil {
+		return fmt.Errorf("failed to count team members: %w", err)
+	}
+	if count >= hackathon.TeamSize {
+		return &TeamFullError{Current: count, Max: hackathon.TeamSize}
+	}
+	return nil
+}
+
+// CheckCanJoin - может ли пользователь вступить в команду прямо сейчас:
+// команда открыта, у пользователя нет команды на этом хакатоне и есть свободное место
+func (s *TeamMembershipService) CheckCanJoin(ctx context.Context, userID int64, team *models.Team) error {
+	if team.Status == models.TeamStatusClosed {
+		return ErrTeamClosed
+	}
+	current, err := s.TeamForHackathon(ctx, userID, team.HackathonID)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		return ErrAlreadyInTeam
+	}
+	return s.CheckCapacity(ctx, team)
+}
+
+// JoinByCode - вступить в команду по коду приглашения
+func (s *TeamMembershipService) JoinByCode(ctx context.Context, userID int64, code string) (*models.Team, error) {
+	team, err := s.teams.GetByInviteCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to load team: %w", err)
+	}
+	if err := s.CheckCanJoin(ctx, userID, team); err != nil {
+		return nil, err
+	}
+
+	if err := s.addMember(ctx, team, userID, nil); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// AcceptInvite - принять приглашение от имени приглашённого
+func (s *TeamMembershipService) AcceptInvite(ctx context.Context, userID, inviteID int64) (*models.TeamInvite, *models.Team, error) {
+	invite, err := s.teams.GetInvite(ctx, inviteID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, ErrInviteNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load invite: %w", err)
+	}
+	if invite.InvitedUserID != userID {
+		return nil, nil, ErrNotInvitee
+	}
+	if invite.Status != "pending" {
+		return nil, nil, ErrInviteProcessed
+	}
+
+	team, err := s.loadTeam(ctx, invite.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.CheckCanJoin(ctx, userID, team); err != nil {
+		return nil, nil, err
+	}
+
+	err = s.addMember(ctx, team, userID, func(tx repositories.TeamRepository) error {
+		if err := tx.SetInviteStatus(ctx, invite.ID, "accepted"); err != nil {
+			return fmt.Errorf("failed to update invite status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invite.Status = "accepted"
+	metrics.InvitesAccepted.Inc()
+	return invite, team, nil
+}
+
+// DeclineInvite - отклонить приглашение от имени приглашённого
+func (s *TeamMembershipService) DeclineInvite(ctx context.Context, userID, inviteID int64) (*models.TeamInvite, error) {
+	invite, err := s.teams.GetInvite(ctx, inviteID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInviteNotFound
+		}
+		return nil, fmt.Errorf("failed to load invite: %w", err)
+	}
+	if invite.InvitedUserID != userID {
+		return nil, ErrNotInvitee
+	}
+	if invite.Status != "pending" {
+		return nil, ErrInviteProcessed
+	}
+
+	if err := s.teams.SetInviteStatus(ctx, invite.ID, "declined"); err != nil {
+		return nil, fmt.Errorf("failed to update invite status: %w", err)
+	}
+	invite.Status = "declined"
+	return invite, nil
+}
+
+// KickMember - убрать участника из команды и вернуть его в поиск на хакатоне.
+// Права капитана проверяет вызывающий; false, если пользователь в команде не состоял.
+func (s *TeamMembershipService) KickMember(ctx context.Context, team *models.Team, userID int64) (bool, error) {
+	var kicked bool
+	err := s.teams.Transaction(ctx, func(tx repositories.TeamRepository) error {
+		removed, err := tx.RemoveMember(ctx, team.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove team member: %w", err)
+		}
+		if !removed {
+			return nil
+		}
+		if err := tx.MarkParticipantLooking(ctx, userID, team.HackathonID); err != nil {
+			return fmt.Errorf("failed to update hackathon participant status: %w", err)
+		}
+		kicked = true
+		return nil
+	})
+	return kicked, err
+}
+
+// HandleJoinRequest - капитан принимает или отклоняет заявку на вступление
+func (s *TeamMembershipService) HandleJoinRequest(ctx context.Context, captainID, requestID int64, accept bool) (*models.TeamJoinRequest, *models.Team, error) {
+	request, err := s.teams.GetJoinRequest(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, ErrJoinRequestNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load join request: %w", err)
+	}
+
+	team, err := s.loadTeam(ctx, request.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if team.CaptainID != captainID {
+		return nil, nil, ErrNotTeamCaptain
+	}
+	if request.Status != "pending" {
+		return nil, nil, ErrRequestProcessed
+	}
+
+	if !accept {
+		if err := s.teams.SetJoinRequestStatus(ctx, request.ID, "rejected"); err != nil {
+			return nil, nil, fmt.Errorf("failed to update request status: %w", err)
+		}
+		request.Status = "rejected"
+		return request, team, nil
+	}
+
+	// Заявку подали, когда команда была открыта - закрытие не мешает капитану её принять
+	current, err := s.TeamForHackathon(ctx, request.UserID, team.HackathonID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current != nil {
+		return nil, nil, ErrAlreadyInTeam
+	}
+	if err := s.CheckCapacity(ctx, team); err != nil {
+		return nil, nil, err
+	}
+
+	err = s.addMember(ctx, team, request.UserID, func(tx repositories.TeamRepository) error {
+		if err := tx.SetJoinRequestStatus(ctx, request.ID, "accepted"); err != nil {
+			return fmt.Errorf("failed to update request status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request.Status = "accepted"
+	return request, team, nil
+}
+
+// addMember - перевести пользователя в команду, отметить регистрацию на хакатон
+// и отменить остальные его приглашения и заявки на этом хакатоне. Всё, включая extra, - в одной транзакции:
+// если что-то не записалось, пользователь не окажется в команде с висящими приглашениями.
+func (s *TeamMembershipService) addMember(ctx context.Context, team *models.Team, userID int64, extra func(tx repositories.TeamRepository) error) error {
+	return s.teams.Transaction(ctx, func(tx repositories.TeamRepository) error {
+		if err := tx.SetUserTeam(ctx, userID, team.ID); err != nil {
+			return fmt.Errorf("failed to join team: %w", err)
+		}
+		if extra != nil {
+			if err := extra(tx); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.MarkParticipantInTeam(ctx, userID, team.HackathonID); err != nil {
+			return fmt.Errorf("failed to update hackathon participant status: %w", err)
+		}
+		// Принятое приглашение или заявка уже не pending и не будут отменены
+		if err := tx.CancelPendingInHackathon(ctx, userID, team.HackathonID); err != nil {
+			return fmt.Errorf("failed to cancel pending invites and join requests: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *TeamMembershipService) loadTeam(ctx context.Context, teamID int64) (*models.Team, error) {
+	team, err := s.teams.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to load team: %w", err)
+	}
+	return team, nil
+}
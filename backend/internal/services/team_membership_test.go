@@ -0,0 +1,483 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"errors"
+	"testing"
+)
+
+// memoryTeams - TeamRepository в памяти. Transaction откатывает изменения, если fn вернула ошибку.
+type memoryTeams struct {
+	teams        map[int64]*models.Team
+	userTeam     map[int64]int64 // users.team_id
+	participants map[[2]int64]string
+	invites      map[int64]*models.TeamInvite
+	requests     map[int64]*models.TeamJoinRequest
+
+	// failCancel - ошибка для CancelPendingInHackathon
+	failCancel error
+}
+
+func newMemoryTeams() *memoryTeams {
+	return &memoryTeams{
+		teams:        map[int64]*models.Team{},
+		userTeam:     map[int64]int64{},
+		participants: map[[2]int64]string{},
+		invites:      map[int64]*models.TeamInvite{},
+		requests:     map[int64]*models.TeamJoinRequest{},
+	}
+}
+
+func (m *memoryTeams) snapshot() *memoryTeams {
+	c := newMemoryTeams()
+	c.failCancel = m.failCancel
+	for id, t := range m.teams {
+		copied := *t
+		c.teams[id] = &copied
+	}
+	for k, v := range m.userTeam {
+		c.userTeam[k] = v
+	}
+	for k, v := range m.participants {
+		c.participants[k] = v
+	}
+	for id, inv := range m.invites {
+		copied := *inv
+		c.invites[id] = &copied
+	}
+	for id, req := range m.requests {
+		copied := *req
+		c.requests[id] = &copied
+	}
+	return c
+}
+
+func (m *memoryTeams) Transaction(_ context.Context, fn func(tx repositories.TeamRepository) error) error {
+	saved := m.snapshot()
+	if err := fn(m); err != nil {
+		*m = *saved
+		return err
+	}
+	return nil
+}
+
+func (m *memoryTeams) GetByID(_ context.Context, id int64) (*models.Team, error) {
+	if t, ok := m.teams[id]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (m *memoryTeams) GetByInviteCode(_ context.Context, code string) (*models.Team, error) {
+	for _, t := range m.teams {
+		if t.InviteCode != nil && *t.InviteCode == code {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (m *memoryTeams) GetUserTeam(ctx context.Context, userID int64) (*models.Team, error) {
+	teamID, ok := m.userTeam[userID]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return m.GetByID(ctx, teamID)
+}
+
+func (m *memoryTeams) GetCaptainedBy(_ context.Context, userID int64) (*models.Team, error) {
+	for _, t := range m.teams {
+		if t.CaptainID == userID {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (m *memoryTeams) GetCaptainedInHackathon(_ context.Context, userID, hackathonID int64) (*models.Team, error) {
+	for _, t := range m.teams {
+		if t.CaptainID == userID && t.HackathonID == hackathonID {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (m *memoryTeams) CountMembers(_ context.Context, team *models.Team) (int, error) {
+	count := 0
+	for _, teamID := range m.userTeam {
+		if teamID == team.ID {
+			count++
+		}
+	}
+	if m.userTeam[team.CaptainID] != team.ID {
+		count++
+	}
+	return count, nil
+}
+
+func (m *memoryTeams) SetUserTeam(_ context.Context, userID, teamID int64) error {
+	m.userTeam[userID] = teamID
+	return nil
+}
+
+func (m *memoryTeams) MarkParticipantInTeam(_ context.Context, userID, hackathonID int64) error {
+	key := [2]int64{userID, hackathonID}
+	if _, ok := m.participants[key]; ok {
+		m.participants[key] = "in_team"
+	}
+	return nil
+}
+
+func (m *memoryTeams) RemoveMember(_ context.Context, teamID, userID int64) (bool, error) {
+	if m.userTeam[userID] != teamID {
+		return false, nil
+	}
+	delete(m.userTeam, userID)
+	return true, nil
+}
+
+func (m *memoryTeams) MarkParticipantLooking(_ context.Context, userID, hackathonID int64) error {
+	key := [2]int64{userID, hackathonID}
+	if _, ok := m.participants[key]; ok {
+		m.participants[key] = "looking"
+	}
+	return nil
+}
+
+func (m *memoryTeams) GetInvite(_ context.Context, id int64) (*models.TeamInvite, error) {
+	if inv, ok := m.invites[id]; ok {
+		copied := *inv
+		return &copied, nil
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (m *memoryTeams) GetPendingInvite(_ context.Context, teamID, userID int64) (*models.TeamInvite, error) {
+	for _, inv := range m.invites {
+		if inv.TeamID == teamID && inv.InvitedUserID == userID && inv.Status == "pending" {
+			copied := *inv
+			return &copied, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (m *memoryTeams) CreateInvite(_ context.Context, invite *models.TeamInvite) error {
+	invite.ID = int64(len(m.invites) + 1)
+	copied := *invite
+	m.invites[invite.ID] = &copied
+	return nil
+}
+
+func (m *memoryTeams) SetInviteStatus(_ context.Context, inviteID int64, status string) error {
+	if inv, ok := m.invites[inviteID]; ok {
+		inv.Status = status
+	}
+	return nil
+}
+
+func (m *memoryTeams) ListIncomingInvites(_ context.Context, userID int64) ([]models.TeamInvite, error) {
+	var invites []models.TeamInvite
+	for _, inv := range m.invites {
+		if inv.InvitedUserID == userID && inv.Status == "pending" {
+			invites = append(invites, *inv)
+		}
+	}
+	return invites, nil
+}
+
+func (m *memoryTeams) ListOutgoingInvites(_ context.Context, inviterID int64) ([]models.TeamInvite, error) {
+	var invites []models.TeamInvite
+	for _, inv := range m.invites {
+		if inv.InviterID == inviterID {
+			invites = append(invites, *inv)
+		}
+	}
+	return invites, nil
+}
+
+func (m *memoryTeams) GetJoinRequest(_ context.Context, id int64) (*models.TeamJoinRequest, error) {
+	if req, ok := m.requests[id]; ok {
+		copied := *req
+		return &copied, nil
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (m *memoryTeams) SetJoinRequestStatus(_ context.Context, requestID int64, status string) error {
+	if req, ok := m.requests[requestID]; ok {
+		req.Status = status
+	}
+	return nil
+}
+
+func (m *memoryTeams) CancelPendingInHackathon(_ context.Context, userID, hackathonID int64) error {
+	if m.failCancel != nil {
+		return m.failCancel
+	}
+	inHackathon := func(teamID int64) bool {
+		t, ok := m.teams[teamID]
+		return ok && t.HackathonID == hackathonID
+	}
+	for _, inv := range m.invites {
+		if inv.InvitedUserID == userID && inv.Status == "pending" && inHackathon(inv.TeamID) {
+			inv.Status = "cancelled"
+		}
+	}
+	for _, req := range m.requests {
+		if req.UserID == userID && req.Status == "pending" && inHackathon(req.TeamID) {
+			req.Status = "cancelled"
+		}
+	}
+	return nil
+}
+
+// memoryHackathons - HackathonRepository в памяти
+type memoryHackathons map[int64]*models.Hackathon
+
+func (m memoryHackathons) GetByID(_ context.Context, id int64) (*models.Hackathon, error) {
+	if h, ok := m[id]; ok {
+		return h, nil
+	}
+	return nil, repositories.ErrNotFound
+}
+
+// Данные для тестов: хакатоны 1 и 2 с командами по 3 человека.
+// Команда 10 на хакатоне 1: капитан 100 и участник 101, одно место свободно.
+const (
+	testHackathon      = 1
+	otherHackathon     = 2
+	openTeam           = 10
+	closedTeam         = 11
+	fullTeam           = 12
+	otherHackathonTeam = 20
+	captain            = 100
+	applicant          = 200
+)
+
+func newMembershipFixture() (*TeamMembershipService, *memoryTeams) {
+	teams := newMemoryTeams()
+	teams.teams[openTeam] = &models.Team{ID: openTeam, HackathonID: testHackathon, CaptainID: captain, Status: models.TeamStatusLooking}
+	teams.teams[closedTeam] = &models.Team{ID: closedTeam, HackathonID: testHackathon, CaptainID: 110, Status: models.TeamStatusClosed}
+	teams.teams[fullTeam] = &models.Team{ID: fullTeam, HackathonID: testHackathon, CaptainID: 120, Status: models.TeamStatusLooking}
+	teams.teams[otherHackathonTeam] = &models.Team{ID: otherHackathonTeam, HackathonID: otherHackathon, CaptainID: 130, Status: models.TeamStatusLooking}
+
+	teams.userTeam[captain] = openTeam
+	teams.userTeam[101] = openTeam
+	teams.userTeam[120] = fullTeam
+	teams.userTeam[121] = fullTeam
+	teams.userTeam[122] = fullTeam
+	teams.participants[[2]int64{applicant, testHackathon}] = "looking"
+
+	hackathons := memoryHackathons{
+		testHackathon:  {ID: testHackathon, TeamSize: 3},
+		otherHackathon: {ID: otherHackathon, TeamSize: 3},
+	}
+	return NewTeamMembershipService(teams, hackathons), teams
+}
+
+func TestCheckCanJoin(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int64
+		teamID int64
+		setup  func(*memoryTeams)
+		want   error
+	}{
+		{"open team with a free place", applicant, openTeam, nil, nil},
+		{"closed team", applicant, closedTeam, nil, ErrTeamClosed},
+		{"full team", applicant, fullTeam, nil, &TeamFullError{}},
+		{"member of another team on the hackathon", 121, openTeam, nil, ErrAlreadyInTeam},
+		{"captain of another team on the hackathon", 110, openTeam, nil, ErrAlreadyInTeam},
+		{"team on another hackathon does not count", applicant, openTeam, func(m *memoryTeams) {
+			m.userTeam[applicant] = otherHackathonTeam
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, teams := newMembershipFixture()
+			if tt.setup != nil {
+				tt.setup(teams)
+			}
+			team, _ := teams.GetByID(context.Background(), tt.teamID)
+
+			err := svc.CheckCanJoin(context.Background(), tt.userID, team)
+			assertMembershipError(t, err, tt.want)
+		})
+	}
+}
+
+func TestHandleJoinRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		captainID int64
+		request   models.TeamJoinRequest
+		accept    bool
+		setup     func(*memoryTeams)
+		want      error
+		status    string
+	}{
+		{"accept", captain, models.TeamJoinRequest{TeamID: openTeam, UserID: applicant, Status: "pending"}, true, nil, nil, "accepted"},
+		{"reject", captain, models.TeamJoinRequest{TeamID: openTeam, UserID: applicant, Status: "pending"}, false, nil, nil, "rejected"},
+		{"team closed after the request", 110, models.TeamJoinRequest{TeamID: closedTeam, UserID: applicant, Status: "pending"}, true, nil, nil, "accepted"},
+		{"full team", 120, models.TeamJoinRequest{TeamID: fullTeam, UserID: applicant, Status: "pending"}, true, nil, &TeamFullError{}, "pending"},
+		{"already in a team", captain, models.TeamJoinRequest{TeamID: openTeam, UserID: applicant, Status: "pending"}, true, func(m *memoryTeams) {
+			m.userTeam[applicant] = closedTeam
+		}, ErrAlreadyInTeam, "pending"},
+		{"not captain", 101, models.TeamJoinRequest{TeamID: openTeam, UserID: applicant, Status: "pending"}, true, nil, ErrNotTeamCaptain, "pending"},
+		{"already processed", captain, models.TeamJoinRequest{TeamID: openTeam, UserID: applicant, Status: "rejected"}, true, nil, ErrRequestProcessed, "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, teams := newMembershipFixture()
+			tt.request.ID = 1
+			teams.requests[1] = &tt.request
+			if tt.setup != nil {
+				tt.setup(teams)
+			}
+
+			_, _, err := svc.HandleJoinRequest(context.Background(), tt.captainID, 1, tt.accept)
+			assertMembershipError(t, err, tt.want)
+			if got := teams.requests[1].Status; got != tt.status {
+				t.Fatalf("request status %q, want %q", got, tt.status)
+			}
+
+			joined := teams.userTeam[tt.request.UserID] == tt.request.TeamID
+			if joined != (tt.status == "accepted") {
+				t.Fatalf("user in team = %v, request status %q", joined, tt.status)
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		svc, _ := newMembershipFixture()
+		if _, _, err := svc.HandleJoinRequest(context.Background(), captain, 404, true); !errors.Is(err, ErrJoinRequestNotFound) {
+			t.Fatalf("got %v, want ErrJoinRequestNotFound", err)
+		}
+	})
+}
+
+func TestAcceptInvite(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int64
+		invite models.TeamInvite
+		want   error
+	}{
+		{"accept", applicant, models.TeamInvite{TeamID: openTeam, InvitedUserID: applicant, InviterID: captain, Status: "pending"}, nil},
+		{"someone else's invite", 201, models.TeamInvite{TeamID: openTeam, InvitedUserID: applicant, InviterID: captain, Status: "pending"}, ErrNotInvitee},
+		{"already declined", applicant, models.TeamInvite{TeamID: openTeam, InvitedUserID: applicant, InviterID: captain, Status: "declined"}, ErrInviteProcessed},
+		{"team closed", applicant, models.TeamInvite{TeamID: closedTeam, InvitedUserID: applicant, InviterID: 110, Status: "pending"}, ErrTeamClosed},
+		{"team full", applicant, models.TeamInvite{TeamID: fullTeam, InvitedUserID: applicant, InviterID: 120, Status: "pending"}, &TeamFullError{}},
+		{"team deleted", applicant, models.TeamInvite{TeamID: 404, InvitedUserID: applicant, InviterID: captain, Status: "pending"}, ErrTeamNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, teams := newMembershipFixture()
+			tt.invite.ID = 1
+			teams.invites[1] = &tt.invite
+			// Второе приглашение на тот же хакатон отменяется после вступления
+			teams.invites[2] = &models.TeamInvite{ID: 2, TeamID: closedTeam, InvitedUserID: applicant, Status: "pending"}
+
+			_, team, err := svc.AcceptInvite(context.Background(), tt.userID, 1)
+			assertMembershipError(t, err, tt.want)
+			if tt.want != nil {
+				if teams.invites[2].Status != "pending" {
+					t.Fatal("other invites changed after a failed accept")
+				}
+				return
+			}
+
+			if team.ID != tt.invite.TeamID || teams.userTeam[applicant] != tt.invite.TeamID {
+				t.Fatalf("user not moved into team %d", tt.invite.TeamID)
+			}
+			if teams.invites[1].Status != "accepted" || teams.invites[2].Status != "cancelled" {
+				t.Fatalf("invite statuses %q, %q", teams.invites[1].Status, teams.invites[2].Status)
+			}
+			if status := teams.participants[[2]int64{applicant, testHackathon}]; status != "in_team" {
+				t.Fatalf("participant status %q, want in_team", status)
+			}
+		})
+	}
+}
+
+func TestAddMemberRollsBackOnError(t *testing.T) {
+	svc, teams := newMembershipFixture()
+	teams.invites[1] = &models.TeamInvite{ID: 1, TeamID: openTeam, InvitedUserID: applicant, InviterID: captain, Status: "pending"}
+	teams.failCancel = errors.New("connection reset")
+
+	if _, _, err := svc.AcceptInvite(context.Background(), applicant, 1); err == nil {
+		t.Fatal("AcceptInvite succeeded although pending invites were not cancelled")
+	}
+	if _, ok := teams.userTeam[applicant]; ok {
+		t.Fatal("user joined the team despite the failed transaction")
+	}
+	if teams.invites[1].Status != "pending" {
+		t.Fatalf("invite status %q, want pending", teams.invites[1].Status)
+	}
+}
+
+func TestKickMember(t *testing.T) {
+	svc, teams := newMembershipFixture()
+	teams.participants[[2]int64{101, testHackathon}] = "in_team"
+	team, _ := teams.GetByID(context.Background(), openTeam)
+
+	kicked, err := svc.KickMember(context.Background(), team, 101)
+	if err != nil || !kicked {
+		t.Fatalf("KickMember = %v, %v; want true", kicked, err)
+	}
+	if _, ok := teams.userTeam[101]; ok {
+		t.Fatal("member still in team")
+	}
+	if status := teams.participants[[2]int64{101, testHackathon}]; status != "looking" {
+		t.Fatalf("participant status %q, want looking", status)
+	}
+
+	// Участник чужой команды не трогается
+	if kicked, err := svc.KickMember(context.Background(), team, 121); err != nil || kicked {
+		t.Fatalf("KickMember of another team's member = %v, %v", kicked, err)
+	}
+	if teams.userTeam[121] != fullTeam {
+		t.Fatal("member of another team was removed")
+	}
+}
+
+func TestDeclineInvite(t *testing.T) {
+	svc, teams := newMembershipFixture()
+	teams.invites[1] = &models.TeamInvite{ID: 1, TeamID: openTeam, InvitedUserID: applicant, InviterID: captain, Status: "pending"}
+
+	if _, err := svc.DeclineInvite(context.Background(), 201, 1); !errors.Is(err, ErrNotInvitee) {
+		t.Fatalf("someone else's invite: got %v", err)
+	}
+	if _, err := svc.DeclineInvite(context.Background(), applicant, 1); err != nil {
+		t.Fatalf("DeclineInvite: %v", err)
+	}
+	if teams.invites[1].Status != "declined" {
+		t.Fatalf("invite status %q", teams.invites[1].Status)
+	}
+	if _, err := svc.DeclineInvite(context.Background(), applicant, 1); !errors.Is(err, ErrInviteProcessed) {
+		t.Fatalf("second decline: got %v", err)
+	}
+}
+
+// assertMembershipError - want == &TeamFullError{} совпадает с любой ошибкой заполненной команды
+func assertMembershipError(t *testing.T, err, want error) {
+	t.Helper()
+	var full *TeamFullError
+	if _, ok := want.(*TeamFullError); ok {
+		if !errors.As(err, &full) {
+			t.Fatalf("got %v, want TeamFullError", err)
+		}
+		return
+	}
+	if !errors.Is(err, want) {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+}
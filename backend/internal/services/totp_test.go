@@ -0,0 +1,101 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Секрет из RFC 6238 (приложение B), для SHA1 - "12345678901234567890"
+var rfcTOTPSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// Эталонные 8-значные коды RFC, у нас берутся последние 6 цифр
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		counter := uint64(tt.unix / int64(totpPeriod.Seconds()))
+		if got := totpCode([]byte("12345678901234567890"), counter); got != tt.want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / int64(totpPeriod.Seconds())
+
+	tests := []struct {
+		name     string
+		code     string
+		at       time.Time
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", "005924", now, 0, step, true},
+		{"surrounding spaces", " 005924 ", now, 0, step, true},
+		{"previous step within skew", "005924", now.Add(totpPeriod), 0, step, true},
+		{"next step within skew", "005924", now.Add(-totpPeriod), 0, step, true},
+		{"outside skew", "005924", now.Add(2 * totpPeriod), 0, 0, false},
+		{"wrong code", "123456", now, 0, 0, false},
+		{"wrong length", "05924", now, 0, 0, false},
+		{"replay of accepted step", "005924", now, step, 0, false},
+		{"replay of earlier step", "005924", now.Add(totpPeriod), step, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(rfcTOTPSecret, tt.code, tt.at, tt.lastStep)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Fatalf("ValidateTOTP = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPBadSecret(t *testing.T) {
+	if _, ok := ValidateTOTP("not base32 !", "005924", time.Unix(1234567890, 0), 0); ok {
+		t.Fatal("code accepted with a malformed secret")
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q does not decode to 20 bytes: %v", secret, err)
+	}
+
+	now := time.Now()
+	code := totpCode(key, uint64(now.Unix()/int64(totpPeriod.Seconds())))
+	if _, ok := ValidateTOTP(strings.ToLower(secret), code, now, 0); !ok {
+		t.Fatal("freshly generated code rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("ABCDEF", "ITAM Hackathon Admin", "anna")
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("invalid URI %q: %v", uri, err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Fatalf("unexpected URI %q", uri)
+	}
+	q := parsed.Query()
+	if q.Get("secret") != "ABCDEF" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Fatalf("unexpected params in %q", uri)
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"backend/internal/logging"
+	"backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Выгрузка и удаление персональных данных по запросу субъекта (152-ФЗ).
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserAlreadyErased = errors.New("user data has already been erased")
+)
+
+// UserExport - архив всех данных пользователя
+type UserExport struct {
+	ExportedAt       time.Time                    `json:"exportedAt"`
+	Profile          models.User                  `json:"profile"`
+	Customization    *models.ProfileCustomization `json:"customization,omitempty"`
+	SwipePreferences []models.SwipePreference     `json:"swipePreferences"`
+	Swipes           []models.Swipe               `json:"swipes"`
+	Matches          []models.Match               `json:"matches"`
+	Notifications    []models.Notification        `json:"notifications"`
+	Inventory        UserExportInventory          `json:"inventory"`
+	Achievements     []models.UserAchievement     `json:"achievements"`
+	TeamHistory      UserExportTeamHistory        `json:"teamHistory"`
+	Sessions         []RefreshTokenFamily         `json:"sessions"`
+}
+
+// UserExportInventory - предметы и кейсы пользователя
+type UserExportInventory struct {
+	Items []models.CustomizationItem `json:"items"`
+	Cases []models.UserCase          `json:"cases"`
+}
+
+// UserExportTeamHistory - всё, что связывает пользователя с командами
+type UserExportTeamHistory struct {
+	CurrentTeam     *models.Team                  `json:"currentTeam,omitempty"`
+	CaptainOf       []models.Team                 `json:"captainOf"`
+	Participations  []models.HackathonParticipant `json:"participations"`
+	JoinRequests    []models.TeamJoinRequest      `json:"joinRequests"`
+	InvitesReceived []models.TeamInvite           `json:"invitesReceived"`
+	InvitesSent     []models.TeamInvite           `json:"invitesSent"`
+}
+
+// ErasureResult - что произошло при удалении данных
+type ErasureResult struct {
+	UserID               int64           `json:"userId"`
+	ErasedAt             time.Time       `json:"erasedAt"`
+	TeamsHandedOver      map[int64]int64 `json:"teamsHandedOver"` // команда -> новый капитан
+	TeamsDissolved       []int64         `json:"teamsDissolved"`
+	SwipesDeleted        int64           `json:"swipesDeleted"`
+	NotificationsDeleted int64           `json:"notificationsDeleted"`
+	SessionsRevoked      int             `json:"sessionsRevoked"`
+}
+
+// UserDataService - выгрузка и удаление персональных данных
+type UserDataService struct {
+	db       *gorm.DB
+	sessions *RefreshTokenStore
+}
+
+func NewUserDataService(db *gorm.DB, sessions *RefreshTokenStore) *UserDataService {
+	return &UserDataService{db: db, sessions: sessions}
+}
+
+// Export - собрать архив данных пользователя
+func (s *UserDataService) Export(ctx context.Context, userID int64) (*UserExport, error) {
+	db := s.db.WithContext(ctx)
+
+	export := &UserExport{ExportedAt: time.Now().UTC()}
+	if err := db.First(&export.Profile, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	var customization models.ProfileCustomization
+	if err := db.Where("user_id = ?", userID).First(&customization).Error; err == nil {
+		export.Customization = &customization
+	}
+
+	if export.Profile.TeamID != nil {
+		var team models.Team
+		if err := db.First(&team, *export.Profile.TeamID).Error; err == nil {
+			export.TeamHistory.CurrentTeam = &team
+		}
+	}
+
+	queries := []struct {
+		dest  interface{}
+		where string
+	}{
+		{&export.SwipePreferences, "user_id = ?"},
+		{&export.Swipes, "target_user_id = ?"},
+		{&export.Matches, "user_id = ?"},
+		{&export.Notifications, "user_id = ?"},
+		{&export.Inventory.Items, "user_id = ?"},
+		{&export.Inventory.Cases, "user_id = ?"},
+		{&export.Achievements, "user_id = ?"},
+		{&export.TeamHistory.CaptainOf, "captain_id = ?"},
+		{&export.TeamHistory.Participations, "user_id = ?"},
+		{&export.TeamHistory.JoinRequests, "user_id = ?"},
+		{&export.TeamHistory.InvitesReceived, "invited_user_id = ?"},
+		{&export.TeamHistory.InvitesSent, "inviter_id = ?"},
+	}
+	for _, q := range queries {
+		if err := db.Where(q.where, userID).Order("id").Find(q.dest).Error; err != nil {
+			return nil, fmt.Errorf("failed to export user data: %w", err)
+		}
+	}
+
+	sessions, err := s.sessions.ListFamilies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Sessions = sessions
+
+	return export, nil
+}
+
+// Erase - обезличить пользователя.
+// Строка users остаётся (на неё ссылаются участия, мэтчи и журнал аудита), но без
+// персональных данных. Свайпы, уведомления, инвентарь и заявки удаляются, команды,
+// где пользователь капитан, передаются другому участнику или распускаются.
+func (s *UserDataService) Erase(ctx context.Context, userID int64) (*ErasureResult, error) {
+	result := &ErasureResult{
+		UserID:          userID,
+		ErasedAt:        time.Now().UTC(),
+		TeamsHandedOver: map[int64]int64{},
+		TeamsDissolved:  []int64{},
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to load user: %w", err)
+		}
+		if user.ErasedAt != nil {
+			return ErrUserAlreadyErased
+		}
+
+		if err := s.releaseCaptaincy(tx, userID, result); err != nil {
+			return err
+		}
+
+		swipes := tx.Where("target_user_id = ?", userID).Delete(&models.Swipe{})
+		if swipes.Error != nil {
+			return fmt.Errorf("failed to delete swipes: %w", swipes.Error)
+		}
+		result.SwipesDeleted = swipes.RowsAffected
+
+		notifications := tx.Where("user_id = ?", userID).Delete(&models.Notification{})
+		if notifications.Error != nil {
+			return fmt.Errorf("failed to delete notifications: %w", notifications.Error)
+		}
+		result.NotificationsDeleted = notifications.RowsAffected
+
+		personal := []struct {
+			model interface{}
+			where string
+			args  []interface{}
+		}{
+			{&models.SwipePreference{}, "user_id = ?", []interface{}{userID}},
+			{&models.CustomizationItem{}, "user_id = ?", []interface{}{userID}},
+			{&models.UserCase{}, "user_id = ?", []interface{}{userID}},
+			{&models.UserAchievement{}, "user_id = ?", []interface{}{userID}},
+			{&models.ProfileCustomization{}, "user_id = ?", []interface{}{userID}},
+			{&models.TeamJoinRequest{}, "user_id = ?", []interface{}{userID}},
+			{&models.TeamInvite{}, "invited_user_id = ? OR inviter_id = ?", []interface{}{userID, userID}},
+			{&models.AdminCredential{}, "user_id = ?", []interface{}{userID}},
+		}
+		for _, p := range personal {
+			if err := tx.Where(p.where, p.args...).Delete(p.model).Error; err != nil {
+				return fmt.Errorf("failed to delete personal data: %w", err)
+			}
+		}
+
+		// Участия остаются для статистики хакатонов, но пользователь больше не ищет команду
+		if err := tx.Model(&models.HackathonParticipant{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"team_id": nil, "status": "left"}).Error; err != nil {
+			return fmt.Errorf("failed to update participations: %w", err)
+		}
+
+		// Отрицательный telegram ID: уникален и не совпадёт с настоящим, так что
+		// повторный вход через Telegram создаст новый аккаунт
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"telegram_user_id":      -userID,
+			"username":              fmt.Sprintf("deleted_%d", userID),
+			"name":                  "Удалённый пользователь",
+			"bio":                   "",
+			"avatar_url":            "",
+			"skills":                pq.StringArray{},
+			"verified_skills":       pq.StringArray{},
+			"experience":            "",
+			"looking_for":           pq.StringArray{},
+			"contact_info":          "",
+			"tags":                  pq.StringArray{},
+			"authorized":            false,
+			"role":                  models.RoleUser,
+			"team_id":               nil,
+			"current_hackathon_id":  nil,
+			"profile_complete":      false,
+			"notifications_enabled": false,
+			"erased_at":             result.ErasedAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to anonymize user: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Сессии живут в Redis, поэтому отзываются уже после коммита
+	revoked, err := s.sessions.RevokeAllForUser(ctx, userID, "")
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to revoke sessions of erased user", logging.UserID(userID), logging.Err(err))
+	}
+	result.SessionsRevoked = revoked
+
+	return result, nil
+}
+
+// releaseCaptaincy - передать команды пользователя другому участнику или распустить их
+func (s *UserDataService) releaseCaptaincy(tx *gorm.DB, userID int64, result *ErasureResult) error {
+	var teams []models.Team
+	if err := tx.Where("captain_id = ?", userID).Find(&teams).Error; err != nil {
+		return fmt.Errorf("failed to load captained teams: %w", err)
+	}
+
+	for _, team := range teams {
+		var successor models.User
+		err := tx.Where("team_id = ? AND id <> ?", team.ID, userID).Order("id").First(&successor).Error
+		if err == nil {
+			if err := tx.Model(&models.Team{}).Where("id = ?", team.ID).Update("captain_id", successor.ID).Error; err != nil {
+				return fmt.Errorf("failed to hand over team %d: %w", team.ID, err)
+			}
+			result.TeamsHandedOver[team.ID] = successor.ID
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to find successor for team %d: %w", team.ID, err)
+		}
+
+		// Больше никого нет - команда распускается
+		if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamJoinRequest{}).Error; err != nil {
+			return fmt.Errorf("failed to dissolve team %d: %w", team.ID, err)
+		}
+		if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamInvite{}).Error; err != nil {
+			return fmt.Errorf("failed to dissolve team %d: %w", team.ID, err)
+		}
+		if err := tx.Model(&models.HackathonParticipant{}).Where("team_id = ?", team.ID).Update("team_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to dissolve team %d: %w", team.ID, err)
+		}
+		if err := tx.Delete(&models.Team{}, team.ID).Error; err != nil {
+			return fmt.Errorf("failed to dissolve team %d: %w", team.ID, err)
+		}
+		result.TeamsDissolved = append(result.TeamsDissolved, team.ID)
+	}
+
+	return nil
+}
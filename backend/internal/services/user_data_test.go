@@ -0,0 +1,243 @@
+package services
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB - отдельная БД SQLite в памяти со схемой нужных моделей
+func testDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// userDataFixture - пользователь 1 со всеми видами данных.
+// Он капитан команды 10 (в ней ещё 5 и 3) и команды 20, где кроме него никого нет.
+type userDataFixture struct {
+	db       *gorm.DB
+	sessions *RefreshTokenStore
+	service  *UserDataService
+}
+
+func newUserDataFixture(t *testing.T) *userDataFixture {
+	t.Helper()
+	db := testDB(t,
+		&models.User{}, &models.Team{}, &models.HackathonParticipant{}, &models.Swipe{}, &models.SwipePreference{},
+		&models.Match{}, &models.Notification{}, &models.CustomizationItem{}, &models.UserCase{},
+		&models.UserAchievement{}, &models.ProfileCustomization{}, &models.TeamJoinRequest{},
+		&models.TeamInvite{}, &models.AdminCredential{},
+	)
+	client, _ := newTestRedis(t)
+	sessions := NewRefreshTokenStore(client, time.Hour)
+
+	teamID, soloID := int64(10), int64(20)
+	records := []interface{}{
+		&models.Team{ID: 10, HackathonID: 1, Name: "Keepers", CaptainID: 1},
+		&models.Team{ID: 20, HackathonID: 2, Name: "Solo", CaptainID: 1},
+		// Пятый создан раньше третьего: преемник выбирается по id, а не по порядку вставки
+		&models.User{ID: 5, TelegramUserID: 500, Username: "five", TeamID: &teamID},
+		&models.User{ID: 3, TelegramUserID: 300, Username: "three", TeamID: &teamID},
+		&models.User{
+			ID: 1, TelegramUserID: 100, Username: "captain", Name: "Иван", Bio: "bio", AvatarURL: "a.png",
+			Skills: []string{"go"}, Experience: "senior", ContactInfo: "@captain", Tags: []string{"tag"},
+			Role: models.RoleAdmin, TeamID: &teamID, ProfileComplete: true, NotificationsEnabled: true,
+		},
+		&models.ProfileCustomization{UserID: 1},
+		&models.SwipePreference{UserID: 1, HackathonID: 1},
+		&models.Swipe{SwiperTeamID: 30, TargetUserID: 1, Action: "like"},
+		&models.Swipe{SwiperTeamID: 31, TargetUserID: 1, Action: "dislike"},
+		&models.Swipe{SwiperTeamID: 30, TargetUserID: 5, Action: "like"},
+		&models.Match{TeamID: 30, UserID: 1},
+		&models.Notification{UserID: 1, Title: "hi"},
+		&models.Notification{UserID: 5, Title: "hi"},
+		&models.CustomizationItem{UserID: 1, ItemID: "bg", ItemType: "background", Rarity: models.RarityRare, Name: "bg"},
+		&models.UserCase{UserID: 1, CaseType: "starter", CaseName: "Starter", Rarity: models.RarityCommon},
+		&models.UserAchievement{UserID: 1, AchievementID: "first", Name: "First"},
+		&models.HackathonParticipant{HackathonID: 1, UserID: 1, TeamID: &teamID, Status: "in_team"},
+		&models.HackathonParticipant{HackathonID: 2, UserID: 7, TeamID: &soloID, Status: "in_team"},
+		&models.TeamJoinRequest{TeamID: 40, UserID: 1},
+		&models.TeamJoinRequest{TeamID: 20, UserID: 8},
+		&models.TeamInvite{TeamID: 40, InvitedUserID: 1, InviterID: 9},
+		&models.TeamInvite{TeamID: 10, InvitedUserID: 6, InviterID: 1},
+		&models.TeamInvite{TeamID: 20, InvitedUserID: 9, InviterID: 4},
+		&models.AdminCredential{UserID: 1, Login: "captain", PasswordHash: "hash"},
+	}
+	for _, r := range records {
+		if err := db.Create(r).Error; err != nil {
+			t.Fatalf("create %T: %v", r, err)
+		}
+	}
+	if _, _, err := sessions.Issue(context.Background(), 1, SessionClient{UserAgent: "ua"}); err != nil {
+		t.Fatal(err)
+	}
+
+	return &userDataFixture{db: db, sessions: sessions, service: NewUserDataService(db, sessions)}
+}
+
+// count - сколько строк модели подходит под условие
+func (f *userDataFixture) count(t *testing.T, model interface{}, where string, args ...interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := f.db.Model(model).Where(where, args...).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestUserDataExport(t *testing.T) {
+	f := newUserDataFixture(t)
+
+	export, err := f.service.Export(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Profile.Username != "captain" || export.Profile.ContactInfo != "@captain" || export.Customization == nil {
+		t.Fatalf("profile %+v, customization %v", export.Profile, export.Customization)
+	}
+
+	sizes := map[string][2]int{
+		"swipePreferences": {len(export.SwipePreferences), 1},
+		"swipes":           {len(export.Swipes), 2},
+		"matches":          {len(export.Matches), 1},
+		"notifications":    {len(export.Notifications), 1},
+		"items":            {len(export.Inventory.Items), 1},
+		"cases":            {len(export.Inventory.Cases), 1},
+		"achievements":     {len(export.Achievements), 1},
+		"captainOf":        {len(export.TeamHistory.CaptainOf), 2},
+		"participations":   {len(export.TeamHistory.Participations), 1},
+		"joinRequests":     {len(export.TeamHistory.JoinRequests), 1},
+		"invitesReceived":  {len(export.TeamHistory.InvitesReceived), 1},
+		"invitesSent":      {len(export.TeamHistory.InvitesSent), 1},
+		"sessions":         {len(export.Sessions), 1},
+	}
+	for name, size := range sizes {
+		if size[0] != size[1] {
+			t.Errorf("%s: %d records, want %d", name, size[0], size[1])
+		}
+	}
+	if team := export.TeamHistory.CurrentTeam; team == nil || team.ID != 10 {
+		t.Errorf("current team %+v", team)
+	}
+	// В архив попадают только свои данные
+	for _, n := range export.Notifications {
+		if n.UserID != 1 {
+			t.Errorf("foreign notification %+v", n)
+		}
+	}
+
+	if _, err := f.service.Export(context.Background(), 404); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("unknown user: %v", err)
+	}
+}
+
+func TestUserDataErase(t *testing.T) {
+	f := newUserDataFixture(t)
+	ctx := context.Background()
+
+	result, err := f.service.Erase(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Команда 10 переходит участнику с меньшим id, команда 20 распускается
+	if len(result.TeamsHandedOver) != 1 || result.TeamsHandedOver[10] != 3 {
+		t.Errorf("handed over %v, want team 10 to user 3", result.TeamsHandedOver)
+	}
+	if len(result.TeamsDissolved) != 1 || result.TeamsDissolved[0] != 20 {
+		t.Errorf("dissolved %v, want [20]", result.TeamsDissolved)
+	}
+	if result.SwipesDeleted != 2 || result.NotificationsDeleted != 1 || result.SessionsRevoked != 1 {
+		t.Errorf("result %+v", result)
+	}
+
+	var team models.Team
+	if err := f.db.First(&team, 10).Error; err != nil || team.CaptainID != 3 {
+		t.Errorf("team 10: captain %d, err %v", team.CaptainID, err)
+	}
+	if n := f.count(t, &models.Team{}, "id = ?", 20); n != 0 {
+		t.Error("solo team not deleted")
+	}
+	if n := f.count(t, &models.TeamJoinRequest{}, "team_id = ?", 20); n != 0 {
+		t.Error("join requests of the dissolved team kept")
+	}
+	if n := f.count(t, &models.TeamInvite{}, "team_id = ?", 20); n != 0 {
+		t.Error("invites of the dissolved team kept")
+	}
+	if n := f.count(t, &models.HackathonParticipant{}, "team_id = ?", 20); n != 0 {
+		t.Error("participants still point to the dissolved team")
+	}
+
+	var user models.User
+	if err := f.db.First(&user, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.ErasedAt == nil || user.TelegramUserID != -1 || user.Username != "deleted_1" ||
+		user.Name != "Удалённый пользователь" || user.Bio != "" || user.AvatarURL != "" ||
+		len(user.Skills) != 0 || len(user.Tags) != 0 || user.Experience != "" || user.ContactInfo != "" ||
+		user.Role != models.RoleUser || user.TeamID != nil || user.ProfileComplete || user.NotificationsEnabled {
+		t.Errorf("user not anonymized: %+v", user)
+	}
+
+	for _, p := range []struct {
+		model interface{}
+		where string
+	}{
+		{&models.Swipe{}, "target_user_id = 1"},
+		{&models.Notification{}, "user_id = 1"},
+		{&models.SwipePreference{}, "user_id = 1"},
+		{&models.CustomizationItem{}, "user_id = 1"},
+		{&models.UserCase{}, "user_id = 1"},
+		{&models.UserAchievement{}, "user_id = 1"},
+		{&models.ProfileCustomization{}, "user_id = 1"},
+		{&models.TeamJoinRequest{}, "user_id = 1"},
+		{&models.TeamInvite{}, "invited_user_id = 1 OR inviter_id = 1"},
+		{&models.AdminCredential{}, "user_id = 1"},
+	} {
+		if n := f.count(t, p.model, p.where); n != 0 {
+			t.Errorf("%T: %d rows kept", p.model, n)
+		}
+	}
+	// Чужие данные не тронуты, участие и мэтч остаются для статистики
+	if f.count(t, &models.Swipe{}, "target_user_id = 5") != 1 || f.count(t, &models.Notification{}, "user_id = 5") != 1 {
+		t.Error("other users' data deleted")
+	}
+	if f.count(t, &models.Match{}, "user_id = 1") != 1 {
+		t.Error("match deleted")
+	}
+	if f.count(t, &models.HackathonParticipant{}, "user_id = 1 AND team_id IS NULL AND status = 'left'") != 1 {
+		t.Error("participation not released")
+	}
+
+	if sessions, err := f.sessions.ListFamilies(ctx, 1); err != nil || len(sessions) != 0 {
+		t.Errorf("sessions %v, err %v", sessions, err)
+	}
+
+	if _, err := f.service.Erase(ctx, 1); !errors.Is(err, ErrUserAlreadyErased) {
+		t.Fatalf("second erase: %v", err)
+	}
+	if _, err := f.service.Erase(ctx, 404); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("unknown user: %v", err)
+	}
+}
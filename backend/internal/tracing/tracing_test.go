@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"backend/internal/config"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// useRecorder - глобальный TracerProvider, который складывает спаны в память
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	if _, err := Setup(context.Background(), config.TracingConfig{Exporter: config.TracingExporterNone}); err != nil {
+		t.Fatal(err)
+	}
+	if fields := Inject(context.Background()); len(fields) != 0 {
+		t.Fatalf("fields without a trace: %v", fields)
+	}
+
+	useRecorder(t)
+	ctx, span := Tracer().Start(context.Background(), "request")
+	defer span.End()
+
+	fields := Inject(ctx)
+	sc := span.SpanContext()
+	want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"
+	if fields["traceparent"] != want {
+		t.Fatalf("traceparent %q, want %q", fields["traceparent"], want)
+	}
+}
+
+type tracedRow struct {
+	ID    int64
+	Email string
+}
+
+func TestGormPluginSpans(t *testing.T) {
+	recorder := useRecorder(t)
+	db, err := gorm.Open(sqlite.Open("file:tracing?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&tracedRow{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Вне трейса спаны не создаются
+	db.Create(&tracedRow{Email: "first@example.com"})
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Fatalf("%d spans without a parent", len(spans))
+	}
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	var row tracedRow
+	db.WithContext(ctx).Where("email = ?", "first@example.com").First(&row)
+	db.WithContext(ctx).Where("id = ?", 404).First(&row)
+	parent.End()
+
+	var queries []sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if strings.HasPrefix(s.Name(), "gorm.query") {
+			queries = append(queries, s)
+		}
+	}
+	if len(queries) != 2 {
+		t.Fatalf("%d query spans, want 2", len(queries))
+	}
+	for _, s := range queries {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() || s.SpanKind() != trace.SpanKindClient {
+			t.Fatalf("span %s: parent %v, kind %v", s.Name(), s.Parent().SpanID(), s.SpanKind())
+		}
+		for _, attr := range s.Attributes() {
+			// В текст запроса значения не попадают
+			if attr.Key == semconv.DBQueryTextKey && strings.Contains(attr.Value.AsString(), "example.com") {
+				t.Fatalf("query text with values: %s", attr.Value.AsString())
+			}
+		}
+		// "Не найдено" - не ошибка
+		if s.Status().Code == codes.Error {
+			t.Fatalf("span %s marked as error", s.Name())
+		}
+	}
+}
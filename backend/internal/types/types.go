@@ -2,11 +2,14 @@ package types
 
 type Token struct {
 	Token string `json:"token" binding:"required"`
+	// Verifier для токена, выданного с challenge (PKCE-подобная привязка к браузеру)
+	CodeVerifier string `json:"codeVerifier,omitempty"`
 }
 
 type LoginAdmin struct {
 	UserName string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	TOTPCode string `json:"totpCode,omitempty"`
 }
 
 type RegisterUserRequest struct {
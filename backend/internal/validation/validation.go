@@ -0,0 +1,278 @@
+// Package validation - проверка тел запросов.
+//
+// Register добавляет в валидатор gin (тег binding) правила предметной области:
+//
+//	user_role, hackathon_status, team_status,
+//	rarity                                   - значения перечислений models
+//	experience, team_role                    - models.ExperienceLevels, models.TeamRoles
+//	image_url                                - http(s) URL или data:image/...
+//	css_value                                - цвет или градиент без url() и выражений
+//	date, date_before=Field                  - дата в одном из DateLayouts и порядок дат
+//
+// Error превращает ошибку ShouldBindJSON в ответ API: нарушения правил -
+// 422 со списком всех полей, битый JSON - 400.
+package validation
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// DateLayouts - форматы дат, которые принимает API
+var DateLayouts = []string{time.RFC3339, "2006-01-02", "2006-01-02T15:04:05"}
+
+// ParseDate - разобрать дату в одном из DateLayouts
+func ParseDate(value string) (time.Time, error) {
+	for _, layout := range DateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// ============================================
+// REGISTRATION
+// ============================================
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+)
+
+// Register - зарегистрировать правила в валидаторе gin; повторные вызовы ничего не делают
+func Register() error {
+	registerOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			registerErr = errors.New("gin validator is not go-playground/validator")
+			return
+		}
+
+		// В details - имена полей из JSON, а не из Go
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+
+		rules := map[string]validator.Func{
+			"user_role":        func(fl validator.FieldLevel) bool { return models.UserRole(fl.Field().String()).IsValid() },
+			"hackathon_status": func(fl validator.FieldLevel) bool { return models.HackathonStatus(fl.Field().String()).IsValid() },
+			"team_status":      func(fl validator.FieldLevel) bool { return models.TeamStatus(fl.Field().String()).IsValid() },
+			"rarity":           func(fl validator.FieldLevel) bool { return models.RarityType(fl.Field().String()).IsValid() },
+			"experience":       func(fl validator.FieldLevel) bool { return contains(models.ExperienceLevels, fl.Field().String()) },
+			"team_role":        func(fl validator.FieldLevel) bool { return contains(models.TeamRoles, fl.Field().String()) },
+			"image_url":        func(fl validator.FieldLevel) bool { return isImageURL(fl.Field().String()) },
+			"css_value":        func(fl validator.FieldLevel) bool { return isCSSValue(fl.Field().String()) },
+			"date":             isDate,
+			"date_before":      isDateBefore,
+		}
+		for tag, fn := range rules {
+			if err := v.RegisterValidation(tag, fn); err != nil {
+				registerErr = fmt.Errorf("failed to register %s: %w", tag, err)
+				return
+			}
+		}
+	})
+	return registerErr
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isImageURL - пустая строка допустима (сброс картинки)
+func isImageURL(value string) bool {
+	if value == "" {
+		return true
+	}
+	if strings.HasPrefix(value, "data:image/") {
+		return strings.Contains(value, ";base64,")
+	}
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// cssValuePattern - цвета (#fff, rgb(), hsl()), имена и linear/radial-gradient
+var cssValuePattern = regexp.MustCompile(`^[a-zA-Z0-9#%.,()\s+-]+$`)
+
+// isCSSValue - пустая строка допустима (сброс оформления)
+func isCSSValue(value string) bool {
+	if value == "" {
+		return true
+	}
+	if !cssValuePattern.MatchString(value) {
+		return false
+	}
+	lower := strings.ToLower(value)
+	// url() и expression() подгружают ресурсы или выполняют код
+	return !strings.Contains(lower, "url(") && !strings.Contains(lower, "expression(")
+}
+
+func isDate(fl validator.FieldLevel) bool {
+	_, err := ParseDate(fl.Field().String())
+	return err == nil
+}
+
+// isDateBefore - поле раньше поля из параметра; пустые и неразборчивые даты
+// не проверяются здесь (это задача omitempty и date)
+func isDateBefore(fl validator.FieldLevel) bool {
+	other := fl.Parent().FieldByName(fl.Param())
+	if !other.IsValid() || other.Kind() != reflect.String {
+		return false
+	}
+	if fl.Field().String() == "" || other.String() == "" {
+		return true
+	}
+	this, err1 := ParseDate(fl.Field().String())
+	that, err2 := ParseDate(other.String())
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	return this.Before(that)
+}
+
+// ============================================
+// ERRORS
+// ============================================
+
+// Error - ответ API для ошибки ShouldBindJSON
+func Error(err error) *apierror.Error {
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		details := make([]apierror.FieldError, 0, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			details = append(details, apierror.FieldError{Field: fieldPath(fe), Message: message(fe)})
+		}
+		return apierror.Validation(details...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return apierror.Validation(apierror.FieldError{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonType(typeErr.Type),
+		})
+	}
+
+	return apierror.BadRequest(apierror.CodeBadRequest, "invalid request body")
+}
+
+// fieldPath - путь поля без имени структуры: "lookingFor[1]"
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return "must contain at most " + fe.Param() + " items"
+		}
+		return "must be at most " + fe.Param() + " characters"
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return "must contain at least " + fe.Param() + " items"
+		}
+		return "must be at least " + fe.Param()
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "lte":
+		return "must be less than or equal to " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "user_role":
+		return "must be one of: " + joinValues(models.RoleUser, models.RoleHackathonCreator, models.RoleAdmin)
+	case "hackathon_status":
+		return "must be one of: " + joinValues(models.HackathonStatusDraft, models.HackathonStatusRegistrationOpen,
+			models.HackathonStatusActive, models.HackathonStatusCompleted)
+	case "team_status":
+		return "must be one of: " + joinValues(models.TeamStatusLooking, models.TeamStatusReady, models.TeamStatusClosed)
+	case "rarity":
+		return "must be one of: " + joinValues(models.RarityCommon, models.RarityUncommon, models.RarityRare,
+			models.RarityEpic, models.RarityLegendary)
+	case "experience":
+		return "must be one of: " + strings.Join(models.ExperienceLevels, ", ")
+	case "team_role":
+		return "must be one of: " + strings.Join(models.TeamRoles, ", ")
+	case "image_url":
+		return "must be an http(s) URL or a data:image URI"
+	case "css_value":
+		return "must be a CSS color or gradient"
+	case "date":
+		return "must be a date in RFC3339 or YYYY-MM-DD format"
+	case "date_before":
+		return "must be before " + lowerFirst(fe.Param())
+	}
+	return "is invalid"
+}
+
+func joinValues[T ~string](values ...T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = string(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+// DateOrder - дедлайн регистрации до начала, начало до конца; nil-даты пропускаются.
+// Для частичных обновлений, где часть дат уже сохранена и тег date_before не видит их.
+func DateOrder(registrationDeadline, start, end *time.Time) []apierror.FieldError {
+	var details []apierror.FieldError
+	if registrationDeadline != nil && start != nil && !registrationDeadline.Before(*start) {
+		details = append(details, apierror.FieldError{Field: "registrationDeadline", Message: "must be before startDate"})
+	}
+	if start != nil && end != nil && !start.Before(*end) {
+		details = append(details, apierror.FieldError{Field: "startDate", Message: "must be before endDate"})
+	}
+	return details
+}
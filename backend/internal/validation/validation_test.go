@@ -0,0 +1,171 @@
+package validation
+
+import (
+	"backend/internal/apierror"
+	"backend/internal/models"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func validate(t *testing.T, obj interface{}) error {
+	t.Helper()
+	if err := Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+func intPtr(v int) *int { return &v }
+
+type ruleRequest struct {
+	Role       string   `json:"role" binding:"omitempty,user_role"`
+	Status     string   `json:"status" binding:"omitempty,hackathon_status"`
+	TeamStatus string   `json:"teamStatus" binding:"omitempty,team_status"`
+	Experience string   `json:"experience" binding:"omitempty,experience"`
+	LookingFor []string `json:"lookingFor" binding:"omitempty,dive,team_role"`
+	ImageUrl   string   `json:"imageUrl" binding:"omitempty,image_url"`
+	Background string   `json:"background" binding:"omitempty,css_value"`
+	StartDate  string   `json:"startDate" binding:"omitempty,date,date_before=EndDate"`
+	EndDate    string   `json:"endDate" binding:"omitempty,date"`
+	MaxTeams   *int     `json:"maxTeams" binding:"omitempty,gte=0"`
+	Rarity     string   `json:"rarity" binding:"omitempty,rarity"`
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   ruleRequest
+		field string // "" - запрос корректен
+	}{
+		{"empty request", ruleRequest{}, ""},
+		{"valid values", ruleRequest{
+			Role: "admin", Status: "active", TeamStatus: "closed",
+			ImageUrl: "https://example.com/a.png", Background: "linear-gradient(90deg, #fff 0%, rgb(0,0,0) 100%)",
+			StartDate: "2026-10-01", EndDate: "2026-10-03T10:00:00Z", MaxTeams: intPtr(0), Rarity: "legendary",
+		}, ""},
+		{"data image", ruleRequest{ImageUrl: "data:image/png;base64,AAAA"}, ""},
+		{"unknown role", ruleRequest{Role: "root"}, "role"},
+		{"unknown hackathon status", ruleRequest{Status: "archived"}, "status"},
+		{"unknown team status", ruleRequest{TeamStatus: "open"}, "teamStatus"},
+		{"unknown experience", ruleRequest{Experience: "guru"}, "experience"},
+		{"unknown team role", ruleRequest{LookingFor: []string{"backend", "wizard"}}, "lookingFor[1]"},
+		{"javascript url", ruleRequest{ImageUrl: "javascript:alert(1)"}, "imageUrl"},
+		{"data uri without base64", ruleRequest{ImageUrl: "data:image/svg+xml,<svg/>"}, "imageUrl"},
+		{"css url()", ruleRequest{Background: "url(http://evil)"}, "background"},
+		{"css expression()", ruleRequest{Background: "expression(alert(1))"}, "background"},
+		{"bad date", ruleRequest{EndDate: "03.10.2026"}, "endDate"},
+		{"start after end", ruleRequest{StartDate: "2026-10-05", EndDate: "2026-10-03"}, "startDate"},
+		{"negative max teams", ruleRequest{MaxTeams: intPtr(-1)}, "maxTeams"},
+		{"unknown rarity", ruleRequest{Rarity: "mythic"}, "rarity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(t, &tt.req)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			apiErr := Error(err)
+			if apiErr.Status != http.StatusUnprocessableEntity || len(apiErr.Details) != 1 {
+				t.Fatalf("got %+v, want one field error", apiErr)
+			}
+			if apiErr.Details[0].Field != tt.field {
+				t.Fatalf("field %q, want %q", apiErr.Details[0].Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	type request struct {
+		Name string `json:"name" binding:"required,max=3"`
+		Role string `json:"role" binding:"omitempty,user_role"`
+	}
+
+	err := validate(t, &request{Name: "", Role: "root"})
+	got := Error(err).Details
+	want := []apierror.FieldError{
+		{Field: "name", Message: "is required"},
+		{Field: "role", Message: "must be one of: user, hackathon_creator, admin"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("details %+v, want %+v", got, want)
+	}
+
+	// max для строк считается в символах
+	if err := validate(t, &request{Name: "жжж"}); err != nil {
+		t.Fatalf("3 cyrillic characters rejected: %v", err)
+	}
+	if got := Error(validate(t, &request{Name: "жжжж"})).Details[0].Message; got != "must be at most 3 characters" {
+		t.Fatalf("max message %q", got)
+	}
+}
+
+func TestGiveCaseRequestRarity(t *testing.T) {
+	req := models.GiveCaseRequest{UserIDs: []int64{1}, CaseType: "event", CaseName: "Приз", Rarity: "mythic"}
+	apiErr := Error(validate(t, &req))
+	if apiErr.Status != http.StatusUnprocessableEntity || len(apiErr.Details) != 1 || apiErr.Details[0].Field != "rarity" {
+		t.Fatalf("got %+v, want 422 for rarity", apiErr)
+	}
+	if want := "must be one of: common, uncommon, rare, epic, legendary"; apiErr.Details[0].Message != want {
+		t.Fatalf("message %q", apiErr.Details[0].Message)
+	}
+
+	req.Rarity = models.RarityEpic
+	if err := validate(t, &req); err != nil {
+		t.Fatalf("valid rarity rejected: %v", err)
+	}
+}
+
+func TestErrorForBadJSON(t *testing.T) {
+	var req struct {
+		TeamSize int `json:"teamSize"`
+	}
+	typeErr := json.Unmarshal([]byte(`{"teamSize":"four"}`), &req)
+	apiErr := Error(typeErr)
+	if apiErr.Status != http.StatusUnprocessableEntity || apiErr.Details[0].Field != "teamSize" || apiErr.Details[0].Message != "must be a number" {
+		t.Fatalf("type error: %+v", apiErr)
+	}
+
+	syntaxErr := json.Unmarshal([]byte(`{"teamSize":`), &req)
+	if apiErr := Error(syntaxErr); apiErr.Status != http.StatusBadRequest {
+		t.Fatalf("syntax error: status %d", apiErr.Status)
+	}
+}
+
+func TestDateOrder(t *testing.T) {
+	day := func(d int) *time.Time {
+		v := time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+
+	if details := DateOrder(day(1), day(2), day(3)); len(details) != 0 {
+		t.Fatalf("valid order rejected: %+v", details)
+	}
+	if details := DateOrder(nil, day(2), nil); len(details) != 0 {
+		t.Fatalf("missing dates rejected: %+v", details)
+	}
+
+	details := DateOrder(day(3), day(2), day(1))
+	if len(details) != 2 || details[0].Field != "registrationDeadline" || details[1].Field != "startDate" {
+		t.Fatalf("unexpected details %+v", details)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, value := range []string{"2026-10-17", "2026-10-17T12:30:00", "2026-10-17T12:30:00+03:00"} {
+		if _, err := ParseDate(value); err != nil {
+			t.Errorf("ParseDate(%q): %v", value, err)
+		}
+	}
+	if _, err := ParseDate("17.10.2026"); err == nil {
+		t.Error("ParseDate accepted 17.10.2026")
+	}
+}
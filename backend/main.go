@@ -2,9 +2,45 @@ package main
 
 import (
 	_ "backend/docs"
+	"backend/internal/cli"
+	"backend/internal/config"
 	"backend/internal/handlers"
+	"backend/internal/logging"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 )
 
 func main() {
-	handlers.StartServer()
+	// Подкоманды (управление админами и т.п.); без подкоманды - HTTP сервер, флаги идут в конфиг
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+
+	logger, err := logging.New(cfg.Log, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+	// Через slog.SetDefault в тот же логгер попадает и вывод пакета log (gorm, библиотеки)
+	slog.SetDefault(logger)
+
+	// SIGTERM от docker/k8s при выкатке: дорабатываем запросы и уведомления и выходим
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := handlers.StartServer(ctx, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
 }
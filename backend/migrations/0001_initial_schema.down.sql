@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS profile_customizations;
+DROP TABLE IF EXISTS user_achievements;
+DROP TABLE IF EXISTS user_cases;
+DROP TABLE IF EXISTS customization_items;
+DROP TABLE IF EXISTS case_items;
+DROP TABLE IF EXISTS case_contents;
+DROP TABLE IF EXISTS cases;
+DROP TABLE IF EXISTS clothes;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS swipe_preferences;
+DROP TABLE IF EXISTS matches;
+DROP TABLE IF EXISTS swipes;
+DROP TABLE IF EXISTS team_invites;
+DROP TABLE IF EXISTS team_join_requests;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS hackathon_participants;
+DROP TABLE IF EXISTS hackathons;
+DROP TABLE IF EXISTS users;
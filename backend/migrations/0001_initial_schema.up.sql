@@ -0,0 +1,245 @@
+-- Базовая схема: то, что раньше создавал AutoMigrate.
+-- IF NOT EXISTS везде, чтобы миграцию можно было применить к базе, которую уже вёл AutoMigrate.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                    BIGSERIAL PRIMARY KEY,
+    telegram_user_id      BIGINT NOT NULL,
+    username              TEXT NOT NULL,
+    authorized            BOOLEAN DEFAULT false,
+    role                  VARCHAR(30) DEFAULT 'user',
+    name                  TEXT,
+    bio                   TEXT,
+    avatar_url            TEXT,
+    skills                TEXT[],
+    verified_skills       TEXT[],
+    experience            TEXT,
+    looking_for           TEXT[],
+    contact_info          TEXT,
+    pts                   BIGINT DEFAULT 0,
+    mmr                   BIGINT DEFAULT 1000,
+    skill_rating          BIGINT,
+    tags                  TEXT[],
+    team_id               BIGINT,
+    current_hackathon_id  BIGINT,
+    profile_complete      BOOLEAN DEFAULT false,
+    notifications_enabled BOOLEAN DEFAULT true,
+    created_at            TIMESTAMPTZ,
+    updated_at            TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_telegram_user_id ON users (telegram_user_id);
+CREATE INDEX IF NOT EXISTS idx_users_team_id ON users (team_id);
+CREATE INDEX IF NOT EXISTS idx_users_current_hackathon_id ON users (current_hackathon_id);
+
+CREATE TABLE IF NOT EXISTS hackathons (
+    id                        BIGSERIAL PRIMARY KEY,
+    name                      TEXT NOT NULL,
+    description               TEXT,
+    image_url                 TEXT,
+    creator_id                BIGINT,
+    status                    VARCHAR(30) DEFAULT 'draft',
+    start_date                TIMESTAMPTZ,
+    end_date                  TIMESTAMPTZ,
+    registration_deadline     TIMESTAMPTZ,
+    age_limit                 BIGINT,
+    tags                      TEXT[],
+    required_stack            TEXT[],
+    team_size                 BIGINT NOT NULL DEFAULT 4,
+    max_teams                 BIGINT DEFAULT 0,
+    configuration_template_id BIGINT,
+    created_at                TIMESTAMPTZ,
+    updated_at                TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_hackathons_creator_id ON hackathons (creator_id);
+CREATE INDEX IF NOT EXISTS idx_hackathons_configuration_template_id ON hackathons (configuration_template_id);
+
+CREATE TABLE IF NOT EXISTS hackathon_participants (
+    id           BIGSERIAL PRIMARY KEY,
+    hackathon_id BIGINT,
+    user_id      BIGINT,
+    team_id      BIGINT,
+    status       VARCHAR(20) DEFAULT 'looking',
+    created_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_hackathon_participants_hackathon_id ON hackathon_participants (hackathon_id);
+CREATE INDEX IF NOT EXISTS idx_hackathon_participants_user_id ON hackathon_participants (user_id);
+CREATE INDEX IF NOT EXISTS idx_hackathon_participants_team_id ON hackathon_participants (team_id);
+
+CREATE TABLE IF NOT EXISTS teams (
+    id           BIGSERIAL PRIMARY KEY,
+    hackathon_id BIGINT,
+    name         TEXT NOT NULL,
+    description  TEXT,
+    captain_id   BIGINT,
+    status       VARCHAR(20) DEFAULT 'looking',
+    invite_code  VARCHAR(20),
+    background   VARCHAR(100),
+    border_color VARCHAR(50),
+    name_color   VARCHAR(100),
+    avatar_url   TEXT,
+    created_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_teams_hackathon_id ON teams (hackathon_id);
+CREATE INDEX IF NOT EXISTS idx_teams_captain_id ON teams (captain_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_teams_invite_code ON teams (invite_code);
+
+CREATE TABLE IF NOT EXISTS team_join_requests (
+    id         BIGSERIAL PRIMARY KEY,
+    team_id    BIGINT,
+    user_id    BIGINT,
+    status     VARCHAR(20) DEFAULT 'pending',
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_team_join_requests_team_id ON team_join_requests (team_id);
+CREATE INDEX IF NOT EXISTS idx_team_join_requests_user_id ON team_join_requests (user_id);
+
+CREATE TABLE IF NOT EXISTS team_invites (
+    id              BIGSERIAL PRIMARY KEY,
+    team_id         BIGINT,
+    invited_user_id BIGINT,
+    inviter_id      BIGINT,
+    status          VARCHAR(20) DEFAULT 'pending',
+    created_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_team_invites_team_id ON team_invites (team_id);
+CREATE INDEX IF NOT EXISTS idx_team_invites_invited_user_id ON team_invites (invited_user_id);
+CREATE INDEX IF NOT EXISTS idx_team_invites_inviter_id ON team_invites (inviter_id);
+
+CREATE TABLE IF NOT EXISTS swipes (
+    id             BIGSERIAL PRIMARY KEY,
+    swiper_team_id BIGINT,
+    target_user_id BIGINT,
+    action         VARCHAR(20),
+    created_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_swipes_swiper_team_id ON swipes (swiper_team_id);
+CREATE INDEX IF NOT EXISTS idx_swipes_target_user_id ON swipes (target_user_id);
+
+CREATE TABLE IF NOT EXISTS matches (
+    id         BIGSERIAL PRIMARY KEY,
+    team_id    BIGINT,
+    user_id    BIGINT,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_matches_team_id ON matches (team_id);
+CREATE INDEX IF NOT EXISTS idx_matches_user_id ON matches (user_id);
+
+CREATE TABLE IF NOT EXISTS swipe_preferences (
+    id                   BIGSERIAL PRIMARY KEY,
+    user_id              BIGINT,
+    hackathon_id         BIGINT,
+    min_mmr              BIGINT,
+    max_mmr              BIGINT,
+    preferred_skills     TEXT[],
+    preferred_experience TEXT[],
+    preferred_roles      TEXT[],
+    verified_only        BOOLEAN DEFAULT false,
+    created_at           TIMESTAMPTZ,
+    updated_at           TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_hackathon_pref ON swipe_preferences (user_id, hackathon_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT,
+    type       VARCHAR(50),
+    title      TEXT,
+    message    TEXT,
+    data       JSONB,
+    is_read    BOOLEAN DEFAULT false,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id);
+
+-- Старая система предметов (кейсы с одеждой)
+CREATE TABLE IF NOT EXISTS clothes (
+    id          BIGSERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT,
+    rarity      VARCHAR(20) NOT NULL,
+    slot        VARCHAR(20) NOT NULL,
+    image_path  TEXT NOT NULL,
+    created_at  TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS cases (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT,
+    hackathon_id BIGINT,
+    opened       BOOLEAN DEFAULT false,
+    opened_at    TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_cases_user_id ON cases (user_id);
+CREATE INDEX IF NOT EXISTS idx_cases_hackathon_id ON cases (hackathon_id);
+
+CREATE TABLE IF NOT EXISTS case_contents (
+    id         BIGSERIAL PRIMARY KEY,
+    case_id    BIGINT,
+    item_type  TEXT NOT NULL,
+    item_id    BIGINT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_case_contents_case_id ON case_contents (case_id);
+
+CREATE TABLE IF NOT EXISTS case_items (
+    item_type TEXT,
+    item_id   BIGINT
+);
+
+-- Кастомизация профиля
+CREATE TABLE IF NOT EXISTS customization_items (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    item_id     TEXT NOT NULL,
+    item_type   VARCHAR(30) NOT NULL,
+    rarity      VARCHAR(20) NOT NULL,
+    name        TEXT NOT NULL,
+    value       TEXT,
+    is_equipped BOOLEAN DEFAULT false,
+    quantity    BIGINT DEFAULT 1,
+    obtained_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_customization_items_user_id ON customization_items (user_id);
+
+CREATE TABLE IF NOT EXISTS user_cases (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    case_type   TEXT NOT NULL,
+    case_name   TEXT NOT NULL,
+    rarity      VARCHAR(20) NOT NULL,
+    is_opened   BOOLEAN DEFAULT false,
+    received_at TIMESTAMPTZ,
+    opened_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_user_cases_user_id ON user_cases (user_id);
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT NOT NULL,
+    achievement_id TEXT NOT NULL,
+    name           TEXT NOT NULL,
+    description    TEXT,
+    icon_url       TEXT,
+    rarity         VARCHAR(20),
+    progress       BIGINT DEFAULT 0,
+    max_progress   BIGINT DEFAULT 100,
+    is_completed   BOOLEAN DEFAULT false,
+    earned_at      TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_user_achievements_user_id ON user_achievements (user_id);
+
+CREATE TABLE IF NOT EXISTS profile_customizations (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT NOT NULL,
+    background_id   TEXT,
+    name_color_id   TEXT,
+    avatar_frame_id TEXT,
+    title_id        TEXT,
+    effect_id       TEXT,
+    badge1_id       TEXT,
+    badge2_id       TEXT,
+    badge3_id       TEXT,
+    updated_at      TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profile_customizations_user_id ON profile_customizations (user_id);
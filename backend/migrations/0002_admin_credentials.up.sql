@@ -0,0 +1,16 @@
+-- Персональные учётные данные админ-панели (bcrypt, TOTP, блокировка)
+CREATE TABLE IF NOT EXISTS admin_credentials (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT NOT NULL,
+    login           TEXT NOT NULL,
+    password_hash   TEXT NOT NULL,
+    totp_secret     TEXT,
+    totp_enabled    BOOLEAN DEFAULT false,
+    failed_attempts BIGINT DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    last_login_at   TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_credentials_user_id ON admin_credentials (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_credentials_login ON admin_credentials (login);
@@ -0,0 +1,16 @@
+-- API ключи организаторов, привязанные к хакатону
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            BIGSERIAL PRIMARY KEY,
+    hackathon_id  BIGINT NOT NULL,
+    created_by_id BIGINT NOT NULL,
+    name          TEXT NOT NULL,
+    prefix        VARCHAR(16) NOT NULL,
+    key_hash      VARCHAR(64) NOT NULL,
+    permissions   TEXT[],
+    last_used_at  TIMESTAMPTZ,
+    revoked_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_hackathon_id ON api_keys (hackathon_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_created_by_id ON api_keys (created_by_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
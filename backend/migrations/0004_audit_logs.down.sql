@@ -0,0 +1,2 @@
+-- Журнал аудита не откатывается: его записи нельзя терять.
+-- Если таблицу действительно нужно удалить, сделайте это вручную.
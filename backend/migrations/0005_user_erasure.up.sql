@@ -0,0 +1,2 @@
+-- Отметка об удалении персональных данных по запросу пользователя
+ALTER TABLE users ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
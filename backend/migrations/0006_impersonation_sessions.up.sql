@@ -0,0 +1,15 @@
+-- Сеансы просмотра приложения админом от имени пользователя
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+    id         VARCHAR(64) PRIMARY KEY,
+    admin_id   BIGINT NOT NULL,
+    user_id    BIGINT NOT NULL,
+    reason     TEXT NOT NULL,
+    ip         VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    ended_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_admin_id ON impersonation_sessions (admin_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_user_id ON impersonation_sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_created_at ON impersonation_sessions (created_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notifications_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_hackathons_created_at_id;
+DROP INDEX IF EXISTS idx_teams_hackathon_id_created_at_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
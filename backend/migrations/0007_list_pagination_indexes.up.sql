@@ -0,0 +1,5 @@
+-- Индексы курсорной пагинации списков: (столбец сортировки, id) по умолчанию
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_teams_hackathon_id_created_at_id ON teams (hackathon_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_hackathons_created_at_id ON hackathons (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id_created_at_id ON notifications (user_id, created_at, id);
@@ -0,0 +1 @@
+ALTER TABLE admin_credentials DROP COLUMN IF EXISTS totp_last_step;
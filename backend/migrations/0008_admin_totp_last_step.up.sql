@@ -0,0 +1,2 @@
+-- Шаг последнего принятого TOTP кода: повтор того же кода в окне допуска отклоняется
+ALTER TABLE admin_credentials ADD COLUMN IF NOT EXISTS totp_last_step BIGINT DEFAULT 0;
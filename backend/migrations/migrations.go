@@ -0,0 +1,10 @@
+// Package migrations - SQL миграции схемы БД, вшитые в бинарник.
+//
+// Каждая версия - пара файлов NNNN_name.up.sql и NNNN_name.down.sql.
+// Номера только растут; применённую миграцию не редактируют - вместо этого добавляют новую.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -1,20 +0,0 @@
--- Team customization fields will be added by GORM AutoMigrate
--- This migration only creates tables that GORM doesn't manage
-
--- Create notifications table if not exists
-CREATE TABLE IF NOT EXISTS notifications (
-    id BIGSERIAL PRIMARY KEY,
-    user_id BIGINT NOT NULL,
-    type VARCHAR(50) NOT NULL,
-    title VARCHAR(255) NOT NULL,
-    message TEXT,
-    data JSONB,
-    is_read BOOLEAN DEFAULT FALSE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
-CREATE INDEX IF NOT EXISTS idx_notifications_is_read ON notifications(user_id, is_read);
-
--- team_join_requests table will be created by GORM AutoMigrate
--- No manual creation needed
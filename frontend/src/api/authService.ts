@@ -127,7 +127,7 @@ export const authService = {
       throw new Error('Telegram initData not available');
     }
 
-    const response = await axiosClient.post<AuthResponse>('/auth/telegram', {
+    const response = await axiosClient.post<AuthResponse>('/api/auth/telegram', {
       initData: data,
     });
 
@@ -168,7 +168,9 @@ export const authService = {
   logout: async (): Promise<void> => {
     try {
       // Попытка сделать запрос на сервер (для инвалидации токена)
-      await axiosClient.post('/auth/logout');
+      await axiosClient.post('/api/auth/logout', {
+        refreshToken: tokenUtils.getRefreshToken() || undefined,
+      });
     } catch (error) {
       // Игнорируем ошибки logout
       console.log('Logout request failed, clearing local tokens');
@@ -187,7 +189,7 @@ export const authService = {
       throw new Error('No refresh token available');
     }
 
-    const response = await axiosClient.post<AuthResponse>('/auth/refresh', {
+    const response = await axiosClient.post<AuthResponse>('/api/auth/refresh', {
       refreshToken,
     });
 
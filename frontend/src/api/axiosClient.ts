@@ -1,4 +1,4 @@
-import axios, { AxiosError, AxiosResponse, InternalAxiosRequestConfig } from 'axios';
+import axios, { AxiosError, AxiosRequestConfig, AxiosResponse, InternalAxiosRequestConfig } from 'axios';
 
 // Получаем базовый URL из переменных окружения
 // В продакшене через nginx все /api/* запросы проксируются на backend
@@ -42,6 +42,54 @@ axiosClient.interceptors.request.use(
   }
 );
 
+/**
+ * Обновление access токена по refresh токену.
+ * Один запрос на все параллельные 401: refresh токен одноразовый,
+ * повторное использование отзывает сессию на сервере.
+ */
+let refreshPromise: Promise<string | null> | null = null;
+
+const refreshAccessToken = (): Promise<string | null> => {
+  if (!refreshPromise) {
+    const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY);
+    if (!refreshToken) {
+      return Promise.resolve(null);
+    }
+
+    refreshPromise = axios
+      .post(`${API_BASE_URL}/api/auth/refresh`, { refreshToken })
+      .then((response) => {
+        localStorage.setItem(TOKEN_KEY, response.data.token);
+        localStorage.setItem(REFRESH_TOKEN_KEY, response.data.refreshToken);
+        return response.data.token as string;
+      })
+      .catch(() => {
+        localStorage.removeItem(REFRESH_TOKEN_KEY);
+        return null;
+      })
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
+/**
+ * Idempotency-Key для POST, повтор которых создал бы дубликат (свайп, приглашение, кейс).
+ * Ключ создаётся один раз на действие; повтор с ним же получает сохранённый ответ backend.
+ */
+export const IDEMPOTENCY_KEY_HEADER = 'Idempotency-Key';
+const MAX_IDEMPOTENT_RETRIES = 2;
+
+const newIdempotencyKey = (): string =>
+  typeof crypto !== 'undefined' && 'randomUUID' in crypto
+    ? crypto.randomUUID()
+    : `${Date.now().toString(36)}-${Math.random().toString(36).slice(2)}`;
+
+export const withIdempotencyKey = (): AxiosRequestConfig => ({
+  headers: { [IDEMPOTENCY_KEY_HEADER]: newIdempotencyKey() },
+});
+
 /**
  * Response Interceptor - обработка ошибок
  */
@@ -50,7 +98,31 @@ axiosClient.interceptors.response.use(
     return response;
   },
   async (error: AxiosError) => {
-    
+    const originalRequest = error.config as
+      | (InternalAxiosRequestConfig & { _retry?: boolean; _idempotentRetries?: number })
+      | undefined;
+
+    // Сеть оборвалась, а у запроса есть Idempotency-Key - повторяем с тем же ключом:
+    // если первый запрос дошёл, backend вернёт его ответ, а не выполнит действие ещё раз
+    if (!error.response && originalRequest?.headers?.[IDEMPOTENCY_KEY_HEADER]) {
+      const attempt = (originalRequest._idempotentRetries ?? 0) + 1;
+      if (attempt <= MAX_IDEMPOTENT_RETRIES) {
+        originalRequest._idempotentRetries = attempt;
+        await new Promise((resolve) => setTimeout(resolve, 500 * attempt));
+        return axiosClient(originalRequest);
+      }
+    }
+
+    // Access токен истёк - пробуем обновить его и повторить запрос один раз
+    if (error.response?.status === 401 && originalRequest && !originalRequest._retry) {
+      originalRequest._retry = true;
+      const newToken = await refreshAccessToken();
+      if (newToken) {
+        originalRequest.headers.Authorization = `Bearer ${newToken}`;
+        return axiosClient(originalRequest);
+      }
+    }
+
     // Если получили 401 - токен истёк или невалидный
     // НЕ удаляем токены автоматически - пользователь сам выйдет или получит новый токен
     if (error.response?.status === 401) {
@@ -1,10 +1,34 @@
-import axiosClient from './axiosClient';
+import axiosClient, { withIdempotencyKey } from './axiosClient';
 import { User, Hackathon, Team, Invite, UserSkill, UserRole, UserStatus, GamificationTitle } from '../types';
 
 // ============================================
 // HELPER FUNCTIONS
 // ============================================
 
+// Страница списка: GET /api/...?limit=&cursor=&sort=&filter[name]=
+export interface Page<T> {
+  items: T[];
+  nextCursor: string | null;
+  total: number;
+}
+
+// Параметры страницы списка; cursor - nextCursor предыдущей страницы
+export interface PageOptions {
+  search?: string;
+  sort?: string;
+  limit?: number;
+  cursor?: string | null;
+}
+
+// Query-параметры страницы для axios; пустые значения не передаются
+const pageParams = (options: PageOptions, filters: Record<string, string | undefined> = {}) => ({
+  limit: options.limit,
+  sort: options.sort,
+  cursor: options.cursor || undefined,
+  'filter[search]': options.search || undefined,
+  ...Object.fromEntries(Object.entries(filters).map(([name, value]) => [`filter[${name}]`, value || undefined])),
+});
+
 // Трансформирует роль с бэкенда в роль фронтенда
 const mapBackendRole = (role: string): UserRole => {
   const roleMap: Record<string, UserRole> = {
@@ -144,8 +168,6 @@ export interface UpdateProfileData {
   skills?: UserSkill[];
   experience?: string;
   avatar?: string;
-  pts?: number;
-  mmr?: number;
   lookingFor?: string[];
   verifiedSkills?: string[]; // Для прямой отправки verified skills
 }
@@ -158,8 +180,6 @@ const transformProfileForBackend = (data: UpdateProfileData) => {
   if (data.bio !== undefined) result.bio = data.bio;
   if (data.experience !== undefined) result.experience = data.experience;
   if (data.avatar !== undefined) result.avatarUrl = data.avatar;
-  if (data.pts !== undefined) result.pts = data.pts;
-  if (data.mmr !== undefined) result.mmr = data.mmr;
   if (data.lookingFor !== undefined) result.lookingFor = data.lookingFor;
   
   // Преобразуем skills из UserSkill[] в string[]
@@ -227,6 +247,21 @@ export const userService = {
     });
     return response.data;
   },
+
+  /**
+   * Выгрузить все свои данные (JSON-архив)
+   */
+  exportMyData: async (): Promise<Blob> => {
+    const response = await axiosClient.get('/api/users/me/export', { responseType: 'blob' });
+    return response.data;
+  },
+
+  /**
+   * Удалить свои персональные данные. Необратимо: аккаунт обезличивается, все сессии закрываются
+   */
+  eraseMe: async (): Promise<void> => {
+    await axiosClient.post('/api/users/me/erase', { confirm: true });
+  },
 };
 
 // ============================================
@@ -246,10 +281,15 @@ export interface CreateHackathonData {
 
 export const hackathonService = {
   /**
-   * Получить список всех хакатонов
+   * Получить страницу хакатонов
+   * sort: createdAt | startDate | name, с "-" - по убыванию
    */
-  getAll: async (): Promise<Hackathon[]> => {
-    const response = await axiosClient.get<Hackathon[]>('/api/hackathons');
+  getPage: async (options: PageOptions & {
+    status?: 'draft' | 'registration_open' | 'active' | 'completed';
+  } = {}): Promise<Page<Hackathon>> => {
+    const response = await axiosClient.get<Page<Hackathon>>('/api/hackathons', {
+      params: pageParams(options, { status: options.status }),
+    });
     return response.data;
   },
 
@@ -339,10 +379,14 @@ export const swipeService = {
    * Отправить свайп
    */
   swipe: async (targetUserId: number, action: 'like' | 'pass'): Promise<SwipeResponse> => {
-    const response = await axiosClient.post<SwipeResponse>('/api/swipe', {
-      targetUserId,
-      action,
-    });
+    const response = await axiosClient.post<SwipeResponse>(
+      '/api/swipe',
+      {
+        targetUserId,
+        action,
+      },
+      withIdempotencyKey()
+    );
     return response.data;
   },
 
@@ -397,10 +441,10 @@ export const notificationService = {
    * Получить уведомления
    */
   getAll: async (unreadOnly: boolean = false, limit: number = 50): Promise<{ notifications: Notification[]; unreadCount: number }> => {
-    const params: any = { limit };
-    if (unreadOnly) params.unread = 'true';
-    const response = await axiosClient.get('/api/notifications', { params });
-    return response.data;
+    const params: Record<string, string | number> = { limit };
+    if (unreadOnly) params['filter[unread]'] = 'true';
+    const response = await axiosClient.get<Page<Notification> & { unreadCount: number }>('/api/notifications', { params });
+    return { notifications: response.data.items, unreadCount: response.data.unreadCount };
   },
 
   /**
@@ -496,11 +540,13 @@ export interface CandidateCompatibility {
 
 export const teamService = {
   /**
-   * Получить все команды (для хакатона)
+   * Получить страницу команд (для хакатона)
+   * sort: createdAt | name, с "-" - по убыванию
    */
-  getAll: async (hackathonId?: string): Promise<Team[]> => {
-    const params = hackathonId ? { hackathonId } : {};
-    const response = await axiosClient.get<Team[]>('/api/teams', { params });
+  getPage: async (hackathonId?: string, options: PageOptions = {}): Promise<Page<Team>> => {
+    const response = await axiosClient.get<Page<Team>>('/api/teams', {
+      params: { hackathonId: hackathonId || undefined, ...pageParams(options) },
+    });
     return response.data;
   },
 
@@ -605,10 +651,14 @@ export const teamService = {
   },
 
   /**
-   * Получить публичный список команд хакатона
+   * Получить страницу публичного списка команд хакатона
    */
-  getPublicTeams: async (hackathonId: string): Promise<Team[]> => {
-    const response = await axiosClient.get<Team[]>('/api/teams/public', { params: { hackathonId } });
+  getPublicTeams: async (hackathonId: string, options: PageOptions & {
+    status?: 'looking' | 'ready' | 'closed';
+  } = {}): Promise<Page<Team>> => {
+    const response = await axiosClient.get<Page<Team>>('/api/teams/public', {
+      params: { hackathonId, ...pageParams(options, { status: options.status }) },
+    });
     return response.data;
   },
 
@@ -616,7 +666,11 @@ export const teamService = {
    * Отправить запрос на вступление в команду
    */
   requestJoin: async (teamId: string, message?: string): Promise<{ requestId: number }> => {
-    const response = await axiosClient.post<{ requestId: number }>(`/api/teams/${teamId}/request-join`, { message });
+    const response = await axiosClient.post<{ requestId: number }>(
+      `/api/teams/${teamId}/request-join`,
+      { message },
+      withIdempotencyKey()
+    );
     return response.data;
   },
 
@@ -684,11 +738,15 @@ export const inviteService = {
    * Отправить приглашение
    */
   send: async (toUserId: string, teamId: string, message?: string): Promise<Invite> => {
-    const response = await axiosClient.post<Invite>('/api/invites', {
-      toUserId,
-      teamId,
-      message,
-    });
+    const response = await axiosClient.post<Invite>(
+      '/api/invites',
+      {
+        toUserId,
+        teamId,
+        message,
+      },
+      withIdempotencyKey()
+    );
     return response.data;
   },
 
@@ -728,6 +786,18 @@ export interface AdminStats {
   usersInTeam: number;
 }
 
+export interface ImpersonationSession {
+  id: string;
+  adminId: number;
+  userId: number;
+  reason: string;
+  ip: string;
+  userAgent: string;
+  createdAt: string;
+  expiresAt: string;
+  endedAt?: string;
+}
+
 export const adminService = {
   /**
    * Получить статистику
@@ -738,14 +808,29 @@ export const adminService = {
   },
 
   /**
-   * Получить всех пользователей
+   * Получить страницу пользователей
+   * sort: createdAt | name | pts | mmr, с "-" - по убыванию
    */
-  getUsers: async (filters?: { 
-    status?: string; 
+  getUsers: async (options: {
+    status?: 'looking' | 'in_team';
+    role?: 'user' | 'hackathon_creator' | 'admin';
     hackathonId?: string;
     search?: string;
-  }): Promise<{ users: User[]; total: number }> => {
-    const response = await axiosClient.get<{ users: User[]; total: number }>('/api/admin/users', { params: filters });
+    sort?: string;
+    limit?: number;
+    cursor?: string | null;
+  } = {}): Promise<Page<User>> => {
+    const response = await axiosClient.get<Page<User>>('/api/admin/users', {
+      params: {
+        limit: options.limit,
+        sort: options.sort,
+        cursor: options.cursor || undefined,
+        'filter[status]': options.status,
+        'filter[role]': options.role,
+        'filter[hackathonId]': options.hackathonId,
+        'filter[search]': options.search || undefined,
+      },
+    });
     return response.data;
   },
 
@@ -758,11 +843,12 @@ export const adminService = {
   },
 
   /**
-   * Получить все команды (Admin)
+   * Получить страницу команд (Admin)
    */
-  getTeams: async (hackathonId?: string): Promise<Team[]> => {
-    const params = hackathonId ? { hackathonId } : {};
-    const response = await axiosClient.get<Team[]>('/api/admin/teams', { params });
+  getTeams: async (options: PageOptions & { hackathonId?: string; status?: 'looking' | 'ready' | 'closed' } = {}): Promise<Page<Team>> => {
+    const response = await axiosClient.get<Page<Team>>('/api/admin/teams', {
+      params: pageParams(options, { hackathonId: options.hackathonId, status: options.status }),
+    });
     return response.data;
   },
 
@@ -793,7 +879,145 @@ export const adminService = {
     caseName: string;
     rarity: string;
   }): Promise<{ message: string; givenCount: number }> => {
-    const response = await axiosClient.post('/api/admin/cases/give', data);
+    const response = await axiosClient.post('/api/admin/cases/give', data, withIdempotencyKey());
+    return response.data;
+  },
+
+  /**
+   * Посмотреть приложение от имени пользователя (только чтение, без refresh токена)
+   */
+  impersonate: async (userId: number, reason: string, ttlMinutes?: number): Promise<{
+    accessToken: string;
+    expiresAt: string;
+    session: ImpersonationSession;
+  }> => {
+    const response = await axiosClient.post(`/api/admin/users/${userId}/impersonate`, { reason, ttlMinutes });
+    return response.data;
+  },
+
+  /**
+   * История сеансов имперсонации
+   */
+  getImpersonations: async (filters?: { adminId?: number; userId?: number; limit?: number }): Promise<Page<ImpersonationSession>> => {
+    const response = await axiosClient.get<Page<ImpersonationSession>>('/api/admin/impersonations', { params: filters });
+    return response.data;
+  },
+
+  /**
+   * Досрочно завершить сеанс имперсонации
+   */
+  endImpersonation: async (sessionId: string): Promise<void> => {
+    await axiosClient.delete(`/api/admin/impersonations/${sessionId}`);
+  },
+};
+
+// ============================================
+// CREATOR SERVICE - Управление своими хакатонами (hackathon_creator, admin)
+// ============================================
+
+export type ApiKeyPermission = 'participants:read' | 'teams:read' | 'announcements:write';
+
+export interface HackathonApiKey {
+  id: number;
+  hackathonId: number;
+  createdById: number;
+  name: string;
+  prefix: string;
+  permissions: ApiKeyPermission[];
+  lastUsedAt?: string;
+  revokedAt?: string;
+  createdAt: string;
+}
+
+export const creatorService = {
+  /**
+   * Хакатоны, которыми управляет текущий пользователь (админ видит все)
+   */
+  getMyHackathons: async (): Promise<Hackathon[]> => {
+    const response = await axiosClient.get<Hackathon[]>('/api/creator/hackathons');
+    return response.data;
+  },
+
+  /**
+   * Создать хакатон от своего имени
+   */
+  create: async (data: CreateHackathonData): Promise<Hackathon> => {
+    const response = await axiosClient.post<Hackathon>('/api/creator/hackathons', data);
+    return response.data;
+  },
+
+  /**
+   * Обновить свой хакатон
+   */
+  update: async (id: string, data: Partial<CreateHackathonData>): Promise<Hackathon> => {
+    const response = await axiosClient.put<Hackathon>(`/api/creator/hackathons/${id}`, data);
+    return response.data;
+  },
+
+  /**
+   * Сменить статус своего хакатона
+   */
+  setStatus: async (
+    id: string,
+    status: 'draft' | 'registration_open' | 'active' | 'completed'
+  ): Promise<Hackathon> => {
+    const response = await axiosClient.put<Hackathon>(`/api/creator/hackathons/${id}/status`, { status });
+    return response.data;
+  },
+
+  /**
+   * Участники своего хакатона
+   */
+  getParticipants: async (id: string): Promise<any[]> => {
+    const response = await axiosClient.get(`/api/creator/hackathons/${id}/participants`);
+    return response.data;
+  },
+
+  /**
+   * Выдать кейсы участникам своего хакатона
+   */
+  giveCases: async (
+    id: string,
+    data: { userIds: number[]; caseType: string; caseName: string; rarity: string }
+  ): Promise<{ message: string; givenCount: number }> => {
+    const response = await axiosClient.post(`/api/creator/hackathons/${id}/cases/give`, data, withIdempotencyKey());
+    return response.data;
+  },
+
+  /**
+   * Ключи интеграций своего хакатона
+   */
+  getApiKeys: async (id: string): Promise<HackathonApiKey[]> => {
+    const response = await axiosClient.get<HackathonApiKey[]>(`/api/creator/hackathons/${id}/api-keys`);
+    return response.data;
+  },
+
+  /**
+   * Выпустить ключ интеграции. Сам ключ (key) возвращается только один раз
+   */
+  createApiKey: async (
+    id: string,
+    data: { name: string; permissions: ApiKeyPermission[] }
+  ): Promise<{ key: string; apiKey: HackathonApiKey }> => {
+    const response = await axiosClient.post(`/api/creator/hackathons/${id}/api-keys`, data);
+    return response.data;
+  },
+
+  /**
+   * Отозвать ключ интеграции
+   */
+  revokeApiKey: async (id: string, keyId: number): Promise<void> => {
+    await axiosClient.delete(`/api/creator/hackathons/${id}/api-keys/${keyId}`);
+  },
+
+  /**
+   * Экспорт участников своего хакатона в CSV
+   */
+  exportParticipantsCSV: async (id: string): Promise<Blob> => {
+    const response = await axiosClient.get(`/api/creator/hackathons/${id}/export`, {
+      params: { format: 'csv' },
+      responseType: 'blob',
+    });
     return response.data;
   },
 };
@@ -890,7 +1114,7 @@ export const inventoryService = {
    * Открыть кейс
    */
   openCase: async (caseId: number): Promise<OpenCaseResponse> => {
-    const response = await axiosClient.post<OpenCaseResponse>('/api/inventory/cases/open', { caseId });
+    const response = await axiosClient.post<OpenCaseResponse>('/api/inventory/cases/open', { caseId }, withIdempotencyKey());
     return response.data;
   },
 
@@ -113,7 +113,7 @@ export function QuizFlow({ onComplete, onClose }: QuizFlowProps) {
   const [answers, setAnswers] = useState<Record<string, QuizOption>>({});
   const [isCalculating, setIsCalculating] = useState(false);
   const [finalPTS, setFinalPTS] = useState<number | null>(null);
-  const { updateProfile } = useAuthStore();
+  const { updateProfileLocal } = useAuthStore();
 
   const currentQuestion = QUIZ_QUESTIONS[currentStep];
   const progress = ((currentStep + 1) / QUIZ_QUESTIONS.length) * 100;
@@ -154,15 +154,14 @@ export function QuizFlow({ onComplete, onClose }: QuizFlowProps) {
         const newMmr = 1000 + mmrBonus;
         
         setFinalPTS(totalPTS);
-        // Обновляем и локально и на сервере
-        updateProfile({ pts: totalPTS, mmr: newMmr })
-          .catch(err => console.error('Failed to save PTS:', err));
+        // pts/mmr сервер не принимает от клиента - сохраняем только локально
+        updateProfileLocal({ pts: totalPTS, mmr: newMmr });
         setIsCalculating(false);
       }, 2000);
     } else {
       setCurrentStep(prev => prev + 1);
     }
-  }, [currentStep, answers, currentQuestion, isLastQuestion, updateProfile]);
+  }, [currentStep, answers, currentQuestion, isLastQuestion, updateProfileLocal]);
 
   // Предыдущий вопрос
   const prevStep = useCallback(() => {
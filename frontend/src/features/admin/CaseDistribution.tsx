@@ -1,4 +1,4 @@
-import { useState, useMemo, useEffect } from 'react';
+import { useState, useEffect, useRef } from 'react';
 import { 
   Search, 
   Gift,
@@ -14,13 +14,15 @@ import {
 } from 'lucide-react';
 import { User, ProfileCustomization } from '../../types';
 import { caseTemplates, getRarityColor, nameColors, avatarFrames, badges } from '../../data/customization/items';
-import axiosClient from '../../api/axiosClient';
 import { adminService } from '../../api/services';
 import { CustomizedName, CustomizedAvatar } from '../../components/profile/CustomizedAvatar';
 
 // Тип шаблона кейса для админки
 type CaseTemplate = typeof caseTemplates[number];
 
+const PAGE_SIZE = 50;
+const SEARCH_DEBOUNCE_MS = 300;
+
 interface GiftRecord {
   userId: string;
   userName: string;
@@ -34,25 +36,46 @@ interface GiftRecord {
  */
 export function CaseDistribution() {
   const [users, setUsers] = useState<User[]>([]);
+  // Все когда-либо загруженные пользователи: выбор сохраняется при смене поиска
+  const [knownUsers, setKnownUsers] = useState<Record<string, User>>({});
+  const [totalUsers, setTotalUsers] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
   const [isLoading, setIsLoading] = useState(true);
+  const [isLoadingMore, setIsLoadingMore] = useState(false);
+  const [hasLoaded, setHasLoaded] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [searchQuery, setSearchQuery] = useState('');
+  const [debouncedSearch, setDebouncedSearch] = useState('');
   const [selectedUsers, setSelectedUsers] = useState<Set<string>>(new Set());
   const [selectedCase, setSelectedCase] = useState<CaseTemplate | null>(null);
   const [giftHistory, setGiftHistory] = useState<GiftRecord[]>([]);
   const [showConfirmModal, setShowConfirmModal] = useState(false);
   const [showSuccessToast, setShowSuccessToast] = useState(false);
   const [filterStatus, setFilterStatus] = useState<'all' | 'looking' | 'in_team'>('all');
+  // Номер последнего запроса: ответы на прежние фильтры отбрасываются
+  const latestRequest = useRef(0);
 
-  // Fetch users from API
-  const fetchUsers = async () => {
+  // Загрузка страницы пользователей; поиск и фильтр по статусу выполняются на бэкенде
+  const fetchUsers = async (cursor: string | null = null) => {
+    const requestId = ++latestRequest.current;
     try {
-      setIsLoading(true);
       setError(null);
-      const response = await axiosClient.get('/api/admin/users?page=1&limit=1000');
+      if (cursor) {
+        setIsLoadingMore(true);
+      } else {
+        setIsLoading(true);
+      }
+      const page = await adminService.getUsers({
+        search: debouncedSearch.trim(),
+        status: filterStatus === 'all' ? undefined : filterStatus,
+        limit: PAGE_SIZE,
+        cursor,
+      });
+      // Ответ устарел: фильтры уже сменились
+      if (requestId !== latestRequest.current) return;
       
       // Transform backend response to frontend User type
-      const transformedUsers: User[] = (response.data?.users || []).map((u: any) => {
+      const transformedUsers: User[] = (page.items as any[]).map((u: any) => {
         // Transform customization data
         let customization: ProfileCustomization | undefined;
         if (u.customization) {
@@ -102,12 +125,23 @@ export function CaseDistribution() {
         };
       });
       
-      setUsers(transformedUsers);
+      setUsers(prev => cursor ? [...prev, ...transformedUsers] : transformedUsers);
+      setKnownUsers(prev => ({
+        ...prev,
+        ...Object.fromEntries(transformedUsers.map(u => [u.id, u])),
+      }));
+      setNextCursor(page.nextCursor);
+      setTotalUsers(page.total);
     } catch (err: any) {
+      if (requestId !== latestRequest.current) return;
       console.error('Failed to fetch users:', err);
       setError(err.response?.data?.error || 'Не удалось загрузить пользователей');
     } finally {
-      setIsLoading(false);
+      if (requestId === latestRequest.current) {
+        setIsLoading(false);
+        setIsLoadingMore(false);
+        setHasLoaded(true);
+      }
     }
   };
 
@@ -118,18 +152,15 @@ export function CaseDistribution() {
     return 'inactive';
   };
 
+  // Поиск уходит на бэкенд после паузы в наборе
   useEffect(() => {
-    fetchUsers();
-  }, []);
+    const timer = setTimeout(() => setDebouncedSearch(searchQuery), SEARCH_DEBOUNCE_MS);
+    return () => clearTimeout(timer);
+  }, [searchQuery]);
 
-  // Фильтрация пользователей
-  const filteredUsers = useMemo(() => {
-    return users.filter(user => {
-      const matchesSearch = user.name.toLowerCase().includes(searchQuery.toLowerCase());
-      const matchesStatus = filterStatus === 'all' || user.status === filterStatus;
-      return matchesSearch && matchesStatus;
-    });
-  }, [users, searchQuery, filterStatus]);
+  useEffect(() => {
+    fetchUsers();
+  }, [debouncedSearch, filterStatus]);
 
   // Выбор/снятие выбора пользователя
   const toggleUser = (userId: string) => {
@@ -144,9 +175,9 @@ export function CaseDistribution() {
     });
   };
 
-  // Выбрать всех отфильтрованных
+  // Выбрать всех загруженных по текущему фильтру
   const selectAll = () => {
-    setSelectedUsers(new Set(filteredUsers.map(u => u.id)));
+    setSelectedUsers(prev => new Set([...prev, ...users.map(u => u.id)]));
   };
 
   // Снять выбор со всех
@@ -179,7 +210,7 @@ export function CaseDistribution() {
       // Создаем записи об отправке для UI
       const newRecords: GiftRecord[] = [];
       selectedUsers.forEach(userId => {
-        const user = users.find(u => u.id === userId);
+        const user = knownUsers[userId];
         if (user) {
           newRecords.push({
             userId,
@@ -211,7 +242,7 @@ export function CaseDistribution() {
   };
 
   // Loading state
-  if (isLoading) {
+  if (isLoading && !hasLoaded) {
     return (
       <div className="flex items-center justify-center min-h-[400px]">
         <Loader2 className="w-8 h-8 animate-spin text-primary" />
@@ -225,7 +256,7 @@ export function CaseDistribution() {
       <div className="flex flex-col items-center justify-center min-h-[400px] gap-4">
         <AlertCircle className="w-12 h-12 text-error" />
         <p className="text-error">{error}</p>
-        <button className="btn btn-primary" onClick={fetchUsers}>
+        <button className="btn btn-primary" onClick={() => fetchUsers()}>
           Попробовать снова
         </button>
       </div>
@@ -375,7 +406,7 @@ export function CaseDistribution() {
 
               {/* Список пользователей */}
               <div className="max-h-[400px] overflow-y-auto space-y-2">
-                {filteredUsers.map(user => (
+                {users.map(user => (
                   <label
                     key={user.id}
                     className={`flex items-center gap-3 p-3 rounded-xl cursor-pointer transition-all ${
@@ -427,12 +458,25 @@ export function CaseDistribution() {
                   </label>
                 ))}
 
-                {filteredUsers.length === 0 && (
+                {users.length === 0 && (
                   <div className="text-center py-8 text-base-content/60">
                     Пользователи не найдены
                   </div>
                 )}
               </div>
+
+              {nextCursor && (
+                <div className="flex justify-center mt-4">
+                  <button
+                    className="btn btn-sm btn-outline"
+                    onClick={() => fetchUsers(nextCursor)}
+                    disabled={isLoadingMore || isLoading}
+                  >
+                    {isLoadingMore && <span className="loading loading-spinner loading-xs"></span>}
+                    Загрузить ещё ({users.length} из {totalUsers})
+                  </button>
+                </div>
+              )}
             </div>
           </div>
         </div>
@@ -503,7 +547,7 @@ export function CaseDistribution() {
                 <p className="text-sm text-base-content/60 mb-2">Получатели:</p>
                 <div className="flex flex-wrap gap-2 max-h-24 overflow-y-auto">
                   {Array.from(selectedUsers).map(userId => {
-                    const user = users.find(u => u.id === userId);
+                    const user = knownUsers[userId];
                     return user && (
                       <span key={userId} className="badge badge-outline">
                         {user.name}
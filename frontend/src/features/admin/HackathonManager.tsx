@@ -3,7 +3,7 @@ import {
   Plus, 
   Search, 
   Calendar,
-  Users,
+  Trophy,
   Edit2,
   Trash2,
   Eye,
@@ -17,6 +17,7 @@ import {
 } from 'lucide-react';
 import { Hackathon } from '../../types';
 import axiosClient from '../../api/axiosClient';
+import { hackathonService } from '../../api/services';
 import { ImageCropper } from '../../components/common/ImageCropper';
 
 // Status badge styles
@@ -34,15 +35,42 @@ const statusLabels: Record<string, string> = {
   completed: 'Завершён',
 };
 
+// Статус фильтра -> статус бэкенда
+const backendStatuses = {
+  upcoming: 'draft',
+  registration: 'registration_open',
+  active: 'active',
+  completed: 'completed',
+} as const;
+
+const PAGE_SIZE = 50;
+const SEARCH_DEBOUNCE_MS = 300;
+
+// Счётчики для карточек: total страниц с limit=1 по каждому статусу
+interface HackathonCounts {
+  total: number;
+  active: number;
+  registration: number;
+  completed: number;
+}
+
 /**
  * HackathonManager - Управление хакатонами (Admin)
  */
 export function HackathonManager() {
   const [hackathons, setHackathons] = useState<Hackathon[]>([]);
+  const [totalHackathons, setTotalHackathons] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
+  const [counts, setCounts] = useState<HackathonCounts | null>(null);
   const [isLoading, setIsLoading] = useState(true);
+  const [isLoadingMore, setIsLoadingMore] = useState(false);
+  const [hasLoaded, setHasLoaded] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [searchQuery, setSearchQuery] = useState('');
-  const [statusFilter, setStatusFilter] = useState<string>('all');
+  const [debouncedSearch, setDebouncedSearch] = useState('');
+  const [statusFilter, setStatusFilter] = useState<'all' | keyof typeof backendStatuses>('all');
+  // Номер последнего запроса: ответы на прежние фильтры отбрасываются
+  const latestRequest = useRef(0);
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [editingHackathon, setEditingHackathon] = useState<Hackathon | null>(null);
 
@@ -67,15 +95,27 @@ export function HackathonManager() {
   // Validation errors
   const [validationErrors, setValidationErrors] = useState<string[]>([]);
 
-  // Fetch hackathons from API
-  const fetchHackathons = async () => {
+  // Загрузка страницы хакатонов; поиск и фильтр по статусу выполняются на бэкенде
+  const fetchHackathons = async (cursor: string | null = null) => {
+    const requestId = ++latestRequest.current;
     try {
-      setIsLoading(true);
       setError(null);
-      const response = await axiosClient.get('/api/hackathons');
+      if (cursor) {
+        setIsLoadingMore(true);
+      } else {
+        setIsLoading(true);
+      }
+      const page = await hackathonService.getPage({
+        search: debouncedSearch.trim(),
+        status: statusFilter === 'all' ? undefined : backendStatuses[statusFilter],
+        limit: PAGE_SIZE,
+        cursor,
+      });
+      // Ответ устарел: фильтры уже сменились
+      if (requestId !== latestRequest.current) return;
       
       // Transform backend response to frontend Hackathon type
-      const transformedHackathons: Hackathon[] = (response.data || []).map((h: any) => ({
+      const transformedHackathons: Hackathon[] = (page.items as any[]).map((h: any) => ({
         id: String(h.id),
         name: h.name || '',
         description: h.description || '',
@@ -91,15 +131,47 @@ export function HackathonManager() {
         createdAt: new Date(h.createdAt),
       }));
       
-      setHackathons(transformedHackathons);
+      setHackathons(prev => cursor ? [...prev, ...transformedHackathons] : transformedHackathons);
+      setNextCursor(page.nextCursor);
+      setTotalHackathons(page.total);
     } catch (err: any) {
+      if (requestId !== latestRequest.current) return;
       console.error('Failed to fetch hackathons:', err);
       setError(err.response?.data?.error || 'Не удалось загрузить хакатоны');
     } finally {
-      setIsLoading(false);
+      if (requestId === latestRequest.current) {
+        setIsLoading(false);
+        setIsLoadingMore(false);
+        setHasLoaded(true);
+      }
     }
   };
 
+  // Счётчики по всем хакатонам, без учёта поиска
+  const fetchCounts = async () => {
+    try {
+      const [total, active, registration, completed] = await Promise.all([
+        hackathonService.getPage({ limit: 1 }),
+        hackathonService.getPage({ limit: 1, status: 'active' }),
+        hackathonService.getPage({ limit: 1, status: 'registration_open' }),
+        hackathonService.getPage({ limit: 1, status: 'completed' }),
+      ]);
+      setCounts({
+        total: total.total,
+        active: active.total,
+        registration: registration.total,
+        completed: completed.total,
+      });
+    } catch (err) {
+      console.error('Failed to fetch hackathon counts:', err);
+    }
+  };
+
+  // Список и счётчики после создания, изменения или удаления
+  const refresh = async () => {
+    await Promise.all([fetchHackathons(), fetchCounts()]);
+  };
+
   // Map backend status to frontend status
   const mapBackendStatus = (status: string): Hackathon['status'] => {
     switch (status) {
@@ -116,16 +188,19 @@ export function HackathonManager() {
     }
   };
 
+  // Поиск уходит на бэкенд после паузы в наборе
+  useEffect(() => {
+    const timer = setTimeout(() => setDebouncedSearch(searchQuery), SEARCH_DEBOUNCE_MS);
+    return () => clearTimeout(timer);
+  }, [searchQuery]);
+
   useEffect(() => {
     fetchHackathons();
-  }, []);
+  }, [debouncedSearch, statusFilter]);
 
-  // Filter hackathons
-  const filteredHackathons = hackathons.filter(h => {
-    const matchesSearch = h.name.toLowerCase().includes(searchQuery.toLowerCase());
-    const matchesStatus = statusFilter === 'all' || h.status === statusFilter;
-    return matchesSearch && matchesStatus;
-  });
+  useEffect(() => {
+    fetchCounts();
+  }, []);
 
   // Close modal and reset state
   const closeModal = () => {
@@ -282,10 +357,16 @@ export function HackathonManager() {
       }
       
       // Refresh list
-      await fetchHackathons();
+      await refresh();
       closeModal();
     } catch (err: any) {
       console.error('Failed to save hackathon:', err);
+      // 422: сервер перечисляет все нарушения по полям
+      const details: { field: string; message: string }[] | undefined = err.response?.data?.details;
+      if (details?.length) {
+        setValidationErrors(details.map(d => `${d.field}: ${d.message}`));
+        return;
+      }
       setValidationErrors([err.response?.data?.error || 'Не удалось сохранить хакатон']);
     }
   };
@@ -295,7 +376,7 @@ export function HackathonManager() {
     if (confirm('Удалить хакатон?')) {
       try {
         await axiosClient.delete(`/api/admin/hackathons/${id}`);
-        await fetchHackathons();
+        await refresh();
       } catch (err: any) {
         console.error('Failed to delete hackathon:', err);
         alert(err.response?.data?.error || 'Не удалось удалить хакатон');
@@ -304,7 +385,7 @@ export function HackathonManager() {
   };
 
   // Loading state
-  if (isLoading) {
+  if (isLoading && !hasLoaded) {
     return (
       <div className="flex items-center justify-center min-h-[400px]">
         <Loader2 className="w-8 h-8 animate-spin text-primary" />
@@ -318,7 +399,7 @@ export function HackathonManager() {
       <div className="flex flex-col items-center justify-center min-h-[400px] gap-4">
         <AlertCircle className="w-12 h-12 text-error" />
         <p className="text-error">{error}</p>
-        <button className="btn btn-primary" onClick={fetchHackathons}>
+        <button className="btn btn-primary" onClick={() => fetchHackathons()}>
           Попробовать снова
         </button>
       </div>
@@ -358,7 +439,7 @@ export function HackathonManager() {
         <select 
           className="select select-bordered w-48"
           value={statusFilter}
-          onChange={(e) => setStatusFilter(e.target.value)}
+          onChange={(e) => setStatusFilter(e.target.value as typeof statusFilter)}
         >
           <option value="all">Все статусы</option>
           <option value="upcoming">Скоро</option>
@@ -375,7 +456,7 @@ export function HackathonManager() {
             <Calendar className="w-8 h-8" />
           </div>
           <div className="stat-title">Всего</div>
-          <div className="stat-value text-primary">{hackathons.length}</div>
+          <div className="stat-value text-primary">{counts?.total ?? '—'}</div>
         </div>
         <div className="stat bg-base-200 rounded-lg">
           <div className="stat-figure text-success">
@@ -383,7 +464,7 @@ export function HackathonManager() {
           </div>
           <div className="stat-title">Активных</div>
           <div className="stat-value text-success">
-            {hackathons.filter(h => h.status === 'active').length}
+            {counts?.active ?? '—'}
           </div>
         </div>
         <div className="stat bg-base-200 rounded-lg">
@@ -392,16 +473,16 @@ export function HackathonManager() {
           </div>
           <div className="stat-title">Регистрация</div>
           <div className="stat-value text-warning">
-            {hackathons.filter(h => h.status === 'registration').length}
+            {counts?.registration ?? '—'}
           </div>
         </div>
         <div className="stat bg-base-200 rounded-lg">
           <div className="stat-figure text-info">
-            <Users className="w-8 h-8" />
+            <Trophy className="w-8 h-8" />
           </div>
-          <div className="stat-title">Участников</div>
+          <div className="stat-title">Завершённых</div>
           <div className="stat-value text-info">
-            {hackathons.reduce((sum, h) => sum + h.participantsCount, 0)}
+            {counts?.completed ?? '—'}
           </div>
         </div>
       </div>
@@ -420,7 +501,7 @@ export function HackathonManager() {
             </tr>
           </thead>
           <tbody>
-            {filteredHackathons.map(hackathon => (
+            {hackathons.map(hackathon => (
               <tr key={hackathon.id} className="hover">
                 <td>
                   <div className="flex items-center gap-3">
@@ -497,7 +578,7 @@ export function HackathonManager() {
           </tbody>
         </table>
 
-        {filteredHackathons.length === 0 && (
+        {hackathons.length === 0 && (
           <div className="p-8 text-center text-base-content/60">
             <AlertCircle className="w-12 h-12 mx-auto mb-2 opacity-50" />
             <p>Хакатоны не найдены</p>
@@ -505,6 +586,20 @@ export function HackathonManager() {
         )}
       </div>
 
+      {/* Pagination */}
+      {nextCursor && (
+        <div className="flex justify-center">
+          <button
+            className="btn btn-sm btn-outline"
+            onClick={() => fetchHackathons(nextCursor)}
+            disabled={isLoadingMore || isLoading}
+          >
+            {isLoadingMore && <span className="loading loading-spinner loading-xs"></span>}
+            Загрузить ещё ({hackathons.length} из {totalHackathons})
+          </button>
+        </div>
+      )}
+
       {/* Add/Edit Modal */}
       {isModalOpen && (
         <dialog className="modal modal-open">
@@ -1,4 +1,4 @@
-import { useState, useMemo, useEffect } from 'react';
+import { useState, useEffect, useRef } from 'react';
 import { 
   Search, 
   Filter,
@@ -15,7 +15,7 @@ import {
   AlertCircle
 } from 'lucide-react';
 import { User, ProfileCustomization } from '../../types';
-import axiosClient from '../../api/axiosClient';
+import { adminService } from '../../api/services';
 import { CustomizedName, CustomizedAvatar } from '../../components/profile/CustomizedAvatar';
 import { nameColors, avatarFrames, badges } from '../../data/customization/items';
 
@@ -23,25 +23,79 @@ import { nameColors, avatarFrames, badges } from '../../data/customization/items
 const statusStyles: Record<string, string> = {
   looking: 'badge-success',
   in_team: 'badge-info',
-  inactive: 'badge-ghost',
 };
 
 const statusLabels: Record<string, string> = {
   looking: 'Ищет команду',
   in_team: 'В команде',
-  inactive: 'Неактивен',
 };
 
-// Role badge styles
+// Role badge styles (роли бэкенда)
 const roleStyles: Record<string, string> = {
-  participant: 'badge-outline',
-  captain: 'badge-primary',
+  user: 'badge-outline',
+  hackathon_creator: 'badge-primary',
   admin: 'badge-secondary',
 };
 
+// Преобразует пользователя от бэкенда к формату фронтенда
+const transformUser = (u: any): User => {
+  // Transform customization data
+  let customization: ProfileCustomization | undefined;
+  if (u.customization) {
+    const userBadges: typeof badges = [];
+    if (u.customization.badge1Id) {
+      const badge = badges.find(b => b.id === u.customization.badge1Id);
+      if (badge) userBadges.push(badge);
+    }
+    if (u.customization.badge2Id) {
+      const badge = badges.find(b => b.id === u.customization.badge2Id);
+      if (badge) userBadges.push(badge);
+    }
+    if (u.customization.badge3Id) {
+      const badge = badges.find(b => b.id === u.customization.badge3Id);
+      if (badge) userBadges.push(badge);
+    }
+    
+    customization = {
+      nameColor: u.customization.nameColorId 
+        ? nameColors.find(c => c.id === u.customization.nameColorId)
+        : undefined,
+      avatarFrame: u.customization.avatarFrameId 
+        ? avatarFrames.find(f => f.id === u.customization.avatarFrameId)
+        : undefined,
+      badges: userBadges,
+      showcaseAchievements: [],
+    };
+  }
+  
+  return {
+    id: String(u.id),
+    telegramId: u.telegram_id || u.telegramId,
+    name: u.name || 'Без имени',
+    role: u.role || 'participant',
+    status: u.team_id || u.teamId ? 'in_team' : 'looking',
+    skills: Array.isArray(u.skills) 
+      ? u.skills.map((s: string) => ({ name: s, level: 3 }))
+      : [],
+    experience: u.experience || '',
+    mmr: u.mmr || 1000,
+    pts: u.pts || 0,
+    title: 'Участник',
+    nftStickers: [],
+    bio: u.bio || '',
+    avatar: u.avatarUrl || u.avatar_url || `https://api.dicebear.com/7.x/avataaars/svg?seed=${u.id}`,
+    createdAt: u.created_at || u.createdAt ? new Date(u.created_at || u.createdAt) : new Date(),
+    updatedAt: u.updated_at || u.updatedAt ? new Date(u.updated_at || u.updatedAt) : new Date(),
+    customization,
+  };
+};
+
 type SortField = 'name' | 'pts' | 'mmr' | 'createdAt';
 type SortOrder = 'asc' | 'desc';
 
+const PAGE_SIZE = 50;
+const SEARCH_DEBOUNCE_MS = 300;
+
 /**
  * UserTable - Таблица пользователей (Admin)
  */
@@ -51,121 +105,66 @@ export function UserTable() {
   const [error, setError] = useState<string | null>(null);
   const [totalUsers, setTotalUsers] = useState(0);
   const [searchQuery, setSearchQuery] = useState('');
+  const [debouncedSearch, setDebouncedSearch] = useState('');
   const [statusFilter, setStatusFilter] = useState<string>('all');
   const [roleFilter, setRoleFilter] = useState<string>('all');
   const [sortField, setSortField] = useState<SortField>('createdAt');
   const [sortOrder, setSortOrder] = useState<SortOrder>('desc');
   const [selectedUsers, setSelectedUsers] = useState<Set<string>>(new Set());
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
+  const [isLoadingMore, setIsLoadingMore] = useState(false);
+  const [hasLoaded, setHasLoaded] = useState(false);
+  // Номер последнего запроса: ответы на прежние фильтры отбрасываются
+  const latestRequest = useRef(0);
 
-  // Загрузка пользователей с бэкенда
-  const fetchUsers = async () => {
+  // Загрузка страницы пользователей; сортировка и фильтры выполняются на бэкенде
+  const fetchUsers = async (cursor: string | null = null) => {
+    const requestId = ++latestRequest.current;
     try {
       setError(null);
-      setIsLoading(true);
-      const response = await axiosClient.get('/api/admin/users', {
-        params: { limit: 100 }
-      });
-      
-      // Преобразуем данные от бэкенда к формату фронтенда
-      const backendUsers = response.data.users || [];
-      const transformedUsers: User[] = backendUsers.map((u: any) => {
-        // Transform customization data
-        let customization: ProfileCustomization | undefined;
-        if (u.customization) {
-          const userBadges: typeof badges = [];
-          if (u.customization.badge1Id) {
-            const badge = badges.find(b => b.id === u.customization.badge1Id);
-            if (badge) userBadges.push(badge);
-          }
-          if (u.customization.badge2Id) {
-            const badge = badges.find(b => b.id === u.customization.badge2Id);
-            if (badge) userBadges.push(badge);
-          }
-          if (u.customization.badge3Id) {
-            const badge = badges.find(b => b.id === u.customization.badge3Id);
-            if (badge) userBadges.push(badge);
-          }
-          
-          customization = {
-            nameColor: u.customization.nameColorId 
-              ? nameColors.find(c => c.id === u.customization.nameColorId)
-              : undefined,
-            avatarFrame: u.customization.avatarFrameId 
-              ? avatarFrames.find(f => f.id === u.customization.avatarFrameId)
-              : undefined,
-            badges: userBadges,
-            showcaseAchievements: [],
-          };
-        }
-        
-        return {
-          id: String(u.id),
-          telegramId: u.telegram_id || u.telegramId,
-          name: u.name || 'Без имени',
-          role: u.role || 'participant',
-          status: u.team_id || u.teamId ? 'in_team' : 'looking',
-          skills: Array.isArray(u.skills) 
-            ? u.skills.map((s: string) => ({ name: s, level: 3 }))
-            : [],
-          experience: u.experience || '',
-          mmr: u.mmr || 1000,
-          pts: u.pts || 0,
-          title: 'Участник',
-          nftStickers: [],
-          bio: u.bio || '',
-          avatar: u.avatarUrl || u.avatar_url || `https://api.dicebear.com/7.x/avataaars/svg?seed=${u.id}`,
-          createdAt: u.created_at || u.createdAt ? new Date(u.created_at || u.createdAt) : new Date(),
-          updatedAt: u.updated_at || u.updatedAt ? new Date(u.updated_at || u.updatedAt) : new Date(),
-          customization,
-        };
+      if (cursor) {
+        setIsLoadingMore(true);
+      } else {
+        setIsLoading(true);
+      }
+      const page = await adminService.getUsers({
+        search: debouncedSearch.trim(),
+        status: statusFilter === 'all' ? undefined : statusFilter as 'looking' | 'in_team',
+        role: roleFilter === 'all' ? undefined : roleFilter as 'user' | 'hackathon_creator' | 'admin',
+        sort: sortOrder === 'desc' ? `-${sortField}` : sortField,
+        limit: PAGE_SIZE,
+        cursor,
       });
-      
-      setUsers(transformedUsers);
-      setTotalUsers(response.data.total || transformedUsers.length);
+      // Ответ устарел: фильтры уже сменились
+      if (requestId !== latestRequest.current) return;
+
+      const transformedUsers = page.items.map(transformUser);
+      setUsers(prev => cursor ? [...prev, ...transformedUsers] : transformedUsers);
+      setNextCursor(page.nextCursor);
+      setTotalUsers(page.total);
+      if (!cursor) setSelectedUsers(new Set());
     } catch (err: any) {
+      if (requestId !== latestRequest.current) return;
       console.error('Failed to fetch users:', err);
       setError('Не удалось загрузить пользователей');
     } finally {
-      setIsLoading(false);
+      if (requestId === latestRequest.current) {
+        setIsLoading(false);
+        setIsLoadingMore(false);
+        setHasLoaded(true);
+      }
     }
   };
 
+  // Поиск уходит на бэкенд после паузы в наборе
   useEffect(() => {
-    fetchUsers();
-  }, []);
-
-  // Filter and sort users
-  const filteredUsers = useMemo(() => {
-    let result = users.filter(user => {
-      const matchesSearch = user.name.toLowerCase().includes(searchQuery.toLowerCase()) ||
-        user.skills.some(s => s.name.toLowerCase().includes(searchQuery.toLowerCase()));
-      const matchesStatus = statusFilter === 'all' || user.status === statusFilter;
-      const matchesRole = roleFilter === 'all' || user.role === roleFilter;
-      return matchesSearch && matchesStatus && matchesRole;
-    });
-
-    // Sort
-    result.sort((a, b) => {
-      let comparison = 0;
-      switch (sortField) {
-        case 'name':
-          comparison = a.name.localeCompare(b.name);
-          break;
-        case 'pts':
-          comparison = a.pts - b.pts;
-          break;
-        case 'mmr':
-          comparison = a.mmr - b.mmr;
-          break;
-        case 'createdAt':
-          comparison = new Date(a.createdAt).getTime() - new Date(b.createdAt).getTime();
-          break;
-      }
-      return sortOrder === 'asc' ? comparison : -comparison;
-    });
+    const timer = setTimeout(() => setDebouncedSearch(searchQuery), SEARCH_DEBOUNCE_MS);
+    return () => clearTimeout(timer);
+  }, [searchQuery]);
 
-    return result;
-  }, [users, searchQuery, statusFilter, roleFilter, sortField, sortOrder]);
+  useEffect(() => {
+    fetchUsers();
+  }, [debouncedSearch, statusFilter, roleFilter, sortField, sortOrder]);
 
   // Toggle sort
   const handleSort = (field: SortField) => {
@@ -179,10 +178,10 @@ export function UserTable() {
 
   // Select all
   const toggleSelectAll = () => {
-    if (selectedUsers.size === filteredUsers.length) {
+    if (selectedUsers.size === users.length) {
       setSelectedUsers(new Set());
     } else {
-      setSelectedUsers(new Set(filteredUsers.map(u => u.id)));
+      setSelectedUsers(new Set(users.map(u => u.id)));
     }
   };
 
@@ -200,7 +199,7 @@ export function UserTable() {
   // Export CSV
   const exportCSV = () => {
     const headers = ['Имя', 'Email', 'Роль', 'Статус', 'PTS', 'MMR', 'Навыки'];
-    const rows = filteredUsers.map(u => [
+    const rows = users.map(u => [
       u.name,
       u.email || '-',
       u.role,
@@ -230,7 +229,8 @@ export function UserTable() {
       : <ChevronDown className="w-4 h-4 inline ml-1" />;
   };
 
-  if (isLoading) {
+  // Полноэкранный спиннер только при первой загрузке, чтобы поле поиска не теряло фокус
+  if (isLoading && !hasLoaded) {
     return (
       <div className="flex items-center justify-center h-64">
         <span className="loading loading-spinner loading-lg text-primary"></span>
@@ -243,7 +243,7 @@ export function UserTable() {
       <div className="alert alert-error">
         <AlertCircle className="w-5 h-5" />
         <span>{error}</span>
-        <button className="btn btn-sm btn-ghost" onClick={fetchUsers}>
+        <button className="btn btn-sm btn-ghost" onClick={() => fetchUsers()}>
           Повторить
         </button>
       </div>
@@ -257,13 +257,13 @@ export function UserTable() {
         <div>
           <h1 className="text-2xl font-bold">Пользователи</h1>
           <p className="text-base-content/60">
-            Всего: {totalUsers} | Показано: {filteredUsers.length}
+            Всего: {totalUsers} | Показано: {users.length}
             {selectedUsers.size > 0 && ` | Выбрано: ${selectedUsers.size}`}
           </p>
         </div>
         <div className="flex gap-2">
-          <button className="btn btn-ghost btn-sm" onClick={fetchUsers}>
-            <RefreshCw className="w-4 h-4" />
+          <button className="btn btn-ghost btn-sm" onClick={() => fetchUsers()} disabled={isLoading}>
+            <RefreshCw className={`w-4 h-4 ${isLoading ? 'animate-spin' : ''}`} />
           </button>
           <button className="btn btn-outline" onClick={exportCSV}>
             <Download className="w-4 h-4" />
@@ -281,7 +281,7 @@ export function UserTable() {
             </span>
             <input
               type="text"
-              placeholder="Поиск по имени или навыкам..."
+              placeholder="Поиск по имени или username..."
               className="input input-bordered join-item w-full"
               value={searchQuery}
               onChange={(e) => setSearchQuery(e.target.value)}
@@ -299,7 +299,6 @@ export function UserTable() {
             <option value="all">Все статусы</option>
             <option value="looking">Ищет команду</option>
             <option value="in_team">В команде</option>
-            <option value="inactive">Неактивен</option>
           </select>
           
           <select 
@@ -308,8 +307,8 @@ export function UserTable() {
             onChange={(e) => setRoleFilter(e.target.value)}
           >
             <option value="all">Все роли</option>
-            <option value="participant">Участник</option>
-            <option value="captain">Капитан</option>
+            <option value="user">Участник</option>
+            <option value="hackathon_creator">Организатор</option>
             <option value="admin">Админ</option>
           </select>
         </div>
@@ -324,7 +323,7 @@ export function UserTable() {
                 <input 
                   type="checkbox" 
                   className="checkbox checkbox-sm"
-                  checked={selectedUsers.size === filteredUsers.length && filteredUsers.length > 0}
+                  checked={selectedUsers.size === users.length && users.length > 0}
                   onChange={toggleSelectAll}
                 />
               </th>
@@ -354,7 +353,7 @@ export function UserTable() {
             </tr>
           </thead>
           <tbody>
-            {filteredUsers.map(user => (
+            {users.map(user => (
               <tr key={user.id} className="hover">
                 <td>
                   <input 
@@ -451,23 +450,26 @@ export function UserTable() {
           </tbody>
         </table>
 
-        {filteredUsers.length === 0 && (
+        {users.length === 0 && (
           <div className="p-8 text-center text-base-content/60">
             <p>Пользователи не найдены</p>
           </div>
         )}
       </div>
 
-      {/* Pagination placeholder */}
-      <div className="flex justify-center">
-        <div className="join">
-          <button className="join-item btn btn-sm">«</button>
-          <button className="join-item btn btn-sm btn-active">1</button>
-          <button className="join-item btn btn-sm">2</button>
-          <button className="join-item btn btn-sm">3</button>
-          <button className="join-item btn btn-sm">»</button>
+      {/* Pagination */}
+      {nextCursor && (
+        <div className="flex justify-center">
+          <button
+            className="btn btn-sm btn-outline"
+            onClick={() => fetchUsers(nextCursor)}
+            disabled={isLoadingMore || isLoading}
+          >
+            {isLoadingMore && <span className="loading loading-spinner loading-xs"></span>}
+            Загрузить ещё ({users.length} из {totalUsers})
+          </button>
         </div>
-      </div>
+      )}
     </div>
   );
 }
@@ -1,4 +1,4 @@
-import { useState, useEffect } from 'react';
+import { useState, useEffect, useRef } from 'react';
 import { 
   Users, 
   Search, 
@@ -169,38 +169,76 @@ function TeamCard({ team, onRequestJoin, myRequests, isLoading }: TeamCardProps)
   );
 }
 
+const PAGE_SIZE = 30;
+const SEARCH_DEBOUNCE_MS = 300;
+
 /**
  * TeamBrowser - Просмотр и поиск команд
  */
 export function TeamBrowser() {
   const { user } = useAuthStore();
   const [teams, setTeams] = useState<Team[]>([]);
+  const [totalTeams, setTotalTeams] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
   const [myRequests, setMyRequests] = useState<MyJoinRequest[]>([]);
   const [isLoading, setIsLoading] = useState(true);
+  const [isLoadingMore, setIsLoadingMore] = useState(false);
+  const [hasLoaded, setHasLoaded] = useState(false);
   const [requestingTeamId, setRequestingTeamId] = useState<string | null>(null);
   const [searchQuery, setSearchQuery] = useState('');
+  const [debouncedSearch, setDebouncedSearch] = useState('');
   const [statusFilter, setStatusFilter] = useState<'all' | 'open' | 'closed'>('all');
   const [successMessage, setSuccessMessage] = useState('');
+  // Номер последнего запроса: ответы на прежние фильтры отбрасываются
+  const latestRequest = useRef(0);
+
+  // Поиск уходит на бэкенд после паузы в наборе
+  useEffect(() => {
+    const timer = setTimeout(() => setDebouncedSearch(searchQuery), SEARCH_DEBOUNCE_MS);
+    return () => clearTimeout(timer);
+  }, [searchQuery]);
+
+  useEffect(() => {
+    fetchTeams();
+  }, [user?.currentHackathonId, debouncedSearch, statusFilter]);
 
   useEffect(() => {
-    loadData();
-  }, [user?.currentHackathonId]);
+    teamService.getMyJoinRequests()
+      .then(setMyRequests)
+      .catch(error => console.error('Failed to load join requests:', error));
+  }, []);
 
-  const loadData = async () => {
+  // Загрузка страницы команд; поиск и фильтр по статусу выполняются на бэкенде
+  const fetchTeams = async (cursor: string | null = null) => {
     if (!user?.currentHackathonId) return;
-    
-    setIsLoading(true);
+    const requestId = ++latestRequest.current;
+    if (cursor) {
+      setIsLoadingMore(true);
+    } else {
+      setIsLoading(true);
+    }
     try {
-      const [teamsData, requestsData] = await Promise.all([
-        teamService.getPublicTeams(String(user.currentHackathonId)),
-        teamService.getMyJoinRequests(),
-      ]);
-      setTeams(teamsData);
-      setMyRequests(requestsData);
+      const page = await teamService.getPublicTeams(String(user.currentHackathonId), {
+        search: debouncedSearch.trim(),
+        status: statusFilter === 'all' ? undefined : statusFilter === 'open' ? 'looking' : 'closed',
+        limit: PAGE_SIZE,
+        cursor,
+      });
+      // Ответ устарел: фильтры уже сменились
+      if (requestId !== latestRequest.current) return;
+
+      setTeams(prev => cursor ? [...prev, ...page.items] : page.items);
+      setNextCursor(page.nextCursor);
+      setTotalTeams(page.total);
     } catch (error) {
+      if (requestId !== latestRequest.current) return;
       console.error('Failed to load teams:', error);
     } finally {
-      setIsLoading(false);
+      if (requestId === latestRequest.current) {
+        setIsLoading(false);
+        setIsLoadingMore(false);
+        setHasLoaded(true);
+      }
     }
   };
 
@@ -221,27 +259,12 @@ export function TeamBrowser() {
     }
   };
 
-  // Filter teams
-  const filteredTeams = teams.filter(team => {
-    // Exclude own team
-    if (user?.currentTeamId && String(team.id) === String(user.currentTeamId)) return false;
-    
-    // Search filter
-    if (searchQuery) {
-      const query = searchQuery.toLowerCase();
-      const matchesName = team.name.toLowerCase().includes(query);
-      const matchesCaptain = team.captain?.name.toLowerCase().includes(query);
-      if (!matchesName && !matchesCaptain) return false;
-    }
-    
-    // Status filter
-    if (statusFilter === 'open' && team.status !== 'looking' && team.status !== 'open') return false;
-    if (statusFilter === 'closed' && team.status !== 'closed') return false;
-    
-    return true;
-  });
+  // Свою команду не показываем
+  const filteredTeams = teams.filter(team =>
+    !(user?.currentTeamId && String(team.id) === String(user.currentTeamId))
+  );
 
-  if (isLoading) {
+  if (isLoading && !hasLoaded) {
     return (
       <div className="flex items-center justify-center min-h-[400px]">
         <span className="loading loading-spinner loading-lg text-primary" />
@@ -276,7 +299,7 @@ export function TeamBrowser() {
             <Search className="absolute left-3 top-1/2 -translate-y-1/2 w-4 h-4 text-base-content/40" />
             <input
               type="text"
-              placeholder="Поиск команд..."
+              placeholder="Поиск по названию и описанию..."
               value={searchQuery}
               onChange={e => setSearchQuery(e.target.value)}
               className="input input-bordered input-sm w-full pl-9"
@@ -334,6 +357,19 @@ export function TeamBrowser() {
             ))}
           </div>
         )}
+
+        {nextCursor && (
+          <div className="flex justify-center mt-4">
+            <button
+              className="btn btn-sm btn-outline"
+              onClick={() => fetchTeams(nextCursor)}
+              disabled={isLoadingMore || isLoading}
+            >
+              {isLoadingMore && <span className="loading loading-spinner loading-xs"></span>}
+              Загрузить ещё ({teams.length} из {totalTeams})
+            </button>
+          </div>
+        )}
       </div>
     </div>
   );
@@ -3,10 +3,10 @@ import { useNavigate } from 'react-router-dom';
 import { Shield, Eye, EyeOff, ArrowLeft, Lock } from 'lucide-react';
 import { ROUTES } from '../../routes';
 import { useAuthStore, useUIStore } from '../../store/useStore';
-import axiosClient from '../../api/axiosClient';
+import axiosClient, { tokenUtils } from '../../api/axiosClient';
 
 /**
- * AdminPasswordPage - Страница ввода пароля администратора
+ * AdminPasswordPage - Страница входа администратора (логин, пароль и TOTP код)
  * Доступна только после ввода секретной команды /login-admin
  */
 export function AdminPasswordPage() {
@@ -14,7 +14,10 @@ export function AdminPasswordPage() {
   const { theme } = useUIStore();
   const { setUser, setToken } = useAuthStore();
   
+  const [username, setUsername] = useState('');
   const [password, setPassword] = useState('');
+  const [totpCode, setTotpCode] = useState('');
+  const [totpRequired, setTotpRequired] = useState(false);
   const [showPassword, setShowPassword] = useState(false);
   const [error, setError] = useState('');
   const [isLoading, setIsLoading] = useState(false);
@@ -26,13 +29,20 @@ export function AdminPasswordPage() {
     setIsLoading(true);
 
     try {
-      const response = await axiosClient.post('/admin/api/login', { password });
+      const response = await axiosClient.post('/admin/api/login', {
+        username,
+        password,
+        totpCode: totpCode || undefined,
+      });
       
       if (response.data.token && response.data.user) {
         setToken(response.data.token);
+        if (response.data.refreshToken) {
+          tokenUtils.setRefreshToken(response.data.refreshToken);
+        }
         setUser({
           id: String(response.data.user.id),
-          name: response.data.user.name,
+          name: response.data.user.name || response.data.user.username,
           role: 'admin',
           status: 'inactive',
           skills: [],
@@ -47,6 +57,16 @@ export function AdminPasswordPage() {
         navigate(ROUTES.ADMIN_DASHBOARD);
       }
     } catch (err: any) {
+      // Пароль верный, но включён второй фактор - просим код
+      if (err.response?.data?.code === 'TOTP_REQUIRED') {
+        setTotpRequired(true);
+        setError('Введите код из приложения-аутентификатора');
+        return;
+      }
+      if (err.response?.status === 429) {
+        setError('Слишком много неудачных попыток. Попробуйте позже.');
+        return;
+      }
       setAttempts(prev => prev + 1);
       if (attempts >= 2) {
         setError('Превышено количество попыток. Возврат на главную...');
@@ -54,7 +74,7 @@ export function AdminPasswordPage() {
           navigate(ROUTES.HOME);
         }, 2000);
       } else {
-        setError(`Неверный пароль. Осталось попыток: ${2 - attempts}`);
+        setError(`Неверный логин, пароль или код. Осталось попыток: ${2 - attempts}`);
       }
     } finally {
       setIsLoading(false);
@@ -83,12 +103,31 @@ export function AdminPasswordPage() {
           <div className="alert alert-warning mb-4">
             <Lock className="w-5 h-5" />
             <span className="text-sm">
-              Доступ ограничен. Введите пароль администратора для продолжения.
+              Доступ ограничен. Войдите под своей учётной записью администратора.
             </span>
           </div>
 
           {/* Form */}
           <form onSubmit={handleSubmit} className="space-y-4">
+            <div className="form-control">
+              <label className="label">
+                <span className="label-text">Логин</span>
+              </label>
+              <input
+                type="text"
+                placeholder="Введите логин..."
+                className={`input input-bordered w-full ${error ? 'input-error' : ''}`}
+                value={username}
+                onChange={(e) => {
+                  setUsername(e.target.value);
+                  setError('');
+                }}
+                disabled={isLoading || attempts >= 3}
+                autoComplete="username"
+                autoFocus
+              />
+            </div>
+
             <div className="form-control">
               <label className="label">
                 <span className="label-text">Пароль администратора</span>
@@ -104,7 +143,7 @@ export function AdminPasswordPage() {
                     setError('');
                   }}
                   disabled={isLoading || attempts >= 3}
-                  autoFocus
+                  autoComplete="current-password"
                 />
                 <button
                   type="button"
@@ -120,6 +159,29 @@ export function AdminPasswordPage() {
               </div>
             </div>
 
+            {totpRequired && (
+              <div className="form-control">
+                <label className="label">
+                  <span className="label-text">Код подтверждения</span>
+                </label>
+                <input
+                  type="text"
+                  inputMode="numeric"
+                  placeholder="123456"
+                  maxLength={6}
+                  className={`input input-bordered w-full tracking-widest ${error ? 'input-error' : ''}`}
+                  value={totpCode}
+                  onChange={(e) => {
+                    setTotpCode(e.target.value.replace(/\D/g, ''));
+                    setError('');
+                  }}
+                  disabled={isLoading || attempts >= 3}
+                  autoComplete="one-time-code"
+                  autoFocus
+                />
+              </div>
+            )}
+
             {error && (
               <div className="alert alert-error">
                 <span className="text-sm">{error}</span>
@@ -129,7 +191,7 @@ export function AdminPasswordPage() {
             <button
               type="submit"
               className="btn btn-primary w-full"
-              disabled={!password || isLoading || attempts >= 3}
+              disabled={!username || !password || (totpRequired && totpCode.length !== 6) || isLoading || attempts >= 3}
             >
               {isLoading ? (
                 <>
@@ -3,8 +3,9 @@ import { useNavigate, useSearchParams } from 'react-router-dom';
 import { Loader2, CheckCircle, XCircle, ArrowLeft, Copy, Check } from 'lucide-react';
 import { useAuthStore } from '../../store/useStore';
 import { ROUTES } from '../../routes';
-import axiosClient from '../../api/axiosClient';
+import axiosClient, { tokenUtils } from '../../api/axiosClient';
 import { transformUserFromBackend } from '../../api/services';
+import { createLoginChallenge, getLoginVerifier, clearLoginVerifier } from '../../utils/loginChallenge';
 
 const TELEGRAM_BOT_USERNAME = 'itam_chan_bot';
 
@@ -13,7 +14,8 @@ const TELEGRAM_BOT_USERNAME = 'itam_chan_bot';
  * 
  * Флоу:
  * 1. Пользователь нажимает "Войти через Telegram" → открывается бот
- * 2. Бот выдаёт токен (действует 10 минут)
+ *    (в ссылке передаётся challenge, verifier остаётся в этом браузере)
+ * 2. Бот выдаёт одноразовый токен (действует 10 минут)
  * 3. Пользователь вводит токен на этой странице ИЛИ переходит по ссылке с токеном
  * 4. Фронтенд отправляет токен на /api/token
  * 5. Бэкенд возвращает JWT → пользователь авторизован
@@ -56,11 +58,19 @@ export function TokenAuthPage() {
     setError(null);
 
     try {
-      const response = await axiosClient.post('/api/token', { token: tokenToUse });
+      const response = await axiosClient.post('/api/token', {
+        token: tokenToUse,
+        codeVerifier: getLoginVerifier(),
+      });
       
       if (response.data.token && response.data.user) {
+        clearLoginVerifier();
+
         // Сохраняем JWT токен
         setToken(response.data.token);
+        if (response.data.refreshToken) {
+          tokenUtils.setRefreshToken(response.data.refreshToken);
+        }
         
         // Используем transformUserFromBackend для правильного преобразования данных
         // (включая skills из строкового массива в объекты UserSkill)
@@ -93,7 +103,9 @@ export function TokenAuthPage() {
       }
     } catch (err: any) {
       console.error('Token auth error:', err);
-      if (err.response?.status === 400) {
+      if (err.response?.status === 429) {
+        setError('Слишком много неудачных попыток. Попробуйте позже.');
+      } else if (err.response?.status === 400) {
         setError('Неверный или истёкший токен. Получите новый в боте.');
       } else {
         setError('Ошибка авторизации. Попробуйте снова.');
@@ -103,8 +115,17 @@ export function TokenAuthPage() {
     }
   };
 
-  const openTelegramBot = () => {
-    window.open(`https://t.me/${TELEGRAM_BOT_USERNAME}?start=login`, '_blank');
+  const openTelegramBot = async () => {
+    // Окно открываем сразу, иначе после await браузер может заблокировать popup
+    const botWindow = window.open('', '_blank');
+    const challenge = await createLoginChallenge();
+    const start = challenge ? `login_${challenge}` : 'login';
+    const url = `https://t.me/${TELEGRAM_BOT_USERNAME}?start=${start}`;
+    if (botWindow) {
+      botWindow.location.href = url;
+    } else {
+      window.location.href = url;
+    }
   };
 
   const copyBotLink = () => {
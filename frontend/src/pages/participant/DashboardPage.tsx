@@ -10,7 +10,7 @@ import { useEffect } from 'react';
 export function DashboardPage() {
   const navigate = useNavigate();
   const { user, becomeCaptain } = useAuthStore();
-  const { selectedHackathon, fetchHackathons, hackathons, selectHackathon } = useHackathonStore();
+  const { selectedHackathon, fetchHackathons, hackathons, hackathonsTotal, selectHackathon } = useHackathonStore();
   const { invites, fetchInvites } = useInviteStore();
 
   useEffect(() => {
@@ -129,7 +129,7 @@ export function DashboardPage() {
               <h3 className="font-semibold">Хакатоны</h3>
               <p className="text-sm text-base-content/60">Найти и выбрать хакатон</p>
             </div>
-            <div className="badge badge-warning">{hackathons.length}</div>
+            <div className="badge badge-warning">{hackathonsTotal}</div>
           </div>
         </div>
 
@@ -160,12 +160,18 @@ export const useFetchHackathons = () => {
   const setLoading = (loading: boolean) => 
     useHackathonStore.setState({ isLoading: loading });
   
+  // Первая страница; следующие - useHackathonStore().fetchMoreHackathons
   const fetchHackathons = async () => {
     setLoading(true);
     
     try {
-      const hackathons = await hackathonService.getAll();
-      useHackathonStore.setState({ hackathons, isLoading: false });
+      const page = await hackathonService.getPage();
+      useHackathonStore.setState({
+        hackathons: page.items,
+        hackathonsCursor: page.nextCursor,
+        hackathonsTotal: page.total,
+        isLoading: false,
+      });
     } catch (error) {
       console.error('Failed to fetch hackathons:', error);
       setLoading(false);
@@ -177,7 +183,13 @@ export const useFetchHackathons = () => {
     
     try {
       const hackathons = await hackathonService.getActive();
-      useHackathonStore.setState({ hackathons, isLoading: false });
+      // Активные приходят одним списком, догружать нечего
+      useHackathonStore.setState({
+        hackathons,
+        hackathonsCursor: null,
+        hackathonsTotal: hackathons.length,
+        isLoading: false,
+      });
     } catch (error) {
       console.error('Failed to fetch active hackathons:', error);
       setLoading(false);
@@ -264,12 +276,18 @@ export const useSwipeAPI = () => {
 export const useTeamAPI = () => {
   const { teams, currentTeam, teamMembers } = useTeamStore();
   
+  // Первая страница; следующие - useTeamStore().fetchMoreTeams
   const fetchTeams = async (hackathonId?: string) => {
     useTeamStore.setState({ isLoading: true });
     
     try {
-      const teams = await teamService.getAll(hackathonId);
-      useTeamStore.setState({ teams, isLoading: false });
+      const page = await teamService.getPage(hackathonId);
+      useTeamStore.setState({
+        teams: page.items,
+        teamsCursor: page.nextCursor,
+        teamsTotal: page.total,
+        isLoading: false,
+      });
     } catch (error) {
       console.error('Failed to fetch teams:', error);
       useTeamStore.setState({ isLoading: false });
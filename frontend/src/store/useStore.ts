@@ -247,13 +247,19 @@ export const useAuthStore = create<AuthStore>()(
 // HACKATHON STORE
 // ============================================
 interface HackathonStore {
+  // Загруженные страницы хакатонов
   hackathons: Hackathon[];
+  // Курсор следующей страницы; null - загружено всё
+  hackathonsCursor: string | null;
+  hackathonsTotal: number;
   selectedHackathon: Hackathon | null;
   isLoading: boolean;
+  isLoadingMore: boolean;
   error: string | null;
   
   // Actions
   fetchHackathons: () => Promise<void>;
+  fetchMoreHackathons: () => Promise<void>;
   selectHackathon: (id: string) => void;
   createHackathon: (data: Omit<Hackathon, 'id' | 'createdAt' | 'participantsCount' | 'teamsCount'>) => Promise<void>;
   updateHackathon: (id: string, data: Partial<Hackathon>) => Promise<void>;
@@ -262,15 +268,24 @@ interface HackathonStore {
 
 export const useHackathonStore = create<HackathonStore>((set, get) => ({
   hackathons: [],
+  hackathonsCursor: null,
+  hackathonsTotal: 0,
   selectedHackathon: null,
   isLoading: false,
+  isLoadingMore: false,
   error: null,
 
+  // Первая страница; следующие - fetchMoreHackathons
   fetchHackathons: async () => {
     set({ isLoading: true, error: null });
     try {
-      const hackathons = await hackathonService.getAll();
-      set({ hackathons, isLoading: false });
+      const page = await hackathonService.getPage();
+      set({
+        hackathons: page.items,
+        hackathonsCursor: page.nextCursor,
+        hackathonsTotal: page.total,
+        isLoading: false,
+      });
     } catch (error) {
       console.error('Fetch hackathons error:', error);
       set({ 
@@ -280,6 +295,27 @@ export const useHackathonStore = create<HackathonStore>((set, get) => ({
     }
   },
 
+  fetchMoreHackathons: async () => {
+    const { hackathonsCursor, isLoadingMore } = get();
+    if (!hackathonsCursor || isLoadingMore) return;
+    set({ isLoadingMore: true, error: null });
+    try {
+      const page = await hackathonService.getPage({ cursor: hackathonsCursor });
+      set(state => ({
+        hackathons: [...state.hackathons, ...page.items],
+        hackathonsCursor: page.nextCursor,
+        hackathonsTotal: page.total,
+        isLoadingMore: false,
+      }));
+    } catch (error) {
+      console.error('Fetch more hackathons error:', error);
+      set({ 
+        isLoadingMore: false, 
+        error: error instanceof Error ? error.message : 'Ошибка загрузки хакатонов'
+      });
+    }
+  },
+
   selectHackathon: (id) => {
     const hackathon = get().hackathons.find(h => h.id === id) || null;
     set({ selectedHackathon: hackathon });
@@ -300,6 +336,7 @@ export const useHackathonStore = create<HackathonStore>((set, get) => ({
       });
       set(state => ({ 
         hackathons: [...state.hackathons, newHackathon],
+        hackathonsTotal: state.hackathonsTotal + 1,
         isLoading: false 
       }));
     } catch (error) {
@@ -343,6 +380,7 @@ export const useHackathonStore = create<HackathonStore>((set, get) => ({
       await hackathonService.delete(id);
       set(state => ({
         hackathons: state.hackathons.filter(h => h.id !== id),
+        hackathonsTotal: Math.max(0, state.hackathonsTotal - 1),
         isLoading: false,
       }));
     } catch (error) {
@@ -455,14 +493,20 @@ export const useSwipeStore = create<SwipeStore>((set, get) => ({
 // TEAM STORE
 // ============================================
 interface TeamStore {
+  // Загруженные страницы команд
   teams: Team[];
+  // Курсор следующей страницы; null - загружено всё
+  teamsCursor: string | null;
+  teamsTotal: number;
   currentTeam: Team | null;
   teamMembers: User[];
   isLoading: boolean;
+  isLoadingMore: boolean;
   error: string | null;
   
   // Actions
   fetchTeams: (hackathonId?: string) => Promise<void>;
+  fetchMoreTeams: (hackathonId?: string) => Promise<void>;
   fetchMyTeam: () => Promise<void>;
   createTeam: (data: { name: string; hackathonId: string; description?: string }) => Promise<Team | null>;
   joinTeam: (teamId: string, code?: string) => Promise<void>;
@@ -472,18 +516,27 @@ interface TeamStore {
   updateTeamStatus: (teamId: string, status: 'looking' | 'closed') => Promise<void>;
 }
 
-export const useTeamStore = create<TeamStore>((set) => ({
+export const useTeamStore = create<TeamStore>((set, get) => ({
   teams: [],
+  teamsCursor: null,
+  teamsTotal: 0,
   currentTeam: null,
   teamMembers: [],
   isLoading: false,
+  isLoadingMore: false,
   error: null,
 
+  // Первая страница; следующие - fetchMoreTeams
   fetchTeams: async (hackathonId) => {
     set({ isLoading: true, error: null });
     try {
-      const teams = await teamService.getAll(hackathonId);
-      set({ teams, isLoading: false });
+      const page = await teamService.getPage(hackathonId);
+      set({
+        teams: page.items,
+        teamsCursor: page.nextCursor,
+        teamsTotal: page.total,
+        isLoading: false,
+      });
     } catch (error) {
       console.error('Fetch teams error:', error);
       set({ 
@@ -493,6 +546,27 @@ export const useTeamStore = create<TeamStore>((set) => ({
     }
   },
 
+  fetchMoreTeams: async (hackathonId) => {
+    const { teamsCursor, isLoadingMore } = get();
+    if (!teamsCursor || isLoadingMore) return;
+    set({ isLoadingMore: true, error: null });
+    try {
+      const page = await teamService.getPage(hackathonId, { cursor: teamsCursor });
+      set(state => ({
+        teams: [...state.teams, ...page.items],
+        teamsCursor: page.nextCursor,
+        teamsTotal: page.total,
+        isLoadingMore: false,
+      }));
+    } catch (error) {
+      console.error('Fetch more teams error:', error);
+      set({ 
+        isLoadingMore: false, 
+        error: error instanceof Error ? error.message : 'Ошибка загрузки команд'
+      });
+    }
+  },
+
   fetchMyTeam: async () => {
     set({ isLoading: true, error: null });
     try {
@@ -527,6 +601,7 @@ export const useTeamStore = create<TeamStore>((set) => ({
         : [];
       set(state => ({ 
         teams: [...state.teams, newTeam],
+        teamsTotal: state.teamsTotal + 1,
         currentTeam: newTeam,
         teamMembers: members,
         isLoading: false,
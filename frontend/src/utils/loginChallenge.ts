@@ -0,0 +1,41 @@
+/**
+ * PKCE-подобная привязка токена входа из TG бота к браузеру.
+ *
+ * Браузер генерирует verifier, передаёт боту только challenge = base64url(sha256(verifier))
+ * через ссылку t.me/<bot>?start=login_<challenge>, а при обмене токена на /api/token
+ * отправляет сам verifier. Украденный токен без verifier бесполезен.
+ */
+
+const VERIFIER_KEY = 'login_code_verifier';
+
+function base64url(bytes: Uint8Array): string {
+  let binary = '';
+  bytes.forEach((b) => {
+    binary += String.fromCharCode(b);
+  });
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+}
+
+/**
+ * Создать новый verifier и вернуть challenge для ссылки на бота.
+ * Возвращает null, если WebCrypto недоступен (например, не https) - тогда вход без привязки.
+ */
+export async function createLoginChallenge(): Promise<string | null> {
+  if (!window.crypto?.subtle) {
+    return null;
+  }
+
+  const verifier = base64url(window.crypto.getRandomValues(new Uint8Array(32)));
+  const digest = await window.crypto.subtle.digest('SHA-256', new TextEncoder().encode(verifier));
+  sessionStorage.setItem(VERIFIER_KEY, verifier);
+  return base64url(new Uint8Array(digest));
+}
+
+/** Текущий verifier, если вход начинали из этого браузера */
+export function getLoginVerifier(): string | undefined {
+  return sessionStorage.getItem(VERIFIER_KEY) || undefined;
+}
+
+export function clearLoginVerifier() {
+  sessionStorage.removeItem(VERIFIER_KEY);
+}
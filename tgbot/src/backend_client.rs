@@ -0,0 +1,71 @@
+use anyhow::Result;
+use hmac::{Hmac, Mac};
+use rand::distributions::Alphanumeric;
+use rand::{thread_rng, Rng};
+use reqwest::Method;
+use sha2::{Digest, Sha256};
+use std::env;
+
+/// Service ID the backend knows the bot secret under
+const SERVICE_ID: &str = "tgbot";
+
+pub fn backend_url() -> String {
+    env::var("BACKEND_URL").unwrap_or_else(|_| "http://backend:8080".to_string())
+}
+
+/// Builds a request to a service-authenticated backend endpoint (`/api/bot/...`).
+///
+/// The signature is HMAC-SHA256 with `BOT_SERVICE_SECRET` over
+/// `METHOD\nPATH?QUERY\nTIMESTAMP\nNONCE\nhex(sha256(body))`,
+/// matching `middleware.ServiceAuthMiddleware` on the backend.
+pub fn signed_request(
+    client: &reqwest::Client,
+    method: Method,
+    path_and_query: &str,
+    body: Option<&serde_json::Value>,
+) -> Result<reqwest::RequestBuilder> {
+    let secret = env::var("BOT_SERVICE_SECRET")
+        .map_err(|_| anyhow::anyhow!("BOT_SERVICE_SECRET is not set"))?;
+
+    let body_bytes = match body {
+        Some(value) => serde_json::to_vec(value)?,
+        None => Vec::new(),
+    };
+
+    let timestamp = chrono::Utc::now().timestamp().to_string();
+    let nonce: String = thread_rng()
+        .sample_iter(&Alphanumeric)
+        .take(32)
+        .map(char::from)
+        .collect();
+
+    let string_to_sign = format!(
+        "{}\n{}\n{}\n{}\n{}",
+        method.as_str(),
+        path_and_query,
+        timestamp,
+        nonce,
+        hex::encode(Sha256::digest(&body_bytes)),
+    );
+
+    let mut mac = Hmac::<Sha256>::new_from_slice(secret.as_bytes())
+        .map_err(|e| anyhow::anyhow!("Invalid BOT_SERVICE_SECRET: {}", e))?;
+    mac.update(string_to_sign.as_bytes());
+    let signature = hex::encode(mac.finalize().into_bytes());
+
+    let url = format!("{}{}", backend_url(), path_and_query);
+    let mut request = client
+        .request(method, url)
+        .header("X-Service-Id", SERVICE_ID)
+        .header("X-Service-Timestamp", timestamp)
+        .header("X-Service-Nonce", nonce)
+        .header("X-Service-Signature", signature);
+
+    if body.is_some() {
+        request = request
+            .header(reqwest::header::CONTENT_TYPE, "application/json")
+            .body(body_bytes);
+    }
+
+    Ok(request)
+}
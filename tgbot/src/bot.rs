@@ -1,18 +1,15 @@
-use chrono::Local;
-use rand::distributions::Alphanumeric;
-use rand::{thread_rng, Rng};
 use teloxide::prelude::*;
 use teloxide::types::{InlineKeyboardButton, InlineKeyboardMarkup, ParseMode};
 use teloxide::utils::command::BotCommands;
 use teloxide::utils::markdown::escape;
 
-use crate::redis_client;
+use crate::{backend_client, login_tokens};
 
 #[derive(BotCommands, Clone)]
 #[command(rename_rule = "lowercase", description = "Доступные команды:")]
 pub enum Command {
     #[command(description = "начать работу с ботом")]
-    Start,
+    Start(String),
     #[command(description = "получить токен для входа на сайт")]
     Login,
     #[command(description = "информация о системе")]
@@ -26,11 +23,19 @@ pub enum Command {
 /// Handles bot command responses
 pub async fn answer(bot: Bot, msg: Message, cmd: Command) -> ResponseResult<()> {
     match cmd {
-        Command::Start => {
-            handle_start(&bot, &msg).await?;
+        Command::Start(payload) => {
+            // The site opens the bot via t.me/<bot>?start=login or ?start=login_<challenge>
+            if payload == "login" {
+                handle_login(&bot, &msg, None).await?;
+            } else if let Some(challenge) = payload.strip_prefix("login_") {
+                let challenge = Some(challenge).filter(|c| login_tokens::is_valid_challenge(c));
+                handle_login(&bot, &msg, challenge).await?;
+            } else {
+                handle_start(&bot, &msg).await?;
+            }
         }
         Command::Login => {
-            handle_login(&bot, &msg).await?;
+            handle_login(&bot, &msg, None).await?;
         }
         Command::Info => {
             handle_info(&bot, &msg).await?;
@@ -87,9 +92,13 @@ async fn handle_start(bot: &Bot, msg: &Message) -> ResponseResult<()> {
     Ok(())
 }
 
-/// Handle /login command
-async fn handle_login(bot: &Bot, msg: &Message) -> ResponseResult<()> {
-    let token = match generate_unique_token(msg).await {
+/// Handle /login command and `/start login[_<challenge>]` deep links
+async fn handle_login(bot: &Bot, msg: &Message, challenge: Option<&str>) -> ResponseResult<()> {
+    let Some(user) = msg.from() else {
+        return Ok(());
+    };
+
+    let token = match login_tokens::issue_login_token(user, challenge).await {
         Ok(t) => t,
         Err(e) => {
             log::error!("Error generating token: {}", e);
@@ -99,15 +108,7 @@ async fn handle_login(bot: &Bot, msg: &Message) -> ResponseResult<()> {
         }
     };
 
-    let token_md = escape(&token);
-
-    let text = format!(
-        "🔐 *Ваш токен для авторизации:*\n\n\
-        `{}`\n\n\
-        ⏰ Токен действителен *10 минут*\n\n\
-        📋 Скопируйте токен и вставьте его на сайте для входа\\.",
-        token_md
-    );
+    let text = login_tokens::login_token_message(&token, challenge.is_some());
 
     bot.send_message(msg.chat.id, text)
         .parse_mode(ParseMode::MarkdownV2)
@@ -211,11 +212,12 @@ async fn handle_help(bot: &Bot, msg: &Message) -> ResponseResult<()> {
 
 /// Get notification status from backend
 pub async fn get_notification_status(telegram_id: i64) -> anyhow::Result<(bool, String)> {
-    let backend_url = std::env::var("BACKEND_URL").unwrap_or_else(|_| "http://backend:8080".to_string());
-    let url = format!("{}/api/bot/notifications/{}", backend_url, telegram_id);
+    let path = format!("/api/bot/notifications/{}", telegram_id);
     
     let client = reqwest::Client::new();
-    let response = client.get(&url).send().await?;
+    let response = backend_client::signed_request(&client, reqwest::Method::GET, &path, None)?
+        .send()
+        .await?;
     
     if response.status().is_success() {
         let data: serde_json::Value = response.json().await?;
@@ -229,13 +231,11 @@ pub async fn get_notification_status(telegram_id: i64) -> anyhow::Result<(bool,
 
 /// Update notification settings via backend
 pub async fn update_notification_settings(telegram_id: i64, enabled: bool) -> anyhow::Result<()> {
-    let backend_url = std::env::var("BACKEND_URL").unwrap_or_else(|_| "http://backend:8080".to_string());
-    let url = format!("{}/api/bot/notifications/{}", backend_url, telegram_id);
+    let path = format!("/api/bot/notifications/{}", telegram_id);
+    let body = serde_json::json!({ "enabled": enabled });
     
     let client = reqwest::Client::new();
-    let response = client
-        .put(&url)
-        .json(&serde_json::json!({ "enabled": enabled }))
+    let response = backend_client::signed_request(&client, reqwest::Method::PUT, &path, Some(&body))?
         .send()
         .await?;
     
@@ -245,36 +245,3 @@ pub async fn update_notification_settings(telegram_id: i64, enabled: bool) -> an
         Err(anyhow::anyhow!("Failed to update settings"))
     }
 }
-
-/// Generates a unique token for user authentication and saves it to Redis
-async fn generate_unique_token(msg: &Message) -> anyhow::Result<String> {
-    use ::redis::AsyncCommands;
-    let mut redis_conn = redis_client::create_redis_conn().await?;
-
-    loop {
-        let token = random_string();
-        let exists: bool = redis_conn.exists(&token).await?;
-
-        if !exists {
-            let user_id_u64 = msg.from().map(|u| u.id.0).unwrap_or(0);
-            let username = msg
-                .from()
-                .and_then(|u| u.username.clone())
-                .unwrap_or_else(|| "-".into());
-
-            let time = Local::now().format("%H:%M").to_string();
-            let value = format!("{};{};{}", user_id_u64, username, time);
-
-            let _: () = redis_conn.set_ex(&token, &value, 600).await?;
-
-            log::info!("Token saved to Redis - key: {}, value: {}", token, value);
-
-            return Ok(token);
-        }
-    }
-}
-
-fn random_string() -> String {
-    let mut rng = thread_rng();
-    (0..16).map(|_| rng.sample(Alphanumeric) as char).collect()
-}
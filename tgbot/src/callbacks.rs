@@ -3,6 +3,7 @@ use teloxide::prelude::*;
 use teloxide::types::{CallbackQuery, InlineKeyboardButton, InlineKeyboardMarkup, ParseMode};
 use serde::{Deserialize, Serialize};
 
+use crate::backend_client;
 use crate::bot::{get_notification_status, update_notification_settings};
 
 /// Response from backend for join request actions
@@ -12,6 +13,20 @@ struct JoinRequestResponse {
     message: Option<String>,
 }
 
+/// Error body returned by the backend: `error` is human-readable, `code` is stable
+#[derive(Deserialize)]
+struct ApiError {
+    #[serde(default)]
+    code: String,
+}
+
+/// Extract the machine-readable error code from a backend error body
+fn error_code(body: &str) -> String {
+    serde_json::from_str::<ApiError>(body)
+        .map(|e| e.code)
+        .unwrap_or_default()
+}
+
 /// Request body for responding to join requests
 #[derive(Serialize)]
 struct RespondToJoinRequest {
@@ -111,12 +126,13 @@ async fn handle_join_response(
                 let error_text = response.text().await.unwrap_or_default();
                 log::error!("Backend error: {} - {}", status, error_text);
                 
-                if error_text.contains("already processed") || error_text.contains("not found") {
-                    "⚠️ Эта заявка уже была обработана ранее."
-                } else if error_text.contains("team is full") {
-                    "⚠️ Команда уже заполнена. Нельзя принять нового участника."
-                } else {
-                    "❌ Ошибка при обработке заявки. Попробуйте позже."
+                match error_code(&error_text).as_str() {
+                    "ALREADY_PROCESSED" | "JOIN_REQUEST_NOT_FOUND" => {
+                        "⚠️ Эта заявка уже была обработана ранее."
+                    }
+                    "TEAM_FULL" => "⚠️ Команда уже заполнена. Нельзя принять нового участника.",
+                    "ALREADY_IN_TEAM" => "⚠️ Пользователь уже состоит в другой команде.",
+                    _ => "❌ Ошибка при обработке заявки. Попробуйте позже.",
                 }
             }
         }
@@ -160,25 +176,22 @@ async fn handle_invite_response(
         .await?;
     
     // Call backend API to process the invite
-    let backend_url = env::var("BACKEND_URL").unwrap_or_else(|_| "http://backend:8080".to_string());
-    
-    // Get user's telegram ID to find their auth token
     let telegram_id = q.from.id.0 as i64;
     
-    // Get user's JWT token from backend (need to implement this or use a different approach)
-    // For now, we'll call a special bot endpoint that doesn't require auth
-    let url = if accept {
-        format!("{}/api/bot/invites/{}/accept?telegramId={}", backend_url, invite_id, telegram_id)
+    // Bot endpoints act on behalf of the user by telegram ID and are authorized
+    // by the bot's signature, not by the user's JWT
+    let path = if accept {
+        format!("/api/bot/invites/{}/accept?telegramId={}", invite_id, telegram_id)
     } else {
-        format!("{}/api/bot/invites/{}/decline?telegramId={}", backend_url, invite_id, telegram_id)
+        format!("/api/bot/invites/{}/decline?telegramId={}", invite_id, telegram_id)
     };
     
     let client = reqwest::Client::new();
     
-    let result = client
-        .post(&url)
-        .send()
-        .await;
+    let result = match backend_client::signed_request(&client, reqwest::Method::POST, &path, None) {
+        Ok(request) => request.send().await.map_err(anyhow::Error::from),
+        Err(e) => Err(e),
+    };
     
     let response_text = match result {
         Ok(response) => {
@@ -193,12 +206,14 @@ async fn handle_invite_response(
                 let error_text = response.text().await.unwrap_or_default();
                 log::error!("Backend error: {} - {}", status, error_text);
                 
-                if error_text.contains("already processed") || error_text.contains("not found") {
-                    "⚠️ Это приглашение уже было обработано ранее."
-                } else if error_text.contains("not your invite") {
-                    "⚠️ Это приглашение адресовано другому пользователю."
-                } else {
-                    "❌ Ошибка при обработке приглашения. Попробуйте позже."
+                match error_code(&error_text).as_str() {
+                    "ALREADY_PROCESSED" | "INVITE_NOT_FOUND" => {
+                        "⚠️ Это приглашение уже было обработано ранее."
+                    }
+                    "NOT_INVITEE" => "⚠️ Это приглашение адресовано другому пользователю.",
+                    "TEAM_FULL" => "⚠️ Команда уже заполнена.",
+                    "ALREADY_IN_TEAM" => "⚠️ Вы уже состоите в команде на этом хакатоне.",
+                    _ => "❌ Ошибка при обработке приглашения. Попробуйте позже.",
                 }
             }
         }
@@ -227,22 +242,16 @@ async fn handle_invite_response(
 
 /// Handle get_token callback - generate and show token
 async fn handle_get_token(bot: &Bot, q: &CallbackQuery) -> ResponseResult<()> {
-    use chrono::Local;
-    use rand::distributions::Alphanumeric;
-    use rand::{thread_rng, Rng};
-    use crate::redis_client;
-    use ::redis::AsyncCommands;
-    use teloxide::utils::markdown::escape;
+    use crate::login_tokens;
     
     bot.answer_callback_query(&q.id)
         .text("Генерирую токен...")
         .await?;
     
-    // Generate token
-    let mut redis_conn = match redis_client::create_redis_conn().await {
-        Ok(conn) => conn,
+    let token = match login_tokens::issue_login_token(&q.from, None).await {
+        Ok(t) => t,
         Err(e) => {
-            log::error!("Redis connection error: {}", e);
+            log::error!("Error generating token: {}", e);
             if let Some(message) = &q.message {
                 bot.send_message(message.chat.id, "❌ Ошибка при генерации токена. Попробуйте позже.")
                     .await?;
@@ -251,23 +260,7 @@ async fn handle_get_token(bot: &Bot, q: &CallbackQuery) -> ResponseResult<()> {
         }
     };
     
-    let token: String = (0..16).map(|_| thread_rng().sample(Alphanumeric) as char).collect();
-    
-    let user_id_u64 = q.from.id.0;
-    let username = q.from.username.clone().unwrap_or_else(|| "-".into());
-    let time = Local::now().format("%H:%M").to_string();
-    let value = format!("{};{};{}", user_id_u64, username, time);
-    
-    let _: () = redis_conn.set_ex(&token, &value, 600).await.unwrap_or(());
-    
-    let token_md = escape(&token);
-    let text = format!(
-        "🔐 *Ваш токен для авторизации:*\n\n\
-        `{}`\n\n\
-        ⏰ Токен действителен *10 минут*\n\n\
-        📋 Скопируйте токен и вставьте его на сайте для входа\\.",
-        token_md
-    );
+    let text = login_tokens::login_token_message(&token, false);
     
     if let Some(message) = &q.message {
         bot.send_message(message.chat.id, text)
@@ -0,0 +1,97 @@
+use anyhow::Result;
+use rand::distributions::Alphanumeric;
+use rand::{thread_rng, Rng};
+use serde::Serialize;
+use sha2::{Digest, Sha256};
+use teloxide::types::User;
+
+use crate::redis_client;
+
+/// Login token lifetime in seconds; the backend enforces the same upper bound
+pub const LOGIN_TOKEN_TTL_SECS: i64 = 600;
+
+/// Payload format version understood by the backend (`services.LoginTokenPayload`)
+const LOGIN_TOKEN_VERSION: u8 = 1;
+
+#[derive(Serialize)]
+#[serde(rename_all = "camelCase")]
+struct LoginTokenPayload<'a> {
+    v: u8,
+    telegram_id: i64,
+    username: &'a str,
+    issued_at: i64,
+    expires_at: i64,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    challenge: Option<&'a str>,
+}
+
+/// Checks a PKCE-style challenge passed via `/start login_<challenge>`:
+/// base64url(sha256(verifier)) without padding is exactly 43 characters
+pub fn is_valid_challenge(challenge: &str) -> bool {
+    challenge.len() == 43
+        && challenge
+            .chars()
+            .all(|c| c.is_ascii_alphanumeric() || c == '-' || c == '_')
+}
+
+/// Issues a one-time login token for the user and stores it in Redis.
+///
+/// Only the SHA-256 of the token is used as the key, so a Redis dump does not
+/// contain usable tokens. With a challenge the token is bound to the browser
+/// that holds the matching verifier.
+pub async fn issue_login_token(user: &User, challenge: Option<&str>) -> Result<String> {
+    let mut conn = redis_client::create_redis_conn().await?;
+
+    let username = user.username.clone().unwrap_or_else(|| user.full_name());
+    let issued_at = chrono::Utc::now().timestamp();
+    let payload = serde_json::to_string(&LoginTokenPayload {
+        v: LOGIN_TOKEN_VERSION,
+        telegram_id: user.id.0 as i64,
+        username: &username,
+        issued_at,
+        expires_at: issued_at + LOGIN_TOKEN_TTL_SECS,
+        challenge,
+    })?;
+
+    loop {
+        let token: String = thread_rng()
+            .sample_iter(&Alphanumeric)
+            .take(16)
+            .map(char::from)
+            .collect();
+        let key = format!("login:token:{}", hex::encode(Sha256::digest(token.as_bytes())));
+
+        // SET NX: never overwrite somebody else's token on a collision
+        let created: Option<String> = ::redis::cmd("SET")
+            .arg(&key)
+            .arg(&payload)
+            .arg("NX")
+            .arg("EX")
+            .arg(LOGIN_TOKEN_TTL_SECS)
+            .query_async(&mut conn)
+            .await?;
+
+        if created.is_some() {
+            log::info!("Login token issued for user {}", user.id.0);
+            return Ok(token);
+        }
+    }
+}
+
+/// MarkdownV2 message with the token for the user
+pub fn login_token_message(token: &str, bound_to_browser: bool) -> String {
+    let hint = if bound_to_browser {
+        "📋 Вернитесь на сайт в том же браузере и вставьте токен\\. В другом браузере он не сработает\\."
+    } else {
+        "📋 Скопируйте токен и вставьте его на сайте для входа\\."
+    };
+
+    format!(
+        "🔐 *Ваш токен для авторизации:*\n\n\
+        `{}`\n\n\
+        ⏰ Токен действителен *10 минут* и только для одного входа\n\n\
+        {}",
+        teloxide::utils::markdown::escape(token),
+        hint
+    )
+}
@@ -1,5 +1,7 @@
+mod backend_client;
 mod bot;
 mod callbacks;
+mod login_tokens;
 mod notifications;
 mod redis_client;
 
@@ -5,7 +5,7 @@ use std::env;
 use teloxide::prelude::*;
 use teloxide::types::{InlineKeyboardButton, InlineKeyboardMarkup};
 
-use crate::redis_client;
+use crate::{backend_client, redis_client};
 
 #[derive(Deserialize)]
 pub struct AuthorizedUsersResponse {
@@ -50,6 +50,9 @@ pub struct Notification {
     pub user_name: Option<String>,
     #[serde(rename = "inviterName")]
     pub inviter_name: Option<String>,
+    /// X-Request-ID of the backend HTTP request that produced this event
+    #[serde(rename = "httpRequestId")]
+    pub http_request_id: Option<String>,
 }
 
 /// Consumes notifications from Redis stream and sends them to Telegram users
@@ -104,19 +107,28 @@ pub async fn consume_notifications_stream(bot: Bot) -> Result<()> {
                             }
                         }
 
+                        // W3C trace context of the backend request, for correlating with its traces
+                        if let Some(traceparent) = data.get("traceparent") {
+                            log::debug!("Message {} traceparent={}", stream_id.id, traceparent);
+                        }
+
                         if let Some(json_data) = data.get("data") {
                             log::debug!("Parsing notification data: {}", json_data);
                             
                             // Try to parse as structured notification first
                             if let Ok(notification) = serde_json::from_str::<Notification>(json_data) {
-                                log::info!("Received structured notification: {:?}", notification);
+                                let request_id = notification.http_request_id.as_deref().unwrap_or("-");
+                                log::info!(
+                                    "Received notification type={:?} request_id={}",
+                                    notification.notification_type, request_id
+                                );
                                 
                                 match notification.notification_type.as_deref() {
                                     Some("join_request") => {
                                         // Send to specific user (team captain) with accept/reject buttons
                                         if let Some(target_user_id) = notification.target_user_id {
                                             if let Err(e) = send_join_request_notification(&bot, target_user_id, &notification).await {
-                                                log::error!("Error sending join request notification: {}", e);
+                                                log::error!("Error sending join request notification (request_id={}): {}", request_id, e);
                                             }
                                         } else {
                                             log::warn!("No target user for join_request notification");
@@ -126,7 +138,7 @@ pub async fn consume_notifications_stream(bot: Bot) -> Result<()> {
                                         // Send to user who is invited to join team with accept/reject buttons
                                         if let Some(target_user_id) = notification.target_user_id {
                                             if let Err(e) = send_team_invite_notification(&bot, target_user_id, &notification).await {
-                                                log::error!("Error sending team invite notification: {}", e);
+                                                log::error!("Error sending team invite notification (request_id={}): {}", request_id, e);
                                             }
                                         } else {
                                             log::warn!("No target user for team_invite notification");
@@ -136,15 +148,15 @@ pub async fn consume_notifications_stream(bot: Bot) -> Result<()> {
                                         // Send to the user who requested to join or sent invite
                                         if let Some(target_user_id) = notification.target_user_id {
                                             if let Err(e) = send_notification_to_user(&bot, target_user_id, &notification.message).await {
-                                                log::error!("Error sending response notification: {}", e);
+                                                log::error!("Error sending response notification (request_id={}): {}", request_id, e);
                                             }
                                         }
                                     }
                                     _ => {
                                         // General notification - send to all users
-                                        log::info!("Sending general notification: {}", notification.message);
+                                        log::info!("Sending general notification (request_id={})", request_id);
                                         if let Err(e) = send_notification_to_all_users(&bot, &notification.message).await {
-                                            log::error!("Error sending notifications: {}", e);
+                                            log::error!("Error sending notifications (request_id={}): {}", request_id, e);
                                         }
                                     }
                                 }
@@ -282,11 +294,17 @@ async fn send_notification_to_all_users(bot: &Bot, message: &str) -> Result<()>
 
 /// Check if user has notifications enabled
 async fn check_notifications_enabled(telegram_user_id: i64) -> bool {
-    let backend_url = env::var("BACKEND_URL").unwrap_or_else(|_| "http://backend:8080".to_string());
-    let url = format!("{}/api/bot/notifications/{}", backend_url, telegram_user_id);
+    let path = format!("/api/bot/notifications/{}", telegram_user_id);
     
     let client = reqwest::Client::new();
-    match client.get(&url).send().await {
+    let request = match backend_client::signed_request(&client, reqwest::Method::GET, &path, None) {
+        Ok(r) => r,
+        Err(e) => {
+            log::error!("Failed to build backend request: {}", e);
+            return true;
+        }
+    };
+    match request.send().await {
         Ok(response) if response.status().is_success() => {
             if let Ok(data) = response.json::<serde_json::Value>().await {
                 data.get("notificationsEnabled").and_then(|v| v.as_bool()).unwrap_or(true)